@@ -18,6 +18,7 @@ func main() {
 		serverAddress = flag.String("server", "localhost:8080", "File service server address")
 		batchMode     = flag.Bool("batch", false, "Run in batch mode")
 		timeout       = flag.Duration("timeout", 30*time.Second, "Connection timeout")
+		apiKey        = flag.String("api-key", "", "API key sent to the server")
 	)
 
 	flag.Parse()
@@ -26,7 +27,7 @@ func main() {
 	defer cancel()
 
 	fmt.Printf("Connection to file service at %s...\n", *serverAddress)
-	fileClient, err := file.NewClient(*serverAddress)
+	fileClient, err := file.NewClient(*serverAddress, *apiKey)
 	if err != nil {
 		log.Fatalf("FAILED TO CREATE CLIENT: %v", err)
 	}
@@ -25,6 +25,9 @@ type UploadFileRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Filename      string                 `protobuf:"bytes,1,opt,name=filename,proto3" json:"filename,omitempty"`
 	Data          []byte                 `protobuf:"bytes,2,opt,name=data,proto3" json:"data,omitempty"`
+	TtlSeconds    int64                  `protobuf:"varint,3,opt,name=ttl_seconds,json=ttlSeconds,proto3" json:"ttl_seconds,omitempty"`           // Delete the file this many seconds after upload, 0 - keep forever
+	ExpiresAt     int64                  `protobuf:"varint,4,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`              // Or delete it at this Unix time (seconds), 0 - keep forever; set at most one of the two
+	ReplaceFileId string                 `protobuf:"bytes,5,opt,name=replace_file_id,json=replaceFileId,proto3" json:"replace_file_id,omitempty"` // Store the upload as a new version of this file, which keeps its ID; expiry is kept unless set
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -73,9 +76,31 @@ func (x *UploadFileRequest) GetData() []byte {
 	return nil
 }
 
+func (x *UploadFileRequest) GetTtlSeconds() int64 {
+	if x != nil {
+		return x.TtlSeconds
+	}
+	return 0
+}
+
+func (x *UploadFileRequest) GetExpiresAt() int64 {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return 0
+}
+
+func (x *UploadFileRequest) GetReplaceFileId() string {
+	if x != nil {
+		return x.ReplaceFileId
+	}
+	return ""
+}
+
 type UploadFileResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	FileId        string                 `protobuf:"bytes,1,opt,name=file_id,json=fileId,proto3" json:"file_id,omitempty"`
+	Version       int32                  `protobuf:"varint,2,opt,name=version,proto3" json:"version,omitempty"` // Version of the file holding the uploaded content, 1 for a new file
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -117,9 +142,19 @@ func (x *UploadFileResponse) GetFileId() string {
 	return ""
 }
 
+func (x *UploadFileResponse) GetVersion() int32 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
 type GetFileRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	FileId        string                 `protobuf:"bytes,1,opt,name=file_id,json=fileId,proto3" json:"file_id,omitempty"`
+	Watermark     *WatermarkPolicy       `protobuf:"bytes,2,opt,name=watermark,proto3" json:"watermark,omitempty"` // Apply watermark on the fly, ignored if the credential enforces its own
+	Format        string                 `protobuf:"bytes,3,opt,name=format,proto3" json:"format,omitempty"`       // Convert image on the fly: png, jpeg, bmp or tiff; empty - as stored
+	Version       int32                  `protobuf:"varint,4,opt,name=version,proto3" json:"version,omitempty"`    // Fetch this version of the file, 0 - the latest
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -161,6 +196,27 @@ func (x *GetFileRequest) GetFileId() string {
 	return ""
 }
 
+func (x *GetFileRequest) GetWatermark() *WatermarkPolicy {
+	if x != nil {
+		return x.Watermark
+	}
+	return nil
+}
+
+func (x *GetFileRequest) GetFormat() string {
+	if x != nil {
+		return x.Format
+	}
+	return ""
+}
+
+func (x *GetFileRequest) GetVersion() int32 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
 type GetFileResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Filename      string                 `protobuf:"bytes,1,opt,name=filename,proto3" json:"filename,omitempty"`
@@ -215,6 +271,8 @@ func (x *GetFileResponse) GetData() []byte {
 
 type ListFilesRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
+	Color         string                 `protobuf:"bytes,1,opt,name=color,proto3" json:"color,omitempty"`                                  // Filter by dominant color (#rrggbb), empty - no filter
+	MaxDistance   float64                `protobuf:"fixed64,2,opt,name=max_distance,json=maxDistance,proto3" json:"max_distance,omitempty"` // Max RGB distance from the color to the image palette, 0 - server default
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -249,6 +307,20 @@ func (*ListFilesRequest) Descriptor() ([]byte, []int) {
 	return file_api_file_proto_rawDescGZIP(), []int{4}
 }
 
+func (x *ListFilesRequest) GetColor() string {
+	if x != nil {
+		return x.Color
+	}
+	return ""
+}
+
+func (x *ListFilesRequest) GetMaxDistance() float64 {
+	if x != nil {
+		return x.MaxDistance
+	}
+	return 0
+}
+
 type ListFilesResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Files         []*FileInfo            `protobuf:"bytes,1,rep,name=files,proto3" json:"files,omitempty"`
@@ -295,10 +367,28 @@ func (x *ListFilesResponse) GetFiles() []*FileInfo {
 
 type FileInfo struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	FileId        string                 `protobuf:"bytes,1,opt,name=file_id,json=fileId,proto3" json:"file_id,omitempty"`
+	FileId        string                 `protobuf:"bytes,1,opt,name=file_id,json=fileId,proto3" json:"file_id,omitempty"` // file-<hex>; files uploaded earlier keep their content ID (sha256-<hex> or 32-char MD5 hex)
 	Filename      string                 `protobuf:"bytes,2,opt,name=filename,proto3" json:"filename,omitempty"`
 	CreatedAt     int64                  `protobuf:"varint,3,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
 	UpdatedAt     int64                  `protobuf:"varint,4,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	Palette       []string               `protobuf:"bytes,5,rep,name=palette,proto3" json:"palette,omitempty"`                          // Dominant colors (#rrggbb), most dominant first
+	Histogram     []uint32               `protobuf:"varint,6,rep,packed,name=histogram,proto3" json:"histogram,omitempty"`              // Coarse RGB histogram, 4x4x4 bins
+	FrameCount    int32                  `protobuf:"varint,7,opt,name=frame_count,json=frameCount,proto3" json:"frame_count,omitempty"` // Animated GIF only
+	FrameDelaysMs []int32                `protobuf:"varint,8,rep,packed,name=frame_delays_ms,json=frameDelaysMs,proto3" json:"frame_delays_ms,omitempty"`
+	DurationMs    int64                  `protobuf:"varint,9,opt,name=duration_ms,json=durationMs,proto3" json:"duration_ms,omitempty"`
+	VariantOf     string                 `protobuf:"bytes,10,opt,name=variant_of,json=variantOf,proto3" json:"variant_of,omitempty"`                                                      // Source file ID for derived variants
+	Variants      []*Variant             `protobuf:"bytes,11,rep,name=variants,proto3" json:"variants,omitempty"`                                                                         // Derived variants of this file
+	Blurhash      string                 `protobuf:"bytes,12,opt,name=blurhash,proto3" json:"blurhash,omitempty"`                                                                         // BlurHash placeholder, images only
+	Format        string                 `protobuf:"bytes,13,opt,name=format,proto3" json:"format,omitempty"`                                                                             // Source image format: png, jpeg, gif, webp, bmp, tiff or svg
+	Sanitized     bool                   `protobuf:"varint,14,opt,name=sanitized,proto3" json:"sanitized,omitempty"`                                                                      // SVG was modified when active content was stripped on upload
+	Digests       map[string]string      `protobuf:"bytes,15,rep,name=digests,proto3" json:"digests,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"` // Content digests by algorithm (sha256, md5), hex encoded
+	LegacyIds     []string               `protobuf:"bytes,16,rep,name=legacy_ids,json=legacyIds,proto3" json:"legacy_ids,omitempty"`                                                      // Former IDs (pre-SHA-256) that still resolve to this file
+	Owner         string                 `protobuf:"bytes,17,opt,name=owner,proto3" json:"owner,omitempty"`                                                                               // Name of the client that uploaded the file (empty for anonymous uploads)
+	BlobId        string                 `protobuf:"bytes,18,opt,name=blob_id,json=blobId,proto3" json:"blob_id,omitempty"`                                                               // ID of the stored content, shared by files with identical bytes
+	ExpiresAt     int64                  `protobuf:"varint,19,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`                                                     // Unix time (seconds) after which the file is deleted, 0 - never
+	Corrupted     bool                   `protobuf:"varint,20,opt,name=corrupted,proto3" json:"corrupted,omitempty"`                                                                      // Content failed integrity verification and is quarantined; re-upload it to restore
+	Version       int32                  `protobuf:"varint,21,opt,name=version,proto3" json:"version,omitempty"`                                                                          // Version number of this content, starting at 1
+	VersionOf     string                 `protobuf:"bytes,22,opt,name=version_of,json=versionOf,proto3" json:"version_of,omitempty"`                                                      // For a previous version: ID of the file it belongs to
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -361,36 +451,2296 @@ func (x *FileInfo) GetUpdatedAt() int64 {
 	return 0
 }
 
-var File_api_file_proto protoreflect.FileDescriptor
+func (x *FileInfo) GetPalette() []string {
+	if x != nil {
+		return x.Palette
+	}
+	return nil
+}
 
-const file_api_file_proto_rawDesc = "" +
-	"\n" +
-	"\x0eapi/file.proto\"C\n" +
-	"\x11UploadFileRequest\x12\x1a\n" +
-	"\bfilename\x18\x01 \x01(\tR\bfilename\x12\x12\n" +
-	"\x04data\x18\x02 \x01(\fR\x04data\"-\n" +
-	"\x12UploadFileResponse\x12\x17\n" +
-	"\afile_id\x18\x01 \x01(\tR\x06fileId\")\n" +
-	"\x0eGetFileRequest\x12\x17\n" +
-	"\afile_id\x18\x01 \x01(\tR\x06fileId\"A\n" +
-	"\x0fGetFileResponse\x12\x1a\n" +
-	"\bfilename\x18\x01 \x01(\tR\bfilename\x12\x12\n" +
-	"\x04data\x18\x02 \x01(\fR\x04data\"\x12\n" +
-	"\x10ListFilesRequest\"4\n" +
-	"\x11ListFilesResponse\x12\x1f\n" +
-	"\x05files\x18\x01 \x03(\v2\t.FileInfoR\x05files\"}\n" +
-	"\bFileInfo\x12\x17\n" +
-	"\afile_id\x18\x01 \x01(\tR\x06fileId\x12\x1a\n" +
-	"\bfilename\x18\x02 \x01(\tR\bfilename\x12\x1d\n" +
-	"\n" +
-	"created_at\x18\x03 \x01(\x03R\tcreatedAt\x12\x1d\n" +
+func (x *FileInfo) GetHistogram() []uint32 {
+	if x != nil {
+		return x.Histogram
+	}
+	return nil
+}
+
+func (x *FileInfo) GetFrameCount() int32 {
+	if x != nil {
+		return x.FrameCount
+	}
+	return 0
+}
+
+func (x *FileInfo) GetFrameDelaysMs() []int32 {
+	if x != nil {
+		return x.FrameDelaysMs
+	}
+	return nil
+}
+
+func (x *FileInfo) GetDurationMs() int64 {
+	if x != nil {
+		return x.DurationMs
+	}
+	return 0
+}
+
+func (x *FileInfo) GetVariantOf() string {
+	if x != nil {
+		return x.VariantOf
+	}
+	return ""
+}
+
+func (x *FileInfo) GetVariants() []*Variant {
+	if x != nil {
+		return x.Variants
+	}
+	return nil
+}
+
+func (x *FileInfo) GetBlurhash() string {
+	if x != nil {
+		return x.Blurhash
+	}
+	return ""
+}
+
+func (x *FileInfo) GetFormat() string {
+	if x != nil {
+		return x.Format
+	}
+	return ""
+}
+
+func (x *FileInfo) GetSanitized() bool {
+	if x != nil {
+		return x.Sanitized
+	}
+	return false
+}
+
+func (x *FileInfo) GetDigests() map[string]string {
+	if x != nil {
+		return x.Digests
+	}
+	return nil
+}
+
+func (x *FileInfo) GetLegacyIds() []string {
+	if x != nil {
+		return x.LegacyIds
+	}
+	return nil
+}
+
+func (x *FileInfo) GetOwner() string {
+	if x != nil {
+		return x.Owner
+	}
+	return ""
+}
+
+func (x *FileInfo) GetBlobId() string {
+	if x != nil {
+		return x.BlobId
+	}
+	return ""
+}
+
+func (x *FileInfo) GetExpiresAt() int64 {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return 0
+}
+
+func (x *FileInfo) GetCorrupted() bool {
+	if x != nil {
+		return x.Corrupted
+	}
+	return false
+}
+
+func (x *FileInfo) GetVersion() int32 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+func (x *FileInfo) GetVersionOf() string {
+	if x != nil {
+		return x.VersionOf
+	}
+	return ""
+}
+
+type GetFileInfoRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	FileId        string                 `protobuf:"bytes,1,opt,name=file_id,json=fileId,proto3" json:"file_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetFileInfoRequest) Reset() {
+	*x = GetFileInfoRequest{}
+	mi := &file_api_file_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetFileInfoRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetFileInfoRequest) ProtoMessage() {}
+
+func (x *GetFileInfoRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_file_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetFileInfoRequest.ProtoReflect.Descriptor instead.
+func (*GetFileInfoRequest) Descriptor() ([]byte, []int) {
+	return file_api_file_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *GetFileInfoRequest) GetFileId() string {
+	if x != nil {
+		return x.FileId
+	}
+	return ""
+}
+
+type GetFileInfoResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	File          *FileInfo              `protobuf:"bytes,1,opt,name=file,proto3" json:"file,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetFileInfoResponse) Reset() {
+	*x = GetFileInfoResponse{}
+	mi := &file_api_file_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetFileInfoResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetFileInfoResponse) ProtoMessage() {}
+
+func (x *GetFileInfoResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_file_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetFileInfoResponse.ProtoReflect.Descriptor instead.
+func (*GetFileInfoResponse) Descriptor() ([]byte, []int) {
+	return file_api_file_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *GetFileInfoResponse) GetFile() *FileInfo {
+	if x != nil {
+		return x.File
+	}
+	return nil
+}
+
+type Variant struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Kind          string                 `protobuf:"bytes,1,opt,name=kind,proto3" json:"kind,omitempty"`
+	FileId        string                 `protobuf:"bytes,2,opt,name=file_id,json=fileId,proto3" json:"file_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Variant) Reset() {
+	*x = Variant{}
+	mi := &file_api_file_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Variant) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Variant) ProtoMessage() {}
+
+func (x *Variant) ProtoReflect() protoreflect.Message {
+	mi := &file_api_file_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Variant.ProtoReflect.Descriptor instead.
+func (*Variant) Descriptor() ([]byte, []int) {
+	return file_api_file_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *Variant) GetKind() string {
+	if x != nil {
+		return x.Kind
+	}
+	return ""
+}
+
+func (x *Variant) GetFileId() string {
+	if x != nil {
+		return x.FileId
+	}
+	return ""
+}
+
+type GetFrameRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	FileId        string                 `protobuf:"bytes,1,opt,name=file_id,json=fileId,proto3" json:"file_id,omitempty"`
+	Index         int32                  `protobuf:"varint,2,opt,name=index,proto3" json:"index,omitempty"`
+	Poster        bool                   `protobuf:"varint,3,opt,name=poster,proto3" json:"poster,omitempty"` // Return the poster frame used for thumbnails, index is ignored
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetFrameRequest) Reset() {
+	*x = GetFrameRequest{}
+	mi := &file_api_file_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetFrameRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetFrameRequest) ProtoMessage() {}
+
+func (x *GetFrameRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_file_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetFrameRequest.ProtoReflect.Descriptor instead.
+func (*GetFrameRequest) Descriptor() ([]byte, []int) {
+	return file_api_file_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *GetFrameRequest) GetFileId() string {
+	if x != nil {
+		return x.FileId
+	}
+	return ""
+}
+
+func (x *GetFrameRequest) GetIndex() int32 {
+	if x != nil {
+		return x.Index
+	}
+	return 0
+}
+
+func (x *GetFrameRequest) GetPoster() bool {
+	if x != nil {
+		return x.Poster
+	}
+	return false
+}
+
+type GetFrameResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Data          []byte                 `protobuf:"bytes,1,opt,name=data,proto3" json:"data,omitempty"` // PNG
+	Index         int32                  `protobuf:"varint,2,opt,name=index,proto3" json:"index,omitempty"`
+	FrameCount    int32                  `protobuf:"varint,3,opt,name=frame_count,json=frameCount,proto3" json:"frame_count,omitempty"`
+	DelayMs       int32                  `protobuf:"varint,4,opt,name=delay_ms,json=delayMs,proto3" json:"delay_ms,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetFrameResponse) Reset() {
+	*x = GetFrameResponse{}
+	mi := &file_api_file_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetFrameResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetFrameResponse) ProtoMessage() {}
+
+func (x *GetFrameResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_file_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetFrameResponse.ProtoReflect.Descriptor instead.
+func (*GetFrameResponse) Descriptor() ([]byte, []int) {
+	return file_api_file_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *GetFrameResponse) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+func (x *GetFrameResponse) GetIndex() int32 {
+	if x != nil {
+		return x.Index
+	}
+	return 0
+}
+
+func (x *GetFrameResponse) GetFrameCount() int32 {
+	if x != nil {
+		return x.FrameCount
+	}
+	return 0
+}
+
+func (x *GetFrameResponse) GetDelayMs() int32 {
+	if x != nil {
+		return x.DelayMs
+	}
+	return 0
+}
+
+type GetSpriteSheetRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	FileId        string                 `protobuf:"bytes,1,opt,name=file_id,json=fileId,proto3" json:"file_id,omitempty"`
+	Columns       int32                  `protobuf:"varint,2,opt,name=columns,proto3" json:"columns,omitempty"` // 0 - all frames in one row
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetSpriteSheetRequest) Reset() {
+	*x = GetSpriteSheetRequest{}
+	mi := &file_api_file_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetSpriteSheetRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetSpriteSheetRequest) ProtoMessage() {}
+
+func (x *GetSpriteSheetRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_file_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetSpriteSheetRequest.ProtoReflect.Descriptor instead.
+func (*GetSpriteSheetRequest) Descriptor() ([]byte, []int) {
+	return file_api_file_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *GetSpriteSheetRequest) GetFileId() string {
+	if x != nil {
+		return x.FileId
+	}
+	return ""
+}
+
+func (x *GetSpriteSheetRequest) GetColumns() int32 {
+	if x != nil {
+		return x.Columns
+	}
+	return 0
+}
+
+type GetSpriteSheetResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Data          []byte                 `protobuf:"bytes,1,opt,name=data,proto3" json:"data,omitempty"`                         // PNG
+	FrameMap      string                 `protobuf:"bytes,2,opt,name=frame_map,json=frameMap,proto3" json:"frame_map,omitempty"` // JSON array of {index, x, y, width, height, delay_ms}
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetSpriteSheetResponse) Reset() {
+	*x = GetSpriteSheetResponse{}
+	mi := &file_api_file_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetSpriteSheetResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetSpriteSheetResponse) ProtoMessage() {}
+
+func (x *GetSpriteSheetResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_file_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetSpriteSheetResponse.ProtoReflect.Descriptor instead.
+func (*GetSpriteSheetResponse) Descriptor() ([]byte, []int) {
+	return file_api_file_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *GetSpriteSheetResponse) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+func (x *GetSpriteSheetResponse) GetFrameMap() string {
+	if x != nil {
+		return x.FrameMap
+	}
+	return ""
+}
+
+type ContactSheetRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	FileIds       []string               `protobuf:"bytes,1,rep,name=file_ids,json=fileIds,proto3" json:"file_ids,omitempty"`
+	Columns       int32                  `protobuf:"varint,2,opt,name=columns,proto3" json:"columns,omitempty"`                         // 0 - roughly square grid
+	CellWidth     int32                  `protobuf:"varint,3,opt,name=cell_width,json=cellWidth,proto3" json:"cell_width,omitempty"`    // 0 - server default
+	CellHeight    int32                  `protobuf:"varint,4,opt,name=cell_height,json=cellHeight,proto3" json:"cell_height,omitempty"` // 0 - server default
+	Padding       int32                  `protobuf:"varint,5,opt,name=padding,proto3" json:"padding,omitempty"`
+	Background    string                 `protobuf:"bytes,6,opt,name=background,proto3" json:"background,omitempty"` // #rrggbb, empty - white
+	Captions      bool                   `protobuf:"varint,7,opt,name=captions,proto3" json:"captions,omitempty"`    // Render filenames under cells
+	Format        string                 `protobuf:"bytes,8,opt,name=format,proto3" json:"format,omitempty"`         // png (default) or jpeg
+	Crop          string                 `protobuf:"bytes,9,opt,name=crop,proto3" json:"crop,omitempty"`             // fit (default), center or smart
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ContactSheetRequest) Reset() {
+	*x = ContactSheetRequest{}
+	mi := &file_api_file_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ContactSheetRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ContactSheetRequest) ProtoMessage() {}
+
+func (x *ContactSheetRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_file_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ContactSheetRequest.ProtoReflect.Descriptor instead.
+func (*ContactSheetRequest) Descriptor() ([]byte, []int) {
+	return file_api_file_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *ContactSheetRequest) GetFileIds() []string {
+	if x != nil {
+		return x.FileIds
+	}
+	return nil
+}
+
+func (x *ContactSheetRequest) GetColumns() int32 {
+	if x != nil {
+		return x.Columns
+	}
+	return 0
+}
+
+func (x *ContactSheetRequest) GetCellWidth() int32 {
+	if x != nil {
+		return x.CellWidth
+	}
+	return 0
+}
+
+func (x *ContactSheetRequest) GetCellHeight() int32 {
+	if x != nil {
+		return x.CellHeight
+	}
+	return 0
+}
+
+func (x *ContactSheetRequest) GetPadding() int32 {
+	if x != nil {
+		return x.Padding
+	}
+	return 0
+}
+
+func (x *ContactSheetRequest) GetBackground() string {
+	if x != nil {
+		return x.Background
+	}
+	return ""
+}
+
+func (x *ContactSheetRequest) GetCaptions() bool {
+	if x != nil {
+		return x.Captions
+	}
+	return false
+}
+
+func (x *ContactSheetRequest) GetFormat() string {
+	if x != nil {
+		return x.Format
+	}
+	return ""
+}
+
+func (x *ContactSheetRequest) GetCrop() string {
+	if x != nil {
+		return x.Crop
+	}
+	return ""
+}
+
+type ContactSheetResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Data          []byte                 `protobuf:"bytes,1,opt,name=data,proto3" json:"data,omitempty"`
+	Format        string                 `protobuf:"bytes,2,opt,name=format,proto3" json:"format,omitempty"`
+	Crops         []*CropRect            `protobuf:"bytes,3,rep,name=crops,proto3" json:"crops,omitempty"` // Source region used for each input, in request order
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ContactSheetResponse) Reset() {
+	*x = ContactSheetResponse{}
+	mi := &file_api_file_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ContactSheetResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ContactSheetResponse) ProtoMessage() {}
+
+func (x *ContactSheetResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_file_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ContactSheetResponse.ProtoReflect.Descriptor instead.
+func (*ContactSheetResponse) Descriptor() ([]byte, []int) {
+	return file_api_file_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *ContactSheetResponse) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+func (x *ContactSheetResponse) GetFormat() string {
+	if x != nil {
+		return x.Format
+	}
+	return ""
+}
+
+func (x *ContactSheetResponse) GetCrops() []*CropRect {
+	if x != nil {
+		return x.Crops
+	}
+	return nil
+}
+
+// Region of the source image in its own pixel coordinates
+type CropRect struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	X             int32                  `protobuf:"varint,1,opt,name=x,proto3" json:"x,omitempty"`
+	Y             int32                  `protobuf:"varint,2,opt,name=y,proto3" json:"y,omitempty"`
+	Width         int32                  `protobuf:"varint,3,opt,name=width,proto3" json:"width,omitempty"`
+	Height        int32                  `protobuf:"varint,4,opt,name=height,proto3" json:"height,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CropRect) Reset() {
+	*x = CropRect{}
+	mi := &file_api_file_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CropRect) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CropRect) ProtoMessage() {}
+
+func (x *CropRect) ProtoReflect() protoreflect.Message {
+	mi := &file_api_file_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CropRect.ProtoReflect.Descriptor instead.
+func (*CropRect) Descriptor() ([]byte, []int) {
+	return file_api_file_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *CropRect) GetX() int32 {
+	if x != nil {
+		return x.X
+	}
+	return 0
+}
+
+func (x *CropRect) GetY() int32 {
+	if x != nil {
+		return x.Y
+	}
+	return 0
+}
+
+func (x *CropRect) GetWidth() int32 {
+	if x != nil {
+		return x.Width
+	}
+	return 0
+}
+
+func (x *CropRect) GetHeight() int32 {
+	if x != nil {
+		return x.Height
+	}
+	return 0
+}
+
+type WatermarkPolicy struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	WatermarkFileId string                 `protobuf:"bytes,1,opt,name=watermark_file_id,json=watermarkFileId,proto3" json:"watermark_file_id,omitempty"`
+	Position        string                 `protobuf:"bytes,2,opt,name=position,proto3" json:"position,omitempty"` // center, top-left, top-right, bottom-left, bottom-right (default)
+	Opacity         float64                `protobuf:"fixed64,3,opt,name=opacity,proto3" json:"opacity,omitempty"` // 0..1, 0 - server default
+	Scale           float64                `protobuf:"fixed64,4,opt,name=scale,proto3" json:"scale,omitempty"`     // Watermark width relative to the image width, 0..1, 0 - server default
+	Tile            bool                   `protobuf:"varint,5,opt,name=tile,proto3" json:"tile,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *WatermarkPolicy) Reset() {
+	*x = WatermarkPolicy{}
+	mi := &file_api_file_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatermarkPolicy) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatermarkPolicy) ProtoMessage() {}
+
+func (x *WatermarkPolicy) ProtoReflect() protoreflect.Message {
+	mi := &file_api_file_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatermarkPolicy.ProtoReflect.Descriptor instead.
+func (*WatermarkPolicy) Descriptor() ([]byte, []int) {
+	return file_api_file_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *WatermarkPolicy) GetWatermarkFileId() string {
+	if x != nil {
+		return x.WatermarkFileId
+	}
+	return ""
+}
+
+func (x *WatermarkPolicy) GetPosition() string {
+	if x != nil {
+		return x.Position
+	}
+	return ""
+}
+
+func (x *WatermarkPolicy) GetOpacity() float64 {
+	if x != nil {
+		return x.Opacity
+	}
+	return 0
+}
+
+func (x *WatermarkPolicy) GetScale() float64 {
+	if x != nil {
+		return x.Scale
+	}
+	return 0
+}
+
+func (x *WatermarkPolicy) GetTile() bool {
+	if x != nil {
+		return x.Tile
+	}
+	return false
+}
+
+type CreateWatermarkVariantRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	FileId        string                 `protobuf:"bytes,1,opt,name=file_id,json=fileId,proto3" json:"file_id,omitempty"`
+	Watermark     *WatermarkPolicy       `protobuf:"bytes,2,opt,name=watermark,proto3" json:"watermark,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateWatermarkVariantRequest) Reset() {
+	*x = CreateWatermarkVariantRequest{}
+	mi := &file_api_file_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateWatermarkVariantRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateWatermarkVariantRequest) ProtoMessage() {}
+
+func (x *CreateWatermarkVariantRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_file_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateWatermarkVariantRequest.ProtoReflect.Descriptor instead.
+func (*CreateWatermarkVariantRequest) Descriptor() ([]byte, []int) {
+	return file_api_file_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *CreateWatermarkVariantRequest) GetFileId() string {
+	if x != nil {
+		return x.FileId
+	}
+	return ""
+}
+
+func (x *CreateWatermarkVariantRequest) GetWatermark() *WatermarkPolicy {
+	if x != nil {
+		return x.Watermark
+	}
+	return nil
+}
+
+type CreateWatermarkVariantResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	FileId        string                 `protobuf:"bytes,1,opt,name=file_id,json=fileId,proto3" json:"file_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateWatermarkVariantResponse) Reset() {
+	*x = CreateWatermarkVariantResponse{}
+	mi := &file_api_file_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateWatermarkVariantResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateWatermarkVariantResponse) ProtoMessage() {}
+
+func (x *CreateWatermarkVariantResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_file_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateWatermarkVariantResponse.ProtoReflect.Descriptor instead.
+func (*CreateWatermarkVariantResponse) Descriptor() ([]byte, []int) {
+	return file_api_file_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *CreateWatermarkVariantResponse) GetFileId() string {
+	if x != nil {
+		return x.FileId
+	}
+	return ""
+}
+
+type CompareImagesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	FileIdA       string                 `protobuf:"bytes,1,opt,name=file_id_a,json=fileIdA,proto3" json:"file_id_a,omitempty"`
+	FileIdB       string                 `protobuf:"bytes,2,opt,name=file_id_b,json=fileIdB,proto3" json:"file_id_b,omitempty"`
+	Tolerance     int32                  `protobuf:"varint,3,opt,name=tolerance,proto3" json:"tolerance,omitempty"`                        // Max per-channel difference (0-255) for a pixel to count as unchanged
+	IncludeDiff   bool                   `protobuf:"varint,4,opt,name=include_diff,json=includeDiff,proto3" json:"include_diff,omitempty"` // Return a PNG with changed pixels highlighted
+	Mismatch      string                 `protobuf:"bytes,5,opt,name=mismatch,proto3" json:"mismatch,omitempty"`                           // Different dimensions: reject (default) or resize
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CompareImagesRequest) Reset() {
+	*x = CompareImagesRequest{}
+	mi := &file_api_file_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CompareImagesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CompareImagesRequest) ProtoMessage() {}
+
+func (x *CompareImagesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_file_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CompareImagesRequest.ProtoReflect.Descriptor instead.
+func (*CompareImagesRequest) Descriptor() ([]byte, []int) {
+	return file_api_file_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *CompareImagesRequest) GetFileIdA() string {
+	if x != nil {
+		return x.FileIdA
+	}
+	return ""
+}
+
+func (x *CompareImagesRequest) GetFileIdB() string {
+	if x != nil {
+		return x.FileIdB
+	}
+	return ""
+}
+
+func (x *CompareImagesRequest) GetTolerance() int32 {
+	if x != nil {
+		return x.Tolerance
+	}
+	return 0
+}
+
+func (x *CompareImagesRequest) GetIncludeDiff() bool {
+	if x != nil {
+		return x.IncludeDiff
+	}
+	return false
+}
+
+func (x *CompareImagesRequest) GetMismatch() string {
+	if x != nil {
+		return x.Mismatch
+	}
+	return ""
+}
+
+type CompareImagesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Psnr          float64                `protobuf:"fixed64,1,opt,name=psnr,proto3" json:"psnr,omitempty"` // dB, +Inf for identical images
+	Ssim          float64                `protobuf:"fixed64,2,opt,name=ssim,proto3" json:"ssim,omitempty"`
+	ChangedPixels int64                  `protobuf:"varint,3,opt,name=changed_pixels,json=changedPixels,proto3" json:"changed_pixels,omitempty"`
+	TotalPixels   int64                  `protobuf:"varint,4,opt,name=total_pixels,json=totalPixels,proto3" json:"total_pixels,omitempty"`
+	Diff          []byte                 `protobuf:"bytes,5,opt,name=diff,proto3" json:"diff,omitempty"` // PNG, only with include_diff
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CompareImagesResponse) Reset() {
+	*x = CompareImagesResponse{}
+	mi := &file_api_file_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CompareImagesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CompareImagesResponse) ProtoMessage() {}
+
+func (x *CompareImagesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_file_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CompareImagesResponse.ProtoReflect.Descriptor instead.
+func (*CompareImagesResponse) Descriptor() ([]byte, []int) {
+	return file_api_file_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *CompareImagesResponse) GetPsnr() float64 {
+	if x != nil {
+		return x.Psnr
+	}
+	return 0
+}
+
+func (x *CompareImagesResponse) GetSsim() float64 {
+	if x != nil {
+		return x.Ssim
+	}
+	return 0
+}
+
+func (x *CompareImagesResponse) GetChangedPixels() int64 {
+	if x != nil {
+		return x.ChangedPixels
+	}
+	return 0
+}
+
+func (x *CompareImagesResponse) GetTotalPixels() int64 {
+	if x != nil {
+		return x.TotalPixels
+	}
+	return 0
+}
+
+func (x *CompareImagesResponse) GetDiff() []byte {
+	if x != nil {
+		return x.Diff
+	}
+	return nil
+}
+
+type CropImageRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	FileId        string                 `protobuf:"bytes,1,opt,name=file_id,json=fileId,proto3" json:"file_id,omitempty"`
+	AspectWidth   int32                  `protobuf:"varint,2,opt,name=aspect_width,json=aspectWidth,proto3" json:"aspect_width,omitempty"` // Aspect ratio of the crop; 0 - taken from width/height, or 1:1
+	AspectHeight  int32                  `protobuf:"varint,3,opt,name=aspect_height,json=aspectHeight,proto3" json:"aspect_height,omitempty"`
+	Width         int32                  `protobuf:"varint,4,opt,name=width,proto3" json:"width,omitempty"` // Output size; 0 - derived from the other side or the crop itself
+	Height        int32                  `protobuf:"varint,5,opt,name=height,proto3" json:"height,omitempty"`
+	Mode          string                 `protobuf:"bytes,6,opt,name=mode,proto3" json:"mode,omitempty"`     // smart (default) or center
+	Format        string                 `protobuf:"bytes,7,opt,name=format,proto3" json:"format,omitempty"` // png (default) or jpeg
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CropImageRequest) Reset() {
+	*x = CropImageRequest{}
+	mi := &file_api_file_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CropImageRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CropImageRequest) ProtoMessage() {}
+
+func (x *CropImageRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_file_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CropImageRequest.ProtoReflect.Descriptor instead.
+func (*CropImageRequest) Descriptor() ([]byte, []int) {
+	return file_api_file_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *CropImageRequest) GetFileId() string {
+	if x != nil {
+		return x.FileId
+	}
+	return ""
+}
+
+func (x *CropImageRequest) GetAspectWidth() int32 {
+	if x != nil {
+		return x.AspectWidth
+	}
+	return 0
+}
+
+func (x *CropImageRequest) GetAspectHeight() int32 {
+	if x != nil {
+		return x.AspectHeight
+	}
+	return 0
+}
+
+func (x *CropImageRequest) GetWidth() int32 {
+	if x != nil {
+		return x.Width
+	}
+	return 0
+}
+
+func (x *CropImageRequest) GetHeight() int32 {
+	if x != nil {
+		return x.Height
+	}
+	return 0
+}
+
+func (x *CropImageRequest) GetMode() string {
+	if x != nil {
+		return x.Mode
+	}
+	return ""
+}
+
+func (x *CropImageRequest) GetFormat() string {
+	if x != nil {
+		return x.Format
+	}
+	return ""
+}
+
+type CropImageResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Data          []byte                 `protobuf:"bytes,1,opt,name=data,proto3" json:"data,omitempty"`
+	Format        string                 `protobuf:"bytes,2,opt,name=format,proto3" json:"format,omitempty"`
+	Crop          *CropRect              `protobuf:"bytes,3,opt,name=crop,proto3" json:"crop,omitempty"` // Chosen region of the source image
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CropImageResponse) Reset() {
+	*x = CropImageResponse{}
+	mi := &file_api_file_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CropImageResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CropImageResponse) ProtoMessage() {}
+
+func (x *CropImageResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_file_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CropImageResponse.ProtoReflect.Descriptor instead.
+func (*CropImageResponse) Descriptor() ([]byte, []int) {
+	return file_api_file_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *CropImageResponse) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+func (x *CropImageResponse) GetFormat() string {
+	if x != nil {
+		return x.Format
+	}
+	return ""
+}
+
+func (x *CropImageResponse) GetCrop() *CropRect {
+	if x != nil {
+		return x.Crop
+	}
+	return nil
+}
+
+type OptimizeRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	FileId        string                 `protobuf:"bytes,1,opt,name=file_id,json=fileId,proto3" json:"file_id,omitempty"`
+	Quality       int32                  `protobuf:"varint,2,opt,name=quality,proto3" json:"quality,omitempty"`                 // JPEG target quality 1-100, 0 - server default
+	MinSsim       float64                `protobuf:"fixed64,3,opt,name=min_ssim,json=minSsim,proto3" json:"min_ssim,omitempty"` // Minimum SSIM of re-encoded JPEG, 0 - server default
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *OptimizeRequest) Reset() {
+	*x = OptimizeRequest{}
+	mi := &file_api_file_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OptimizeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OptimizeRequest) ProtoMessage() {}
+
+func (x *OptimizeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_file_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OptimizeRequest.ProtoReflect.Descriptor instead.
+func (*OptimizeRequest) Descriptor() ([]byte, []int) {
+	return file_api_file_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *OptimizeRequest) GetFileId() string {
+	if x != nil {
+		return x.FileId
+	}
+	return ""
+}
+
+func (x *OptimizeRequest) GetQuality() int32 {
+	if x != nil {
+		return x.Quality
+	}
+	return 0
+}
+
+func (x *OptimizeRequest) GetMinSsim() float64 {
+	if x != nil {
+		return x.MinSsim
+	}
+	return 0
+}
+
+type OptimizeResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	VariantId     string                 `protobuf:"bytes,1,opt,name=variant_id,json=variantId,proto3" json:"variant_id,omitempty"` // Optimized variant, empty if the file could not be made smaller
+	Format        string                 `protobuf:"bytes,2,opt,name=format,proto3" json:"format,omitempty"`
+	OriginalSize  int64                  `protobuf:"varint,3,opt,name=original_size,json=originalSize,proto3" json:"original_size,omitempty"`
+	OptimizedSize int64                  `protobuf:"varint,4,opt,name=optimized_size,json=optimizedSize,proto3" json:"optimized_size,omitempty"`
+	BytesSaved    int64                  `protobuf:"varint,5,opt,name=bytes_saved,json=bytesSaved,proto3" json:"bytes_saved,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *OptimizeResponse) Reset() {
+	*x = OptimizeResponse{}
+	mi := &file_api_file_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OptimizeResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OptimizeResponse) ProtoMessage() {}
+
+func (x *OptimizeResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_file_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OptimizeResponse.ProtoReflect.Descriptor instead.
+func (*OptimizeResponse) Descriptor() ([]byte, []int) {
+	return file_api_file_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *OptimizeResponse) GetVariantId() string {
+	if x != nil {
+		return x.VariantId
+	}
+	return ""
+}
+
+func (x *OptimizeResponse) GetFormat() string {
+	if x != nil {
+		return x.Format
+	}
+	return ""
+}
+
+func (x *OptimizeResponse) GetOriginalSize() int64 {
+	if x != nil {
+		return x.OriginalSize
+	}
+	return 0
+}
+
+func (x *OptimizeResponse) GetOptimizedSize() int64 {
+	if x != nil {
+		return x.OptimizedSize
+	}
+	return 0
+}
+
+func (x *OptimizeResponse) GetBytesSaved() int64 {
+	if x != nil {
+		return x.BytesSaved
+	}
+	return 0
+}
+
+type GetQuotaRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetQuotaRequest) Reset() {
+	*x = GetQuotaRequest{}
+	mi := &file_api_file_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetQuotaRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetQuotaRequest) ProtoMessage() {}
+
+func (x *GetQuotaRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_file_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetQuotaRequest.ProtoReflect.Descriptor instead.
+func (*GetQuotaRequest) Descriptor() ([]byte, []int) {
+	return file_api_file_proto_rawDescGZIP(), []int{26}
+}
+
+type GetQuotaResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Owner         string                 `protobuf:"bytes,1,opt,name=owner,proto3" json:"owner,omitempty"`                             // Caller's client name, empty for anonymous clients
+	Usage         *Usage                 `protobuf:"bytes,2,opt,name=usage,proto3" json:"usage,omitempty"`                             // Files owned by the caller: count and summed sizes
+	Quota         *Quota                 `protobuf:"bytes,3,opt,name=quota,proto3" json:"quota,omitempty"`                             // Caller's limits
+	TotalUsage    *Usage                 `protobuf:"bytes,4,opt,name=total_usage,json=totalUsage,proto3" json:"total_usage,omitempty"` // All files: count and stored bytes (identical content counted once)
+	TotalQuota    *Quota                 `protobuf:"bytes,5,opt,name=total_quota,json=totalQuota,proto3" json:"total_quota,omitempty"` // Server-wide limits
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetQuotaResponse) Reset() {
+	*x = GetQuotaResponse{}
+	mi := &file_api_file_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetQuotaResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetQuotaResponse) ProtoMessage() {}
+
+func (x *GetQuotaResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_file_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetQuotaResponse.ProtoReflect.Descriptor instead.
+func (*GetQuotaResponse) Descriptor() ([]byte, []int) {
+	return file_api_file_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *GetQuotaResponse) GetOwner() string {
+	if x != nil {
+		return x.Owner
+	}
+	return ""
+}
+
+func (x *GetQuotaResponse) GetUsage() *Usage {
+	if x != nil {
+		return x.Usage
+	}
+	return nil
+}
+
+func (x *GetQuotaResponse) GetQuota() *Quota {
+	if x != nil {
+		return x.Quota
+	}
+	return nil
+}
+
+func (x *GetQuotaResponse) GetTotalUsage() *Usage {
+	if x != nil {
+		return x.TotalUsage
+	}
+	return nil
+}
+
+func (x *GetQuotaResponse) GetTotalQuota() *Quota {
+	if x != nil {
+		return x.TotalQuota
+	}
+	return nil
+}
+
+type Usage struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Files         int64                  `protobuf:"varint,1,opt,name=files,proto3" json:"files,omitempty"`
+	Bytes         int64                  `protobuf:"varint,2,opt,name=bytes,proto3" json:"bytes,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Usage) Reset() {
+	*x = Usage{}
+	mi := &file_api_file_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Usage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Usage) ProtoMessage() {}
+
+func (x *Usage) ProtoReflect() protoreflect.Message {
+	mi := &file_api_file_proto_msgTypes[28]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Usage.ProtoReflect.Descriptor instead.
+func (*Usage) Descriptor() ([]byte, []int) {
+	return file_api_file_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *Usage) GetFiles() int64 {
+	if x != nil {
+		return x.Files
+	}
+	return 0
+}
+
+func (x *Usage) GetBytes() int64 {
+	if x != nil {
+		return x.Bytes
+	}
+	return 0
+}
+
+type Quota struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	MaxFiles      int64                  `protobuf:"varint,1,opt,name=max_files,json=maxFiles,proto3" json:"max_files,omitempty"` // 0 - unlimited
+	MaxBytes      int64                  `protobuf:"varint,2,opt,name=max_bytes,json=maxBytes,proto3" json:"max_bytes,omitempty"` // 0 - unlimited
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Quota) Reset() {
+	*x = Quota{}
+	mi := &file_api_file_proto_msgTypes[29]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Quota) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Quota) ProtoMessage() {}
+
+func (x *Quota) ProtoReflect() protoreflect.Message {
+	mi := &file_api_file_proto_msgTypes[29]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Quota.ProtoReflect.Descriptor instead.
+func (*Quota) Descriptor() ([]byte, []int) {
+	return file_api_file_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *Quota) GetMaxFiles() int64 {
+	if x != nil {
+		return x.MaxFiles
+	}
+	return 0
+}
+
+func (x *Quota) GetMaxBytes() int64 {
+	if x != nil {
+		return x.MaxBytes
+	}
+	return 0
+}
+
+type SetExpiryRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	FileId        string                 `protobuf:"bytes,1,opt,name=file_id,json=fileId,proto3" json:"file_id,omitempty"`
+	TtlSeconds    int64                  `protobuf:"varint,2,opt,name=ttl_seconds,json=ttlSeconds,proto3" json:"ttl_seconds,omitempty"` // Expire this many seconds from now
+	ExpiresAt     int64                  `protobuf:"varint,3,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`    // Or expire at this Unix time (seconds); both 0 - keep the file forever
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetExpiryRequest) Reset() {
+	*x = SetExpiryRequest{}
+	mi := &file_api_file_proto_msgTypes[30]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetExpiryRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetExpiryRequest) ProtoMessage() {}
+
+func (x *SetExpiryRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_file_proto_msgTypes[30]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetExpiryRequest.ProtoReflect.Descriptor instead.
+func (*SetExpiryRequest) Descriptor() ([]byte, []int) {
+	return file_api_file_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *SetExpiryRequest) GetFileId() string {
+	if x != nil {
+		return x.FileId
+	}
+	return ""
+}
+
+func (x *SetExpiryRequest) GetTtlSeconds() int64 {
+	if x != nil {
+		return x.TtlSeconds
+	}
+	return 0
+}
+
+func (x *SetExpiryRequest) GetExpiresAt() int64 {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return 0
+}
+
+type SetExpiryResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ExpiresAt     int64                  `protobuf:"varint,1,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"` // New expiry Unix time (seconds), 0 - never
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetExpiryResponse) Reset() {
+	*x = SetExpiryResponse{}
+	mi := &file_api_file_proto_msgTypes[31]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetExpiryResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetExpiryResponse) ProtoMessage() {}
+
+func (x *SetExpiryResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_file_proto_msgTypes[31]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetExpiryResponse.ProtoReflect.Descriptor instead.
+func (*SetExpiryResponse) Descriptor() ([]byte, []int) {
+	return file_api_file_proto_rawDescGZIP(), []int{31}
+}
+
+func (x *SetExpiryResponse) GetExpiresAt() int64 {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return 0
+}
+
+type FsckRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Repair        bool                   `protobuf:"varint,1,opt,name=repair,proto3" json:"repair,omitempty"` // Repair found issues instead of only reporting them
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FsckRequest) Reset() {
+	*x = FsckRequest{}
+	mi := &file_api_file_proto_msgTypes[32]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FsckRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FsckRequest) ProtoMessage() {}
+
+func (x *FsckRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_file_proto_msgTypes[32]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FsckRequest.ProtoReflect.Descriptor instead.
+func (*FsckRequest) Descriptor() ([]byte, []int) {
+	return file_api_file_proto_rawDescGZIP(), []int{32}
+}
+
+func (x *FsckRequest) GetRepair() bool {
+	if x != nil {
+		return x.Repair
+	}
+	return false
+}
+
+type FsckIssue struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Kind          string                 `protobuf:"bytes,1,opt,name=kind,proto3" json:"kind,omitempty"`                      // missing_blob, unindexed_blob, hash_mismatch, empty_blob or temp_file
+	BlobId        string                 `protobuf:"bytes,2,opt,name=blob_id,json=blobId,proto3" json:"blob_id,omitempty"`    // Content ID (path inside the storage for temp_file)
+	FileIds       []string               `protobuf:"bytes,3,rep,name=file_ids,json=fileIds,proto3" json:"file_ids,omitempty"` // Files referencing the content
+	Detail        string                 `protobuf:"bytes,4,opt,name=detail,proto3" json:"detail,omitempty"`
+	Repaired      bool                   `protobuf:"varint,5,opt,name=repaired,proto3" json:"repaired,omitempty"`
+	Action        string                 `protobuf:"bytes,6,opt,name=action,proto3" json:"action,omitempty"` // Repair performed or proposed
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FsckIssue) Reset() {
+	*x = FsckIssue{}
+	mi := &file_api_file_proto_msgTypes[33]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FsckIssue) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FsckIssue) ProtoMessage() {}
+
+func (x *FsckIssue) ProtoReflect() protoreflect.Message {
+	mi := &file_api_file_proto_msgTypes[33]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FsckIssue.ProtoReflect.Descriptor instead.
+func (*FsckIssue) Descriptor() ([]byte, []int) {
+	return file_api_file_proto_rawDescGZIP(), []int{33}
+}
+
+func (x *FsckIssue) GetKind() string {
+	if x != nil {
+		return x.Kind
+	}
+	return ""
+}
+
+func (x *FsckIssue) GetBlobId() string {
+	if x != nil {
+		return x.BlobId
+	}
+	return ""
+}
+
+func (x *FsckIssue) GetFileIds() []string {
+	if x != nil {
+		return x.FileIds
+	}
+	return nil
+}
+
+func (x *FsckIssue) GetDetail() string {
+	if x != nil {
+		return x.Detail
+	}
+	return ""
+}
+
+func (x *FsckIssue) GetRepaired() bool {
+	if x != nil {
+		return x.Repaired
+	}
+	return false
+}
+
+func (x *FsckIssue) GetAction() string {
+	if x != nil {
+		return x.Action
+	}
+	return ""
+}
+
+type FsckResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	StartedAt     int64                  `protobuf:"varint,1,opt,name=started_at,json=startedAt,proto3" json:"started_at,omitempty"` // Unix time (seconds)
+	DurationMs    int64                  `protobuf:"varint,2,opt,name=duration_ms,json=durationMs,proto3" json:"duration_ms,omitempty"`
+	Repair        bool                   `protobuf:"varint,3,opt,name=repair,proto3" json:"repair,omitempty"`
+	Files         int64                  `protobuf:"varint,4,opt,name=files,proto3" json:"files,omitempty"` // Files checked
+	Blobs         int64                  `protobuf:"varint,5,opt,name=blobs,proto3" json:"blobs,omitempty"` // Stored contents checked
+	Issues        []*FsckIssue           `protobuf:"bytes,6,rep,name=issues,proto3" json:"issues,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FsckResponse) Reset() {
+	*x = FsckResponse{}
+	mi := &file_api_file_proto_msgTypes[34]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FsckResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FsckResponse) ProtoMessage() {}
+
+func (x *FsckResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_file_proto_msgTypes[34]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FsckResponse.ProtoReflect.Descriptor instead.
+func (*FsckResponse) Descriptor() ([]byte, []int) {
+	return file_api_file_proto_rawDescGZIP(), []int{34}
+}
+
+func (x *FsckResponse) GetStartedAt() int64 {
+	if x != nil {
+		return x.StartedAt
+	}
+	return 0
+}
+
+func (x *FsckResponse) GetDurationMs() int64 {
+	if x != nil {
+		return x.DurationMs
+	}
+	return 0
+}
+
+func (x *FsckResponse) GetRepair() bool {
+	if x != nil {
+		return x.Repair
+	}
+	return false
+}
+
+func (x *FsckResponse) GetFiles() int64 {
+	if x != nil {
+		return x.Files
+	}
+	return 0
+}
+
+func (x *FsckResponse) GetBlobs() int64 {
+	if x != nil {
+		return x.Blobs
+	}
+	return 0
+}
+
+func (x *FsckResponse) GetIssues() []*FsckIssue {
+	if x != nil {
+		return x.Issues
+	}
+	return nil
+}
+
+type ListVersionsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	FileId        string                 `protobuf:"bytes,1,opt,name=file_id,json=fileId,proto3" json:"file_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListVersionsRequest) Reset() {
+	*x = ListVersionsRequest{}
+	mi := &file_api_file_proto_msgTypes[35]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListVersionsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListVersionsRequest) ProtoMessage() {}
+
+func (x *ListVersionsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_file_proto_msgTypes[35]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListVersionsRequest.ProtoReflect.Descriptor instead.
+func (*ListVersionsRequest) Descriptor() ([]byte, []int) {
+	return file_api_file_proto_rawDescGZIP(), []int{35}
+}
+
+func (x *ListVersionsRequest) GetFileId() string {
+	if x != nil {
+		return x.FileId
+	}
+	return ""
+}
+
+type ListVersionsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Versions      []*FileInfo            `protobuf:"bytes,1,rep,name=versions,proto3" json:"versions,omitempty"` // Latest first; previous versions have their own file_id and version_of set
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListVersionsResponse) Reset() {
+	*x = ListVersionsResponse{}
+	mi := &file_api_file_proto_msgTypes[36]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListVersionsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListVersionsResponse) ProtoMessage() {}
+
+func (x *ListVersionsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_file_proto_msgTypes[36]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListVersionsResponse.ProtoReflect.Descriptor instead.
+func (*ListVersionsResponse) Descriptor() ([]byte, []int) {
+	return file_api_file_proto_rawDescGZIP(), []int{36}
+}
+
+func (x *ListVersionsResponse) GetVersions() []*FileInfo {
+	if x != nil {
+		return x.Versions
+	}
+	return nil
+}
+
+type RestoreVersionRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	FileId        string                 `protobuf:"bytes,1,opt,name=file_id,json=fileId,proto3" json:"file_id,omitempty"`
+	Version       int32                  `protobuf:"varint,2,opt,name=version,proto3" json:"version,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RestoreVersionRequest) Reset() {
+	*x = RestoreVersionRequest{}
+	mi := &file_api_file_proto_msgTypes[37]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RestoreVersionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RestoreVersionRequest) ProtoMessage() {}
+
+func (x *RestoreVersionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_file_proto_msgTypes[37]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RestoreVersionRequest.ProtoReflect.Descriptor instead.
+func (*RestoreVersionRequest) Descriptor() ([]byte, []int) {
+	return file_api_file_proto_rawDescGZIP(), []int{37}
+}
+
+func (x *RestoreVersionRequest) GetFileId() string {
+	if x != nil {
+		return x.FileId
+	}
+	return ""
+}
+
+func (x *RestoreVersionRequest) GetVersion() int32 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+type RestoreVersionResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Version       int32                  `protobuf:"varint,1,opt,name=version,proto3" json:"version,omitempty"` // New latest version holding the restored content
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RestoreVersionResponse) Reset() {
+	*x = RestoreVersionResponse{}
+	mi := &file_api_file_proto_msgTypes[38]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RestoreVersionResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RestoreVersionResponse) ProtoMessage() {}
+
+func (x *RestoreVersionResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_file_proto_msgTypes[38]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RestoreVersionResponse.ProtoReflect.Descriptor instead.
+func (*RestoreVersionResponse) Descriptor() ([]byte, []int) {
+	return file_api_file_proto_rawDescGZIP(), []int{38}
+}
+
+func (x *RestoreVersionResponse) GetVersion() int32 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+var File_api_file_proto protoreflect.FileDescriptor
+
+const file_api_file_proto_rawDesc = "" +
+	"\n" +
+	"\x0eapi/file.proto\"\xab\x01\n" +
+	"\x11UploadFileRequest\x12\x1a\n" +
+	"\bfilename\x18\x01 \x01(\tR\bfilename\x12\x12\n" +
+	"\x04data\x18\x02 \x01(\fR\x04data\x12\x1f\n" +
+	"\vttl_seconds\x18\x03 \x01(\x03R\n" +
+	"ttlSeconds\x12\x1d\n" +
+	"\n" +
+	"expires_at\x18\x04 \x01(\x03R\texpiresAt\x12&\n" +
+	"\x0freplace_file_id\x18\x05 \x01(\tR\rreplaceFileId\"G\n" +
+	"\x12UploadFileResponse\x12\x17\n" +
+	"\afile_id\x18\x01 \x01(\tR\x06fileId\x12\x18\n" +
+	"\aversion\x18\x02 \x01(\x05R\aversion\"\x8b\x01\n" +
+	"\x0eGetFileRequest\x12\x17\n" +
+	"\afile_id\x18\x01 \x01(\tR\x06fileId\x12.\n" +
+	"\twatermark\x18\x02 \x01(\v2\x10.WatermarkPolicyR\twatermark\x12\x16\n" +
+	"\x06format\x18\x03 \x01(\tR\x06format\x12\x18\n" +
+	"\aversion\x18\x04 \x01(\x05R\aversion\"A\n" +
+	"\x0fGetFileResponse\x12\x1a\n" +
+	"\bfilename\x18\x01 \x01(\tR\bfilename\x12\x12\n" +
+	"\x04data\x18\x02 \x01(\fR\x04data\"K\n" +
+	"\x10ListFilesRequest\x12\x14\n" +
+	"\x05color\x18\x01 \x01(\tR\x05color\x12!\n" +
+	"\fmax_distance\x18\x02 \x01(\x01R\vmaxDistance\"4\n" +
+	"\x11ListFilesResponse\x12\x1f\n" +
+	"\x05files\x18\x01 \x03(\v2\t.FileInfoR\x05files\"\xe8\x05\n" +
+	"\bFileInfo\x12\x17\n" +
+	"\afile_id\x18\x01 \x01(\tR\x06fileId\x12\x1a\n" +
+	"\bfilename\x18\x02 \x01(\tR\bfilename\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\x03 \x01(\x03R\tcreatedAt\x12\x1d\n" +
+	"\n" +
+	"updated_at\x18\x04 \x01(\x03R\tupdatedAt\x12\x18\n" +
+	"\apalette\x18\x05 \x03(\tR\apalette\x12\x1c\n" +
+	"\thistogram\x18\x06 \x03(\rR\thistogram\x12\x1f\n" +
+	"\vframe_count\x18\a \x01(\x05R\n" +
+	"frameCount\x12&\n" +
+	"\x0fframe_delays_ms\x18\b \x03(\x05R\rframeDelaysMs\x12\x1f\n" +
+	"\vduration_ms\x18\t \x01(\x03R\n" +
+	"durationMs\x12\x1d\n" +
+	"\n" +
+	"variant_of\x18\n" +
+	" \x01(\tR\tvariantOf\x12$\n" +
+	"\bvariants\x18\v \x03(\v2\b.VariantR\bvariants\x12\x1a\n" +
+	"\bblurhash\x18\f \x01(\tR\bblurhash\x12\x16\n" +
+	"\x06format\x18\r \x01(\tR\x06format\x12\x1c\n" +
+	"\tsanitized\x18\x0e \x01(\bR\tsanitized\x120\n" +
+	"\adigests\x18\x0f \x03(\v2\x16.FileInfo.DigestsEntryR\adigests\x12\x1d\n" +
+	"\n" +
+	"legacy_ids\x18\x10 \x03(\tR\tlegacyIds\x12\x14\n" +
+	"\x05owner\x18\x11 \x01(\tR\x05owner\x12\x17\n" +
+	"\ablob_id\x18\x12 \x01(\tR\x06blobId\x12\x1d\n" +
+	"\n" +
+	"expires_at\x18\x13 \x01(\x03R\texpiresAt\x12\x1c\n" +
+	"\tcorrupted\x18\x14 \x01(\bR\tcorrupted\x12\x18\n" +
+	"\aversion\x18\x15 \x01(\x05R\aversion\x12\x1d\n" +
+	"\n" +
+	"version_of\x18\x16 \x01(\tR\tversionOf\x1a:\n" +
+	"\fDigestsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"-\n" +
+	"\x12GetFileInfoRequest\x12\x17\n" +
+	"\afile_id\x18\x01 \x01(\tR\x06fileId\"4\n" +
+	"\x13GetFileInfoResponse\x12\x1d\n" +
+	"\x04file\x18\x01 \x01(\v2\t.FileInfoR\x04file\"6\n" +
+	"\aVariant\x12\x12\n" +
+	"\x04kind\x18\x01 \x01(\tR\x04kind\x12\x17\n" +
+	"\afile_id\x18\x02 \x01(\tR\x06fileId\"X\n" +
+	"\x0fGetFrameRequest\x12\x17\n" +
+	"\afile_id\x18\x01 \x01(\tR\x06fileId\x12\x14\n" +
+	"\x05index\x18\x02 \x01(\x05R\x05index\x12\x16\n" +
+	"\x06poster\x18\x03 \x01(\bR\x06poster\"x\n" +
+	"\x10GetFrameResponse\x12\x12\n" +
+	"\x04data\x18\x01 \x01(\fR\x04data\x12\x14\n" +
+	"\x05index\x18\x02 \x01(\x05R\x05index\x12\x1f\n" +
+	"\vframe_count\x18\x03 \x01(\x05R\n" +
+	"frameCount\x12\x19\n" +
+	"\bdelay_ms\x18\x04 \x01(\x05R\adelayMs\"J\n" +
+	"\x15GetSpriteSheetRequest\x12\x17\n" +
+	"\afile_id\x18\x01 \x01(\tR\x06fileId\x12\x18\n" +
+	"\acolumns\x18\x02 \x01(\x05R\acolumns\"I\n" +
+	"\x16GetSpriteSheetResponse\x12\x12\n" +
+	"\x04data\x18\x01 \x01(\fR\x04data\x12\x1b\n" +
+	"\tframe_map\x18\x02 \x01(\tR\bframeMap\"\x8c\x02\n" +
+	"\x13ContactSheetRequest\x12\x19\n" +
+	"\bfile_ids\x18\x01 \x03(\tR\afileIds\x12\x18\n" +
+	"\acolumns\x18\x02 \x01(\x05R\acolumns\x12\x1d\n" +
+	"\n" +
+	"cell_width\x18\x03 \x01(\x05R\tcellWidth\x12\x1f\n" +
+	"\vcell_height\x18\x04 \x01(\x05R\n" +
+	"cellHeight\x12\x18\n" +
+	"\apadding\x18\x05 \x01(\x05R\apadding\x12\x1e\n" +
+	"\n" +
+	"background\x18\x06 \x01(\tR\n" +
+	"background\x12\x1a\n" +
+	"\bcaptions\x18\a \x01(\bR\bcaptions\x12\x16\n" +
+	"\x06format\x18\b \x01(\tR\x06format\x12\x12\n" +
+	"\x04crop\x18\t \x01(\tR\x04crop\"c\n" +
+	"\x14ContactSheetResponse\x12\x12\n" +
+	"\x04data\x18\x01 \x01(\fR\x04data\x12\x16\n" +
+	"\x06format\x18\x02 \x01(\tR\x06format\x12\x1f\n" +
+	"\x05crops\x18\x03 \x03(\v2\t.CropRectR\x05crops\"T\n" +
+	"\bCropRect\x12\f\n" +
+	"\x01x\x18\x01 \x01(\x05R\x01x\x12\f\n" +
+	"\x01y\x18\x02 \x01(\x05R\x01y\x12\x14\n" +
+	"\x05width\x18\x03 \x01(\x05R\x05width\x12\x16\n" +
+	"\x06height\x18\x04 \x01(\x05R\x06height\"\x9d\x01\n" +
+	"\x0fWatermarkPolicy\x12*\n" +
+	"\x11watermark_file_id\x18\x01 \x01(\tR\x0fwatermarkFileId\x12\x1a\n" +
+	"\bposition\x18\x02 \x01(\tR\bposition\x12\x18\n" +
+	"\aopacity\x18\x03 \x01(\x01R\aopacity\x12\x14\n" +
+	"\x05scale\x18\x04 \x01(\x01R\x05scale\x12\x12\n" +
+	"\x04tile\x18\x05 \x01(\bR\x04tile\"h\n" +
+	"\x1dCreateWatermarkVariantRequest\x12\x17\n" +
+	"\afile_id\x18\x01 \x01(\tR\x06fileId\x12.\n" +
+	"\twatermark\x18\x02 \x01(\v2\x10.WatermarkPolicyR\twatermark\"9\n" +
+	"\x1eCreateWatermarkVariantResponse\x12\x17\n" +
+	"\afile_id\x18\x01 \x01(\tR\x06fileId\"\xab\x01\n" +
+	"\x14CompareImagesRequest\x12\x1a\n" +
+	"\tfile_id_a\x18\x01 \x01(\tR\afileIdA\x12\x1a\n" +
+	"\tfile_id_b\x18\x02 \x01(\tR\afileIdB\x12\x1c\n" +
+	"\ttolerance\x18\x03 \x01(\x05R\ttolerance\x12!\n" +
+	"\finclude_diff\x18\x04 \x01(\bR\vincludeDiff\x12\x1a\n" +
+	"\bmismatch\x18\x05 \x01(\tR\bmismatch\"\x9d\x01\n" +
+	"\x15CompareImagesResponse\x12\x12\n" +
+	"\x04psnr\x18\x01 \x01(\x01R\x04psnr\x12\x12\n" +
+	"\x04ssim\x18\x02 \x01(\x01R\x04ssim\x12%\n" +
+	"\x0echanged_pixels\x18\x03 \x01(\x03R\rchangedPixels\x12!\n" +
+	"\ftotal_pixels\x18\x04 \x01(\x03R\vtotalPixels\x12\x12\n" +
+	"\x04diff\x18\x05 \x01(\fR\x04diff\"\xcd\x01\n" +
+	"\x10CropImageRequest\x12\x17\n" +
+	"\afile_id\x18\x01 \x01(\tR\x06fileId\x12!\n" +
+	"\faspect_width\x18\x02 \x01(\x05R\vaspectWidth\x12#\n" +
+	"\raspect_height\x18\x03 \x01(\x05R\faspectHeight\x12\x14\n" +
+	"\x05width\x18\x04 \x01(\x05R\x05width\x12\x16\n" +
+	"\x06height\x18\x05 \x01(\x05R\x06height\x12\x12\n" +
+	"\x04mode\x18\x06 \x01(\tR\x04mode\x12\x16\n" +
+	"\x06format\x18\a \x01(\tR\x06format\"^\n" +
+	"\x11CropImageResponse\x12\x12\n" +
+	"\x04data\x18\x01 \x01(\fR\x04data\x12\x16\n" +
+	"\x06format\x18\x02 \x01(\tR\x06format\x12\x1d\n" +
+	"\x04crop\x18\x03 \x01(\v2\t.CropRectR\x04crop\"_\n" +
+	"\x0fOptimizeRequest\x12\x17\n" +
+	"\afile_id\x18\x01 \x01(\tR\x06fileId\x12\x18\n" +
+	"\aquality\x18\x02 \x01(\x05R\aquality\x12\x19\n" +
+	"\bmin_ssim\x18\x03 \x01(\x01R\aminSsim\"\xb6\x01\n" +
+	"\x10OptimizeResponse\x12\x1d\n" +
+	"\n" +
+	"variant_id\x18\x01 \x01(\tR\tvariantId\x12\x16\n" +
+	"\x06format\x18\x02 \x01(\tR\x06format\x12#\n" +
+	"\roriginal_size\x18\x03 \x01(\x03R\foriginalSize\x12%\n" +
+	"\x0eoptimized_size\x18\x04 \x01(\x03R\roptimizedSize\x12\x1f\n" +
+	"\vbytes_saved\x18\x05 \x01(\x03R\n" +
+	"bytesSaved\"\x11\n" +
+	"\x0fGetQuotaRequest\"\xb6\x01\n" +
+	"\x10GetQuotaResponse\x12\x14\n" +
+	"\x05owner\x18\x01 \x01(\tR\x05owner\x12\x1c\n" +
+	"\x05usage\x18\x02 \x01(\v2\x06.UsageR\x05usage\x12\x1c\n" +
+	"\x05quota\x18\x03 \x01(\v2\x06.QuotaR\x05quota\x12'\n" +
+	"\vtotal_usage\x18\x04 \x01(\v2\x06.UsageR\n" +
+	"totalUsage\x12'\n" +
+	"\vtotal_quota\x18\x05 \x01(\v2\x06.QuotaR\n" +
+	"totalQuota\"3\n" +
+	"\x05Usage\x12\x14\n" +
+	"\x05files\x18\x01 \x01(\x03R\x05files\x12\x14\n" +
+	"\x05bytes\x18\x02 \x01(\x03R\x05bytes\"A\n" +
+	"\x05Quota\x12\x1b\n" +
+	"\tmax_files\x18\x01 \x01(\x03R\bmaxFiles\x12\x1b\n" +
+	"\tmax_bytes\x18\x02 \x01(\x03R\bmaxBytes\"k\n" +
+	"\x10SetExpiryRequest\x12\x17\n" +
+	"\afile_id\x18\x01 \x01(\tR\x06fileId\x12\x1f\n" +
+	"\vttl_seconds\x18\x02 \x01(\x03R\n" +
+	"ttlSeconds\x12\x1d\n" +
+	"\n" +
+	"expires_at\x18\x03 \x01(\x03R\texpiresAt\"2\n" +
+	"\x11SetExpiryResponse\x12\x1d\n" +
+	"\n" +
+	"expires_at\x18\x01 \x01(\x03R\texpiresAt\"%\n" +
+	"\vFsckRequest\x12\x16\n" +
+	"\x06repair\x18\x01 \x01(\bR\x06repair\"\x9f\x01\n" +
+	"\tFsckIssue\x12\x12\n" +
+	"\x04kind\x18\x01 \x01(\tR\x04kind\x12\x17\n" +
+	"\ablob_id\x18\x02 \x01(\tR\x06blobId\x12\x19\n" +
+	"\bfile_ids\x18\x03 \x03(\tR\afileIds\x12\x16\n" +
+	"\x06detail\x18\x04 \x01(\tR\x06detail\x12\x1a\n" +
+	"\brepaired\x18\x05 \x01(\bR\brepaired\x12\x16\n" +
+	"\x06action\x18\x06 \x01(\tR\x06action\"\xb6\x01\n" +
+	"\fFsckResponse\x12\x1d\n" +
 	"\n" +
-	"updated_at\x18\x04 \x01(\x03R\tupdatedAt2\xa6\x01\n" +
+	"started_at\x18\x01 \x01(\x03R\tstartedAt\x12\x1f\n" +
+	"\vduration_ms\x18\x02 \x01(\x03R\n" +
+	"durationMs\x12\x16\n" +
+	"\x06repair\x18\x03 \x01(\bR\x06repair\x12\x14\n" +
+	"\x05files\x18\x04 \x01(\x03R\x05files\x12\x14\n" +
+	"\x05blobs\x18\x05 \x01(\x03R\x05blobs\x12\"\n" +
+	"\x06issues\x18\x06 \x03(\v2\n" +
+	".FsckIssueR\x06issues\".\n" +
+	"\x13ListVersionsRequest\x12\x17\n" +
+	"\afile_id\x18\x01 \x01(\tR\x06fileId\"=\n" +
+	"\x14ListVersionsResponse\x12%\n" +
+	"\bversions\x18\x01 \x03(\v2\t.FileInfoR\bversions\"J\n" +
+	"\x15RestoreVersionRequest\x12\x17\n" +
+	"\afile_id\x18\x01 \x01(\tR\x06fileId\x12\x18\n" +
+	"\aversion\x18\x02 \x01(\x05R\aversion\"2\n" +
+	"\x16RestoreVersionResponse\x12\x18\n" +
+	"\aversion\x18\x01 \x01(\x05R\aversion2\x9b\a\n" +
 	"\vFileService\x125\n" +
 	"\n" +
 	"UploadFile\x12\x12.UploadFileRequest\x1a\x13.UploadFileResponse\x12,\n" +
 	"\aGetFile\x12\x0f.GetFileRequest\x1a\x10.GetFileResponse\x122\n" +
-	"\tListFiles\x12\x11.ListFilesRequest\x1a\x12.ListFilesResponseB\x06Z\x04/genb\x06proto3"
+	"\tListFiles\x12\x11.ListFilesRequest\x1a\x12.ListFilesResponse\x128\n" +
+	"\vGetFileInfo\x12\x13.GetFileInfoRequest\x1a\x14.GetFileInfoResponse\x12/\n" +
+	"\bGetFrame\x12\x10.GetFrameRequest\x1a\x11.GetFrameResponse\x12A\n" +
+	"\x0eGetSpriteSheet\x12\x16.GetSpriteSheetRequest\x1a\x17.GetSpriteSheetResponse\x12;\n" +
+	"\fContactSheet\x12\x14.ContactSheetRequest\x1a\x15.ContactSheetResponse\x12Y\n" +
+	"\x16CreateWatermarkVariant\x12\x1e.CreateWatermarkVariantRequest\x1a\x1f.CreateWatermarkVariantResponse\x12>\n" +
+	"\rCompareImages\x12\x15.CompareImagesRequest\x1a\x16.CompareImagesResponse\x122\n" +
+	"\tCropImage\x12\x11.CropImageRequest\x1a\x12.CropImageResponse\x12/\n" +
+	"\bOptimize\x12\x10.OptimizeRequest\x1a\x11.OptimizeResponse\x12/\n" +
+	"\bGetQuota\x12\x10.GetQuotaRequest\x1a\x11.GetQuotaResponse\x122\n" +
+	"\tSetExpiry\x12\x11.SetExpiryRequest\x1a\x12.SetExpiryResponse\x12#\n" +
+	"\x04Fsck\x12\f.FsckRequest\x1a\r.FsckResponse\x12;\n" +
+	"\fListVersions\x12\x14.ListVersionsRequest\x1a\x15.ListVersionsResponse\x12A\n" +
+	"\x0eRestoreVersion\x12\x16.RestoreVersionRequest\x1a\x17.RestoreVersionResponseB\x06Z\x04/genb\x06proto3"
 
 var (
 	file_api_file_proto_rawDescOnce sync.Once
@@ -404,29 +2754,101 @@ func file_api_file_proto_rawDescGZIP() []byte {
 	return file_api_file_proto_rawDescData
 }
 
-var file_api_file_proto_msgTypes = make([]protoimpl.MessageInfo, 7)
+var file_api_file_proto_msgTypes = make([]protoimpl.MessageInfo, 40)
 var file_api_file_proto_goTypes = []any{
-	(*UploadFileRequest)(nil),  // 0: UploadFileRequest
-	(*UploadFileResponse)(nil), // 1: UploadFileResponse
-	(*GetFileRequest)(nil),     // 2: GetFileRequest
-	(*GetFileResponse)(nil),    // 3: GetFileResponse
-	(*ListFilesRequest)(nil),   // 4: ListFilesRequest
-	(*ListFilesResponse)(nil),  // 5: ListFilesResponse
-	(*FileInfo)(nil),           // 6: FileInfo
+	(*UploadFileRequest)(nil),              // 0: UploadFileRequest
+	(*UploadFileResponse)(nil),             // 1: UploadFileResponse
+	(*GetFileRequest)(nil),                 // 2: GetFileRequest
+	(*GetFileResponse)(nil),                // 3: GetFileResponse
+	(*ListFilesRequest)(nil),               // 4: ListFilesRequest
+	(*ListFilesResponse)(nil),              // 5: ListFilesResponse
+	(*FileInfo)(nil),                       // 6: FileInfo
+	(*GetFileInfoRequest)(nil),             // 7: GetFileInfoRequest
+	(*GetFileInfoResponse)(nil),            // 8: GetFileInfoResponse
+	(*Variant)(nil),                        // 9: Variant
+	(*GetFrameRequest)(nil),                // 10: GetFrameRequest
+	(*GetFrameResponse)(nil),               // 11: GetFrameResponse
+	(*GetSpriteSheetRequest)(nil),          // 12: GetSpriteSheetRequest
+	(*GetSpriteSheetResponse)(nil),         // 13: GetSpriteSheetResponse
+	(*ContactSheetRequest)(nil),            // 14: ContactSheetRequest
+	(*ContactSheetResponse)(nil),           // 15: ContactSheetResponse
+	(*CropRect)(nil),                       // 16: CropRect
+	(*WatermarkPolicy)(nil),                // 17: WatermarkPolicy
+	(*CreateWatermarkVariantRequest)(nil),  // 18: CreateWatermarkVariantRequest
+	(*CreateWatermarkVariantResponse)(nil), // 19: CreateWatermarkVariantResponse
+	(*CompareImagesRequest)(nil),           // 20: CompareImagesRequest
+	(*CompareImagesResponse)(nil),          // 21: CompareImagesResponse
+	(*CropImageRequest)(nil),               // 22: CropImageRequest
+	(*CropImageResponse)(nil),              // 23: CropImageResponse
+	(*OptimizeRequest)(nil),                // 24: OptimizeRequest
+	(*OptimizeResponse)(nil),               // 25: OptimizeResponse
+	(*GetQuotaRequest)(nil),                // 26: GetQuotaRequest
+	(*GetQuotaResponse)(nil),               // 27: GetQuotaResponse
+	(*Usage)(nil),                          // 28: Usage
+	(*Quota)(nil),                          // 29: Quota
+	(*SetExpiryRequest)(nil),               // 30: SetExpiryRequest
+	(*SetExpiryResponse)(nil),              // 31: SetExpiryResponse
+	(*FsckRequest)(nil),                    // 32: FsckRequest
+	(*FsckIssue)(nil),                      // 33: FsckIssue
+	(*FsckResponse)(nil),                   // 34: FsckResponse
+	(*ListVersionsRequest)(nil),            // 35: ListVersionsRequest
+	(*ListVersionsResponse)(nil),           // 36: ListVersionsResponse
+	(*RestoreVersionRequest)(nil),          // 37: RestoreVersionRequest
+	(*RestoreVersionResponse)(nil),         // 38: RestoreVersionResponse
+	nil,                                    // 39: FileInfo.DigestsEntry
 }
 var file_api_file_proto_depIdxs = []int32{
-	6, // 0: ListFilesResponse.files:type_name -> FileInfo
-	0, // 1: FileService.UploadFile:input_type -> UploadFileRequest
-	2, // 2: FileService.GetFile:input_type -> GetFileRequest
-	4, // 3: FileService.ListFiles:input_type -> ListFilesRequest
-	1, // 4: FileService.UploadFile:output_type -> UploadFileResponse
-	3, // 5: FileService.GetFile:output_type -> GetFileResponse
-	5, // 6: FileService.ListFiles:output_type -> ListFilesResponse
-	4, // [4:7] is the sub-list for method output_type
-	1, // [1:4] is the sub-list for method input_type
-	1, // [1:1] is the sub-list for extension type_name
-	1, // [1:1] is the sub-list for extension extendee
-	0, // [0:1] is the sub-list for field type_name
+	17, // 0: GetFileRequest.watermark:type_name -> WatermarkPolicy
+	6,  // 1: ListFilesResponse.files:type_name -> FileInfo
+	9,  // 2: FileInfo.variants:type_name -> Variant
+	39, // 3: FileInfo.digests:type_name -> FileInfo.DigestsEntry
+	6,  // 4: GetFileInfoResponse.file:type_name -> FileInfo
+	16, // 5: ContactSheetResponse.crops:type_name -> CropRect
+	17, // 6: CreateWatermarkVariantRequest.watermark:type_name -> WatermarkPolicy
+	16, // 7: CropImageResponse.crop:type_name -> CropRect
+	28, // 8: GetQuotaResponse.usage:type_name -> Usage
+	29, // 9: GetQuotaResponse.quota:type_name -> Quota
+	28, // 10: GetQuotaResponse.total_usage:type_name -> Usage
+	29, // 11: GetQuotaResponse.total_quota:type_name -> Quota
+	33, // 12: FsckResponse.issues:type_name -> FsckIssue
+	6,  // 13: ListVersionsResponse.versions:type_name -> FileInfo
+	0,  // 14: FileService.UploadFile:input_type -> UploadFileRequest
+	2,  // 15: FileService.GetFile:input_type -> GetFileRequest
+	4,  // 16: FileService.ListFiles:input_type -> ListFilesRequest
+	7,  // 17: FileService.GetFileInfo:input_type -> GetFileInfoRequest
+	10, // 18: FileService.GetFrame:input_type -> GetFrameRequest
+	12, // 19: FileService.GetSpriteSheet:input_type -> GetSpriteSheetRequest
+	14, // 20: FileService.ContactSheet:input_type -> ContactSheetRequest
+	18, // 21: FileService.CreateWatermarkVariant:input_type -> CreateWatermarkVariantRequest
+	20, // 22: FileService.CompareImages:input_type -> CompareImagesRequest
+	22, // 23: FileService.CropImage:input_type -> CropImageRequest
+	24, // 24: FileService.Optimize:input_type -> OptimizeRequest
+	26, // 25: FileService.GetQuota:input_type -> GetQuotaRequest
+	30, // 26: FileService.SetExpiry:input_type -> SetExpiryRequest
+	32, // 27: FileService.Fsck:input_type -> FsckRequest
+	35, // 28: FileService.ListVersions:input_type -> ListVersionsRequest
+	37, // 29: FileService.RestoreVersion:input_type -> RestoreVersionRequest
+	1,  // 30: FileService.UploadFile:output_type -> UploadFileResponse
+	3,  // 31: FileService.GetFile:output_type -> GetFileResponse
+	5,  // 32: FileService.ListFiles:output_type -> ListFilesResponse
+	8,  // 33: FileService.GetFileInfo:output_type -> GetFileInfoResponse
+	11, // 34: FileService.GetFrame:output_type -> GetFrameResponse
+	13, // 35: FileService.GetSpriteSheet:output_type -> GetSpriteSheetResponse
+	15, // 36: FileService.ContactSheet:output_type -> ContactSheetResponse
+	19, // 37: FileService.CreateWatermarkVariant:output_type -> CreateWatermarkVariantResponse
+	21, // 38: FileService.CompareImages:output_type -> CompareImagesResponse
+	23, // 39: FileService.CropImage:output_type -> CropImageResponse
+	25, // 40: FileService.Optimize:output_type -> OptimizeResponse
+	27, // 41: FileService.GetQuota:output_type -> GetQuotaResponse
+	31, // 42: FileService.SetExpiry:output_type -> SetExpiryResponse
+	34, // 43: FileService.Fsck:output_type -> FsckResponse
+	36, // 44: FileService.ListVersions:output_type -> ListVersionsResponse
+	38, // 45: FileService.RestoreVersion:output_type -> RestoreVersionResponse
+	30, // [30:46] is the sub-list for method output_type
+	14, // [14:30] is the sub-list for method input_type
+	14, // [14:14] is the sub-list for extension type_name
+	14, // [14:14] is the sub-list for extension extendee
+	0,  // [0:14] is the sub-list for field type_name
 }
 
 func init() { file_api_file_proto_init() }
@@ -440,7 +2862,7 @@ func file_api_file_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_api_file_proto_rawDesc), len(file_api_file_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   7,
+			NumMessages:   40,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -6,10 +6,12 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
 )
 
 type Client struct {
@@ -17,14 +19,19 @@ type Client struct {
 	client gen.FileServiceClient
 }
 
+// apiKeyHeader is the metadata key the server reads the API key from
+const apiKeyHeader = "x-api-key"
+
 // NewClient creates a new client for connection to file FileServiceClient
-func NewClient(addr string) (*Client, error) {
+// apiKey is sent with every request, empty apiKey means anonymous access
+func NewClient(addr, apiKey string) (*Client, error) {
 	// Creating new conn w/ timeout
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
 	conn, err := grpc.DialContext(ctx, addr,
 		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithChainUnaryInterceptor(apiKeyInterceptor(apiKey), fileIDInterceptor()),
 		grpc.WithBlock(),
 	)
 	if err != nil {
@@ -37,15 +44,27 @@ func NewClient(addr string) (*Client, error) {
 	}, nil
 }
 
+// apiKeyInterceptor attaches API key to outgoing requests
+func apiKeyInterceptor(apiKey string) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if apiKey != "" {
+			ctx = metadata.AppendToOutgoingContext(ctx, apiKeyHeader, apiKey)
+		}
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
+
 // UploadFile uploads file into the SERVER
-func (c *Client) UploadFile(ctx context.Context, filename string, data []byte) (string, error) {
+// ttl > 0 makes the server delete the file after that time, 0 keeps it forever
+func (c *Client) UploadFile(ctx context.Context, filename string, data []byte, ttl time.Duration) (string, error) {
 	// creating ctx w/ timout for UploadFile
 	uploadCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
 	defer cancel()
 
 	resp, err := c.client.UploadFile(uploadCtx, &gen.UploadFileRequest{
-		Filename: filename,
-		Data:     data,
+		Filename:   filename,
+		Data:       data,
+		TtlSeconds: int64(ttl / time.Second),
 	})
 	if err != nil {
 		return "", fmt.Errorf("UPLOAD FAILED: %w", err)
@@ -68,26 +87,120 @@ func (c *Client) DownloadFile(ctx context.Context, fileID string) (*gen.GetFileR
 	return resp, nil
 }
 
+// DownloadRequestToPath downloads file with extra request options (e.g. watermark) into outputPath
+func (c *Client) DownloadRequestToPath(ctx context.Context, req *gen.GetFileRequest, outputPath string) error {
+	// creating ctx w/ timout for DownloadFile
+	downloadCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	resp, err := c.client.GetFile(downloadCtx, req)
+	if err != nil {
+		return fmt.Errorf("DOWNLOAD FAILED: %w", err)
+	}
+
+	return writeFile(outputPath, resp.Data)
+}
+
 // ListFiles recieving list of files from SERVER
 func (c *Client) ListFiles(ctx context.Context) (*gen.ListFilesResponse, error) {
+	return c.ListFilesByColor(ctx, "", 0)
+}
+
+// ListFilesByColor recieving list of images whose palette is close to the given color
+// empty color means no filter, zero maxDistance means server default
+func (c *Client) ListFilesByColor(ctx context.Context, color string, maxDistance float64) (*gen.ListFilesResponse, error) {
 	// creating ctx w/ timeout for recieve list of files
 	listCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
 	defer cancel()
 
-	resp, err := c.client.ListFiles(listCtx, &gen.ListFilesRequest{})
+	resp, err := c.client.ListFiles(listCtx, &gen.ListFilesRequest{
+		Color:       color,
+		MaxDistance: maxDistance,
+	})
 	if err != nil {
 		return nil, fmt.Errorf("RECIEVING LIST OF FILES FAILED: %w", err)
 	}
 	return resp, nil
 }
 
+// GetFileInfo recieving file metadata w/o content
+func (c *Client) GetFileInfo(ctx context.Context, fileID string) (*gen.FileInfo, error) {
+	// creating ctx w/ timeout for GetFileInfo
+	infoCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	resp, err := c.client.GetFileInfo(infoCtx, &gen.GetFileInfoRequest{FileId: fileID})
+	if err != nil {
+		return nil, fmt.Errorf("RECIEVING FILE INFO FAILED: %w", err)
+	}
+	return resp.File, nil
+}
+
 // UploadFileFromPath
-func (c *Client) UploadFileFromPath(ctx context.Context, filePath string) (string, error) {
+func (c *Client) UploadFileFromPath(ctx context.Context, filePath string, ttl time.Duration) (string, error) {
 	data, err := os.ReadFile(filePath)
 	if err != nil {
 		return "", fmt.Errorf("FAILED TO READ FILE %s: %w", filePath, err)
 	}
 
+	return c.UploadFile(ctx, baseName(filePath), data, ttl)
+}
+
+// ReplaceFile uploads data as a new version of the file, which keeps its ID, and returns the new version number
+func (c *Client) ReplaceFile(ctx context.Context, fileID, filename string, data []byte) (int32, error) {
+	// creating ctx w/ timout for UploadFile
+	uploadCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	resp, err := c.client.UploadFile(uploadCtx, &gen.UploadFileRequest{
+		Filename:      filename,
+		Data:          data,
+		ReplaceFileId: fileID,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("REPLACE FAILED: %w", err)
+	}
+	return resp.Version, nil
+}
+
+// ReplaceFileFromPath uploads the local file as a new version of the file
+func (c *Client) ReplaceFileFromPath(ctx context.Context, fileID, filePath string) (int32, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return 0, fmt.Errorf("FAILED TO READ FILE %s: %w", filePath, err)
+	}
+
+	return c.ReplaceFile(ctx, fileID, baseName(filePath), data)
+}
+
+// ListVersions returns all versions of the file, latest first
+func (c *Client) ListVersions(ctx context.Context, fileID string) ([]*gen.FileInfo, error) {
+	// creating ctx w/ timeout for ListVersions
+	listCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	resp, err := c.client.ListVersions(listCtx, &gen.ListVersionsRequest{FileId: fileID})
+	if err != nil {
+		return nil, fmt.Errorf("RECIEVING FILE VERSIONS FAILED: %w", err)
+	}
+	return resp.Versions, nil
+}
+
+// RestoreVersion makes the content of a previous version the latest one and returns the new version number
+func (c *Client) RestoreVersion(ctx context.Context, fileID string, version int32) (int32, error) {
+	// creating ctx w/ timout for RestoreVersion
+	restoreCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	resp, err := c.client.RestoreVersion(restoreCtx, &gen.RestoreVersionRequest{FileId: fileID, Version: version})
+	if err != nil {
+		return 0, fmt.Errorf("RESTORE FAILED: %w", err)
+	}
+	return resp.Version, nil
+}
+
+// baseName returns the file name part of a local path
+func baseName(filePath string) string {
 	filename := filePath
 	if lastSlash := lastIndex(filePath, "/"); lastSlash != -1 {
 		filename = filePath[lastSlash+1:]
@@ -95,8 +208,15 @@ func (c *Client) UploadFileFromPath(ctx context.Context, filePath string) (strin
 	if lastSlash := lastIndex(filePath, "\\"); lastSlash != -1 {
 		filename = filePath[lastSlash+1:]
 	}
+	return filename
+}
 
-	return c.UploadFile(ctx, filename, data)
+// ConvertToPath downloads an image converted to the format matching outputPath extension
+func (c *Client) ConvertToPath(ctx context.Context, fileID, outputPath string) error {
+	return c.DownloadRequestToPath(ctx, &gen.GetFileRequest{
+		FileId: fileID,
+		Format: outputFormat(outputPath),
+	}, outputPath)
 }
 
 // DownloadFileToPath
@@ -106,6 +226,219 @@ func (c *Client) DownloadFileToPath(ctx context.Context, fileId, outputPath stri
 		return err
 	}
 
+	return writeFile(outputPath, resp.Data)
+}
+
+// GetFrame downloads a single animation frame as PNG, poster=true picks the poster frame
+func (c *Client) GetFrame(ctx context.Context, fileID string, index int, poster bool) (*gen.GetFrameResponse, error) {
+	// creating ctx w/ timout for GetFrame
+	frameCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	resp, err := c.client.GetFrame(frameCtx, &gen.GetFrameRequest{
+		FileId: fileID,
+		Index:  int32(index),
+		Poster: poster,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("GET FRAME FAILED: %w", err)
+	}
+	return resp, nil
+}
+
+// GetFrameToPath downloads a single animation frame as PNG into outputPath
+func (c *Client) GetFrameToPath(ctx context.Context, fileID string, index int, poster bool, outputPath string) (*gen.GetFrameResponse, error) {
+	resp, err := c.GetFrame(ctx, fileID, index, poster)
+	if err != nil {
+		return nil, err
+	}
+	return resp, writeFile(outputPath, resp.Data)
+}
+
+// SpriteSheetToPath converts animated GIF into a PNG sprite sheet
+// and writes the JSON frame map next to it (<outputPath>.json)
+func (c *Client) SpriteSheetToPath(ctx context.Context, fileID string, columns int, outputPath string) error {
+	// creating ctx w/ timout for GetSpriteSheet
+	sheetCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	resp, err := c.client.GetSpriteSheet(sheetCtx, &gen.GetSpriteSheetRequest{
+		FileId:  fileID,
+		Columns: int32(columns),
+	})
+	if err != nil {
+		return fmt.Errorf("SPRITE SHEET FAILED: %w", err)
+	}
+
+	if err := writeFile(outputPath, resp.Data); err != nil {
+		return err
+	}
+	return writeFile(outputPath+".json", []byte(resp.FrameMap))
+}
+
+// ContactSheetToPath composes images into a single contact sheet and writes it to outputPath
+// output format is chosen by extension (.jpg/.jpeg -> jpeg, otherwise png)
+func (c *Client) ContactSheetToPath(ctx context.Context, outputPath string, fileIDs []string) error {
+	// creating ctx w/ timout for ContactSheet
+	sheetCtx, cancel := context.WithTimeout(ctx, 60*time.Second)
+	defer cancel()
+
+	resp, err := c.client.ContactSheet(sheetCtx, &gen.ContactSheetRequest{
+		FileIds:  fileIDs,
+		Captions: true,
+		Padding:  8,
+		Format:   outputFormat(outputPath),
+	})
+	if err != nil {
+		return fmt.Errorf("CONTACT SHEET FAILED: %w", err)
+	}
+
+	return writeFile(outputPath, resp.Data)
+}
+
+// CropToPath crops the image to aspectW:aspectH (mode: smart or center) and writes it to outputPath
+// output format is chosen by extension, returns the chosen region of the source image
+func (c *Client) CropToPath(ctx context.Context, fileID string, aspectW, aspectH int, mode, outputPath string) (*gen.CropRect, error) {
+	// creating ctx w/ timout for CropImage
+	cropCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	resp, err := c.client.CropImage(cropCtx, &gen.CropImageRequest{
+		FileId:       fileID,
+		AspectWidth:  int32(aspectW),
+		AspectHeight: int32(aspectH),
+		Mode:         mode,
+		Format:       outputFormat(outputPath),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("CROP FAILED: %w", err)
+	}
+
+	if err := writeFile(outputPath, resp.Data); err != nil {
+		return nil, err
+	}
+	return resp.Crop, nil
+}
+
+// Optimize stores a size-optimized variant of the image, quality 0 means server default
+func (c *Client) Optimize(ctx context.Context, fileID string, quality int) (*gen.OptimizeResponse, error) {
+	// creating ctx w/ timout for Optimize
+	optimizeCtx, cancel := context.WithTimeout(ctx, 60*time.Second)
+	defer cancel()
+
+	resp, err := c.client.Optimize(optimizeCtx, &gen.OptimizeRequest{
+		FileId:  fileID,
+		Quality: int32(quality),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("OPTIMIZE FAILED: %w", err)
+	}
+	return resp, nil
+}
+
+// GetQuota returns the caller's storage usage and limits along with the server-wide totals
+func (c *Client) GetQuota(ctx context.Context) (*gen.GetQuotaResponse, error) {
+	// creating ctx w/ timout for GetQuota
+	quotaCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	resp, err := c.client.GetQuota(quotaCtx, &gen.GetQuotaRequest{})
+	if err != nil {
+		return nil, fmt.Errorf("GET QUOTA FAILED: %w", err)
+	}
+	return resp, nil
+}
+
+// SetExpiry changes when the server deletes the file, zero expiresAt keeps it forever
+func (c *Client) SetExpiry(ctx context.Context, fileID string, expiresAt time.Time) (time.Time, error) {
+	// creating ctx w/ timout for SetExpiry
+	expiryCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	req := &gen.SetExpiryRequest{FileId: fileID}
+	if !expiresAt.IsZero() {
+		req.ExpiresAt = expiresAt.Unix()
+	}
+	resp, err := c.client.SetExpiry(expiryCtx, req)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("SET EXPIRY FAILED: %w", err)
+	}
+	if resp.ExpiresAt == 0 {
+		return time.Time{}, nil
+	}
+	return time.Unix(resp.ExpiresAt, 0), nil
+}
+
+// Fsck runs the server storage consistency check, repair fixes found issues (requires an admin API key)
+func (c *Client) Fsck(ctx context.Context, repair bool) (*gen.FsckResponse, error) {
+	// creating ctx w/ timout for Fsck, the check reads every stored file
+	fsckCtx, cancel := context.WithTimeout(ctx, 10*time.Minute)
+	defer cancel()
+
+	resp, err := c.client.Fsck(fsckCtx, &gen.FsckRequest{Repair: repair})
+	if err != nil {
+		return nil, fmt.Errorf("FSCK FAILED: %w", err)
+	}
+	return resp, nil
+}
+
+// CreateWatermarkVariant stores a watermarked copy of the file and returns its ID
+func (c *Client) CreateWatermarkVariant(ctx context.Context, fileID string, watermark *gen.WatermarkPolicy) (string, error) {
+	// creating ctx w/ timout for CreateWatermarkVariant
+	variantCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	resp, err := c.client.CreateWatermarkVariant(variantCtx, &gen.CreateWatermarkVariantRequest{
+		FileId:    fileID,
+		Watermark: watermark,
+	})
+	if err != nil {
+		return "", fmt.Errorf("WATERMARK FAILED: %w", err)
+	}
+	return resp.FileId, nil
+}
+
+// CompareImages compares two images, diffPath != "" requests a diff image and writes it there
+func (c *Client) CompareImages(ctx context.Context, fileIDA, fileIDB, diffPath string) (*gen.CompareImagesResponse, error) {
+	// creating ctx w/ timout for CompareImages
+	compareCtx, cancel := context.WithTimeout(ctx, 60*time.Second)
+	defer cancel()
+
+	resp, err := c.client.CompareImages(compareCtx, &gen.CompareImagesRequest{
+		FileIdA:     fileIDA,
+		FileIdB:     fileIDB,
+		IncludeDiff: diffPath != "",
+	})
+	if err != nil {
+		return nil, fmt.Errorf("COMPARE FAILED: %w", err)
+	}
+
+	if diffPath != "" {
+		if err := writeFile(diffPath, resp.Diff); err != nil {
+			return nil, err
+		}
+	}
+	return resp, nil
+}
+
+// outputFormat picks image format by file extension (.jpg/.jpeg -> jpeg, .bmp, .tif/.tiff, otherwise png)
+// .webp is passed as is so the server reports it as unsupported instead of writing PNG into .webp
+func outputFormat(outputPath string) string {
+	switch strings.ToLower(filepath.Ext(outputPath)) {
+	case ".webp":
+		return "webp"
+	case ".jpg", ".jpeg":
+		return "jpeg"
+	case ".bmp":
+		return "bmp"
+	case ".tif", ".tiff":
+		return "tiff"
+	}
+	return "png"
+}
+
+// writeFile writes data into outputPath, creating parent dirs
+func writeFile(outputPath string, data []byte) error {
 	// check if outputPath is a dir
 	if stat, err := os.Stat(outputPath); err == nil && stat.IsDir() {
 		return fmt.Errorf("OUTPUT PATH IS A DIRECTORY: %s", outputPath)
@@ -117,7 +450,7 @@ func (c *Client) DownloadFileToPath(ctx context.Context, fileId, outputPath stri
 		return fmt.Errorf("FAILED TO CREATE DIRECTORY %s: %w", dir, err)
 	}
 
-	err = os.WriteFile(outputPath, resp.Data, 0644)
+	err := os.WriteFile(outputPath, data, 0644)
 	if err != nil {
 		return fmt.Errorf("FAILED TO WRITE FILE TO %s: %w", outputPath, err)
 	}
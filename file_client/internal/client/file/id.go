@@ -0,0 +1,87 @@
+package file
+
+import (
+	"context"
+	"errors"
+	"file_client/gen"
+	"fmt"
+	"strings"
+
+	"google.golang.org/grpc"
+)
+
+// File IDs are random IDs of logical files ("file-<hex>"). Files uploaded
+// before files and content were separated keep their content IDs: content
+// digests prefixed with the hash algorithm ("sha256-<hex>") or, for files
+// uploaded before the server moved to SHA-256, old unprefixed MD5 IDs.
+// All three formats are accepted.
+const (
+	fileIDPrefix   = "file-"
+	fileHexLen     = 32
+	sha256IDPrefix = "sha256-"
+	sha256HexLen   = 64
+	md5HexLen      = 32
+)
+
+// ErrInvalidFileID is returned for IDs in none of the known file ID formats
+var ErrInvalidFileID = errors.New("INVALID FILE ID")
+
+// ValidateFileID checks that id is a file ID, a SHA-256 ID or a legacy MD5 ID
+func ValidateFileID(id string) error {
+	if random, found := strings.CutPrefix(id, fileIDPrefix); found {
+		if isHex(random, fileHexLen) {
+			return nil
+		}
+	} else if digest, found := strings.CutPrefix(id, sha256IDPrefix); found {
+		if isHex(digest, sha256HexLen) {
+			return nil
+		}
+	} else if isHex(id, md5HexLen) {
+		return nil
+	}
+	return fmt.Errorf("%w '%s': expected %s<%d hex>, %s<%d hex> or a %d-character MD5 ID", ErrInvalidFileID, id, fileIDPrefix, fileHexLen, sha256IDPrefix, sha256HexLen, md5HexLen)
+}
+
+// isHex reports whether s is exactly length lowercase hex characters
+func isHex(s string, length int) bool {
+	if len(s) != length {
+		return false
+	}
+	for _, c := range s {
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+// fileIDInterceptor rejects requests with malformed file IDs before they reach the server
+func fileIDInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		for _, id := range requestFileIDs(req) {
+			if err := ValidateFileID(id); err != nil {
+				return err
+			}
+		}
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
+
+// requestFileIDs collects the file IDs referenced by a request
+func requestFileIDs(req interface{}) []string {
+	var ids []string
+	if r, ok := req.(interface{ GetFileId() string }); ok {
+		ids = append(ids, r.GetFileId())
+	}
+	if r, ok := req.(interface{ GetFileIds() []string }); ok {
+		ids = append(ids, r.GetFileIds()...)
+	}
+	if r, ok := req.(*gen.CompareImagesRequest); ok {
+		ids = append(ids, r.GetFileIdA(), r.GetFileIdB())
+	}
+	// Text watermarks have no watermark file
+	if r, ok := req.(interface{ GetWatermark() *gen.WatermarkPolicy }); ok && r.GetWatermark().GetWatermarkFileId() != "" {
+		ids = append(ids, r.GetWatermark().GetWatermarkFileId())
+	}
+	return ids
+}
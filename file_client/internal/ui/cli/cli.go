@@ -3,9 +3,11 @@ package cli
 import (
 	"bufio"
 	"context"
+	"file_client/gen"
 	"file_client/internal/client/file"
 	"fmt"
 	"os"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -50,8 +52,38 @@ func (c *CLI) Run() error {
 			c.handleUpload(args)
 		case "download":
 			c.handleDownload(args)
+		case "convert":
+			c.handleConvert(args)
 		case "list":
-			c.handleList()
+			c.handleList(args)
+		case "info":
+			c.handleInfo(args)
+		case "frame":
+			c.handleFrame(args)
+		case "sprite":
+			c.handleSprite(args)
+		case "sheet":
+			c.handleSheet(args)
+		case "watermark":
+			c.handleWatermark(args)
+		case "compare":
+			c.handleCompare(args)
+		case "crop":
+			c.handleCrop(args)
+		case "optimize":
+			c.handleOptimize(args)
+		case "expire":
+			c.handleExpire(args)
+		case "replace":
+			c.handleReplace(args)
+		case "versions":
+			c.handleVersions(args)
+		case "restore":
+			c.handleRestore(args)
+		case "quota":
+			c.handleQuota()
+		case "fsck":
+			c.handleFsck(args)
 		case "ping":
 			c.handlePing()
 		case "help":
@@ -75,9 +107,24 @@ func (c *CLI) printWelcome() {
 // handleUpload handles upload command
 func (c *CLI) printHelp() {
 	fmt.Println("Available commands:")
-	fmt.Println("  upload <file_path>                    - Upload a file to the server")
-	fmt.Println("  download <file_id> <path+filename>    - Download a file by ID to specified path")
-	fmt.Println("  list                                  - List all files on the server")
+	fmt.Println("  upload <file_path> [ttl]              - Upload a file to the server, optionally deleted after ttl (e.g. 24h)")
+	fmt.Println("  download <file_id>[@version] <path+filename> [mark_id] - Download a file (or its version) by ID, optionally watermarked")
+	fmt.Println("  convert <file_id> <out.png|jpg|bmp|tiff> - Download an image converted to the output format")
+	fmt.Println("  list [#rrggbb [max_distance]]         - List all files on the server, optionally by color")
+	fmt.Println("  info <file_id>                        - Show file metadata (palette, blurhash, animation, variants)")
+	fmt.Println("  frame <file_id> <index|poster> <out>  - Save an animation frame as PNG")
+	fmt.Println("  sprite <file_id> <out> [columns]      - Save GIF as PNG sprite sheet + <out>.json frame map")
+	fmt.Println("  sheet <out.png> <id1> <id2> ...       - Compose images into a contact sheet (png or jpg)")
+	fmt.Println("  watermark <file_id> <mark_id> [pos]   - Store a watermarked variant (pos: center, top-left, ..., tile)")
+	fmt.Println("  compare <id1> <id2> [diff.png]        - Compare two images (PSNR, SSIM, changed pixels)")
+	fmt.Println("  crop <file_id> <w:h> <out> [mode]     - Crop to aspect ratio (mode: smart (default) or center)")
+	fmt.Println("  optimize <file_id> [jpeg_quality]     - Store a size-optimized variant (PNG/JPEG)")
+	fmt.Println("  expire <file_id> <ttl|time|never>     - Change when a file is deleted (e.g. 2h, 2026-01-02T15:04:05Z)")
+	fmt.Println("  replace <file_id> <file_path>         - Upload a new version of a file, keeping its ID")
+	fmt.Println("  versions <file_id>                    - List versions of a file, latest first")
+	fmt.Println("  restore <file_id> <version>           - Make a previous version the latest one")
+	fmt.Println("  quota                                 - Show your storage usage and limits")
+	fmt.Println("  fsck [repair]                         - Check server storage consistency (admin API key)")
 	fmt.Println("  ping                                  - Check server availability")
 	fmt.Println("  help                                  - Show this help message")
 	fmt.Println("  quit/exit/q                           - Exit the client")
@@ -86,13 +133,23 @@ func (c *CLI) printHelp() {
 
 // handleUpload handles upload command
 func (c *CLI) handleUpload(args []string) {
-	if len(args) != 1 {
-		fmt.Println("Usage: upload <file_path>")
+	if len(args) < 1 || len(args) > 2 {
+		fmt.Println("Usage: upload <file_path> [ttl]")
 		return
 	}
 
 	filePath := args[0]
 
+	var ttl time.Duration
+	if len(args) == 2 {
+		d, err := time.ParseDuration(args[1])
+		if err != nil || d < time.Second {
+			fmt.Printf("ERROR: INVALID TTL '%s'\n", args[1])
+			return
+		}
+		ttl = d
+	}
+
 	// check file exists
 	if _, err := os.Stat(filePath); os.IsNotExist(err) {
 		fmt.Printf("ERROR: FILE '%s' DOES NOT EXIST\n", filePath)
@@ -102,7 +159,7 @@ func (c *CLI) handleUpload(args []string) {
 	fmt.Printf("Uploading file '%s'...\n", filePath)
 
 	start := time.Now()
-	fileID, err := c.client.UploadFileFromPath(context.Background(), filePath)
+	fileID, err := c.client.UploadFileFromPath(context.Background(), filePath, ttl)
 	duration := time.Since(start)
 
 	if err != nil {
@@ -112,22 +169,34 @@ func (c *CLI) handleUpload(args []string) {
 
 	fmt.Printf("File uploaded successfully!\n")
 	fmt.Printf("File ID: %s\n", fileID)
+	if ttl > 0 {
+		fmt.Printf("Expires: %s\n", time.Now().Add(ttl).Format("2006-01-02 15:04:05"))
+	}
 	fmt.Printf("Upload time %v\n", duration)
 }
 
 // handleDownload handles download command
 func (c *CLI) handleDownload(args []string) {
-	if len(args) != 2 {
-		fmt.Println("Usage: download <file_id> <output_path>")
+	if len(args) < 2 || len(args) > 3 {
+		fmt.Println("Usage: download <file_id>[@version] <output_path> [watermark_file_id]")
+		return
+	}
+	fileID, version, err := parseVersionRef(args[0])
+	if err != nil {
+		fmt.Printf("ERROR: %v\n", err)
 		return
 	}
-	fileID := args[0]
 	outputPath := args[1]
 
+	req := &gen.GetFileRequest{FileId: fileID, Version: version}
+	if len(args) == 3 {
+		req.Watermark = &gen.WatermarkPolicy{WatermarkFileId: args[2]}
+	}
+
 	fmt.Printf("Downloading file with ID '%s'...\n", fileID)
 
 	start := time.Now()
-	err := c.client.DownloadFileToPath(context.Background(), fileID, outputPath)
+	err = c.client.DownloadRequestToPath(context.Background(), req, outputPath)
 	duration := time.Since(start)
 
 	if err != nil {
@@ -140,11 +209,50 @@ func (c *CLI) handleDownload(args []string) {
 	fmt.Printf("Download time: %v\n", duration)
 }
 
-func (c *CLI) handleList() {
+// handleConvert handles convert command
+func (c *CLI) handleConvert(args []string) {
+	if len(args) != 2 {
+		fmt.Println("Usage: convert <file_id> <output_path.png|jpg|bmp|tiff>")
+		return
+	}
+
+	start := time.Now()
+	err := c.client.ConvertToPath(context.Background(), args[0], args[1])
+	duration := time.Since(start)
+
+	if err != nil {
+		fmt.Printf("ERROR CONVERTING FILE: %v\n", err)
+		return
+	}
+
+	fmt.Printf("Converted file saved to: %s\n", args[1])
+	fmt.Printf("Convert time: %v\n", duration)
+}
+
+func (c *CLI) handleList(args []string) {
+	if len(args) > 2 {
+		fmt.Println("Usage: list [#rrggbb [max_distance]]")
+		return
+	}
+
+	color := ""
+	maxDistance := 0.0
+	if len(args) > 0 {
+		color = args[0]
+	}
+	if len(args) > 1 {
+		d, err := strconv.ParseFloat(args[1], 64)
+		if err != nil {
+			fmt.Printf("ERROR: INVALID MAX DISTANCE '%s'\n", args[1])
+			return
+		}
+		maxDistance = d
+	}
+
 	fmt.Println("Fetching file list...")
 
 	start := time.Now()
-	resp, err := c.client.ListFiles(context.Background())
+	resp, err := c.client.ListFilesByColor(context.Background(), color, maxDistance)
 	duration := time.Since(start)
 
 	if err != nil {
@@ -158,8 +266,14 @@ func (c *CLI) handleList() {
 	}
 
 	fmt.Printf("Found %d files(s) (fetched in %v):\n", len(resp.Files), duration)
-	fmt.Printf("%-36s %-30s %-20s %-20s\n", "ID", "FILENAME", "CREATED", "UPDATED")
-	fmt.Println(strings.Repeat("-", 90))
+	// ID column fits the longest ID: SHA-256 IDs are longer than legacy MD5 ones
+	idWidth := len("ID")
+	for _, file := range resp.Files {
+		idWidth = max(idWidth, len(file.FileId))
+	}
+
+	fmt.Printf("%-*s %-30s %-20s %-20s %-8s\n", idWidth, "ID", "FILENAME", "CREATED", "UPDATED", "COLOR")
+	fmt.Println(strings.Repeat("-", idWidth+81))
 
 	for _, file := range resp.Files {
 		created := time.Unix(file.CreatedAt, 0).Format("2006-01-02 15:04:05")
@@ -170,11 +284,455 @@ func (c *CLI) handleList() {
 			filename = filename[:27] + "..."
 		}
 
-		fmt.Printf("%-36s %-30s %-20s %-20s\n", file.FileId, filename, created, updated)
+		color := "-"
+		if len(file.Palette) > 0 {
+			color = file.Palette[0]
+		}
+
+		fmt.Printf("%-*s %-30s %-20s %-20s %-8s\n", idWidth, file.FileId, filename, created, updated, color)
+	}
+	fmt.Println()
+}
+
+// handleInfo handles info command
+func (c *CLI) handleInfo(args []string) {
+	if len(args) != 1 {
+		fmt.Println("Usage: info <file_id>")
+		return
+	}
+
+	file, err := c.client.GetFileInfo(context.Background(), args[0])
+	if err != nil {
+		fmt.Printf("ERROR GETTING FILE INFO: %v\n", err)
+		return
+	}
+
+	fmt.Printf("File ID:  %s\n", file.FileId)
+	fmt.Printf("Filename: %s\n", file.Filename)
+	if file.Owner != "" {
+		fmt.Printf("Owner:    %s\n", file.Owner)
+	}
+	if file.BlobId != "" && file.BlobId != file.FileId {
+		fmt.Printf("Content:  %s\n", file.BlobId)
+	}
+	if file.Format != "" {
+		fmt.Printf("Format:   %s\n", file.Format)
+	}
+	if file.Sanitized {
+		fmt.Println("Sanitized: active content was removed on upload")
+	}
+	if file.Corrupted {
+		fmt.Println("Corrupted: content failed integrity check, upload the original again to restore")
+	}
+	fmt.Printf("Created:  %s\n", time.Unix(file.CreatedAt, 0).Format("2006-01-02 15:04:05"))
+	fmt.Printf("Updated:  %s\n", time.Unix(file.UpdatedAt, 0).Format("2006-01-02 15:04:05"))
+	if file.ExpiresAt != 0 {
+		fmt.Printf("Expires:  %s\n", time.Unix(file.ExpiresAt, 0).Format("2006-01-02 15:04:05"))
+	}
+	if file.VersionOf != "" {
+		fmt.Printf("Version:  %d of %s\n", file.Version, file.VersionOf)
+	} else if file.Version > 1 {
+		fmt.Printf("Version:  %d\n", file.Version)
+	}
+	for _, algorithm := range []string{"sha256", "md5"} {
+		if digest, ok := file.Digests[algorithm]; ok {
+			fmt.Printf("%-9s %s\n", strings.ToUpper(algorithm)+":", digest)
+		}
+	}
+	if len(file.LegacyIds) > 0 {
+		fmt.Printf("Legacy IDs: %s\n", strings.Join(file.LegacyIds, " "))
+	}
+	if len(file.Palette) > 0 {
+		fmt.Printf("Palette:  %s\n", strings.Join(file.Palette, " "))
+	}
+	if file.Blurhash != "" {
+		fmt.Printf("BlurHash: %s\n", file.Blurhash)
+	}
+	if file.FrameCount > 0 {
+		fmt.Printf("Frames:   %d (%d ms)\n", file.FrameCount, file.DurationMs)
+	}
+	if file.VariantOf != "" {
+		fmt.Printf("Variant of: %s\n", file.VariantOf)
+	}
+	for _, v := range file.Variants {
+		fmt.Printf("Variant:  %s %s\n", v.Kind, v.FileId)
+	}
+	fmt.Println()
+}
+
+// handleFrame handles frame command
+func (c *CLI) handleFrame(args []string) {
+	if len(args) != 3 {
+		fmt.Println("Usage: frame <file_id> <index|poster> <output_path>")
+		return
+	}
+	fileID := args[0]
+	outputPath := args[2]
+
+	index, poster := 0, args[1] == "poster"
+	if !poster {
+		i, err := strconv.Atoi(args[1])
+		if err != nil {
+			fmt.Printf("ERROR: INVALID FRAME INDEX '%s'\n", args[1])
+			return
+		}
+		index = i
+	}
+
+	resp, err := c.client.GetFrameToPath(context.Background(), fileID, index, poster, outputPath)
+	if err != nil {
+		fmt.Printf("ERROR GETTING FRAME: %v\n", err)
+		return
+	}
+
+	fmt.Printf("Frame %d of %d (delay %dms) saved to: %s\n", resp.Index, resp.FrameCount, resp.DelayMs, outputPath)
+}
+
+// handleSprite handles sprite command
+func (c *CLI) handleSprite(args []string) {
+	if len(args) < 2 || len(args) > 3 {
+		fmt.Println("Usage: sprite <file_id> <output_path> [columns]")
+		return
+	}
+	fileID := args[0]
+	outputPath := args[1]
+
+	columns := 0
+	if len(args) == 3 {
+		n, err := strconv.Atoi(args[2])
+		if err != nil {
+			fmt.Printf("ERROR: INVALID COLUMNS '%s'\n", args[2])
+			return
+		}
+		columns = n
+	}
+
+	if err := c.client.SpriteSheetToPath(context.Background(), fileID, columns, outputPath); err != nil {
+		fmt.Printf("ERROR CREATING SPRITE SHEET: %v\n", err)
+		return
+	}
+
+	fmt.Printf("Sprite sheet saved to: %s (frame map: %s.json)\n", outputPath, outputPath)
+}
+
+// handleSheet handles sheet command
+func (c *CLI) handleSheet(args []string) {
+	if len(args) < 2 {
+		fmt.Println("Usage: sheet <output_path> <file_id> [file_id...]")
+		return
+	}
+	outputPath := args[0]
+	fileIDs := args[1:]
+
+	fmt.Printf("Composing contact sheet from %d file(s)...\n", len(fileIDs))
+
+	start := time.Now()
+	err := c.client.ContactSheetToPath(context.Background(), outputPath, fileIDs)
+	duration := time.Since(start)
+
+	if err != nil {
+		fmt.Printf("ERROR CREATING CONTACT SHEET: %v\n", err)
+		return
+	}
+
+	fmt.Printf("Contact sheet saved to: %s\n", outputPath)
+	fmt.Printf("Compose time: %v\n", duration)
+}
+
+// handleWatermark handles watermark command
+func (c *CLI) handleWatermark(args []string) {
+	if len(args) < 2 || len(args) > 3 {
+		fmt.Println("Usage: watermark <file_id> <watermark_file_id> [position|tile]")
+		return
+	}
+
+	policy := &gen.WatermarkPolicy{WatermarkFileId: args[1]}
+	if len(args) == 3 {
+		if args[2] == "tile" {
+			policy.Tile = true
+		} else {
+			policy.Position = args[2]
+		}
+	}
+
+	variantID, err := c.client.CreateWatermarkVariant(context.Background(), args[0], policy)
+	if err != nil {
+		fmt.Printf("ERROR CREATING WATERMARKED VARIANT: %v\n", err)
+		return
+	}
+
+	fmt.Printf("Watermarked variant created!\n")
+	fmt.Printf("File ID: %s\n", variantID)
+}
+
+// handleCompare handles compare command
+func (c *CLI) handleCompare(args []string) {
+	if len(args) < 2 || len(args) > 3 {
+		fmt.Println("Usage: compare <file_id_1> <file_id_2> [diff_output_path]")
+		return
+	}
+
+	diffPath := ""
+	if len(args) == 3 {
+		diffPath = args[2]
+	}
+
+	resp, err := c.client.CompareImages(context.Background(), args[0], args[1], diffPath)
+	if err != nil {
+		fmt.Printf("ERROR COMPARING IMAGES: %v\n", err)
+		return
+	}
+
+	changedPercent := 0.0
+	if resp.TotalPixels > 0 {
+		changedPercent = float64(resp.ChangedPixels) * 100 / float64(resp.TotalPixels)
+	}
+
+	fmt.Printf("PSNR: %.2f dB\n", resp.Psnr)
+	fmt.Printf("SSIM: %.4f\n", resp.Ssim)
+	fmt.Printf("Changed pixels: %d of %d (%.2f%%)\n", resp.ChangedPixels, resp.TotalPixels, changedPercent)
+	if diffPath != "" {
+		fmt.Printf("Diff image saved to: %s\n", diffPath)
+	}
+}
+
+// handleCrop handles crop command
+func (c *CLI) handleCrop(args []string) {
+	if len(args) < 3 || len(args) > 4 {
+		fmt.Println("Usage: crop <file_id> <width:height> <output_path> [smart|center]")
+		return
+	}
+
+	var aspectW, aspectH int
+	if _, err := fmt.Sscanf(args[1], "%d:%d", &aspectW, &aspectH); err != nil || aspectW <= 0 || aspectH <= 0 {
+		fmt.Printf("Invalid aspect ratio: %s (expected e.g. 16:9)\n", args[1])
+		return
+	}
+
+	mode := ""
+	if len(args) == 4 {
+		mode = args[3]
+	}
+
+	rect, err := c.client.CropToPath(context.Background(), args[0], aspectW, aspectH, mode, args[2])
+	if err != nil {
+		fmt.Printf("ERROR CROPPING IMAGE: %v\n", err)
+		return
+	}
+
+	fmt.Printf("Cropped image saved to: %s\n", args[2])
+	fmt.Printf("Crop region: x=%d y=%d %dx%d\n", rect.X, rect.Y, rect.Width, rect.Height)
+}
+
+// handleOptimize handles optimize command
+func (c *CLI) handleOptimize(args []string) {
+	if len(args) < 1 || len(args) > 2 {
+		fmt.Println("Usage: optimize <file_id> [jpeg_quality]")
+		return
+	}
+
+	quality := 0
+	if len(args) == 2 {
+		q, err := strconv.Atoi(args[1])
+		if err != nil {
+			fmt.Printf("ERROR: INVALID QUALITY '%s'\n", args[1])
+			return
+		}
+		quality = q
+	}
+
+	resp, err := c.client.Optimize(context.Background(), args[0], quality)
+	if err != nil {
+		fmt.Printf("ERROR OPTIMIZING FILE: %v\n", err)
+		return
+	}
+
+	if resp.VariantId == "" {
+		fmt.Printf("File is already optimal (%s, %d bytes)\n", resp.Format, resp.OriginalSize)
+		return
+	}
+
+	fmt.Printf("Optimized variant: %s\n", resp.VariantId)
+	fmt.Printf("Size: %d -> %d bytes (saved %d)\n", resp.OriginalSize, resp.OptimizedSize, resp.BytesSaved)
+}
+
+// handleExpire handles expire command
+func (c *CLI) handleExpire(args []string) {
+	if len(args) != 2 {
+		fmt.Println("Usage: expire <file_id> <ttl|RFC3339 time|never>")
+		return
+	}
+
+	// ttl is relative to now, a timestamp is absolute, never removes the expiry
+	var expiresAt time.Time
+	if args[1] != "never" {
+		if d, err := time.ParseDuration(args[1]); err == nil && d >= time.Second {
+			expiresAt = time.Now().Add(d)
+		} else if t, err := time.Parse(time.RFC3339, args[1]); err == nil {
+			expiresAt = t
+		} else {
+			fmt.Printf("ERROR: INVALID EXPIRY '%s'\n", args[1])
+			return
+		}
+	}
+
+	newExpiry, err := c.client.SetExpiry(context.Background(), args[0], expiresAt)
+	if err != nil {
+		fmt.Printf("ERROR SETTING EXPIRY: %v\n", err)
+		return
+	}
+
+	if newExpiry.IsZero() {
+		fmt.Println("File never expires")
+		return
+	}
+	fmt.Printf("File expires: %s\n", newExpiry.Format("2006-01-02 15:04:05"))
+}
+
+// handleReplace handles replace command
+func (c *CLI) handleReplace(args []string) {
+	if len(args) != 2 {
+		fmt.Println("Usage: replace <file_id> <file_path>")
+		return
+	}
+
+	// check file exists
+	if _, err := os.Stat(args[1]); os.IsNotExist(err) {
+		fmt.Printf("ERROR: FILE '%s' DOES NOT EXIST\n", args[1])
+		return
+	}
+
+	start := time.Now()
+	version, err := c.client.ReplaceFileFromPath(context.Background(), args[0], args[1])
+	duration := time.Since(start)
+
+	if err != nil {
+		fmt.Printf("ERROR REPLACING FILE: %v\n", err)
+		return
+	}
+
+	fmt.Printf("File %s replaced, version %d\n", args[0], version)
+	fmt.Printf("Upload time %v\n", duration)
+}
+
+// handleVersions handles versions command
+func (c *CLI) handleVersions(args []string) {
+	if len(args) != 1 {
+		fmt.Println("Usage: versions <file_id>")
+		return
+	}
+
+	versions, err := c.client.ListVersions(context.Background(), args[0])
+	if err != nil {
+		fmt.Printf("ERROR LISTING VERSIONS: %v\n", err)
+		return
+	}
+
+	// the latest version is listed under the file ID, previous ones under their own IDs
+	fmt.Printf("%-8s %-30s %-20s %s\n", "VERSION", "FILENAME", "UPLOADED", "CONTENT")
+	fmt.Println(strings.Repeat("-", 132))
+	for _, v := range versions {
+		filename := v.Filename
+		if len(filename) > 30 {
+			filename = filename[:27] + "..."
+		}
+		uploaded := time.Unix(v.UpdatedAt, 0).Format("2006-01-02 15:04:05")
+		fmt.Printf("%-8d %-30s %-20s %s\n", v.Version, filename, uploaded, v.BlobId)
 	}
 	fmt.Println()
 }
 
+// handleRestore handles restore command
+func (c *CLI) handleRestore(args []string) {
+	if len(args) != 2 {
+		fmt.Println("Usage: restore <file_id> <version>")
+		return
+	}
+	version, err := strconv.Atoi(args[1])
+	if err != nil || version < 1 {
+		fmt.Printf("ERROR: INVALID VERSION '%s'\n", args[1])
+		return
+	}
+
+	newVersion, err := c.client.RestoreVersion(context.Background(), args[0], int32(version))
+	if err != nil {
+		fmt.Printf("ERROR RESTORING VERSION: %v\n", err)
+		return
+	}
+
+	fmt.Printf("Version %d of %s restored as version %d\n", version, args[0], newVersion)
+}
+
+// parseVersionRef splits "<file_id>@<version>" into the ID and version, 0 version means the latest
+func parseVersionRef(ref string) (string, int32, error) {
+	fileID, versionStr, found := strings.Cut(ref, "@")
+	if !found {
+		return ref, 0, nil
+	}
+	version, err := strconv.Atoi(versionStr)
+	if err != nil || version < 1 {
+		return "", 0, fmt.Errorf("INVALID VERSION '%s'", versionStr)
+	}
+	return fileID, int32(version), nil
+}
+
+// handleQuota handles quota command
+func (c *CLI) handleQuota() {
+	resp, err := c.client.GetQuota(context.Background())
+	if err != nil {
+		fmt.Printf("ERROR GETTING QUOTA: %v\n", err)
+		return
+	}
+
+	owner := resp.Owner
+	if owner == "" {
+		owner = "anonymous"
+	}
+	fmt.Printf("Client:  %s\n", owner)
+	fmt.Printf("Files:   %s\n", usageLine(resp.Usage.GetFiles(), resp.Quota.GetMaxFiles()))
+	fmt.Printf("Bytes:   %s\n", usageLine(resp.Usage.GetBytes(), resp.Quota.GetMaxBytes()))
+	fmt.Printf("Server files: %s\n", usageLine(resp.TotalUsage.GetFiles(), resp.TotalQuota.GetMaxFiles()))
+	fmt.Printf("Server bytes: %s\n", usageLine(resp.TotalUsage.GetBytes(), resp.TotalQuota.GetMaxBytes()))
+}
+
+// handleFsck handles fsck command
+func (c *CLI) handleFsck(args []string) {
+	if len(args) > 1 || (len(args) == 1 && args[0] != "repair") {
+		fmt.Println("Usage: fsck [repair]")
+		return
+	}
+
+	resp, err := c.client.Fsck(context.Background(), len(args) == 1)
+	if err != nil {
+		fmt.Printf("ERROR CHECKING STORAGE: %v\n", err)
+		return
+	}
+
+	repaired := 0
+	for _, issue := range resp.Issues {
+		state := "not repaired"
+		if issue.Repaired {
+			state = "repaired"
+			repaired++
+		}
+		fmt.Printf("%-14s %s\n", issue.Kind, issue.BlobId)
+		if len(issue.FileIds) > 0 {
+			fmt.Printf("%-14s files: %s\n", "", strings.Join(issue.FileIds, " "))
+		}
+		fmt.Printf("%-14s %s (%s: %s)\n", "", issue.Detail, state, issue.Action)
+	}
+	fmt.Printf("Checked %d files and %d blobs in %d ms: %d issues, %d repaired\n",
+		resp.Files, resp.Blobs, resp.DurationMs, len(resp.Issues), repaired)
+}
+
+// usageLine formats usage against a limit (0 - unlimited)
+func usageLine(used, limit int64) string {
+	if limit <= 0 {
+		return fmt.Sprintf("%d (unlimited)", used)
+	}
+	return fmt.Sprintf("%d of %d (%.1f%%)", used, limit, float64(used)*100/float64(limit))
+}
+
 func (c *CLI) handlePing() {
 	fmt.Println("Ping server")
 
@@ -205,8 +763,38 @@ func (c *CLI) RunBatch(commands []string) error {
 			c.handleUpload(args)
 		case "download":
 			c.handleDownload(args)
+		case "convert":
+			c.handleConvert(args)
 		case "list":
-			c.handleList()
+			c.handleList(args)
+		case "info":
+			c.handleInfo(args)
+		case "frame":
+			c.handleFrame(args)
+		case "sprite":
+			c.handleSprite(args)
+		case "sheet":
+			c.handleSheet(args)
+		case "watermark":
+			c.handleWatermark(args)
+		case "compare":
+			c.handleCompare(args)
+		case "crop":
+			c.handleCrop(args)
+		case "optimize":
+			c.handleOptimize(args)
+		case "expire":
+			c.handleExpire(args)
+		case "replace":
+			c.handleReplace(args)
+		case "versions":
+			c.handleVersions(args)
+		case "restore":
+			c.handleRestore(args)
+		case "quota":
+			c.handleQuota()
+		case "fsck":
+			c.handleFsck(args)
 		case "ping":
 			c.handlePing()
 		default:
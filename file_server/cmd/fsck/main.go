@@ -0,0 +1,105 @@
+// main.go - автономная проверка целостности хранилища файлового сервера
+// Запускается при остановленном сервере: сверяет метаданные с содержимым и печатает отчет (текстом или JSON)
+// Код завершения: 0 - хранилище согласовано (или все нарушения исправлены), 1 - ошибка проверки,
+// 2 - остались неисправленные нарушения
+package main
+
+import (
+	"context"
+	"encoding/json"
+	filerepo "file_server/internal/repository/file"
+	"file_server/internal/storage"
+	storagebackend "file_server/internal/storage/backend"
+	fsstorage "file_server/internal/storage/fs"
+	s3storage "file_server/internal/storage/s3"
+	"file_server/pkg/model"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+)
+
+func main() {
+	// Парсинг аргументов командной строки (параметры хранилища совпадают с флагами сервера)
+	var (
+		storagePath = flag.String("storage", "./storage/files", "Storage Directory Path")
+		backend     = flag.String("backend", storage.BackendFS, "Storage backend: fs or s3")
+		shardDepth  = flag.Int("shard-depth", fsstorage.DefaultShardDepth, "fs backend: directory fan-out depth (0 - flat)")
+		s3Endpoint  = flag.String("s3-endpoint", "", "S3-compatible endpoint URL, e.g. https://s3.amazonaws.com")
+		s3Bucket    = flag.String("s3-bucket", "", "S3 bucket name")
+		s3Prefix    = flag.String("s3-prefix", "", "S3 key prefix inside the bucket")
+		s3Region    = flag.String("s3-region", "us-east-1", "S3 region used for request signing")
+		keyFile     = flag.String("key-file", "", "Master key file of encrypted content (same as the server)")
+		plaintext   = flag.Bool("allow-plaintext", false, "With -key-file: accept content stored unencrypted (same as the server)")
+		repair      = flag.Bool("repair", false, "Repair found issues instead of only reporting them")
+		asJSON      = flag.Bool("json", false, "Print the report as JSON")
+	)
+	flag.Parse()
+
+	// Открытие хранилища (сервер должен быть остановлен: журнал метаданных открывается на запись)
+	blobs, meta, err := storagebackend.Open(storagebackend.Config{
+		Backend:    *backend,
+		Path:       *storagePath,
+		ShardDepth: *shardDepth,
+		S3: s3storage.Config{
+			Endpoint:  *s3Endpoint,
+			Bucket:    *s3Bucket,
+			Prefix:    *s3Prefix,
+			Region:    *s3Region,
+			AccessKey: os.Getenv("AWS_ACCESS_KEY_ID"),
+			SecretKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+		},
+		KeyFile:        *keyFile,
+		AllowPlaintext: *plaintext,
+	})
+	if err != nil {
+		log.Fatalf("FAILED TO OPEN STORAGE: %v", err)
+	}
+
+	// Репозиторий без сверки при загрузке: все расхождения попадают в отчет
+	repo, err := filerepo.NewRepoForCheck(blobs, meta)
+	if err != nil {
+		log.Fatalf("FAILED TO LOAD REPOSITORY: %v", err)
+	}
+	report, err := repo.Fsck(context.Background(), *repair)
+	if closeErr := repo.Close(); closeErr != nil && err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		log.Fatalf("STORAGE CHECK FAILED: %v", err)
+	}
+
+	// Вывод отчета
+	if *asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(report); err != nil {
+			log.Fatalf("FAILED TO WRITE REPORT: %v", err)
+		}
+	} else {
+		printReport(report)
+	}
+
+	if report.Repaired() < len(report.Issues) {
+		os.Exit(2)
+	}
+}
+
+// printReport печатает отчет в текстовом виде
+func printReport(report *model.FsckReport) {
+	for _, issue := range report.Issues {
+		state := "NOT REPAIRED"
+		if issue.Repaired {
+			state = "REPAIRED"
+		}
+		fmt.Printf("%-14s %s\n", issue.Kind, issue.BlobID)
+		if len(issue.FileIDs) > 0 {
+			fmt.Printf("%-14s files: %s\n", "", strings.Join(issue.FileIDs, " "))
+		}
+		fmt.Printf("%-14s %s; %s: %s\n", "", issue.Detail, state, issue.Action)
+	}
+	fmt.Printf("Checked %d files and %d blobs in %v: %d issues, %d repaired\n",
+		report.Files, report.Blobs, report.Duration.Round(time.Millisecond), len(report.Issues), report.Repaired())
+}
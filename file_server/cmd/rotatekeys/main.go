@@ -0,0 +1,97 @@
+// main.go - ротация мастер-ключа шифрования содержимого
+// Запускается при остановленном сервере: переводит все содержимое на активный ключ из файла ключей.
+// Для зашифрованного прежним ключом содержимого перезаписывается только запись ключа данных (содержимое не изменяется),
+// открытое содержимое, записанное до включения шифрования, шифруется
+// Код завершения: 0 - все содержимое на активном ключе, 1 - ошибка, 2 - часть содержимого перевести не удалось
+package main
+
+import (
+	"file_server/internal/storage"
+	storagebackend "file_server/internal/storage/backend"
+	"file_server/internal/storage/encrypt"
+	fsstorage "file_server/internal/storage/fs"
+	s3storage "file_server/internal/storage/s3"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+)
+
+func main() {
+	// Парсинг аргументов командной строки (параметры хранилища совпадают с флагами сервера)
+	var (
+		storagePath = flag.String("storage", "./storage/files", "Storage Directory Path")
+		backend     = flag.String("backend", storage.BackendFS, "Storage backend: fs or s3")
+		shardDepth  = flag.Int("shard-depth", fsstorage.DefaultShardDepth, "fs backend: directory fan-out depth (0 - flat)")
+		s3Endpoint  = flag.String("s3-endpoint", "", "S3-compatible endpoint URL, e.g. https://s3.amazonaws.com")
+		s3Bucket    = flag.String("s3-bucket", "", "S3 bucket name")
+		s3Prefix    = flag.String("s3-prefix", "", "S3 key prefix inside the bucket")
+		s3Region    = flag.String("s3-region", "us-east-1", "S3 region used for request signing")
+		keyFile     = flag.String("key-file", "", "Master key file (required)")
+		newKey      = flag.Bool("new", false, "Generate a new master key and make it active before rotation (creates the key file if missing)")
+	)
+	flag.Parse()
+	if *keyFile == "" {
+		log.Fatalf("-key-file is required")
+	}
+
+	// Новый активный ключ (прежние ключи остаются в файле для чтения еще не переведенного содержимого)
+	if *newKey {
+		id, err := encrypt.AddKey(*keyFile)
+		if err != nil {
+			log.Fatalf("FAILED TO ADD KEY: %v", err)
+		}
+		fmt.Printf("New active key %s added to %s\n", id, *keyFile)
+	}
+
+	// Открытие хранилища со слоем шифрования (сервер должен быть остановлен: перевод содержимого не согласован с удалениями)
+	blobs, err := storagebackend.OpenEncrypted(storagebackend.Config{
+		Backend:    *backend,
+		Path:       *storagePath,
+		ShardDepth: *shardDepth,
+		S3: s3storage.Config{
+			Endpoint:  *s3Endpoint,
+			Bucket:    *s3Bucket,
+			Prefix:    *s3Prefix,
+			Region:    *s3Region,
+			AccessKey: os.Getenv("AWS_ACCESS_KEY_ID"),
+			SecretKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+		},
+		KeyFile: *keyFile,
+	})
+	if err != nil {
+		log.Fatalf("FAILED TO OPEN STORAGE: %v", err)
+	}
+
+	// Обход содержимого
+	var ids []string
+	if err := blobs.Iterate(func(blob storage.BlobInfo) error {
+		ids = append(ids, blob.ID)
+		return nil
+	}); err != nil {
+		log.Fatalf("FAILED TO SCAN STORAGE: %v", err)
+	}
+	sort.Strings(ids)
+
+	// Перевод каждого содержимого на активный ключ (ошибка одного содержимого не прерывает ротацию)
+	var rotated, current, failed int
+	for _, id := range ids {
+		changed, err := blobs.Rewrap(id)
+		switch {
+		case err != nil:
+			fmt.Printf("FAILED %s: %v\n", id, err)
+			failed++
+		case changed:
+			rotated++
+		default:
+			current++
+		}
+	}
+
+	fmt.Printf("Checked %d blobs: %d moved to the active key, %d already on it, %d failed\n", len(ids), rotated, current, failed)
+	if failed > 0 {
+		os.Exit(2)
+	}
+	fmt.Println("All content uses the active key, previous keys can be removed from the key file")
+}
@@ -4,17 +4,27 @@ package main
 
 import (
 	"context"
+	"errors"
 	"file_server/gen"
+	"file_server/internal/auth"
 	filectrl "file_server/internal/controller/file"
 	filegrpc "file_server/internal/handler/grpc"
+	"file_server/internal/imaging"
 	"file_server/internal/middleware"
 	filerepo "file_server/internal/repository/file"
+	"file_server/internal/storage"
+	storagebackend "file_server/internal/storage/backend"
+	"file_server/internal/storage/compress"
+	fsstorage "file_server/internal/storage/fs"
+	s3storage "file_server/internal/storage/s3"
+	"file_server/pkg/model"
 	"flag"
 	"fmt"
 	"log"
 	"net"
 	"os"
 	"os/signal"
+	"sync"
 	"syscall"
 	"time"
 
@@ -30,32 +40,264 @@ const serviceName = "file-service"
 func main() {
 	// Парсинг аргументов командной строки
 	var (
-		port        = flag.Int("port", 8080, "Server port")                               // Порт для gRPC сервера
-		storagePath = flag.String("storage", "./storage/files", "Storage Directory Path") // Путь к директории хранения файлов
-		showStats   = flag.Bool("stats", false, "Show concurrency statistics")            // Флаг для отображения статистики конкурентности
+		port        = flag.Int("port", 8080, "Server port")                                  // Порт для gRPC сервера
+		storagePath = flag.String("storage", "./storage/files", "Storage Directory Path")    // Путь к директории хранения файлов
+		showStats   = flag.Bool("stats", false, "Show concurrency statistics")               // Флаг для отображения статистики конкурентности
+		credentials = flag.String("credentials", "", "API credentials JSON file")            // Файл учетных данных клиентов (ключ API -> политики)
+		requireKey  = flag.Bool("require-api-key", false, "Reject requests without API key") // Запрет анонимных запросов (обязателен для ключей с водяным знаком)
+
+		// Бэкенд хранилища файлов и метаданных (fs - директория storage, memory - в памяти процесса, s3 - бакет)
+		// Ключи доступа к S3 берутся из переменных окружения AWS_ACCESS_KEY_ID и AWS_SECRET_ACCESS_KEY
+		backend    = flag.String("backend", storage.BackendFS, "Storage backend: fs, memory or s3")
+		shardDepth = flag.Int("shard-depth", fsstorage.DefaultShardDepth, "fs backend: directory fan-out depth (0 - flat)")
+		s3Endpoint = flag.String("s3-endpoint", "", "S3-compatible endpoint URL, e.g. https://s3.amazonaws.com")
+		s3Bucket   = flag.String("s3-bucket", "", "S3 bucket name")
+		s3Prefix   = flag.String("s3-prefix", "", "S3 key prefix inside the bucket")
+		s3Region   = flag.String("s3-region", "us-east-1", "S3 region used for request signing")
+
+		// Прозрачное сжатие содержимого (файл сжимается, только если это экономит не меньше заданной доли размера)
+		compression   = flag.Bool("compress", false, "Compress new content at rest when it saves enough space")
+		compressSaves = flag.Float64("compress-min-saving", compress.DefaultMinSaving, "Minimum fraction of size compression must save to be kept (0.1 - 10%)")
+
+		// Шифрование содержимого (AES-256-GCM, мастер-ключи в файле ключей, ротация - cmd/rotatekeys)
+		keyFile        = flag.String("key-file", "", "Master key file for encryption at rest (empty - new content is not encrypted)")
+		allowPlaintext = flag.Bool("allow-plaintext", false, "With -key-file: still read content stored unencrypted (until cmd/rotatekeys encrypts it)")
+
+		// Контроль свободного места на томе хранилища (только бэкенд fs)
+		minFreeSpace      = flag.Uint64("min-free-space", 256<<20, "fs backend: refuse uploads when free disk space drops below this many bytes (0 - disabled)")
+		diskCheckInterval = flag.Duration("disk-check-interval", 10*time.Second, "fs backend: free disk space monitoring interval")
+
+		// Ограничения места (0 - без ограничения); ограничения отдельных клиентов задаются в файле учетных данных
+		maxStorageBytes = flag.Int64("max-storage-bytes", 0, "Maximum stored content size in bytes (0 - unlimited)")
+		maxFiles        = flag.Int("max-files", 0, "Maximum number of files (0 - unlimited)")
+		clientMaxBytes  = flag.Int64("client-max-bytes", 0, "Default per-client limit on total file size in bytes (0 - unlimited)")
+		clientMaxFiles  = flag.Int("client-max-files", 0, "Default per-client limit on number of files (0 - unlimited)")
+
+		// Количество хранимых версий файла вместе с текущей (самые старые удаляются при замене)
+		maxVersions = flag.Int("max-versions", 10, "Versions kept per file including the latest, older ones are pruned on replace (0 - unlimited)")
+
+		// Проверка целостности хранилища при запуске (check - только отчет, repair - с исправлением нарушений)
+		fsckMode = flag.String("fsck", "", "Storage consistency check at startup: check or repair (empty - disabled)")
+
+		// Фоновое удаление файлов с истекшим сроком хранения
+		janitorInterval = flag.Duration("janitor-interval", time.Minute, "Expired files cleanup interval")
+
+		// Фоновая проверка содержимого на повреждения (bit rot) с ограничением скорости чтения
+		scrubInterval = flag.Duration("scrub-interval", 24*time.Hour, "Background content integrity scrub interval (0 - disabled)")
+		scrubRate     = flag.Int64("scrub-rate", 8<<20, "Scrub read rate limit in bytes per second (0 - unlimited)")
+
+		// Фоновая политика оптимизации изображений
+		optimizeInterval = flag.Duration("optimize-interval", 0, "Background image optimization interval (0 - disabled)")
+		optimizeQuality  = flag.Int("optimize-quality", 0, "JPEG target quality for optimization (0 - default)")
+		optimizeMinSSIM  = flag.Float64("optimize-min-ssim", 0, "Minimum SSIM of optimized JPEG (0 - default)")
 	)
 	flag.Parse()
 
 	// Логирование информации о запуске сервера
 	log.Printf("Start %s on port %d", serviceName, *port)
-	log.Printf("Storage Directory: %s", *storagePath)
+	log.Printf("Storage backend: %s", *backend)
+	switch *backend {
+	case storage.BackendFS:
+		log.Printf("Storage Directory: %s (shard depth %d)", *storagePath, *shardDepth)
+	case storage.BackendS3:
+		log.Printf("Storage bucket: %s/%s (prefix %q)", *s3Endpoint, *s3Bucket, *s3Prefix)
+	}
+	if *keyFile != "" {
+		log.Printf("Encryption at rest: enabled, key file %s", *keyFile)
+	}
+	if *compression {
+		log.Printf("Compression at rest: enabled, minimum saving %.0f%%", *compressSaves*100)
+	}
 	log.Printf("Concurrency limits: Upload/Download=10, List=100")
 
+	// Открытие хранилища содержимого и метаданных выбранного бэкенда
+	blobs, meta, err := storagebackend.Open(storagebackend.Config{
+		Backend:    *backend,
+		Path:       *storagePath,
+		ShardDepth: *shardDepth,
+		S3: s3storage.Config{
+			Endpoint:  *s3Endpoint,
+			Bucket:    *s3Bucket,
+			Prefix:    *s3Prefix,
+			Region:    *s3Region,
+			AccessKey: os.Getenv("AWS_ACCESS_KEY_ID"),
+			SecretKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+		},
+		Compression:    compress.Config{Enabled: *compression, MinSaving: *compressSaves},
+		KeyFile:        *keyFile,
+		AllowPlaintext: *allowPlaintext,
+	})
+	if err != nil {
+		log.Fatalf("FAILED TO OPEN STORAGE: %v", err)
+	}
+
 	// Создание репозитория для работы с файлами
-	// Репозиторий отвечает за сохранение, загрузку и управление файлами на диске
-	repo, err := filerepo.NewRepo(*storagePath)
+	// Репозиторий отвечает за сохранение, загрузку и управление файлами в хранилище
+	// При проверке целостности при запуске расхождения с хранилищем попадают в ее отчет, а не исправляются молча
+	newRepo := filerepo.NewRepo
+	if *fsckMode != "" {
+		newRepo = filerepo.NewRepoForCheck
+	}
+	repo, err := newRepo(blobs, meta)
 	if err != nil {
 		log.Fatalf("FAILED TO CREATE REPOSITORY: %v", err)
 	}
 
+	// Проверка целостности хранилища до приема запросов
+	if *fsckMode != "" {
+		if *fsckMode != "check" && *fsckMode != "repair" {
+			log.Fatalf("INVALID FSCK MODE %q: expected check or repair", *fsckMode)
+		}
+		report, err := repo.Fsck(context.Background(), *fsckMode == "repair")
+		if err != nil {
+			log.Fatalf("STORAGE CHECK FAILED: %v", err)
+		}
+		logFsckReport(report)
+	}
+
 	// Создание контроллера для обработки бизнес-логики
 	// Контроллер координирует работу между gRPC обработчиком и репозиторием
 	ctrl := filectrl.NewController(repo)
 
+	// Разовые и периодические фоновые задачи контроллера и репозитория
+	// При graceful shutdown задачи отменяются, и репозиторий закрывается только после их завершения
+	backgroundCtx, stopBackground := context.WithCancel(context.Background())
+	var background sync.WaitGroup
+
+	// Фоновый перевод файлов с MD5 ID на SHA-256 (прежние ID остаются псевдонимами)
+	background.Go(func() {
+		migrated, err := repo.MigrateIDs(backgroundCtx)
+		if errors.Is(err, context.Canceled) {
+			log.Printf("File ID migration interrupted by shutdown: %d files moved to SHA-256", migrated)
+			return
+		}
+		if err != nil {
+			log.Printf("File ID migration failed: %v", err)
+			return
+		}
+		if migrated > 0 {
+			log.Printf("File ID migration finished: %d files moved to SHA-256", migrated)
+		}
+	})
+
+	// Фоновое удаление файлов с истекшим сроком хранения (текущий проход прерывается между удалениями файлов)
+	background.Go(func() {
+		repo.RunJanitor(backgroundCtx, *janitorInterval)
+	})
+	log.Printf("Expired files cleanup: every %v", *janitorInterval)
+
+	// Фоновая проверка содержимого: поврежденное содержимое переносится в карантин, файлы помечаются поврежденными
+	// Текущий проход прерывается между проверками
+	var scrubber *filerepo.Scrubber
+	if *scrubInterval > 0 {
+		scrubber = filerepo.NewScrubber(repo, *scrubRate)
+		background.Go(func() {
+			scrubber.Run(backgroundCtx, *scrubInterval)
+		})
+		log.Printf("Content scrub: every %v, read rate limit %d bytes/s", *scrubInterval, *scrubRate)
+	}
+
+	// Фоновое вычисление цветовых характеристик для файлов, загруженных ранее
+	background.Go(func() {
+		processed, err := ctrl.BackfillImageMeta(backgroundCtx)
+		if errors.Is(err, context.Canceled) {
+			log.Printf("Image metadata backfill interrupted by shutdown: %d files processed", processed)
+			return
+		}
+		if err != nil {
+			log.Printf("Image metadata backfill failed: %v", err)
+			return
+		}
+		log.Printf("Image metadata backfill finished: %d files processed", processed)
+	})
+
+	// Фоновая оптимизация PNG и JPEG (если включена флагом --optimize-interval)
+	// Оптимизированные копии сохраняются как варианты, исходные файлы не изменяются
+	if *optimizeInterval > 0 {
+		optimizeReq := &model.OptimizeRequest{Quality: *optimizeQuality, MinSSIM: *optimizeMinSSIM}
+		if _, err := (imaging.OptimizeOptions{Quality: optimizeReq.Quality, MinSSIM: optimizeReq.MinSSIM}).Normalize(); err != nil {
+			log.Fatalf("INVALID OPTIMIZE OPTIONS: %v", err)
+		}
+		log.Printf("Background optimization enabled: every %v", *optimizeInterval)
+
+		background.Go(func() {
+			ticker := time.NewTicker(*optimizeInterval)
+			defer ticker.Stop()
+
+			for {
+				// Ожидание следующего прохода или завершения сервера
+				select {
+				case <-backgroundCtx.Done():
+					return
+				case <-ticker.C:
+				}
+
+				optimized, saved, err := ctrl.OptimizeAll(backgroundCtx, optimizeReq)
+				if errors.Is(err, context.Canceled) {
+					return
+				}
+				if err != nil {
+					log.Printf("Background optimization failed: %v", err)
+					continue
+				}
+				if optimized > 0 {
+					log.Printf("Background optimization: %d files optimized, %d bytes saved", optimized, saved)
+				}
+			}
+		})
+	}
+
 	// Создание gRPC обработчика
 	// Обработчик преобразует gRPC запросы в вызовы контроллера
 	grpcHandler := filegrpc.NewGrpc(ctrl)
 
+	// Загрузка учетных данных клиентов
+	// Учетные данные определяют принудительные политики (например, водяной знак) для ключей API
+	credStore, err := auth.LoadStore(*credentials, *requireKey)
+	if err != nil {
+		log.Fatalf("FAILED TO LOAD CREDENTIALS: %v", err)
+	}
+	log.Printf("API credentials loaded: %d (anonymous access allowed: %t)", credStore.Count(), !*requireKey)
+
+	// Ограничения места сервера и клиентов (проверяются при загрузке файлов)
+	limits := filerepo.Limits{
+		Total:   model.Quota{MaxBytes: *maxStorageBytes, MaxFiles: *maxFiles},
+		Client:  model.Quota{MaxBytes: *clientMaxBytes, MaxFiles: *clientMaxFiles},
+		Clients: credStore.Quotas(),
+	}
+	repo.SetLimits(limits)
+	log.Printf("Storage limits: total %+v, per client %+v, %d client overrides", limits.Total, limits.Client, len(limits.Clients))
+
+	// Ограничение истории версий файлов
+	repo.SetVersionRetention(*maxVersions)
+	log.Printf("File versions: keeping %d per file (0 - unlimited)", *maxVersions)
+
+	// Контроль свободного места: загрузки отклоняются при нехватке места, чтение и удаление продолжают работать
+	// Фоновая проверка логирует предупреждение при первом переходе через порог
+	var spaceGuard *fsstorage.SpaceGuard
+	if *backend == storage.BackendFS && *minFreeSpace > 0 {
+		spaceGuard = fsstorage.NewSpaceGuard(*storagePath, *minFreeSpace)
+		repo.SetSpaceGuard(spaceGuard)
+		log.Printf("Storage space: %s", spaceGuard.Refresh())
+
+		background.Go(func() {
+			ticker := time.NewTicker(*diskCheckInterval)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-backgroundCtx.Done():
+					return
+				case <-ticker.C:
+					spaceGuard.Refresh()
+				}
+			}
+		})
+	}
+
+	// Создание middleware аутентификации
+	// Middleware определяет клиента по ключу API и передает его учетные данные в контексте запроса
+	authenticator := middleware.NewAuthenticator(credStore)
+
 	// Создание middleware для ограничения конкурентности
 	// Middleware предотвращает перегрузку сервера, ограничивая количество одновременных запросов
 	concurrencyLimiter := middleware.NewConcurrencyLimiter()
@@ -68,8 +310,11 @@ func main() {
 
 	// Создание gRPC сервера с настройками
 	srv := grpc.NewServer(
-		grpc.UnaryInterceptor(concurrencyLimiter.UnaryServerInterceptor()), // Подключение middleware для ограничения конкурентности
-		grpc.MaxConcurrentStreams(200),                                     // Максимум 200 одновременных потоков
+		grpc.ChainUnaryInterceptor(
+			authenticator.UnaryServerInterceptor(),      // Подключение middleware аутентификации
+			concurrencyLimiter.UnaryServerInterceptor(), // Подключение middleware для ограничения конкурентности
+		),
+		grpc.MaxConcurrentStreams(200), // Максимум 200 одновременных потоков
 	)
 
 	// Регистрация сервиса и включение reflection для отладки
@@ -78,42 +323,63 @@ func main() {
 
 	// Запуск горутины для мониторинга статистики конкурентности (если включен флаг --stats)
 	if *showStats {
-		go func() {
+		background.Go(func() {
 			ticker := time.NewTicker(5 * time.Second) // Таймер для периодического вывода статистики
 			defer ticker.Stop()
 
-			// Бесконечный цикл для периодического вывода статистики
-			for range ticker.C {
+			// Периодический вывод статистики до завершения сервера
+			for {
+				select {
+				case <-backgroundCtx.Done():
+					return
+				case <-ticker.C:
+				}
 				stats := concurrencyLimiter.GetStatsString()
 				log.Printf("Concurrency stats: %s", stats)
+				if spaceGuard != nil {
+					log.Printf("Storage space: %s", spaceGuard.Status())
+				}
+				if stats, err := repo.GetStats(); err == nil {
+					log.Printf("Storage: %d files, %d bytes, content %d bytes logical, %d bytes physical",
+						stats.Files, stats.Bytes, stats.LogicalBytes, stats.PhysicalBytes)
+				}
+				if scrubber != nil {
+					log.Printf("Scrub: %s", scrubber.Status())
+				}
 			}
-		}()
+		})
 	}
 
 	// Настройка graceful shutdown - обработка сигналов завершения
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM) // Перехват сигналов SIGINT (Ctrl+C) и SIGTERM
 
-	// Горутина для обработки сигналов завершения
+	// Горутина для обработки сигналов завершения (stopped закрывается после закрытия репозитория)
+	stopped := make(chan struct{})
 	go func() {
 		<-sigChan // Ожидание сигнала завершения
 		log.Printf("Recieved interrupt signal. Shutting down..")
 
-		// Настройка graceful shutdown с таймаутом
-		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-		defer cancel()
-
-		// Горутина для принудительного завершения при превышении таймаута
-		go func() {
-			<-ctx.Done()
+		// Принудительное завершение при превышении таймаута graceful shutdown
+		timeout := time.AfterFunc(30*time.Second, func() {
 			log.Println("Shutdown timeout exceeded. Exiting")
 			os.Exit(1)
-		}()
+		})
+		defer timeout.Stop()
 
 		// Graceful остановка gRPC сервера
 		srv.GracefulStop()
+
+		// Остановка всех фоновых задач (каждая прерывается между файлами)
+		stopBackground()
+		background.Wait()
+
+		// Закрытие журнала метаданных после завершения всех запросов
+		if err := repo.Close(); err != nil {
+			log.Printf("Failed to close repository: %v", err)
+		}
 		log.Println("Server stopped gracefully")
-		os.Exit(0)
+		close(stopped)
 	}()
 
 	// Запуск gRPC сервера
@@ -125,4 +391,20 @@ func main() {
 	if err := srv.Serve(listener); err != nil {
 		log.Fatalf("Failed to start server: %v", err)
 	}
+
+	// Serve возвращается сразу после начала graceful shutdown - ожидание остановки фоновых задач и закрытия репозитория
+	<-stopped
+}
+
+// logFsckReport логирует отчет проверки целостности хранилища: каждое нарушение и итог
+func logFsckReport(report *model.FsckReport) {
+	for _, issue := range report.Issues {
+		state := "not repaired"
+		if issue.Repaired {
+			state = "repaired"
+		}
+		log.Printf("Storage check: %s %s %v: %s (%s: %s)", issue.Kind, issue.BlobID, issue.FileIDs, issue.Detail, state, issue.Action)
+	}
+	log.Printf("Storage check: %d files, %d blobs, %d issues, %d repaired in %v",
+		report.Files, report.Blobs, len(report.Issues), report.Repaired(), report.Duration.Round(time.Millisecond))
 }
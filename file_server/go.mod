@@ -3,6 +3,7 @@ module file_server
 go 1.25.1
 
 require (
+	golang.org/x/image v0.25.0
 	google.golang.org/grpc v1.75.1
 	google.golang.org/protobuf v1.36.6
 )
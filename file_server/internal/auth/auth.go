@@ -0,0 +1,123 @@
+// auth.go - учетные данные API клиентов
+// Учетные данные загружаются из JSON файла: ключ API -> описание клиента и его политики
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"file_server/pkg/model"
+	"fmt"
+	"os"
+)
+
+// APIKeyHeader - имя заголовка gRPC metadata с ключом API
+const APIKeyHeader = "x-api-key"
+
+var (
+	ErrUnknownAPIKey  = errors.New("UNKNOWN API KEY")
+	ErrAPIKeyRequired = errors.New("API KEY REQUIRED")
+
+	// ErrAnonymousBypass - принудительный водяной знак при разрешенных анонимных запросах:
+	// клиент мог бы получить оригинал, просто не передав ключ API
+	ErrAnonymousBypass = errors.New("WATERMARK ENFORCEMENT REQUIRES API KEY FOR ALL REQUESTS")
+)
+
+// credentialKey - ключ для хранения учетных данных в контексте запроса
+type credentialKey struct{}
+
+// Store - хранилище учетных данных клиентов
+type Store struct {
+	credentials map[string]*model.Credential // Ключ API -> учетные данные
+	required    bool                         // Запрещены ли запросы без ключа API
+}
+
+// NewStore создает хранилище учетных данных
+// Если required = true, запросы без ключа API отклоняются
+func NewStore(credentials map[string]*model.Credential, required bool) *Store {
+	if credentials == nil {
+		credentials = make(map[string]*model.Credential)
+	}
+	return &Store{
+		credentials: credentials,
+		required:    required,
+	}
+}
+
+// LoadStore загружает учетные данные из JSON файла вида {"<api key>": {"name": "...", ...}}
+// Пустой путь означает отсутствие учетных данных (все клиенты анонимные)
+// Учетные данные с принудительным водяным знаком допускаются только при required = true
+func LoadStore(path string, required bool) (*Store, error) {
+	if path == "" {
+		return NewStore(nil, required), nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("FAILED TO READ CREDENTIALS FILE: %w", err)
+	}
+
+	credentials := make(map[string]*model.Credential)
+	if err := json.Unmarshal(data, &credentials); err != nil {
+		return nil, fmt.Errorf("FAILED TO PARSE CREDENTIALS FILE: %w", err)
+	}
+
+	// Имя клиента по умолчанию - короткий префикс ключа (сам ключ не должен попадать в логи)
+	for key, cred := range credentials {
+		if cred == nil {
+			return nil, fmt.Errorf("EMPTY CREDENTIAL FOR KEY %.4s...", key)
+		}
+		if cred.Name == "" {
+			cred.Name = fmt.Sprintf("key-%.4s", key)
+		}
+		if cred.Watermark != nil && !required {
+			return nil, fmt.Errorf("%w (client %s)", ErrAnonymousBypass, cred.Name)
+		}
+	}
+
+	return NewStore(credentials, required), nil
+}
+
+// Authenticate возвращает учетные данные по ключу API
+// Пустой ключ допускается, только если ключ не обязателен (тогда возвращается nil - анонимный клиент)
+func (s *Store) Authenticate(apiKey string) (*model.Credential, error) {
+	if apiKey == "" {
+		if s.required {
+			return nil, ErrAPIKeyRequired
+		}
+		return nil, nil
+	}
+
+	cred, exists := s.credentials[apiKey]
+	if !exists {
+		return nil, ErrUnknownAPIKey
+	}
+	return cred, nil
+}
+
+// Count возвращает количество загруженных учетных данных
+func (s *Store) Count() int {
+	return len(s.credentials)
+}
+
+// Quotas возвращает ограничения места клиентов, для которых они заданы (имя клиента -> ограничение)
+func (s *Store) Quotas() map[string]model.Quota {
+	quotas := make(map[string]model.Quota)
+	for _, cred := range s.credentials {
+		if cred.Quota != nil {
+			quotas[cred.Name] = *cred.Quota
+		}
+	}
+	return quotas
+}
+
+// NewContext возвращает контекст с учетными данными клиента
+func NewContext(ctx context.Context, cred *model.Credential) context.Context {
+	return context.WithValue(ctx, credentialKey{}, cred)
+}
+
+// FromContext возвращает учетные данные клиента из контекста (nil для анонимного клиента)
+func FromContext(ctx context.Context) *model.Credential {
+	cred, _ := ctx.Value(credentialKey{}).(*model.Credential)
+	return cred
+}
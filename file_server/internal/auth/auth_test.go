@@ -0,0 +1,103 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"file_server/pkg/model"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeCredentials сохраняет учетные данные во временный файл и возвращает путь к нему
+func writeCredentials(t *testing.T, data string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "credentials.json")
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadStore(t *testing.T) {
+	path := writeCredentials(t, `{
+		"partner-secret": {"name": "partner-a", "quota": {"max_bytes": 100}},
+		"ops-secret": {"admin": true}
+	}`)
+
+	store, err := LoadStore(path, false)
+	if err != nil {
+		t.Fatalf("LoadStore: %v", err)
+	}
+	if store.Count() != 2 {
+		t.Errorf("Count = %d, want 2", store.Count())
+	}
+
+	// Имя по умолчанию - префикс ключа
+	if cred, err := store.Authenticate("ops-secret"); err != nil || cred.Name != "key-ops-" || !cred.Admin {
+		t.Errorf("Authenticate(ops) = %+v, %v", cred, err)
+	}
+	if quotas := store.Quotas(); len(quotas) != 1 || quotas["partner-a"].MaxBytes != 100 {
+		t.Errorf("Quotas = %+v", quotas)
+	}
+
+	// Анонимный клиент допускается, неизвестный ключ - нет
+	if cred, err := store.Authenticate(""); err != nil || cred != nil {
+		t.Errorf("Authenticate(\"\") = %+v, %v, want anonymous", cred, err)
+	}
+	if _, err := store.Authenticate("guess"); !errors.Is(err, ErrUnknownAPIKey) {
+		t.Errorf("Authenticate(guess) = %v, want ErrUnknownAPIKey", err)
+	}
+}
+
+func TestLoadStoreRequired(t *testing.T) {
+	store, err := LoadStore("", true)
+	if err != nil {
+		t.Fatalf("LoadStore: %v", err)
+	}
+	if _, err := store.Authenticate(""); !errors.Is(err, ErrAPIKeyRequired) {
+		t.Errorf("Authenticate(\"\") = %v, want ErrAPIKeyRequired", err)
+	}
+}
+
+func TestLoadStoreWatermarkRequiresAPIKey(t *testing.T) {
+	path := writeCredentials(t, `{"partner-secret": {"name": "partner-a", "watermark": {"watermark_file_id": "file-1"}}}`)
+
+	// Без обязательного ключа клиент обошел бы водяной знак анонимным запросом
+	if _, err := LoadStore(path, false); !errors.Is(err, ErrAnonymousBypass) {
+		t.Errorf("LoadStore without required key = %v, want ErrAnonymousBypass", err)
+	}
+
+	store, err := LoadStore(path, true)
+	if err != nil {
+		t.Fatalf("LoadStore: %v", err)
+	}
+	if cred, err := store.Authenticate("partner-secret"); err != nil || cred.Watermark == nil {
+		t.Errorf("Authenticate = %+v, %v", cred, err)
+	}
+}
+
+func TestLoadStoreRejectsInvalidFiles(t *testing.T) {
+	tests := map[string]string{
+		"malformed":  `{"key":`,
+		"null entry": `{"key": null}`,
+	}
+	for name, data := range tests {
+		if _, err := LoadStore(writeCredentials(t, data), true); err == nil {
+			t.Errorf("%s: LoadStore succeeded", name)
+		}
+	}
+	if _, err := LoadStore(filepath.Join(t.TempDir(), "missing.json"), true); err == nil {
+		t.Error("LoadStore of a missing file succeeded")
+	}
+}
+
+func TestContext(t *testing.T) {
+	if cred := FromContext(context.Background()); cred != nil {
+		t.Errorf("FromContext of empty context = %+v", cred)
+	}
+	cred := &model.Credential{Name: "alice"}
+	if got := FromContext(NewContext(context.Background(), cred)); got != cred {
+		t.Errorf("FromContext = %+v, want %+v", got, cred)
+	}
+}
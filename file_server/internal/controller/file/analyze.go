@@ -0,0 +1,174 @@
+// analyze.go - вычисление характеристик изображений для метаданных файлов
+// Выполняется при загрузке и при фоновом дозаполнении метаданных старых файлов
+package file
+
+import (
+	"context"
+	"file_server/internal/imaging"
+	"file_server/internal/svg"
+	"file_server/pkg/model"
+	"fmt"
+	"time"
+)
+
+// imageMeta - характеристики, вычисляемые по содержимому изображения
+type imageMeta struct {
+	format   string             // Исходный формат изображения
+	colors   imaging.ColorStats // Палитра и гистограмма
+	blurHash string             // Строка BlurHash для размытого превью
+	gif      *imaging.GIFInfo   // Метаданные анимации (nil для не-GIF)
+}
+
+// inspectImage вычисляет характеристики изображения
+// Для файлов, которые не удалось декодировать как изображение, возвращает nil без ошибки
+// Возвращает ошибку для анимаций, превышающих ограничения по кадрам и пикселям
+func inspectImage(data []byte) (*imageMeta, error) {
+	img, format, err := imaging.Decode(data)
+	if err != nil {
+		return nil, nil
+	}
+
+	meta := &imageMeta{
+		format:   format,
+		colors:   imaging.AnalyzeColors(img),
+		blurHash: imaging.BlurHash(img),
+	}
+
+	// Для GIF дополнительно декодируются все кадры
+	if format == "gif" {
+		g, err := imaging.DecodeGIF(data)
+		if err != nil {
+			return nil, err
+		}
+		info := imaging.AnalyzeGIF(g)
+		meta.gif = &info
+	}
+
+	return meta, nil
+}
+
+// apply записывает характеристики в метаданные файла
+func (m *imageMeta) apply(info *model.FileInfo) {
+	info.Format = m.format
+	info.Palette = m.colors.Palette
+	info.Histogram = m.colors.Histogram
+	info.BlurHash = m.blurHash
+
+	if m.gif != nil {
+		info.FrameCount = m.gif.FrameCount
+		info.FrameDelays = m.gif.DelaysMs
+		info.DurationMs = m.gif.DurationMs
+	}
+}
+
+// saveFile сохраняет файл владельца owner в репозитории вместе с характеристиками изображения
+// и сроком хранения (nil - бессрочно) в одной записи метаданных
+// Изображение анализируется до сохранения, чтобы отклонить недопустимые анимации
+func (c *Controller) saveFile(filename string, data []byte, owner string, expiresAt *time.Time) (string, error) {
+	// SVG документы очищаются от активного содержимого до сохранения
+	// ID файла вычисляется по очищенному содержимому
+	isSVG := svg.IsSVG(filename, data)
+	sanitized := false
+	if isSVG {
+		var err error
+		if data, sanitized, err = svg.Sanitize(data); err != nil {
+			return "", fmt.Errorf("FAILED TO SANITIZE SVG: %w", err)
+		}
+	}
+
+	// Для файлов, не являющихся растровыми изображениями, meta = nil
+	meta, err := inspectImage(data)
+	if err != nil {
+		return "", fmt.Errorf("FAILED TO INSPECT FILE: %w", err)
+	}
+
+	// Делегирование сохранения файла репозиторию вместе с характеристиками изображения и сроком хранения
+	// Отметка об анализе сохраняется и для остальных файлов, чтобы они не анализировались повторно при запуске
+	fileID, err := c.repo.SaveFileWithInfo(filename, data, owner, func(info *model.FileInfo) {
+		if meta != nil {
+			meta.apply(info)
+		}
+		if isSVG {
+			info.Format = "svg"
+			info.Sanitized = sanitized
+		}
+		info.Analyzed = true
+		info.ExpiresAt = expiresAt
+	})
+	if err != nil {
+		return "", fmt.Errorf("FAILED TO SAVE FILE: %w", err)
+	}
+
+	return fileID, nil
+}
+
+// needsAnalysis проверяет, нужно ли вычислять характеристики файла
+// Файлы, проанализированные до появления BlurHash и определения формата, анализируются повторно;
+// файлы с отметкой об анализе (в том числе не-изображения) не анализируются
+func needsAnalysis(info *model.FileInfo) bool {
+	if info.Analyzed {
+		return false
+	}
+	return info.Palette == nil || info.BlurHash == "" || info.Format == ""
+}
+
+// BackfillImageMeta вычисляет характеристики изображений для файлов и их прежних версий, у которых их нет
+// Используется при старте сервера для файлов, загруженных до появления анализа
+// Возвращает количество обработанных изображений
+func (c *Controller) BackfillImageMeta(ctx context.Context) (int, error) {
+	files, err := c.repo.ListFiles()
+	if err != nil {
+		return 0, fmt.Errorf("FAILED TO FIND FILES: %w", err)
+	}
+
+	// Прежние версии не входят в список файлов и добавляются по истории каждого файла
+	for _, info := range files {
+		if len(info.Versions) == 0 {
+			continue
+		}
+		versions, err := c.repo.ListVersions(info.ID)
+		if err != nil {
+			continue // Файл мог быть удален во время обработки
+		}
+		files = append(files, versions[1:]...) // Первая - текущая версия, она уже в списке
+	}
+
+	processed := 0
+	for _, info := range files {
+		// Проверка контекста на отмену операции
+		select {
+		case <-ctx.Done():
+			return processed, ctx.Err()
+		default:
+		}
+
+		if !needsAnalysis(&info) {
+			continue
+		}
+
+		file, err := c.repo.GetFile(info.ID)
+		if err != nil {
+			continue // Файл мог быть удален во время обработки
+		}
+
+		// Для не-изображений и анимаций сверх ограничений сохраняется только отметка об анализе
+		meta, err := inspectImage(file.Data)
+		if err != nil {
+			meta = nil
+		}
+
+		if err := c.repo.UpdateFileInfo(info.ID, func(info *model.FileInfo) {
+			if meta != nil {
+				meta.apply(info)
+			}
+			info.Analyzed = true
+		}); err != nil {
+			continue // Файл мог быть удален во время обработки
+		}
+		if meta != nil {
+			processed++
+		}
+	}
+
+	return processed, nil
+}
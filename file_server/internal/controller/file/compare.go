@@ -0,0 +1,72 @@
+// compare.go - сравнение изображений для контроля качества рендеров
+package file
+
+import (
+	"context"
+	"file_server/internal/imaging"
+	"file_server/pkg/model"
+	"fmt"
+	"image"
+)
+
+// CompareImages сравнивает два изображения и возвращает метрики сходства
+// При запросе строит изображение различий с подсвеченными измененными пикселями
+func (c *Controller) CompareImages(ctx context.Context, req *model.CompareRequest) (*model.CompareResponse, error) {
+	// Проверка контекста на отмену операции
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+	}
+
+	// Загрузка и декодирование обоих изображений
+	a, err := c.decodeFile(req.FileIDA)
+	if err != nil {
+		return nil, err
+	}
+	b, err := c.decodeFile(req.FileIDB)
+	if err != nil {
+		return nil, err
+	}
+
+	// Сравнение
+	result, err := imaging.Compare(a, b, req.Tolerance, req.Mismatch, req.IncludeDiff)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &model.CompareResponse{
+		PSNR:          result.PSNR,
+		SSIM:          result.SSIM,
+		ChangedPixels: result.ChangedPixels,
+		TotalPixels:   result.TotalPixels,
+	}
+
+	// Изображение различий показывает содержимое, поэтому на него тоже распространяется водяной знак клиента
+	if result.Diff != nil {
+		watermark, err := c.outputWatermark(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if resp.Diff, err = imaging.EncodePNG(watermark(result.Diff)); err != nil {
+			return nil, err
+		}
+	}
+
+	return resp, nil
+}
+
+// decodeFile загружает файл из репозитория и декодирует его как изображение
+func (c *Controller) decodeFile(fileID string) (image.Image, error) {
+	file, err := c.repo.GetFile(fileID)
+	if err != nil {
+		return nil, fmt.Errorf("FAILED TO GET FILE %s: %w", fileID, err)
+	}
+
+	img, _, err := imaging.Decode(file.Data)
+	if err != nil {
+		return nil, fmt.Errorf("FAILED TO DECODE FILE %s: %w", fileID, err)
+	}
+
+	return img, nil
+}
@@ -4,9 +4,13 @@ package file
 
 import (
 	"context"
+	"errors"
+	"file_server/internal/auth"
+	"file_server/internal/imaging"
 	"file_server/internal/repository/file"
 	"file_server/pkg/model"
 	"fmt"
+	"sort"
 )
 
 // Controller - контроллер для файловых операций
@@ -26,6 +30,7 @@ func NewController(repo *file.Repository) *Controller {
 
 // UploadFile обрабатывает запрос на загрузку файла
 // Проверяет контекст и делегирует сохранение репозиторию
+// При заданном req.Replace загрузка становится новой версией существующего файла (см. versions.go)
 func (c *Controller) UploadFile(ctx context.Context, req *model.UploadRequest) (*model.UploadResponse, error) {
 	// Проверка контекста на отмену операции
 	select {
@@ -34,20 +39,32 @@ func (c *Controller) UploadFile(ctx context.Context, req *model.UploadRequest) (
 	default:
 	}
 
-	// Делегирование сохранения файла репозиторию
-	fileID, err := c.repo.SaveFile(req.Filename, req.Data)
+	// Срок хранения проверяется до сохранения
+	if err := validateExpiry(req.ExpiresAt); err != nil {
+		return nil, err
+	}
+
+	// Замена содержимого существующего файла новой версией
+	if req.Replace != "" {
+		return c.replaceFile(req.Replace, req.Filename, req.Data, owner(ctx), req.ExpiresAt)
+	}
+
+	// Сохранение файла вместе с характеристиками изображения и сроком хранения (владелец - клиент, загрузивший файл)
+	fileID, err := c.saveFile(req.Filename, req.Data, owner(ctx), req.ExpiresAt)
 	if err != nil {
-		return nil, fmt.Errorf("FAILED TO SAVE FILE: %w", err)
+		return nil, err
 	}
 
 	// Возврат успешного ответа с ID файла
 	return &model.UploadResponse{
-		FileID: fileID,
+		FileID:  fileID,
+		Version: 1,
 	}, nil
 }
 
 // GetFile обрабатывает запрос на получение файла по ID
 // Проверяет контекст и делегирует загрузку репозиторию
+// По умолчанию выдается текущая версия файла, прежняя - по номеру версии
 func (c *Controller) GetFile(ctx context.Context, req *model.GetRequest) (*model.GetResponse, error) {
 	// Проверка контекста на отмену операции
 	select {
@@ -56,22 +73,56 @@ func (c *Controller) GetFile(ctx context.Context, req *model.GetRequest) (*model
 	default:
 	}
 
+	// Прежняя версия файла хранится под своим ID
+	fileID := req.FileID
+	if req.Version > 0 {
+		info, err := c.repo.FileVersion(req.FileID, req.Version)
+		if err != nil {
+			return nil, fmt.Errorf("FAILED TO GET FILE: %w", err)
+		}
+		fileID = info.ID
+	}
+
 	// Делегирование загрузки файла репозиторию
-	file, err := c.repo.GetFile(req.FileID)
+	file, err := c.repo.GetFile(fileID)
 	if err != nil {
 		return nil, fmt.Errorf("FAILED TO GET FILE: %w", err)
 	}
 
+	// Наложение водяного знака (из запроса или принудительного для клиента) и преобразование формата
+	data := file.Data
+	policy, enforced := watermarkPolicy(ctx, req.Watermark)
+	switch {
+	case policy != nil && !alreadyWatermarked(&file.Info, policy):
+		data, _, err = c.watermarkData(file.Data, policy, req.Format)
+		if errors.Is(err, imaging.ErrNotAnImage) && enforced {
+			return nil, ErrWatermarkRequired // Оригинал нельзя выдать клиенту с принудительным водяным знаком
+		}
+	case req.Format != "":
+		data, err = convertData(file.Data, req.Format)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// При преобразовании формата расширение имени файла заменяется на новое
+	filename := file.Info.Filename
+	if req.Format != "" {
+		format, _ := imaging.NormalizeFormat(req.Format) // Формат уже проверен при преобразовании
+		filename = replaceExt(filename, format)
+	}
+
 	// Возврат успешного ответа с данными файла
 	return &model.GetResponse{
-		Filename: file.Info.Filename,
-		Data:     file.Data,
+		Filename: filename,
+		Data:     data,
 	}, nil
 }
 
 // ListFiles обрабатывает запрос на получение списка всех файлов
 // Проверяет контекст и делегирует получение списка репозиторию
-func (c *Controller) ListFiles(ctx context.Context) (*model.ListResponse, error) {
+// При заданном цвете возвращает только изображения с близким цветом палитры (ближайшие первыми)
+func (c *Controller) ListFiles(ctx context.Context, req *model.ListRequest) (*model.ListResponse, error) {
 	// Проверка контекста на отмену операции
 	select {
 	case <-ctx.Done():
@@ -85,6 +136,14 @@ func (c *Controller) ListFiles(ctx context.Context) (*model.ListResponse, error)
 		return nil, fmt.Errorf("FAILED TO FIND FILES: %w", err)
 	}
 
+	// Фильтрация по цвету
+	if req != nil && req.Color != "" {
+		files, err = filterByColor(files, req.Color, req.MaxDistance)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	// Возврат успешного ответа со списком файлов
 	return &model.ListResponse{
 		Files: files,
@@ -105,16 +164,75 @@ func (c *Controller) GetFileInfo(ctx context.Context, fileID string) (*model.Fil
 	return c.repo.GetFileInfo(fileID)
 }
 
-// GetStats получает статистику репозитория (количество файлов и общий размер)
+// GetStats получает статистику репозитория (количество файлов, общий размер, объем содержимого до и после сжатия)
 // Проверяет контекст и делегирует запрос репозиторию
-func (c *Controller) GetStats(ctx context.Context) (int, int64, error) {
+func (c *Controller) GetStats(ctx context.Context) (model.StorageStats, error) {
 	// Проверка контекста на отмену операции
 	select {
 	case <-ctx.Done():
-		return 0, 0, ctx.Err() // Возвращаем ошибку отмены контекста
+		return model.StorageStats{}, ctx.Err() // Возвращаем ошибку отмены контекста
 	default:
 	}
 
 	// Делегирование получения статистики репозиторию
 	return c.repo.GetStats()
 }
+
+// GetQuota возвращает занятое место и ограничения для клиента, выполняющего запрос
+// Проверяет контекст и делегирует запрос репозиторию
+func (c *Controller) GetQuota(ctx context.Context) (*model.QuotaReport, error) {
+	// Проверка контекста на отмену операции
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err() // Возвращаем ошибку отмены контекста
+	default:
+	}
+
+	// Делегирование получения учета места репозиторию
+	report := c.repo.Quota(owner(ctx))
+	return &report, nil
+}
+
+// owner возвращает имя клиента, выполняющего запрос (пусто для анонимного клиента)
+func owner(ctx context.Context) string {
+	if cred := auth.FromContext(ctx); cred != nil {
+		return cred.Name
+	}
+	return ""
+}
+
+// filterByColor оставляет файлы, палитра которых содержит цвет не дальше maxDistance от заданного
+// Результат отсортирован по возрастанию расстояния
+func filterByColor(files []model.FileInfo, hex string, maxDistance float64) ([]model.FileInfo, error) {
+	target, err := imaging.ParseHexColor(hex)
+	if err != nil {
+		return nil, err
+	}
+
+	if maxDistance <= 0 {
+		maxDistance = imaging.DefaultColorDistance
+	}
+
+	type match struct {
+		info     model.FileInfo
+		distance float64
+	}
+
+	matches := make([]match, 0, len(files))
+	for _, info := range files {
+		if d, ok := imaging.PaletteDistance(info.Palette, target); ok && d <= maxDistance {
+			matches = append(matches, match{info: info, distance: d})
+		}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].distance < matches[j].distance
+	})
+
+	result := make([]model.FileInfo, 0, len(matches))
+	for _, m := range matches {
+		result = append(result, m.info)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,394 @@
+package file
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"file_server/internal/auth"
+	"file_server/internal/repository"
+	"file_server/internal/repository/file"
+	"file_server/internal/storage/memory"
+	"file_server/pkg/model"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newTestController создает контроллер поверх хранилища в памяти
+func newTestController(t *testing.T) *Controller {
+	t.Helper()
+	repo, err := file.NewRepo(memory.NewBlobStore(), memory.NewMetaStore())
+	if err != nil {
+		t.Fatalf("NewRepo: %v", err)
+	}
+	t.Cleanup(func() { repo.Close() })
+	return NewController(repo)
+}
+
+// testPNG кодирует однотонное изображение в PNG
+func testPNG(t *testing.T, c color.Color) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, 16, 16))
+	for y := 0; y < 16; y++ {
+		for x := 0; x < 16; x++ {
+			img.Set(x, y, c)
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestControllerUploadAndGet(t *testing.T) {
+	ctx := context.Background()
+	ctrl := newTestController(t)
+	data := testPNG(t, color.RGBA{R: 200, G: 30, B: 30, A: 255})
+
+	uploaded, err := ctrl.UploadFile(ctx, &model.UploadRequest{Filename: "red.png", Data: data})
+	if err != nil {
+		t.Fatalf("UploadFile: %v", err)
+	}
+	if !strings.HasPrefix(uploaded.FileID, model.FileIDPrefix) {
+		t.Errorf("upload returned ID %q, want a logical file ID", uploaded.FileID)
+	}
+
+	// Повторная загрузка того же содержимого другим клиентом создает его собственный файл с тем же содержимым
+	again, err := ctrl.UploadFile(auth.NewContext(ctx, &model.Credential{Name: "bob"}), &model.UploadRequest{Filename: "copy.png", Data: data})
+	if err != nil || again.FileID == uploaded.FileID {
+		t.Fatalf("duplicate upload = %+v, %v; want a new ID", again, err)
+	}
+	copyInfo, err := ctrl.GetFileInfo(ctx, again.FileID)
+	if err != nil || copyInfo.Filename != "copy.png" || copyInfo.Owner != "bob" || copyInfo.BlobID != model.NewBlobID(data) {
+		t.Errorf("duplicate upload info = %+v, %v", copyInfo, err)
+	}
+
+	// Метаданные изображения вычислены при загрузке
+	info, err := ctrl.GetFileInfo(ctx, uploaded.FileID)
+	if err != nil {
+		t.Fatalf("GetFileInfo: %v", err)
+	}
+	if info.Filename != "red.png" || info.Format != "png" || len(info.Palette) == 0 || info.BlurHash == "" {
+		t.Errorf("GetFileInfo = %+v", info)
+	}
+
+	// Скачивание оригинала и с преобразованием формата
+	got, err := ctrl.GetFile(ctx, &model.GetRequest{FileID: uploaded.FileID})
+	if err != nil || !bytes.Equal(got.Data, data) {
+		t.Fatalf("GetFile returned different content: %v", err)
+	}
+	converted, err := ctrl.GetFile(ctx, &model.GetRequest{FileID: uploaded.FileID, Format: "jpeg"})
+	if err != nil {
+		t.Fatalf("GetFile jpeg: %v", err)
+	}
+	if converted.Filename != "red.jpeg" {
+		t.Errorf("converted filename = %q, want red.jpeg", converted.Filename)
+	}
+	if _, err := jpeg.Decode(bytes.NewReader(converted.Data)); err != nil {
+		t.Errorf("converted data is not JPEG: %v", err)
+	}
+}
+
+func TestControllerExpiry(t *testing.T) {
+	ctx := context.Background()
+	ctrl := newTestController(t)
+	data := testPNG(t, color.RGBA{R: 30, G: 200, B: 30, A: 255})
+
+	// Срок хранения в прошлом отклоняется при загрузке
+	past := time.Now().Add(-time.Minute)
+	if _, err := ctrl.UploadFile(ctx, &model.UploadRequest{Filename: "old.png", Data: data, ExpiresAt: &past}); !errors.Is(err, ErrInvalidExpiry) {
+		t.Fatalf("upload with past expiry: %v, want ErrInvalidExpiry", err)
+	}
+
+	expiresAt := time.Now().Add(time.Hour)
+	uploaded, err := ctrl.UploadFile(ctx, &model.UploadRequest{Filename: "temp.png", Data: data, ExpiresAt: &expiresAt})
+	if err != nil {
+		t.Fatalf("UploadFile: %v", err)
+	}
+	info, err := ctrl.GetFileInfo(ctx, uploaded.FileID)
+	if err != nil || info.ExpiresAt == nil || !info.ExpiresAt.Equal(expiresAt) {
+		t.Fatalf("GetFileInfo = %+v, %v; want expiry %v", info, err, expiresAt)
+	}
+
+	// Продление и снятие срока хранения
+	extended := expiresAt.Add(time.Hour)
+	if info, err := ctrl.SetExpiry(ctx, uploaded.FileID, &extended); err != nil || !info.ExpiresAt.Equal(extended) {
+		t.Errorf("SetExpiry extend = %+v, %v", info, err)
+	}
+	if _, err := ctrl.SetExpiry(ctx, uploaded.FileID, &past); !errors.Is(err, ErrInvalidExpiry) {
+		t.Errorf("SetExpiry in the past: %v, want ErrInvalidExpiry", err)
+	}
+	if info, err := ctrl.SetExpiry(ctx, uploaded.FileID, nil); err != nil || info.ExpiresAt != nil {
+		t.Errorf("SetExpiry clear = %+v, %v", info, err)
+	}
+
+	// Срок хранения из запроса замены переходит к файлу
+	if _, err := ctrl.UploadFile(ctx, &model.UploadRequest{Filename: "temp.png", Data: data, Replace: uploaded.FileID, ExpiresAt: &expiresAt}); err != nil {
+		t.Fatalf("replace: %v", err)
+	}
+	if info, _ := ctrl.GetFileInfo(ctx, uploaded.FileID); info.ExpiresAt == nil || !info.ExpiresAt.Equal(expiresAt) || info.Version != 2 {
+		t.Errorf("after replace with expiry = %+v", info)
+	}
+
+	// Другой клиент не может задать срок хранения чужому файлу и его прежней версии
+	alice := auth.NewContext(ctx, &model.Credential{Name: "alice"})
+	bob := auth.NewContext(ctx, &model.Credential{Name: "bob"})
+	owned, err := ctrl.UploadFile(alice, &model.UploadRequest{Filename: "own.png", Data: data})
+	if err != nil {
+		t.Fatalf("UploadFile: %v", err)
+	}
+	if _, err := ctrl.UploadFile(alice, &model.UploadRequest{Filename: "own.png", Data: testPNG(t, color.White), Replace: owned.FileID}); err != nil {
+		t.Fatalf("replace: %v", err)
+	}
+	archived, err := ctrl.repo.FileVersion(owned.FileID, 1)
+	if err != nil {
+		t.Fatalf("FileVersion: %v", err)
+	}
+	soon := time.Now().Add(time.Second)
+	for _, id := range []string{owned.FileID, archived.ID} {
+		if _, err := ctrl.SetExpiry(bob, id, &soon); !errors.Is(err, repository.ErrNotFileOwner) {
+			t.Errorf("SetExpiry(%s) by another client = %v, want ErrNotFileOwner", id, err)
+		}
+		if info, _ := ctrl.GetFileInfo(ctx, id); info.ExpiresAt != nil {
+			t.Errorf("%s expires at %v after foreign SetExpiry", id, info.ExpiresAt)
+		}
+	}
+	if _, err := ctrl.SetExpiry(alice, archived.ID, &extended); err != nil {
+		t.Errorf("SetExpiry of own version: %v", err)
+	}
+}
+
+func TestControllerFsckRequiresAdmin(t *testing.T) {
+	ctx := context.Background()
+	ctrl := newTestController(t)
+
+	// Анонимный клиент и клиент без прав администратора
+	for _, cred := range []*model.Credential{nil, {Name: "bob"}} {
+		if _, err := ctrl.Fsck(auth.NewContext(ctx, cred), false); !errors.Is(err, ErrAdminRequired) {
+			t.Errorf("Fsck by %+v: %v, want ErrAdminRequired", cred, err)
+		}
+	}
+
+	report, err := ctrl.Fsck(auth.NewContext(ctx, &model.Credential{Name: "ops", Admin: true}), true)
+	if err != nil || len(report.Issues) != 0 {
+		t.Errorf("Fsck by admin = %+v, %v", report, err)
+	}
+}
+
+func TestControllerVersions(t *testing.T) {
+	ctx := context.Background()
+	ctrl := newTestController(t)
+	red := testPNG(t, color.RGBA{R: 200, G: 30, B: 30, A: 255})
+	blue := testPNG(t, color.RGBA{R: 30, G: 30, B: 200, A: 255})
+
+	uploaded, err := ctrl.UploadFile(ctx, &model.UploadRequest{Filename: "logo.png", Data: red})
+	if err != nil || uploaded.Version != 1 {
+		t.Fatalf("UploadFile = %+v, %v", uploaded, err)
+	}
+	before, _ := ctrl.GetFileInfo(ctx, uploaded.FileID)
+
+	// Замена сохраняет ID файла, метаданные изображения вычисляются по новому содержимому
+	replaced, err := ctrl.UploadFile(ctx, &model.UploadRequest{Filename: "logo.png", Data: blue, Replace: uploaded.FileID})
+	if err != nil || replaced.FileID != uploaded.FileID || replaced.Version != 2 {
+		t.Fatalf("replace = %+v, %v", replaced, err)
+	}
+	after, _ := ctrl.GetFileInfo(ctx, uploaded.FileID)
+	if after.Palette[0] == before.Palette[0] || after.BlurHash == before.BlurHash {
+		t.Errorf("image metadata not updated: %v -> %v", before.Palette, after.Palette)
+	}
+
+	// Скачивание последней и прежней версии
+	for version, want := range map[int][]byte{0: blue, 1: red, 2: blue} {
+		got, err := ctrl.GetFile(ctx, &model.GetRequest{FileID: uploaded.FileID, Version: version})
+		if err != nil || !bytes.Equal(got.Data, want) {
+			t.Errorf("GetFile version %d: %v", version, err)
+		}
+	}
+
+	// Восстановление прежней версии создает новую версию с ее содержимым
+	restored, err := ctrl.RestoreVersion(ctx, uploaded.FileID, 1)
+	if err != nil || restored.Version != 3 {
+		t.Fatalf("RestoreVersion = %+v, %v", restored, err)
+	}
+	if got, err := ctrl.GetFile(ctx, &model.GetRequest{FileID: uploaded.FileID}); err != nil || !bytes.Equal(got.Data, red) {
+		t.Errorf("GetFile after restore: %v", err)
+	}
+	if versions, err := ctrl.ListVersions(ctx, uploaded.FileID); err != nil || len(versions) != 3 {
+		t.Errorf("ListVersions = %d versions, %v", len(versions), err)
+	}
+	if _, err := ctrl.RestoreVersion(ctx, uploaded.FileID, 7); !errors.Is(err, repository.ErrVersionNotFound) {
+		t.Errorf("RestoreVersion of a missing version = %v, want ErrVersionNotFound", err)
+	}
+	if files, _ := ctrl.ListFiles(ctx, &model.ListRequest{}); len(files.Files) != 1 {
+		t.Errorf("ListFiles = %d files, want 1", len(files.Files))
+	}
+
+	// Другой клиент не может заменить или восстановить чужой файл
+	bob := auth.NewContext(ctx, &model.Credential{Name: "bob"})
+	if _, err := ctrl.UploadFile(bob, &model.UploadRequest{Filename: "logo.png", Data: blue, Replace: uploaded.FileID}); !errors.Is(err, repository.ErrNotFileOwner) {
+		t.Errorf("replace by another client = %v, want ErrNotFileOwner", err)
+	}
+	if _, err := ctrl.RestoreVersion(bob, uploaded.FileID, 2); !errors.Is(err, repository.ErrNotFileOwner) {
+		t.Errorf("restore by another client = %v, want ErrNotFileOwner", err)
+	}
+	if info, _ := ctrl.GetFileInfo(ctx, uploaded.FileID); info.Owner != "" || info.Version != 3 {
+		t.Errorf("file after foreign replace = %+v", info)
+	}
+}
+
+func TestControllerEnforcedWatermark(t *testing.T) {
+	ctx := context.Background()
+	ctrl := newTestController(t)
+	white := color.RGBA{R: 255, G: 255, B: 255, A: 255}
+
+	original, err := ctrl.UploadFile(ctx, &model.UploadRequest{Filename: "white.png", Data: testPNG(t, white)})
+	if err != nil {
+		t.Fatalf("UploadFile: %v", err)
+	}
+	mark, err := ctrl.UploadFile(ctx, &model.UploadRequest{Filename: "mark.png", Data: testPNG(t, color.RGBA{A: 255})})
+	if err != nil {
+		t.Fatalf("UploadFile: %v", err)
+	}
+
+	// centerPixel возвращает цвет центра кадра, выданного клиенту
+	centerPixel := func(ctx context.Context) color.RGBA {
+		t.Helper()
+		frame, err := ctrl.GetFrame(ctx, &model.FrameRequest{FileID: original.FileID})
+		if err != nil {
+			t.Fatalf("GetFrame: %v", err)
+		}
+		img, err := png.Decode(bytes.NewReader(frame.Data))
+		if err != nil {
+			t.Fatalf("decode frame: %v", err)
+		}
+		return color.RGBAModel.Convert(img.At(8, 8)).(color.RGBA)
+	}
+
+	// Клиент без политики получает изображение без изменений
+	if got := centerPixel(ctx); got != white {
+		t.Errorf("anonymous frame center = %v, want %v", got, white)
+	}
+
+	// Сгенерированные сервером изображения клиента с политикой получают водяной знак
+	partner := auth.NewContext(ctx, &model.Credential{Name: "partner-a", Watermark: &model.WatermarkPolicy{
+		WatermarkFileID: mark.FileID, Position: "center", Opacity: 1, Scale: 1,
+	}})
+	if got := centerPixel(partner); got == white {
+		t.Errorf("enforced frame center = %v, want the watermark", got)
+	}
+
+	// Оригинал файла клиенту с политикой не выдается
+	got, err := ctrl.GetFile(partner, &model.GetRequest{FileID: original.FileID})
+	if err != nil {
+		t.Fatalf("GetFile: %v", err)
+	}
+	if bytes.Equal(got.Data, testPNG(t, white)) {
+		t.Error("client with enforced watermark got the original")
+	}
+
+	// Недоступный водяной знак - ошибка, а не оригинал
+	broken := auth.NewContext(ctx, &model.Credential{Name: "partner-b", Watermark: &model.WatermarkPolicy{WatermarkFileID: "file-missing"}})
+	if _, err := ctrl.GetFrame(broken, &model.FrameRequest{FileID: original.FileID}); err == nil {
+		t.Error("GetFrame with a missing watermark succeeded")
+	}
+	if _, err := ctrl.GetFile(broken, &model.GetRequest{FileID: original.FileID}); err == nil {
+		t.Error("GetFile with a missing watermark succeeded")
+	}
+}
+
+func TestControllerBackfillImageMeta(t *testing.T) {
+	ctx := context.Background()
+	ctrl := newTestController(t)
+
+	// Файлы, сохраненные в обход анализа (как до его появления)
+	imageID, err := ctrl.repo.SaveFile("blue.png", testPNG(t, color.RGBA{B: 200, A: 255}), "")
+	if err != nil {
+		t.Fatalf("SaveFile: %v", err)
+	}
+	textID, err := ctrl.repo.SaveFile("notes.txt", []byte("plain text"), "")
+	if err != nil {
+		t.Fatalf("SaveFile: %v", err)
+	}
+	replacementID, err := ctrl.repo.SaveFile("blue.png", testPNG(t, color.RGBA{R: 200, A: 255}), "")
+	if err != nil {
+		t.Fatalf("SaveFile: %v", err)
+	}
+	if _, err := ctrl.repo.ReplaceFile(imageID, replacementID); err != nil {
+		t.Fatalf("ReplaceFile: %v", err)
+	}
+	archived, err := ctrl.repo.FileVersion(imageID, 1)
+	if err != nil {
+		t.Fatalf("FileVersion: %v", err)
+	}
+
+	// Анализ выполняется один раз (в том числе для прежних версий), не-изображения только отмечаются
+	if processed, err := ctrl.BackfillImageMeta(ctx); err != nil || processed != 2 {
+		t.Fatalf("BackfillImageMeta = %d, %v, want 2 images", processed, err)
+	}
+	if info, _ := ctrl.GetFileInfo(ctx, archived.ID); info.Format != "png" || info.BlurHash == "" {
+		t.Errorf("archived version after backfill: %+v", info)
+	}
+	for _, id := range []string{imageID, archived.ID, textID} {
+		if info, _ := ctrl.GetFileInfo(ctx, id); !info.Analyzed || needsAnalysis(info) {
+			t.Errorf("file %s after backfill: %+v", id, info)
+		}
+	}
+	if info, _ := ctrl.GetFileInfo(ctx, imageID); info.Format != "png" || info.BlurHash == "" {
+		t.Errorf("image after backfill: %+v", info)
+	}
+	if processed, err := ctrl.BackfillImageMeta(ctx); err != nil || processed != 0 {
+		t.Errorf("second BackfillImageMeta = %d, %v, want nothing to do", processed, err)
+	}
+
+	// Загруженные файлы отмечаются сразу
+	uploaded, err := ctrl.UploadFile(ctx, &model.UploadRequest{Filename: "more.txt", Data: []byte("more text")})
+	if err != nil {
+		t.Fatalf("UploadFile: %v", err)
+	}
+	if info, _ := ctrl.GetFileInfo(ctx, uploaded.FileID); needsAnalysis(info) {
+		t.Errorf("uploaded file needs analysis: %+v", info)
+	}
+}
+
+func TestControllerVariantsChargeRequester(t *testing.T) {
+	ctx := context.Background()
+	ctrl := newTestController(t)
+	ctrl.repo.SetLimits(file.Limits{Clients: map[string]model.Quota{"alice": {MaxFiles: 1}}})
+	alice := auth.NewContext(ctx, &model.Credential{Name: "alice"})
+	bob := auth.NewContext(ctx, &model.Credential{Name: "bob"})
+
+	uploaded, err := ctrl.UploadFile(alice, &model.UploadRequest{Filename: "logo.png", Data: testPNG(t, color.RGBA{R: 200, A: 255})})
+	if err != nil {
+		t.Fatalf("UploadFile: %v", err)
+	}
+
+	mark, err := ctrl.UploadFile(bob, &model.UploadRequest{Filename: "mark.png", Data: testPNG(t, color.White)})
+	if err != nil {
+		t.Fatalf("UploadFile: %v", err)
+	}
+
+	// Вариант чужого файла учитывается в месте запросившего его клиента, а не владельца файла
+	variant, err := ctrl.CreateWatermarkVariant(bob, &model.WatermarkVariantRequest{FileID: uploaded.FileID, Watermark: &model.WatermarkPolicy{WatermarkFileID: mark.FileID}})
+	if err != nil {
+		t.Fatalf("CreateWatermarkVariant by another client: %v", err)
+	}
+	if info, _ := ctrl.GetFileInfo(ctx, variant.FileID); info.Owner != "bob" || info.VariantOf != uploaded.FileID {
+		t.Errorf("variant = %+v, want bob's variant of alice's file", info)
+	}
+	if usage := ctrl.repo.Quota("alice").Usage; usage.Files != 1 {
+		t.Errorf("alice usage = %+v, want only her upload", usage)
+	}
+	if usage := ctrl.repo.Quota("bob").Usage; usage.Files != 2 {
+		t.Errorf("bob usage = %+v, want the watermark and the variant", usage)
+	}
+
+	// Владелец, исчерпавший свое место, не может создать вариант
+	if _, err := ctrl.CreateWatermarkVariant(alice, &model.WatermarkVariantRequest{FileID: uploaded.FileID, Watermark: &model.WatermarkPolicy{WatermarkFileID: mark.FileID, Position: "top-left"}}); !errors.Is(err, repository.ErrQuotaExceeded) {
+		t.Errorf("CreateWatermarkVariant over quota = %v, want ErrQuotaExceeded", err)
+	}
+}
@@ -0,0 +1,32 @@
+// convert.go - преобразование изображений в другой формат при выдаче
+package file
+
+import (
+	"file_server/internal/imaging"
+	"path/filepath"
+	"strings"
+)
+
+// convertData перекодирует изображение в формат format
+// Если изображение уже в нужном формате, возвращаются исходные байты без перекодирования
+func convertData(data []byte, format string) ([]byte, error) {
+	format, err := imaging.NormalizeFormat(format)
+	if err != nil {
+		return nil, err
+	}
+
+	img, sourceFormat, err := imaging.Decode(data)
+	if err != nil {
+		return nil, err
+	}
+	if sourceFormat == format {
+		return data, nil
+	}
+
+	return imaging.Encode(img, format)
+}
+
+// replaceExt заменяет расширение имени файла на формат: scan.tif -> scan.png
+func replaceExt(filename, format string) string {
+	return strings.TrimSuffix(filename, filepath.Ext(filename)) + "." + format
+}
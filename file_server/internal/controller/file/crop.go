@@ -0,0 +1,103 @@
+// crop.go - обрезка изображений под заданные пропорции
+package file
+
+import (
+	"context"
+	"file_server/internal/imaging"
+	"file_server/pkg/model"
+	"image"
+)
+
+// CropImage обрезает изображение под заданные пропорции и при необходимости масштабирует результат
+// Возвращает выбранную область, чтобы клиент мог повторно использовать ее
+func (c *Controller) CropImage(ctx context.Context, req *model.CropRequest) (*model.CropResponse, error) {
+	// Проверка контекста на отмену операции
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+	}
+
+	// Проверка параметров до загрузки файла
+	format, err := imaging.NormalizeFormat(req.Format)
+	if err != nil {
+		return nil, err
+	}
+	mode := req.Mode
+	if mode == "" {
+		mode = imaging.CropSmart
+	}
+	if mode == imaging.CropFit {
+		return nil, imaging.ErrInvalidCropMode
+	}
+	if req.Width > imaging.MaxOutputSide || req.Height > imaging.MaxOutputSide {
+		return nil, imaging.ErrOutputTooLarge
+	}
+
+	// Пропорции: явно заданные, иначе по размеру результата, иначе квадрат
+	aspectW, aspectH := req.AspectWidth, req.AspectHeight
+	if aspectW == 0 || aspectH == 0 {
+		aspectW, aspectH = 1, 1
+		if req.Width > 0 && req.Height > 0 {
+			aspectW, aspectH = req.Width, req.Height
+		}
+	}
+
+	img, err := c.decodeFile(req.FileID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Выбор области обрезки
+	rect, err := imaging.CropRect(img, aspectW, aspectH, mode)
+	if err != nil {
+		return nil, err
+	}
+
+	// Размер результата: недостающая сторона вычисляется по пропорциям области
+	width, height := req.Width, req.Height
+	switch {
+	case width == 0 && height == 0:
+		width, height = rect.Dx(), rect.Dy()
+	case height == 0:
+		height = max(1, width*rect.Dy()/rect.Dx())
+	case width == 0:
+		width = max(1, height*rect.Dx()/rect.Dy())
+	}
+	if width > imaging.MaxOutputSide || height > imaging.MaxOutputSide {
+		return nil, imaging.ErrOutputTooLarge
+	}
+
+	// Обрезка и масштабирование
+	var result image.Image = imaging.Crop(img, rect)
+	if width != rect.Dx() || height != rect.Dy() {
+		result = imaging.Resize(result, width, height)
+	}
+
+	// Водяной знак, обязательный для клиента, накладывается на итоговое изображение
+	watermark, err := c.outputWatermark(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := imaging.Encode(watermark(result), format)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.CropResponse{
+		Data:   data,
+		Format: format,
+		Crop:   toCropRect(img.Bounds(), rect),
+	}, nil
+}
+
+// toCropRect переводит область изображения в координаты относительно его левого верхнего угла
+func toCropRect(bounds, rect image.Rectangle) model.CropRect {
+	return model.CropRect{
+		X:      rect.Min.X - bounds.Min.X,
+		Y:      rect.Min.Y - bounds.Min.Y,
+		Width:  rect.Dx(),
+		Height: rect.Dy(),
+	}
+}
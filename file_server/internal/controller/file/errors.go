@@ -0,0 +1,9 @@
+package file
+
+import "errors"
+
+var (
+	ErrWatermarkRequired = errors.New("ONLY WATERMARKED IMAGES ARE AVAILABLE FOR THIS CREDENTIAL")
+	ErrInvalidExpiry     = errors.New("EXPIRY TIME IS IN THE PAST")
+	ErrAdminRequired     = errors.New("ADMIN API KEY REQUIRED")
+)
@@ -0,0 +1,53 @@
+// expiry.go - изменение срока хранения файлов
+// Файлы с истекшим сроком хранения удаляются фоновой очисткой репозитория
+package file
+
+import (
+	"context"
+	"file_server/internal/repository"
+	"file_server/pkg/model"
+	"fmt"
+	"time"
+)
+
+// SetExpiry задает срок хранения файла или его прежней версии (nil - бессрочно)
+// Изменить срок хранения может только владелец файла. Возвращает обновленные метаданные файла
+func (c *Controller) SetExpiry(ctx context.Context, fileID string, expiresAt *time.Time) (*model.FileInfo, error) {
+	// Проверка контекста на отмену операции
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err() // Возвращаем ошибку отмены контекста
+	default:
+	}
+
+	// Срок хранения не может быть в прошлом (для удаления файла срок не используется)
+	if err := validateExpiry(expiresAt); err != nil {
+		return nil, err
+	}
+
+	// Проверка владельца (истекший срок удаляет файл вместе с версиями, поэтому чужой срок не меняется)
+	info, err := c.repo.GetFileInfo(fileID)
+	if err != nil {
+		return nil, err
+	}
+	if info.Owner != owner(ctx) {
+		return nil, repository.ErrNotFileOwner
+	}
+
+	// Изменение срока хранения в метаданных файла
+	if err := c.repo.UpdateFileInfo(fileID, func(info *model.FileInfo) {
+		info.ExpiresAt = expiresAt
+	}); err != nil {
+		return nil, fmt.Errorf("FAILED TO UPDATE FILE INFO: %w", err)
+	}
+
+	return c.repo.GetFileInfo(fileID)
+}
+
+// validateExpiry проверяет, что срок хранения не истек к моменту запроса
+func validateExpiry(expiresAt *time.Time) error {
+	if expiresAt != nil && !expiresAt.After(time.Now()) {
+		return ErrInvalidExpiry
+	}
+	return nil
+}
@@ -0,0 +1,33 @@
+// fsck.go - проверка целостности хранилища по запросу администратора
+package file
+
+import (
+	"context"
+	"file_server/internal/auth"
+	"file_server/pkg/model"
+	"fmt"
+)
+
+// Fsck проверяет целостность хранилища и при repair = true исправляет найденные нарушения
+// Доступно только клиентам с правами администратора (см. model.Credential)
+func (c *Controller) Fsck(ctx context.Context, repair bool) (*model.FsckReport, error) {
+	// Проверка контекста на отмену операции
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err() // Возвращаем ошибку отмены контекста
+	default:
+	}
+
+	// Проверка прав администратора
+	if cred := auth.FromContext(ctx); cred == nil || !cred.Admin {
+		return nil, ErrAdminRequired
+	}
+
+	// Делегирование проверки репозиторию
+	report, err := c.repo.Fsck(ctx, repair)
+	if err != nil {
+		return nil, fmt.Errorf("FAILED TO CHECK STORAGE: %w", err)
+	}
+
+	return report, nil
+}
@@ -0,0 +1,149 @@
+// image.go - операции контроллера над изображениями
+// Извлечение кадров анимации и построение спрайт-листов
+package file
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"file_server/internal/imaging"
+	"file_server/pkg/model"
+	"fmt"
+	"image"
+)
+
+// GetFrame возвращает кадр анимации в формате PNG
+// Для статичных изображений единственный кадр (с индексом 0) - само изображение
+func (c *Controller) GetFrame(ctx context.Context, req *model.FrameRequest) (*model.FrameResponse, error) {
+	// Проверка контекста на отмену операции
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+	}
+
+	// Загрузка файла из репозитория
+	file, err := c.repo.GetFile(req.FileID)
+	if err != nil {
+		return nil, fmt.Errorf("FAILED TO GET FILE: %w", err)
+	}
+
+	// Водяной знак, обязательный для клиента
+	watermark, err := c.outputWatermark(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Выбор кадра
+	frame, resp, err := selectFrame(file.Data, req)
+	if err != nil {
+		return nil, err
+	}
+
+	resp.Data, err = imaging.EncodePNG(watermark(frame))
+	if err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+// GetSpriteSheet преобразует анимированный GIF в спрайт-лист PNG с JSON картой кадров
+func (c *Controller) GetSpriteSheet(ctx context.Context, req *model.SpriteSheetRequest) (*model.SpriteSheetResponse, error) {
+	// Проверка контекста на отмену операции
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+	}
+
+	// Загрузка файла из репозитория
+	file, err := c.repo.GetFile(req.FileID)
+	if err != nil {
+		return nil, fmt.Errorf("FAILED TO GET FILE: %w", err)
+	}
+
+	// Спрайт-лист имеет смысл только для GIF
+	g, err := imaging.DecodeGIF(file.Data)
+	if errors.Is(err, imaging.ErrNotAnImage) {
+		return nil, imaging.ErrNotAnimated
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Водяной знак, обязательный для клиента, накладывается на каждый кадр
+	watermark, err := c.outputWatermark(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	frames := imaging.GIFFrames(g)
+	for i, frame := range frames {
+		frames[i] = imaging.ToRGBA(watermark(frame))
+	}
+
+	// Построение спрайт-листа
+	sheet, layout := imaging.SpriteSheet(g, frames, req.Columns)
+	data, err := imaging.EncodePNG(sheet)
+	if err != nil {
+		return nil, err
+	}
+
+	frameMap, err := json.Marshal(layout)
+	if err != nil {
+		return nil, fmt.Errorf("FAILED TO ENCODE FRAME MAP: %w", err)
+	}
+
+	return &model.SpriteSheetResponse{
+		Data:     data,
+		FrameMap: string(frameMap),
+	}, nil
+}
+
+// selectFrame декодирует изображение и выбирает запрошенный кадр
+// Возвращает кадр и заполненный ответ (без данных изображения)
+func selectFrame(data []byte, req *model.FrameRequest) (image.Image, *model.FrameResponse, error) {
+	// Декодирование анимации
+	g, err := imaging.DecodeGIF(data)
+	if errors.Is(err, imaging.ErrNotAnImage) {
+		return stillFrame(data, req)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Сборка полных кадров и выбор нужного
+	frames := imaging.GIFFrames(g)
+	index := req.Index
+	if req.Poster {
+		index = imaging.PosterIndex(frames)
+	}
+	if index < 0 || index >= len(frames) {
+		return nil, nil, imaging.ErrFrameOutOfRange
+	}
+
+	info := imaging.AnalyzeGIF(g)
+	return frames[index], &model.FrameResponse{
+		Index:      index,
+		FrameCount: info.FrameCount,
+		DelayMs:    info.DelaysMs[index],
+	}, nil
+}
+
+// stillFrame возвращает статичное изображение как единственный кадр
+func stillFrame(data []byte, req *model.FrameRequest) (image.Image, *model.FrameResponse, error) {
+	img, _, err := imaging.Decode(data)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if !req.Poster && req.Index != 0 {
+		return nil, nil, imaging.ErrFrameOutOfRange
+	}
+
+	return img, &model.FrameResponse{
+		Index:      0,
+		FrameCount: 1,
+	}, nil
+}
@@ -0,0 +1,134 @@
+// optimize.go - оптимизация размера изображений
+// Оптимизированная копия сохраняется как производный вариант, исходный файл остается доступным по своему ID
+package file
+
+import (
+	"context"
+	"file_server/internal/imaging"
+	"file_server/pkg/model"
+	"fmt"
+)
+
+// VariantOptimized - тип производного варианта с оптимизированным размером
+const VariantOptimized = "optimized"
+
+// Optimize перекодирует изображение для уменьшения размера и сохраняет результат как вариант
+// Если вариант уже существует, возвращает его; если уменьшить размер не удалось, вариант не создается
+// Вариант учитывается в занятом месте клиента, запросившего оптимизацию
+func (c *Controller) Optimize(ctx context.Context, req *model.OptimizeRequest) (*model.OptimizeResponse, error) {
+	return c.optimize(ctx, req, owner(ctx))
+}
+
+// optimize оптимизирует файл и сохраняет вариант от имени owner
+func (c *Controller) optimize(ctx context.Context, req *model.OptimizeRequest, owner string) (*model.OptimizeResponse, error) {
+	// Проверка контекста на отмену операции
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+	}
+
+	// Проверка параметров до загрузки файла
+	opts, err := imaging.OptimizeOptions{Quality: req.Quality, MinSSIM: req.MinSSIM}.Normalize()
+	if err != nil {
+		return nil, err
+	}
+
+	// Загрузка исходного файла
+	file, err := c.repo.GetFile(req.FileID)
+	if err != nil {
+		return nil, fmt.Errorf("FAILED TO GET FILE: %w", err)
+	}
+
+	resp := &model.OptimizeResponse{
+		Format:        file.Info.Format,
+		OriginalSize:  file.Info.Size,
+		OptimizedSize: file.Info.Size,
+	}
+
+	// Повторная оптимизация не выполняется
+	if variantID := findVariant(&file.Info, VariantOptimized); variantID != "" {
+		if variant, err := c.repo.GetFileInfo(variantID); err == nil {
+			resp.VariantID = variantID
+			resp.OptimizedSize = variant.Size
+			resp.BytesSaved = file.Info.Size - variant.Size
+			return resp, nil
+		}
+	}
+
+	// Перекодирование (nil - результат не меньше исходного или хуже порога качества)
+	data, format, err := imaging.Optimize(file.Data, opts)
+	if err != nil {
+		return nil, err
+	}
+	resp.Format = format
+	if data == nil {
+		return resp, nil
+	}
+
+	// Сохранение варианта (владелец - клиент, запросивший вариант) и связь с исходным файлом
+	variantID, err := c.saveFile(variantFilename(file.Info.Filename, "optimized", ""), data, owner, nil)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.linkVariant(file.Info.ID, variantID, VariantOptimized, nil); err != nil {
+		return nil, err
+	}
+
+	resp.VariantID = variantID
+	resp.OptimizedSize = int64(len(data))
+	resp.BytesSaved = resp.OriginalSize - resp.OptimizedSize
+	return resp, nil
+}
+
+// OptimizeAll оптимизирует все исходные PNG и JPEG без оптимизированного варианта
+// Используется фоновой политикой оптимизации; ошибки отдельных файлов пропускаются
+// Варианты учитываются в занятом месте владельцев исходных файлов
+// Возвращает количество созданных вариантов и суммарно сэкономленные байты
+func (c *Controller) OptimizeAll(ctx context.Context, req *model.OptimizeRequest) (int, int64, error) {
+	files, err := c.repo.ListFiles()
+	if err != nil {
+		return 0, 0, fmt.Errorf("FAILED TO FIND FILES: %w", err)
+	}
+
+	optimized, saved := 0, int64(0)
+	for _, info := range files {
+		// Проверка контекста на отмену операции
+		select {
+		case <-ctx.Done():
+			return optimized, saved, ctx.Err()
+		default:
+		}
+
+		// Производные варианты и уже оптимизированные файлы пропускаются
+		if info.VariantOf != "" || findVariant(&info, VariantOptimized) != "" {
+			continue
+		}
+		if info.Format != "png" && info.Format != "jpeg" {
+			continue
+		}
+
+		resp, err := c.optimize(ctx, &model.OptimizeRequest{
+			FileID:  info.ID,
+			Quality: req.Quality,
+			MinSSIM: req.MinSSIM,
+		}, info.Owner)
+		if err != nil || resp.VariantID == "" {
+			continue
+		}
+		optimized++
+		saved += resp.BytesSaved
+	}
+
+	return optimized, saved, nil
+}
+
+// findVariant возвращает ID варианта файла указанного типа (пусто, если варианта нет)
+func findVariant(info *model.FileInfo, kind string) string {
+	for _, v := range info.Variants {
+		if v.Kind == kind {
+			return v.FileID
+		}
+	}
+	return ""
+}
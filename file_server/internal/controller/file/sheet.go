@@ -0,0 +1,108 @@
+// sheet.go - построение контактного листа из нескольких файлов
+package file
+
+import (
+	"context"
+	"file_server/internal/imaging"
+	"file_server/pkg/model"
+	"fmt"
+	"image/color"
+	"math"
+)
+
+// ContactSheet строит контактный лист из изображений с указанными ID
+// Изображения загружаются и декодируются по одному, чтобы ограничить потребление памяти
+func (c *Controller) ContactSheet(ctx context.Context, req *model.ContactSheetRequest) (*model.ContactSheetResponse, error) {
+	// Проверка формата результата до тяжелой работы
+	format, err := imaging.NormalizeFormat(req.Format)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := imaging.CheckCropMode(req.Crop); err != nil {
+		return nil, err
+	}
+
+	// Цвет фона (по умолчанию белый)
+	background := color.RGBA{R: 255, G: 255, B: 255, A: 255}
+	if req.Background != "" {
+		if background, err = imaging.ParseHexColor(req.Background); err != nil {
+			return nil, err
+		}
+	}
+
+	// Параметры сетки со значениями по умолчанию
+	layout := imaging.SheetLayout{
+		Columns:    req.Columns,
+		CellWidth:  req.CellWidth,
+		CellHeight: req.CellHeight,
+		Padding:    req.Padding,
+		Background: background,
+		Captions:   req.Captions,
+	}
+	if layout.Columns == 0 {
+		layout.Columns = int(math.Ceil(math.Sqrt(float64(len(req.FileIDs)))))
+	}
+	if layout.CellWidth == 0 {
+		layout.CellWidth = imaging.DefaultSheetCell
+	}
+	if layout.CellHeight == 0 {
+		layout.CellHeight = imaging.DefaultSheetCell
+	}
+
+	sheet, err := imaging.NewContactSheet(len(req.FileIDs), layout)
+	if err != nil {
+		return nil, err
+	}
+
+	// Водяной знак, обязательный для клиента, накладывается на каждое изображение
+	watermark, err := c.outputWatermark(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Размещение изображений по ячейкам
+	crops := make([]model.CropRect, 0, len(req.FileIDs))
+	for i, fileID := range req.FileIDs {
+		// Проверка контекста на отмену операции
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		default:
+		}
+
+		file, err := c.repo.GetFile(fileID)
+		if err != nil {
+			return nil, fmt.Errorf("FAILED TO GET FILE %s: %w", fileID, err)
+		}
+
+		img, _, err := imaging.Decode(file.Data)
+		if err != nil {
+			return nil, fmt.Errorf("FAILED TO DECODE FILE %s: %w", fileID, err)
+		}
+
+		// Обрезка под пропорции ячейки (в режиме fit область - все изображение)
+		// Водяной знак накладывается после обрезки, чтобы он не оказался за пределами области
+		rect, err := imaging.CropRect(img, layout.CellWidth, layout.CellHeight, req.Crop)
+		if err != nil {
+			return nil, err
+		}
+		crops = append(crops, toCropRect(img.Bounds(), rect))
+		if rect != img.Bounds() {
+			img = imaging.Crop(img, rect)
+		}
+
+		sheet.Draw(i, watermark(img), file.Info.Filename)
+	}
+
+	data, err := imaging.Encode(sheet.Image(), format)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.ContactSheetResponse{
+		Data:   data,
+		Format: format,
+		Crops:  crops,
+	}, nil
+}
@@ -0,0 +1,88 @@
+// versions.go - замена содержимого файлов и работа с их версиями
+// Файл сохраняет ID при замене, прежние версии можно получить, просмотреть и восстановить (см. repository/file/versions.go)
+package file
+
+import (
+	"context"
+	"file_server/internal/repository"
+	"file_server/pkg/model"
+	"fmt"
+	"time"
+)
+
+// ListVersions возвращает метаданные всех версий файла, начиная с текущей
+func (c *Controller) ListVersions(ctx context.Context, fileID string) ([]model.FileInfo, error) {
+	// Проверка контекста на отмену операции
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err() // Возвращаем ошибку отмены контекста
+	default:
+	}
+
+	// Делегирование получения истории файла репозиторию
+	return c.repo.ListVersions(fileID)
+}
+
+// RestoreVersion делает содержимое прежней версии файла новой текущей версией
+// История не переписывается: восстановленное содержимое получает следующий номер версии
+// Восстановление текущей версии ничего не меняет
+func (c *Controller) RestoreVersion(ctx context.Context, fileID string, version int) (*model.UploadResponse, error) {
+	// Проверка контекста на отмену операции
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err() // Возвращаем ошибку отмены контекста
+	default:
+	}
+
+	// Поиск версии
+	if version <= 0 {
+		return nil, repository.ErrVersionNotFound
+	}
+	info, err := c.repo.FileVersion(fileID, version)
+	if err != nil {
+		return nil, err
+	}
+	if info.VersionOf == "" {
+		return &model.UploadResponse{FileID: info.ID, Version: info.CurrentVersion()}, nil
+	}
+
+	// Содержимое версии сохраняется заново как новая версия (с тем же именем файла)
+	file, err := c.repo.GetFile(info.ID)
+	if err != nil {
+		return nil, fmt.Errorf("FAILED TO GET FILE: %w", err)
+	}
+	return c.replaceFile(fileID, file.Info.Filename, file.Data, owner(ctx), nil)
+}
+
+// replaceFile сохраняет содержимое новой версией файла fileID и задает срок хранения (nil - срок не меняется)
+// Заменить файл может только его владелец
+func (c *Controller) replaceFile(fileID, filename string, data []byte, owner string, expiresAt *time.Time) (*model.UploadResponse, error) {
+	// Проверка файла до сохранения содержимого
+	info, err := c.repo.GetFileInfo(fileID)
+	if err != nil {
+		return nil, err
+	}
+	if info.VersionOf != "" {
+		return nil, repository.ErrFileIsVersion
+	}
+	if info.Owner != owner {
+		return nil, repository.ErrNotFileOwner
+	}
+
+	// Содержимое сохраняется отдельным файлом вместе с характеристиками изображения и сроком хранения
+	// и становится новой версией (срок хранения замены переходит к файлу)
+	replacementID, err := c.saveFile(filename, data, owner, expiresAt)
+	if err != nil {
+		return nil, err
+	}
+	updated, err := c.repo.ReplaceFile(fileID, replacementID)
+	if err != nil {
+		c.repo.DeleteFile(replacementID) // Файл мог быть удален во время сохранения замены
+		return nil, fmt.Errorf("FAILED TO REPLACE FILE: %w", err)
+	}
+
+	return &model.UploadResponse{
+		FileID:  updated.ID,
+		Version: updated.Version,
+	}, nil
+}
@@ -0,0 +1,215 @@
+// watermark.go - наложение водяных знаков при выдаче файлов и создание вариантов с водяным знаком
+// Политика может быть передана в запросе или принудительно задана учетными данными клиента
+package file
+
+import (
+	"context"
+	"file_server/internal/auth"
+	"file_server/internal/imaging"
+	"file_server/pkg/model"
+	"fmt"
+	"image"
+	"path/filepath"
+	"strings"
+)
+
+// VariantWatermark - тип производного варианта с водяным знаком
+const VariantWatermark = "watermark"
+
+// CreateWatermarkVariant сохраняет копию изображения с водяным знаком как связанный производный файл
+// Вариант учитывается в занятом месте клиента, запросившего его. Возвращает ID производного файла
+func (c *Controller) CreateWatermarkVariant(ctx context.Context, req *model.WatermarkVariantRequest) (*model.UploadResponse, error) {
+	// Проверка контекста на отмену операции
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+	}
+
+	// Загрузка исходного файла
+	file, err := c.repo.GetFile(req.FileID)
+	if err != nil {
+		return nil, fmt.Errorf("FAILED TO GET FILE: %w", err)
+	}
+
+	// Наложение водяного знака
+	data, format, err := c.watermarkData(file.Data, req.Watermark, "")
+	if err != nil {
+		return nil, err
+	}
+
+	// Такой же вариант уже создан - возвращается существующий
+	if variantID := c.sameVariant(&file.Info, VariantWatermark, data); variantID != "" {
+		return &model.UploadResponse{
+			FileID: variantID,
+		}, nil
+	}
+
+	// Сохранение производного файла (владелец - клиент, запросивший вариант)
+	variantID, err := c.saveFile(variantFilename(file.Info.Filename, "watermarked", format), data, owner(ctx), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// Связь варианта с исходным файлом
+	if err := c.linkVariant(file.Info.ID, variantID, VariantWatermark, func(info *model.FileInfo) {
+		policy := *req.Watermark
+		info.Watermark = &policy
+	}); err != nil {
+		return nil, err
+	}
+
+	return &model.UploadResponse{
+		FileID: variantID,
+	}, nil
+}
+
+// watermarkPolicy возвращает политику водяного знака для запроса
+// Политика из учетных данных клиента имеет приоритет над переданной в запросе
+func watermarkPolicy(ctx context.Context, requested *model.WatermarkPolicy) (policy *model.WatermarkPolicy, enforced bool) {
+	if cred := auth.FromContext(ctx); cred != nil && cred.Watermark != nil {
+		return cred.Watermark, true
+	}
+	return requested, false
+}
+
+// alreadyWatermarked проверяет, что файл - вариант с тем же водяным знаком
+func alreadyWatermarked(info *model.FileInfo, policy *model.WatermarkPolicy) bool {
+	return info.Watermark != nil && *info.Watermark == *policy
+}
+
+// outputWatermark возвращает функцию наложения принудительного водяного знака клиента
+// Используется для изображений, которые сервер генерирует (кадры, контактные листы и т.п.)
+// Для клиентов без принудительной политики функция возвращает изображение без изменений
+func (c *Controller) outputWatermark(ctx context.Context) (func(image.Image) image.Image, error) {
+	policy, enforced := watermarkPolicy(ctx, nil)
+	if !enforced {
+		return func(img image.Image) image.Image { return img }, nil
+	}
+
+	mark, opts, err := c.loadWatermark(policy)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(img image.Image) image.Image {
+		return imaging.ApplyWatermark(img, mark, opts)
+	}, nil
+}
+
+// watermarkData накладывает водяной знак на изображение в байтах и кодирует результат в формат format
+// При пустом format JPEG остается JPEG, остальные форматы кодируются в PNG (чтобы сохранить прозрачность)
+func (c *Controller) watermarkData(data []byte, policy *model.WatermarkPolicy, format string) ([]byte, string, error) {
+	// Проверка формата результата до тяжелой работы
+	if format != "" {
+		var err error
+		if format, err = imaging.NormalizeFormat(format); err != nil {
+			return nil, "", err
+		}
+	}
+
+	img, sourceFormat, err := imaging.Decode(data)
+	if err != nil {
+		return nil, "", err
+	}
+
+	mark, opts, err := c.loadWatermark(policy)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if format == "" {
+		format = "png"
+		if sourceFormat == "jpeg" {
+			format = "jpeg"
+		}
+	}
+
+	result, err := imaging.Encode(imaging.ApplyWatermark(img, mark, opts), format)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return result, format, nil
+}
+
+// loadWatermark загружает изображение водяного знака и проверяет параметры политики
+func (c *Controller) loadWatermark(policy *model.WatermarkPolicy) (image.Image, imaging.WatermarkOptions, error) {
+	if policy == nil || policy.WatermarkFileID == "" {
+		return nil, imaging.WatermarkOptions{}, imaging.ErrInvalidWatermark
+	}
+
+	opts, err := imaging.WatermarkOptions{
+		Position: policy.Position,
+		Opacity:  policy.Opacity,
+		Scale:    policy.Scale,
+		Tile:     policy.Tile,
+	}.Normalize()
+	if err != nil {
+		return nil, opts, err
+	}
+
+	file, err := c.repo.GetFile(policy.WatermarkFileID)
+	if err != nil {
+		return nil, opts, fmt.Errorf("FAILED TO GET WATERMARK FILE: %w", err)
+	}
+
+	mark, _, err := imaging.Decode(file.Data)
+	if err != nil {
+		return nil, opts, fmt.Errorf("FAILED TO DECODE WATERMARK FILE: %w", err)
+	}
+
+	return mark, opts, nil
+}
+
+// linkVariant связывает производный файл с исходным
+// update дополнительно изменяет метаданные производного файла
+func (c *Controller) linkVariant(sourceID, variantID, kind string, update func(info *model.FileInfo)) error {
+	// Производный файл ссылается на исходный
+	if err := c.repo.UpdateFileInfo(variantID, func(info *model.FileInfo) {
+		info.VariantOf = sourceID
+		if update != nil {
+			update(info)
+		}
+	}); err != nil {
+		return fmt.Errorf("FAILED TO UPDATE VARIANT INFO: %w", err)
+	}
+
+	// Исходный файл хранит список своих вариантов (без дубликатов)
+	if err := c.repo.UpdateFileInfo(sourceID, func(info *model.FileInfo) {
+		for _, v := range info.Variants {
+			if v.FileID == variantID && v.Kind == kind {
+				return
+			}
+		}
+		info.Variants = append(append([]model.Variant(nil), info.Variants...), model.Variant{Kind: kind, FileID: variantID})
+	}); err != nil {
+		return fmt.Errorf("FAILED TO UPDATE FILE INFO: %w", err)
+	}
+
+	return nil
+}
+
+// sameVariant возвращает ID варианта вида kind с содержимым data (пусто, если такого варианта нет)
+func (c *Controller) sameVariant(info *model.FileInfo, kind string, data []byte) string {
+	blobID := model.NewBlobID(data)
+	for _, v := range info.Variants {
+		if v.Kind != kind {
+			continue
+		}
+		if variant, err := c.repo.GetFileInfo(v.FileID); err == nil && variant.BlobID == blobID {
+			return v.FileID
+		}
+	}
+	return ""
+}
+
+// variantFilename строит имя производного файла: logo.png -> logo-watermarked.jpeg
+func variantFilename(filename, suffix, format string) string {
+	ext := filepath.Ext(filename)
+	base := strings.TrimSuffix(filename, ext)
+	if format != "" {
+		ext = "." + format
+	}
+	return base + "-" + suffix + ext
+}
@@ -2,11 +2,16 @@ package grpc
 
 import (
 	"context"
+	"errors"
 	"file_server/gen"
 	"file_server/internal/controller/file"
+	"file_server/internal/imaging"
 	"file_server/internal/repository"
+	"file_server/internal/storage"
+	"file_server/internal/svg"
 	"file_server/pkg/model"
 	"fmt"
+	"time"
 
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
@@ -40,10 +45,17 @@ func (h *Handler) UploadFile(ctx context.Context, req *gen.UploadFileRequest) (*
 		return nil, status.Error(codes.InvalidArgument, "data is required")
 	}
 
+	expiresAt, err := toExpiry(req.TtlSeconds, req.ExpiresAt)
+	if err != nil {
+		return nil, err
+	}
+
 	// Преобразование gRPC запроса в внутреннюю модель приложения
 	uploadReq := &model.UploadRequest{
-		Filename: req.Filename,
-		Data:     req.Data,
+		Filename:  req.Filename,
+		Data:      req.Data,
+		ExpiresAt: expiresAt,
+		Replace:   req.ReplaceFileId,
 	}
 
 	// Делегирование обработки контроллеру (бизнес-логика)
@@ -54,7 +66,8 @@ func (h *Handler) UploadFile(ctx context.Context, req *gen.UploadFileRequest) (*
 
 	// Преобразование ответа контроллера в gRPC формат
 	return &gen.UploadFileResponse{
-		FileId: resp.FileID,
+		FileId:  resp.FileID,
+		Version: int32(resp.Version),
 	}, nil
 }
 
@@ -65,10 +78,16 @@ func (h *Handler) GetFile(ctx context.Context, req *gen.GetFileRequest) (*gen.Ge
 	if req.FileId == "" {
 		return nil, status.Error(codes.InvalidArgument, "file_id is required")
 	}
+	if req.Version < 0 {
+		return nil, status.Error(codes.InvalidArgument, "version must not be negative")
+	}
 
 	// Преобразование gRPC запроса в внутреннюю модель приложения
 	getReq := &model.GetRequest{
-		FileID: req.FileId,
+		FileID:    req.FileId,
+		Watermark: toModelWatermark(req.Watermark),
+		Format:    req.Format,
+		Version:   int(req.Version),
 	}
 
 	// Делегирование обработки контроллеру (бизнес-логика)
@@ -87,22 +106,27 @@ func (h *Handler) GetFile(ctx context.Context, req *gen.GetFileRequest) (*gen.Ge
 // ListFiles обрабатывает gRPC запрос на получение списка всех файлов
 // Делегирует контроллеру и преобразует результат в gRPC формат
 func (h *Handler) ListFiles(ctx context.Context, req *gen.ListFilesRequest) (*gen.ListFilesResponse, error) {
+	// Валидация входных данных gRPC запроса
+	if req.MaxDistance < 0 {
+		return nil, status.Error(codes.InvalidArgument, "max_distance must not be negative")
+	}
+
+	// Преобразование gRPC запроса в внутреннюю модель приложения
+	listReq := &model.ListRequest{
+		Color:       req.Color,
+		MaxDistance: req.MaxDistance,
+	}
+
 	// Делегирование обработки контроллеру (бизнес-логика)
-	// ListFiles не требует валидации входных параметров, так как запрос пустой
-	resp, err := h.ctrl.ListFiles(ctx)
+	resp, err := h.ctrl.ListFiles(ctx, listReq)
 	if err != nil {
 		return nil, h.handleError(err) // Преобразование внутренних ошибок в gRPC статусы
 	}
 
 	// Преобразование внутренних моделей файлов в gRPC формат
 	files := make([]*gen.FileInfo, 0, len(resp.Files))
-	for _, file := range resp.Files {
-		files = append(files, &gen.FileInfo{
-			FileId:    file.ID,
-			Filename:  file.Filename,
-			CreatedAt: file.CreatedAt.Unix(), // Преобразование времени в Unix timestamp
-			UpdatedAt: file.UpdatedAt.Unix(), // Преобразование времени в Unix timestamp
-		})
+	for i := range resp.Files {
+		files = append(files, toGenFileInfo(&resp.Files[i]))
 	}
 
 	// Возврат gRPC ответа со списком файлов
@@ -111,32 +135,609 @@ func (h *Handler) ListFiles(ctx context.Context, req *gen.ListFilesRequest) (*ge
 	}, nil
 }
 
+// GetFrame обрабатывает gRPC запрос на получение кадра анимации в формате PNG
+// Валидирует входные данные и делегирует контроллеру
+func (h *Handler) GetFrame(ctx context.Context, req *gen.GetFrameRequest) (*gen.GetFrameResponse, error) {
+	// Валидация входных данных gRPC запроса
+	if req.FileId == "" {
+		return nil, status.Error(codes.InvalidArgument, "file_id is required")
+	}
+
+	// Делегирование обработки контроллеру (бизнес-логика)
+	resp, err := h.ctrl.GetFrame(ctx, &model.FrameRequest{
+		FileID: req.FileId,
+		Index:  int(req.Index),
+		Poster: req.Poster,
+	})
+	if err != nil {
+		return nil, h.handleError(err) // Преобразование внутренних ошибок в gRPC статусы
+	}
+
+	// Преобразование ответа контроллера в gRPC формат
+	return &gen.GetFrameResponse{
+		Data:       resp.Data,
+		Index:      int32(resp.Index),
+		FrameCount: int32(resp.FrameCount),
+		DelayMs:    int32(resp.DelayMs),
+	}, nil
+}
+
+// GetSpriteSheet обрабатывает gRPC запрос на преобразование GIF в спрайт-лист
+// Валидирует входные данные и делегирует контроллеру
+func (h *Handler) GetSpriteSheet(ctx context.Context, req *gen.GetSpriteSheetRequest) (*gen.GetSpriteSheetResponse, error) {
+	// Валидация входных данных gRPC запроса
+	if req.FileId == "" {
+		return nil, status.Error(codes.InvalidArgument, "file_id is required")
+	}
+	if req.Columns < 0 {
+		return nil, status.Error(codes.InvalidArgument, "columns must not be negative")
+	}
+
+	// Делегирование обработки контроллеру (бизнес-логика)
+	resp, err := h.ctrl.GetSpriteSheet(ctx, &model.SpriteSheetRequest{
+		FileID:  req.FileId,
+		Columns: int(req.Columns),
+	})
+	if err != nil {
+		return nil, h.handleError(err) // Преобразование внутренних ошибок в gRPC статусы
+	}
+
+	// Преобразование ответа контроллера в gRPC формат
+	return &gen.GetSpriteSheetResponse{
+		Data:     resp.Data,
+		FrameMap: resp.FrameMap,
+	}, nil
+}
+
+// ContactSheet обрабатывает gRPC запрос на построение контактного листа
+// Валидирует входные данные и делегирует контроллеру
+func (h *Handler) ContactSheet(ctx context.Context, req *gen.ContactSheetRequest) (*gen.ContactSheetResponse, error) {
+	// Валидация входных данных gRPC запроса
+	if len(req.FileIds) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "file_ids are required")
+	}
+	if req.Columns < 0 || req.CellWidth < 0 || req.CellHeight < 0 || req.Padding < 0 {
+		return nil, status.Error(codes.InvalidArgument, "layout values must not be negative")
+	}
+
+	// Преобразование gRPC запроса в внутреннюю модель приложения
+	sheetReq := &model.ContactSheetRequest{
+		FileIDs:    req.FileIds,
+		Columns:    int(req.Columns),
+		CellWidth:  int(req.CellWidth),
+		CellHeight: int(req.CellHeight),
+		Padding:    int(req.Padding),
+		Background: req.Background,
+		Captions:   req.Captions,
+		Format:     req.Format,
+		Crop:       req.Crop,
+	}
+
+	// Делегирование обработки контроллеру (бизнес-логика)
+	resp, err := h.ctrl.ContactSheet(ctx, sheetReq)
+	if err != nil {
+		return nil, h.handleError(err) // Преобразование внутренних ошибок в gRPC статусы
+	}
+
+	// Преобразование ответа контроллера в gRPC формат
+	crops := make([]*gen.CropRect, 0, len(resp.Crops))
+	for _, rect := range resp.Crops {
+		crops = append(crops, toGenCropRect(rect))
+	}
+
+	return &gen.ContactSheetResponse{
+		Data:   resp.Data,
+		Format: resp.Format,
+		Crops:  crops,
+	}, nil
+}
+
+// CreateWatermarkVariant обрабатывает gRPC запрос на сохранение варианта файла с водяным знаком
+// Валидирует входные данные и делегирует контроллеру
+func (h *Handler) CreateWatermarkVariant(ctx context.Context, req *gen.CreateWatermarkVariantRequest) (*gen.CreateWatermarkVariantResponse, error) {
+	// Валидация входных данных gRPC запроса
+	if req.FileId == "" {
+		return nil, status.Error(codes.InvalidArgument, "file_id is required")
+	}
+	if req.Watermark == nil || req.Watermark.WatermarkFileId == "" {
+		return nil, status.Error(codes.InvalidArgument, "watermark.watermark_file_id is required")
+	}
+
+	// Делегирование обработки контроллеру (бизнес-логика)
+	resp, err := h.ctrl.CreateWatermarkVariant(ctx, &model.WatermarkVariantRequest{
+		FileID:    req.FileId,
+		Watermark: toModelWatermark(req.Watermark),
+	})
+	if err != nil {
+		return nil, h.handleError(err) // Преобразование внутренних ошибок в gRPC статусы
+	}
+
+	// Преобразование ответа контроллера в gRPC формат
+	return &gen.CreateWatermarkVariantResponse{
+		FileId: resp.FileID,
+	}, nil
+}
+
+// CompareImages обрабатывает gRPC запрос на сравнение двух изображений
+// Валидирует входные данные и делегирует контроллеру
+func (h *Handler) CompareImages(ctx context.Context, req *gen.CompareImagesRequest) (*gen.CompareImagesResponse, error) {
+	// Валидация входных данных gRPC запроса
+	if req.FileIdA == "" || req.FileIdB == "" {
+		return nil, status.Error(codes.InvalidArgument, "file_id_a and file_id_b are required")
+	}
+	if req.Tolerance < 0 || req.Tolerance > 255 {
+		return nil, status.Error(codes.InvalidArgument, "tolerance must be in range 0-255")
+	}
+
+	// Делегирование обработки контроллеру (бизнес-логика)
+	resp, err := h.ctrl.CompareImages(ctx, &model.CompareRequest{
+		FileIDA:     req.FileIdA,
+		FileIDB:     req.FileIdB,
+		Tolerance:   int(req.Tolerance),
+		IncludeDiff: req.IncludeDiff,
+		Mismatch:    req.Mismatch,
+	})
+	if err != nil {
+		return nil, h.handleError(err) // Преобразование внутренних ошибок в gRPC статусы
+	}
+
+	// Преобразование ответа контроллера в gRPC формат
+	return &gen.CompareImagesResponse{
+		Psnr:          resp.PSNR,
+		Ssim:          resp.SSIM,
+		ChangedPixels: resp.ChangedPixels,
+		TotalPixels:   resp.TotalPixels,
+		Diff:          resp.Diff,
+	}, nil
+}
+
+// CropImage обрабатывает gRPC запрос на обрезку изображения под заданные пропорции
+// Валидирует входные данные и делегирует контроллеру
+func (h *Handler) CropImage(ctx context.Context, req *gen.CropImageRequest) (*gen.CropImageResponse, error) {
+	// Валидация входных данных gRPC запроса
+	if req.FileId == "" {
+		return nil, status.Error(codes.InvalidArgument, "file_id is required")
+	}
+	if req.AspectWidth < 0 || req.AspectHeight < 0 || req.Width < 0 || req.Height < 0 {
+		return nil, status.Error(codes.InvalidArgument, "aspect ratio and size must not be negative")
+	}
+
+	// Делегирование обработки контроллеру (бизнес-логика)
+	resp, err := h.ctrl.CropImage(ctx, &model.CropRequest{
+		FileID:       req.FileId,
+		AspectWidth:  int(req.AspectWidth),
+		AspectHeight: int(req.AspectHeight),
+		Width:        int(req.Width),
+		Height:       int(req.Height),
+		Mode:         req.Mode,
+		Format:       req.Format,
+	})
+	if err != nil {
+		return nil, h.handleError(err) // Преобразование внутренних ошибок в gRPC статусы
+	}
+
+	// Преобразование ответа контроллера в gRPC формат
+	return &gen.CropImageResponse{
+		Data:   resp.Data,
+		Format: resp.Format,
+		Crop:   toGenCropRect(resp.Crop),
+	}, nil
+}
+
+// Optimize обрабатывает gRPC запрос на оптимизацию размера изображения
+// Валидирует входные данные и делегирует контроллеру
+func (h *Handler) Optimize(ctx context.Context, req *gen.OptimizeRequest) (*gen.OptimizeResponse, error) {
+	// Валидация входных данных gRPC запроса
+	if req.FileId == "" {
+		return nil, status.Error(codes.InvalidArgument, "file_id is required")
+	}
+
+	// Делегирование обработки контроллеру (бизнес-логика)
+	resp, err := h.ctrl.Optimize(ctx, &model.OptimizeRequest{
+		FileID:  req.FileId,
+		Quality: int(req.Quality),
+		MinSSIM: req.MinSsim,
+	})
+	if err != nil {
+		return nil, h.handleError(err) // Преобразование внутренних ошибок в gRPC статусы
+	}
+
+	// Преобразование ответа контроллера в gRPC формат
+	return &gen.OptimizeResponse{
+		VariantId:     resp.VariantID,
+		Format:        resp.Format,
+		OriginalSize:  resp.OriginalSize,
+		OptimizedSize: resp.OptimizedSize,
+		BytesSaved:    resp.BytesSaved,
+	}, nil
+}
+
+// GetQuota обрабатывает gRPC запрос на получение занятого места и ограничений клиента
+// Делегирует контроллеру
+func (h *Handler) GetQuota(ctx context.Context, req *gen.GetQuotaRequest) (*gen.GetQuotaResponse, error) {
+	// Делегирование обработки контроллеру (бизнес-логика)
+	report, err := h.ctrl.GetQuota(ctx)
+	if err != nil {
+		return nil, h.handleError(err) // Преобразование внутренних ошибок в gRPC статусы
+	}
+
+	// Преобразование ответа контроллера в gRPC формат
+	return &gen.GetQuotaResponse{
+		Owner:      report.Owner,
+		Usage:      toGenUsage(report.Usage),
+		Quota:      toGenQuota(report.Quota),
+		TotalUsage: toGenUsage(report.TotalUsage),
+		TotalQuota: toGenQuota(report.TotalQuota),
+	}, nil
+}
+
+// Fsck обрабатывает gRPC запрос на проверку целостности хранилища
+// Делегирует контроллеру, который проверяет права администратора
+func (h *Handler) Fsck(ctx context.Context, req *gen.FsckRequest) (*gen.FsckResponse, error) {
+	// Делегирование обработки контроллеру (бизнес-логика)
+	report, err := h.ctrl.Fsck(ctx, req.Repair)
+	if err != nil {
+		return nil, h.handleError(err) // Преобразование внутренних ошибок в gRPC статусы
+	}
+
+	// Преобразование отчета в gRPC формат
+	issues := make([]*gen.FsckIssue, 0, len(report.Issues))
+	for _, issue := range report.Issues {
+		issues = append(issues, &gen.FsckIssue{
+			Kind:     issue.Kind,
+			BlobId:   issue.BlobID,
+			FileIds:  issue.FileIDs,
+			Detail:   issue.Detail,
+			Repaired: issue.Repaired,
+			Action:   issue.Action,
+		})
+	}
+
+	return &gen.FsckResponse{
+		StartedAt:  report.StartedAt.Unix(),
+		DurationMs: report.Duration.Milliseconds(),
+		Repair:     report.Repair,
+		Files:      int64(report.Files),
+		Blobs:      int64(report.Blobs),
+		Issues:     issues,
+	}, nil
+}
+
+// toGenUsage преобразует занятое место в gRPC формат
+func toGenUsage(usage model.Usage) *gen.Usage {
+	return &gen.Usage{
+		Files: int64(usage.Files),
+		Bytes: usage.Bytes,
+	}
+}
+
+// toGenQuota преобразует ограничение места в gRPC формат
+func toGenQuota(quota model.Quota) *gen.Quota {
+	return &gen.Quota{
+		MaxFiles: int64(quota.MaxFiles),
+		MaxBytes: quota.MaxBytes,
+	}
+}
+
+// toGenCropRect преобразует область обрезки в gRPC формат
+func toGenCropRect(rect model.CropRect) *gen.CropRect {
+	return &gen.CropRect{
+		X:      int32(rect.X),
+		Y:      int32(rect.Y),
+		Width:  int32(rect.Width),
+		Height: int32(rect.Height),
+	}
+}
+
 // handleError преобразует внутренние ошибки приложения в gRPC статусы
 // Обеспечивает единообразную обработку ошибок на уровне gRPC API
 func (h *Handler) handleError(err error) error {
-	switch err {
+	switch {
 	// Файл не найден в хранилище
-	case repository.ErrFileNotFound:
+	case errors.Is(err, repository.ErrFileNotFound):
 		return status.Error(codes.NotFound, "FILE NOT FOUND")
 
+	// Зашифрованное содержимое не прошло проверку тега аутентификации (изменено или повреждено в хранилище)
+	case errors.Is(err, storage.ErrBlobAuthFailed):
+		return status.Error(codes.DataLoss, "FILE CONTENT FAILED AUTHENTICATION, IT WAS MODIFIED OR DAMAGED IN STORAGE")
+
+	// Содержимое файла повреждено и изолировано проверкой - данные не отдаются
+	case errors.Is(err, repository.ErrFileCorrupted):
+		return status.Error(codes.DataLoss, "FILE CONTENT IS CORRUPTED, UPLOAD IT AGAIN TO RESTORE")
+
+	// Версия файла не найдена (не существовала или удалена по ограничению количества версий)
+	case errors.Is(err, repository.ErrVersionNotFound):
+		return status.Error(codes.NotFound, "FILE VERSION NOT FOUND")
+
+	// Прежнюю версию нельзя заменить или вести ее собственную историю
+	case errors.Is(err, repository.ErrFileIsVersion):
+		return status.Error(codes.FailedPrecondition, "FILE IS A PREVIOUS VERSION, USE THE ID OF THE FILE IT BELONGS TO")
+
+	// Заменить файл может только его владелец
+	case errors.Is(err, repository.ErrNotFileOwner):
+		return status.Error(codes.PermissionDenied, "FILE BELONGS TO ANOTHER CLIENT")
+
 	// Некорректный формат ID файла
-	case repository.ErrInvalidFileID:
+	case errors.Is(err, repository.ErrInvalidFileID):
 		return status.Error(codes.InvalidArgument, "INVALID FILE ID")
 
 	// Файл превышает максимально допустимый размер
-	case repository.ErrFileTooLarge:
+	case errors.Is(err, repository.ErrFileTooLarge):
 		return status.Error(codes.InvalidArgument, "FILE IS TOO LARGE")
 
 	// Некорректное имя файла (пустое, содержит недопустимые символы)
-	case repository.ErrInvalidFilename:
+	case errors.Is(err, repository.ErrInvalidFilename):
 		return status.Error(codes.InvalidArgument, "INVALID FILENAME")
 
+	// Превышено ограничение места (сообщение содержит текущее значение и ограничение)
+	case errors.Is(err, repository.ErrQuotaExceeded):
+		var quotaErr *repository.QuotaError
+		if errors.As(err, &quotaErr) {
+			return status.Error(codes.ResourceExhausted, quotaErr.Error())
+		}
+		return status.Error(codes.ResourceExhausted, "QUOTA EXCEEDED")
+
+	// Мало свободного места на томе хранилища - загрузки отклоняются, чтение продолжает работать
+	case errors.Is(err, storage.ErrLowDiskSpace):
+		return status.Error(codes.ResourceExhausted, "INSUFFICIENT STORAGE SPACE, UPLOADS ARE TEMPORARILY REFUSED")
+
 	// Проблемы с доступом к хранилищу файлов
-	case repository.ErrStorageUnavailable:
+	case errors.Is(err, repository.ErrStorageUnavailable):
 		return status.Error(codes.Internal, "STORAGE UNAVAILABLE")
 
+	// Некорректный цвет в запросе
+	case errors.Is(err, imaging.ErrInvalidColor):
+		return status.Error(codes.InvalidArgument, "INVALID COLOR")
+
+	// Файл не является изображением
+	case errors.Is(err, imaging.ErrNotAnImage):
+		return status.Error(codes.FailedPrecondition, "FILE IS NOT AN IMAGE")
+
+	// Файл не является анимированным GIF
+	case errors.Is(err, imaging.ErrNotAnimated):
+		return status.Error(codes.FailedPrecondition, "FILE IS NOT AN ANIMATED GIF")
+
+	// Изображение или анимация превышают ограничения
+	case errors.Is(err, imaging.ErrImageTooLarge):
+		return status.Error(codes.InvalidArgument, "IMAGE IS TOO LARGE")
+	case errors.Is(err, imaging.ErrAnimationTooLarge):
+		return status.Error(codes.InvalidArgument, "ANIMATION HAS TOO MANY FRAMES OR PIXELS")
+
+	// Некорректные параметры контактного листа
+	case errors.Is(err, imaging.ErrTooManyInputs):
+		return status.Error(codes.InvalidArgument, fmt.Sprintf("TOO MANY INPUT IMAGES, MAX %d", imaging.MaxSheetInputs))
+	case errors.Is(err, imaging.ErrOutputTooLarge):
+		return status.Error(codes.InvalidArgument, fmt.Sprintf("OUTPUT IMAGE IS TOO LARGE, MAX %dx%d", imaging.MaxSheetWidth, imaging.MaxSheetHeight))
+	case errors.Is(err, imaging.ErrInvalidLayout):
+		return status.Error(codes.InvalidArgument, "INVALID LAYOUT")
+	case errors.Is(err, imaging.ErrInvalidCropMode):
+		return status.Error(codes.InvalidArgument, "INVALID CROP MODE")
+	case errors.Is(err, imaging.ErrUnsupportedFormat):
+		return status.Error(codes.InvalidArgument, "UNSUPPORTED OUTPUT FORMAT")
+
+	// Некорректная политика водяного знака
+	case errors.Is(err, imaging.ErrInvalidWatermark):
+		return status.Error(codes.InvalidArgument, "INVALID WATERMARK POLICY")
+
+	// Срок хранения уже истек
+	case errors.Is(err, file.ErrInvalidExpiry):
+		return status.Error(codes.InvalidArgument, "EXPIRY TIME IS IN THE PAST")
+
+	// Административный вызов без ключа администратора
+	case errors.Is(err, file.ErrAdminRequired):
+		return status.Error(codes.PermissionDenied, "ADMIN API KEY REQUIRED")
+
+	// Клиенту доступны только изображения с водяным знаком
+	case errors.Is(err, file.ErrWatermarkRequired):
+		return status.Error(codes.PermissionDenied, "ONLY WATERMARKED IMAGES ARE AVAILABLE FOR THIS CREDENTIAL")
+
+	// Размеры сравниваемых изображений не совпадают
+	case errors.Is(err, imaging.ErrDimensionMismatch):
+		return status.Error(codes.FailedPrecondition, "IMAGE DIMENSIONS DO NOT MATCH")
+	case errors.Is(err, imaging.ErrInvalidMismatchMode):
+		return status.Error(codes.InvalidArgument, "INVALID MISMATCH MODE, EXPECTED reject OR resize")
+
+	// Оптимизация невозможна или параметры некорректны
+	case errors.Is(err, imaging.ErrNotOptimizable):
+		return status.Error(codes.FailedPrecondition, "ONLY PNG AND JPEG IMAGES CAN BE OPTIMIZED")
+	case errors.Is(err, imaging.ErrInvalidOptimize):
+		return status.Error(codes.InvalidArgument, "INVALID OPTIMIZE OPTIONS, QUALITY 1-100 AND MIN SSIM 0-1 EXPECTED")
+
+	// SVG документ не прошел очистку
+	case errors.Is(err, svg.ErrInvalidSVG):
+		return status.Error(codes.InvalidArgument, "INVALID SVG DOCUMENT")
+	case errors.Is(err, svg.ErrTooManyNodes):
+		return status.Error(codes.InvalidArgument, fmt.Sprintf("SVG DOCUMENT IS TOO COMPLEX, MAX %d NODES", svg.MaxNodes))
+
+	// Запрошенный кадр не существует
+	case errors.Is(err, imaging.ErrFrameOutOfRange):
+		return status.Error(codes.OutOfRange, "FRAME INDEX OUT OF RANGE")
+
 	// Неизвестные ошибки - возвращаем как внутренние ошибки сервера
 	default:
 		return status.Error(codes.Internal, fmt.Sprintf("INTERNAL ERROR: %v", err))
 	}
 }
+
+// GetFileInfo обрабатывает gRPC запрос на получение метаданных файла
+// Возвращает метаданные без содержимого файла
+func (h *Handler) GetFileInfo(ctx context.Context, req *gen.GetFileInfoRequest) (*gen.GetFileInfoResponse, error) {
+	// Валидация входных данных gRPC запроса
+	if req.FileId == "" {
+		return nil, status.Error(codes.InvalidArgument, "file_id is required")
+	}
+
+	// Делегирование обработки контроллеру (бизнес-логика)
+	info, err := h.ctrl.GetFileInfo(ctx, req.FileId)
+	if err != nil {
+		return nil, h.handleError(err) // Преобразование внутренних ошибок в gRPC статусы
+	}
+
+	// Преобразование ответа контроллера в gRPC формат
+	return &gen.GetFileInfoResponse{
+		File: toGenFileInfo(info),
+	}, nil
+}
+
+// SetExpiry обрабатывает gRPC запрос на изменение срока хранения файла
+// Валидирует входные данные и делегирует контроллеру
+func (h *Handler) SetExpiry(ctx context.Context, req *gen.SetExpiryRequest) (*gen.SetExpiryResponse, error) {
+	// Валидация входных данных gRPC запроса
+	if req.FileId == "" {
+		return nil, status.Error(codes.InvalidArgument, "file_id is required")
+	}
+	expiresAt, err := toExpiry(req.TtlSeconds, req.ExpiresAt)
+	if err != nil {
+		return nil, err
+	}
+
+	// Делегирование обработки контроллеру (бизнес-логика)
+	info, err := h.ctrl.SetExpiry(ctx, req.FileId, expiresAt)
+	if err != nil {
+		return nil, h.handleError(err) // Преобразование внутренних ошибок в gRPC статусы
+	}
+
+	// Преобразование ответа контроллера в gRPC формат
+	return &gen.SetExpiryResponse{
+		ExpiresAt: unixOrZero(info.ExpiresAt),
+	}, nil
+}
+
+// ListVersions обрабатывает gRPC запрос на получение истории версий файла
+// Валидирует входные данные и делегирует контроллеру
+func (h *Handler) ListVersions(ctx context.Context, req *gen.ListVersionsRequest) (*gen.ListVersionsResponse, error) {
+	// Валидация входных данных gRPC запроса
+	if req.FileId == "" {
+		return nil, status.Error(codes.InvalidArgument, "file_id is required")
+	}
+
+	// Делегирование обработки контроллеру (бизнес-логика)
+	versions, err := h.ctrl.ListVersions(ctx, req.FileId)
+	if err != nil {
+		return nil, h.handleError(err) // Преобразование внутренних ошибок в gRPC статусы
+	}
+
+	// Преобразование ответа контроллера в gRPC формат
+	resp := &gen.ListVersionsResponse{
+		Versions: make([]*gen.FileInfo, 0, len(versions)),
+	}
+	for i := range versions {
+		resp.Versions = append(resp.Versions, toGenFileInfo(&versions[i]))
+	}
+	return resp, nil
+}
+
+// RestoreVersion обрабатывает gRPC запрос на восстановление прежней версии файла
+// Валидирует входные данные и делегирует контроллеру
+func (h *Handler) RestoreVersion(ctx context.Context, req *gen.RestoreVersionRequest) (*gen.RestoreVersionResponse, error) {
+	// Валидация входных данных gRPC запроса
+	if req.FileId == "" {
+		return nil, status.Error(codes.InvalidArgument, "file_id is required")
+	}
+	if req.Version <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "version must be positive")
+	}
+
+	// Делегирование обработки контроллеру (бизнес-логика)
+	resp, err := h.ctrl.RestoreVersion(ctx, req.FileId, int(req.Version))
+	if err != nil {
+		return nil, h.handleError(err) // Преобразование внутренних ошибок в gRPC статусы
+	}
+
+	// Преобразование ответа контроллера в gRPC формат
+	return &gen.RestoreVersionResponse{
+		Version: int32(resp.Version),
+	}, nil
+}
+
+// toExpiry преобразует срок хранения из запроса (через ttlSeconds секунд или в момент expiresAt) во время
+// Возвращает nil, если срок не задан (файл хранится бессрочно)
+func toExpiry(ttlSeconds, expiresAt int64) (*time.Time, error) {
+	switch {
+	case ttlSeconds < 0 || expiresAt < 0:
+		return nil, status.Error(codes.InvalidArgument, "ttl_seconds and expires_at must not be negative")
+	case ttlSeconds > 0 && expiresAt > 0:
+		return nil, status.Error(codes.InvalidArgument, "set either ttl_seconds or expires_at, not both")
+	case ttlSeconds > 0:
+		at := time.Now().Add(time.Duration(ttlSeconds) * time.Second)
+		return &at, nil
+	case expiresAt > 0:
+		at := time.Unix(expiresAt, 0)
+		return &at, nil
+	}
+	return nil, nil
+}
+
+// unixOrZero преобразует время в Unix timestamp (0 для nil)
+func unixOrZero(t *time.Time) int64 {
+	if t == nil {
+		return 0
+	}
+	return t.Unix()
+}
+
+// toGenFileInfo преобразует метаданные файла в gRPC формат
+func toGenFileInfo(file *model.FileInfo) *gen.FileInfo {
+	return &gen.FileInfo{
+		FileId:        file.ID,
+		Filename:      file.Filename,
+		CreatedAt:     file.CreatedAt.Unix(), // Преобразование времени в Unix timestamp
+		UpdatedAt:     file.UpdatedAt.Unix(), // Преобразование времени в Unix timestamp
+		Palette:       file.Palette,
+		Histogram:     file.Histogram,
+		FrameCount:    int32(file.FrameCount),
+		FrameDelaysMs: toInt32s(file.FrameDelays),
+		DurationMs:    file.DurationMs,
+		VariantOf:     file.VariantOf,
+		Variants:      toGenVariants(file.Variants),
+		Blurhash:      file.BlurHash,
+		Format:        file.Format,
+		Sanitized:     file.Sanitized,
+		Digests:       file.Digests,
+		LegacyIds:     file.LegacyIDs,
+		Owner:         file.Owner,
+		BlobId:        file.BlobID,
+		ExpiresAt:     unixOrZero(file.ExpiresAt),
+		Corrupted:     file.Corrupted,
+		Version:       int32(file.CurrentVersion()),
+		VersionOf:     file.VersionOf,
+	}
+}
+
+// toInt32s преобразует слайс int в слайс int32 для gRPC ответа
+func toInt32s(values []int) []int32 {
+	if len(values) == 0 {
+		return nil
+	}
+	result := make([]int32, len(values))
+	for i, v := range values {
+		result[i] = int32(v)
+	}
+	return result
+}
+
+// toModelWatermark преобразует политику водяного знака из gRPC формата (nil - без водяного знака)
+func toModelWatermark(policy *gen.WatermarkPolicy) *model.WatermarkPolicy {
+	if policy == nil || policy.WatermarkFileId == "" {
+		return nil
+	}
+	return &model.WatermarkPolicy{
+		WatermarkFileID: policy.WatermarkFileId,
+		Position:        policy.Position,
+		Opacity:         policy.Opacity,
+		Scale:           policy.Scale,
+		Tile:            policy.Tile,
+	}
+}
+
+// toGenVariants преобразует список производных вариантов в gRPC формат
+func toGenVariants(variants []model.Variant) []*gen.Variant {
+	if len(variants) == 0 {
+		return nil
+	}
+	result := make([]*gen.Variant, 0, len(variants))
+	for _, v := range variants {
+		result = append(result, &gen.Variant{Kind: v.Kind, FileId: v.FileID})
+	}
+	return result
+}
@@ -0,0 +1,163 @@
+// blurhash.go - построение BlurHash для размытого превью изображения
+// BlurHash - короткая строка (20-30 символов), по которой клиент рисует размытую заглушку до загрузки файла
+// Формат описан в https://github.com/woltapp/blurhash
+package imaging
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"strings"
+)
+
+const (
+	blurHashComponents = 4  // Количество компонент по длинной стороне (по короткой - на одну меньше)
+	blurHashSampleSide = 64 // Размер большей стороны уменьшенной копии для вычисления компонент
+
+	base83Chars = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz#$%*+,-.:;=?@[]^_{|}~"
+)
+
+// BlurHash вычисляет BlurHash изображения
+// Количество компонент выбирается по ориентации изображения (4x3 или 3x4)
+// Прозрачные пиксели смешиваются с белым фоном
+func BlurHash(img image.Image) string {
+	bounds := img.Bounds()
+	if bounds.Empty() {
+		return ""
+	}
+
+	// Компоненты по осям с учетом ориентации
+	nx, ny := blurHashComponents, blurHashComponents-1
+	if bounds.Dy() > bounds.Dx() {
+		nx, ny = ny, nx
+	}
+
+	// Уменьшенная копия: низкочастотные компоненты не зависят от мелких деталей
+	scale := math.Min(1, float64(blurHashSampleSide)/float64(max(bounds.Dx(), bounds.Dy())))
+	w := max(1, int(float64(bounds.Dx())*scale))
+	h := max(1, int(float64(bounds.Dy())*scale))
+	small := Resize(img, w, h)
+
+	// Перевод пикселей в линейное цветовое пространство
+	linear := make([][3]float64, w*h)
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			c := color.NRGBAModel.Convert(small.At(x, y)).(color.NRGBA)
+			a := float64(c.A) / 255
+			linear[y*w+x] = [3]float64{
+				srgbToLinear(float64(c.R)*a + 255*(1-a)),
+				srgbToLinear(float64(c.G)*a + 255*(1-a)),
+				srgbToLinear(float64(c.B)*a + 255*(1-a)),
+			}
+		}
+	}
+
+	// Коэффициенты косинусного преобразования
+	factors := make([][3]float64, 0, nx*ny)
+	for j := 0; j < ny; j++ {
+		for i := 0; i < nx; i++ {
+			factors = append(factors, blurHashFactor(linear, w, h, i, j))
+		}
+	}
+	dc, ac := factors[0], factors[1:]
+
+	var sb strings.Builder
+
+	// Количество компонент
+	sb.WriteString(encodeBase83((nx-1)+(ny-1)*9, 1))
+
+	// Максимальная амплитуда AC компонент
+	maximum := 1.0
+	if len(ac) > 0 {
+		actual := 0.0
+		for _, f := range ac {
+			actual = math.Max(actual, math.Max(math.Abs(f[0]), math.Max(math.Abs(f[1]), math.Abs(f[2]))))
+		}
+		quantised := clampInt(int(math.Floor(actual*166-0.5)), 0, 82)
+		maximum = float64(quantised+1) / 166
+		sb.WriteString(encodeBase83(quantised, 1))
+	} else {
+		sb.WriteString(encodeBase83(0, 1))
+	}
+
+	// Средний цвет и AC компоненты
+	sb.WriteString(encodeBase83(encodeDC(dc), 4))
+	for _, f := range ac {
+		sb.WriteString(encodeBase83(encodeAC(f, maximum), 2))
+	}
+
+	return sb.String()
+}
+
+// blurHashFactor вычисляет коэффициент компоненты (i, j)
+func blurHashFactor(linear [][3]float64, w, h, i, j int) [3]float64 {
+	var sum [3]float64
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			basis := math.Cos(math.Pi*float64(i)*float64(x)/float64(w)) *
+				math.Cos(math.Pi*float64(j)*float64(y)/float64(h))
+			p := linear[y*w+x]
+			sum[0] += basis * p[0]
+			sum[1] += basis * p[1]
+			sum[2] += basis * p[2]
+		}
+	}
+
+	normalisation := 2.0
+	if i == 0 && j == 0 {
+		normalisation = 1
+	}
+	scale := normalisation / float64(w*h)
+	return [3]float64{sum[0] * scale, sum[1] * scale, sum[2] * scale}
+}
+
+// encodeDC кодирует средний цвет
+func encodeDC(c [3]float64) int {
+	return linearToSRGB(c[0])<<16 + linearToSRGB(c[1])<<8 + linearToSRGB(c[2])
+}
+
+// encodeAC квантует AC компоненту относительно максимальной амплитуды
+func encodeAC(c [3]float64, maximum float64) int {
+	quant := func(v float64) int {
+		return clampInt(int(math.Floor(signPow(v/maximum, 0.5)*9+9.5)), 0, 18)
+	}
+	return quant(c[0])*19*19 + quant(c[1])*19 + quant(c[2])
+}
+
+// encodeBase83 кодирует значение в length символов base83
+func encodeBase83(value, length int) string {
+	buf := make([]byte, length)
+	for i := 1; i <= length; i++ {
+		digit := (value / int(math.Pow(83, float64(length-i)))) % 83
+		buf[i-1] = base83Chars[digit]
+	}
+	return string(buf)
+}
+
+// srgbToLinear переводит значение канала (0-255) в линейное пространство (0-1)
+func srgbToLinear(v float64) float64 {
+	v /= 255
+	if v <= 0.04045 {
+		return v / 12.92
+	}
+	return math.Pow((v+0.055)/1.055, 2.4)
+}
+
+// linearToSRGB переводит значение канала из линейного пространства (0-1) в sRGB (0-255)
+func linearToSRGB(v float64) int {
+	v = math.Max(0, math.Min(1, v))
+	if v <= 0.0031308 {
+		return int(v*12.92*255 + 0.5)
+	}
+	return int((1.055*math.Pow(v, 1/2.4)-0.055)*255 + 0.5)
+}
+
+// signPow возводит модуль в степень с сохранением знака
+func signPow(v, exp float64) float64 {
+	return math.Copysign(math.Pow(math.Abs(v), exp), v)
+}
+
+// clampInt ограничивает значение диапазоном [lo, hi]
+func clampInt(v, lo, hi int) int {
+	return max(lo, min(hi, v))
+}
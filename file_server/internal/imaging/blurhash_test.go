@@ -0,0 +1,113 @@
+package imaging
+
+import (
+	"image"
+	"image/color"
+	"strings"
+	"testing"
+)
+
+// decodeBase83 разбирает строку base83
+func decodeBase83(t *testing.T, s string) int {
+	t.Helper()
+	value := 0
+	for _, c := range s {
+		digit := strings.IndexRune(base83Chars, c)
+		if digit < 0 {
+			t.Fatalf("invalid base83 character %q", c)
+		}
+		value = value*83 + digit
+	}
+	return value
+}
+
+func TestBlurHashComponents(t *testing.T) {
+	tests := []struct {
+		name          string
+		width, height int
+		nx, ny        int
+	}{
+		{"landscape", 120, 80, 4, 3},
+		{"portrait", 80, 120, 3, 4},
+		{"square", 50, 50, 4, 3},
+		{"large", 2000, 1000, 4, 3},
+		{"single pixel", 1, 1, 4, 3},
+	}
+	for _, tt := range tests {
+		hash := BlurHash(noiseImage(tt.width, tt.height))
+
+		// Первый символ кодирует количество компонент, длина строки зависит от него
+		flag := decodeBase83(t, hash[:1])
+		nx, ny := flag%9+1, flag/9+1
+		if nx != tt.nx || ny != tt.ny {
+			t.Errorf("%s: components %dx%d, want %dx%d", tt.name, nx, ny, tt.nx, tt.ny)
+		}
+		if len(hash) != 4+2*nx*ny {
+			t.Errorf("%s: BlurHash %q has length %d, want %d", tt.name, hash, len(hash), 4+2*nx*ny)
+		}
+		for _, c := range hash {
+			if !strings.ContainsRune(base83Chars, c) {
+				t.Errorf("%s: BlurHash %q contains %q", tt.name, hash, c)
+			}
+		}
+	}
+
+	if hash := BlurHash(image.NewRGBA(image.Rectangle{})); hash != "" {
+		t.Errorf("BlurHash of an empty image = %q", hash)
+	}
+}
+
+func TestBlurHashSolidColor(t *testing.T) {
+	tests := []struct {
+		name string
+		fill color.Color
+		want color.RGBA
+	}{
+		{"opaque", color.RGBA{R: 200, G: 100, B: 50, A: 255}, color.RGBA{R: 200, G: 100, B: 50}},
+		{"transparent on white", color.Transparent, color.RGBA{R: 255, G: 255, B: 255}},
+	}
+	for _, tt := range tests {
+		img := image.NewNRGBA(image.Rect(0, 0, 40, 30))
+		for y := 0; y < 30; y++ {
+			for x := 0; x < 40; x++ {
+				img.Set(x, y, tt.fill)
+			}
+		}
+		hash := BlurHash(img)
+
+		// Средний цвет совпадает с цветом изображения, амплитуда AC компонент мала
+		dc := decodeBase83(t, hash[2:6])
+		if got := (color.RGBA{R: uint8(dc >> 16), G: uint8(dc >> 8), B: uint8(dc)}); got != tt.want {
+			t.Errorf("%s: DC color = %v, want %v", tt.name, got, tt.want)
+		}
+		if maximum := decodeBase83(t, hash[1:2]); maximum > 10 {
+			t.Errorf("%s: AC amplitude %d, want a flat image", tt.name, maximum)
+		}
+	}
+
+	// Контрастное изображение дает большую амплитуду
+	contrast := fillImage(40, 30, map[color.RGBA]int{{A: 255}: 50})
+	for y := 0; y < 30; y++ {
+		for x := 20; x < 40; x++ {
+			contrast.SetRGBA(x, y, color.RGBA{R: 255, G: 255, B: 255, A: 255})
+		}
+	}
+	if maximum := decodeBase83(t, BlurHash(contrast)[1:2]); maximum < 40 {
+		t.Errorf("AC amplitude of a contrast image = %d, want a large one", maximum)
+	}
+}
+
+func TestBlurHashReference(t *testing.T) {
+	// Значения по алгоритму из описания формата
+	tests := map[int]string{0: "0", 82: "~", 83: "10"}
+	for value, want := range tests {
+		if got := encodeBase83(value, len(want)); got != want {
+			t.Errorf("encodeBase83(%d) = %q, want %q", value, got, want)
+		}
+	}
+	for _, v := range []int{0, 1, 50, 128, 200, 255} {
+		if got := linearToSRGB(srgbToLinear(float64(v))); got != v {
+			t.Errorf("sRGB round trip of %d = %d", v, got)
+		}
+	}
+}
@@ -0,0 +1,165 @@
+// compare.go - сравнение изображений
+// Вычисляет PSNR, SSIM, количество измененных пикселей и строит изображение различий
+package imaging
+
+import (
+	"image"
+	"image/color"
+	"math"
+)
+
+// Режимы обработки изображений разного размера
+const (
+	MismatchReject = "reject" // Отклонить сравнение
+	MismatchResize = "resize" // Привести второе изображение к размеру первого
+)
+
+const (
+	ssimWindow = 8 // Размер окна SSIM
+	ssimStride = 4 // Шаг окна SSIM
+)
+
+// Константы стабилизации SSIM для 8-битных значений: (0.01*255)^2 и (0.03*255)^2
+var (
+	ssimC1 = math.Pow(0.01*255, 2)
+	ssimC2 = math.Pow(0.03*255, 2)
+)
+
+// CompareResult содержит результат сравнения двух изображений
+type CompareResult struct {
+	PSNR          float64     // Пиковое отношение сигнал/шум в дБ (+Inf для идентичных изображений)
+	SSIM          float64     // Индекс структурного сходства от -1 до 1 (1 - идентичны)
+	ChangedPixels int64       // Количество пикселей, отличающихся больше допуска
+	TotalPixels   int64       // Общее количество пикселей
+	Diff          *image.RGBA // Изображение различий (nil, если не запрошено)
+}
+
+// Compare сравнивает два изображения
+// tolerance - максимальное отличие канала (0-255), при котором пиксель считается неизменным
+// mismatch определяет поведение при разных размерах: MismatchReject или MismatchResize
+func Compare(a, b image.Image, tolerance int, mismatch string, withDiff bool) (*CompareResult, error) {
+	if mismatch == "" {
+		mismatch = MismatchReject
+	}
+	if mismatch != MismatchReject && mismatch != MismatchResize {
+		return nil, ErrInvalidMismatchMode
+	}
+
+	// Приведение к общему размеру
+	ra := ToRGBA(a)
+	rb := ToRGBA(b)
+	if ra.Bounds().Size() != rb.Bounds().Size() {
+		if mismatch == MismatchReject {
+			return nil, ErrDimensionMismatch
+		}
+		rb = Resize(rb, ra.Bounds().Dx(), ra.Bounds().Dy())
+	}
+
+	w, h := ra.Bounds().Dx(), ra.Bounds().Dy()
+	result := &CompareResult{TotalPixels: int64(w) * int64(h)}
+	if withDiff {
+		result.Diff = image.NewRGBA(ra.Bounds())
+	}
+
+	// Попиксельное сравнение: среднеквадратичная ошибка и измененные пиксели
+	var sqErr float64
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			pa, pb := ra.RGBAAt(x, y), rb.RGBAAt(x, y)
+			dr, dg, db := absDiff(pa.R, pb.R), absDiff(pa.G, pb.G), absDiff(pa.B, pb.B)
+			sqErr += float64(dr*dr + dg*dg + db*db)
+
+			changed := max(dr, dg, db) > tolerance
+			if changed {
+				result.ChangedPixels++
+			}
+
+			if withDiff {
+				result.Diff.SetRGBA(x, y, diffColor(pa, changed))
+			}
+		}
+	}
+
+	// PSNR по среднеквадратичной ошибке всех каналов
+	mse := sqErr / float64(3*result.TotalPixels)
+	if mse == 0 {
+		result.PSNR = math.Inf(1)
+	} else {
+		result.PSNR = 10 * math.Log10(255*255/mse)
+	}
+
+	result.SSIM = ssim(lumaPlane(ra), lumaPlane(rb), w, h)
+
+	return result, nil
+}
+
+// diffColor возвращает цвет пикселя изображения различий
+// Неизмененные пиксели - блеклая серая копия оригинала, измененные - красные
+func diffColor(c color.RGBA, changed bool) color.RGBA {
+	if changed {
+		return color.RGBA{R: 255, A: 255}
+	}
+	l := uint8((0.299*float64(c.R)+0.587*float64(c.G)+0.114*float64(c.B))*0.3 + 255*0.7)
+	return color.RGBA{R: l, G: l, B: l, A: 255}
+}
+
+// ssim вычисляет средний индекс структурного сходства по окнам ssimWindow x ssimWindow
+func ssim(a, b []float64, w, h int) float64 {
+	// Изображение меньше окна - одно окно на все изображение
+	win := min(ssimWindow, w, h)
+	if win == 0 {
+		return 1
+	}
+
+	var total float64
+	var windows int
+	for y := 0; y+win <= h; y += max(1, min(ssimStride, win)) {
+		for x := 0; x+win <= w; x += max(1, min(ssimStride, win)) {
+			var sumA, sumB, sumAA, sumBB, sumAB float64
+			for dy := 0; dy < win; dy++ {
+				row := (y + dy) * w
+				for dx := 0; dx < win; dx++ {
+					va, vb := a[row+x+dx], b[row+x+dx]
+					sumA += va
+					sumB += vb
+					sumAA += va * va
+					sumBB += vb * vb
+					sumAB += va * vb
+				}
+			}
+
+			n := float64(win * win)
+			meanA, meanB := sumA/n, sumB/n
+			varA := sumAA/n - meanA*meanA
+			varB := sumBB/n - meanB*meanB
+			cov := sumAB/n - meanA*meanB
+
+			total += ((2*meanA*meanB + ssimC1) * (2*cov + ssimC2)) /
+				((meanA*meanA + meanB*meanB + ssimC1) * (varA + varB + ssimC2))
+			windows++
+		}
+	}
+
+	return total / float64(windows)
+}
+
+// lumaPlane возвращает яркость каждого пикселя изображения
+func lumaPlane(img *image.RGBA) []float64 {
+	w, h := img.Bounds().Dx(), img.Bounds().Dy()
+	plane := make([]float64, w*h)
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			c := img.RGBAAt(x, y)
+			plane[y*w+x] = 0.299*float64(c.R) + 0.587*float64(c.G) + 0.114*float64(c.B)
+		}
+	}
+	return plane
+}
+
+// absDiff возвращает модуль разности значений каналов
+func absDiff(a, b uint8) int {
+	if a > b {
+		return int(a - b)
+	}
+	return int(b - a)
+}
@@ -0,0 +1,98 @@
+package imaging
+
+import (
+	"errors"
+	"image"
+	"image/color"
+	"math"
+	"testing"
+)
+
+// noiseImage создает изображение с детерминированным шумом
+func noiseImage(width, height int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	seed := uint32(1)
+	for i := 0; i < len(img.Pix); i += 4 {
+		seed = seed*1664525 + 1013904223
+		img.Pix[i], img.Pix[i+1], img.Pix[i+2], img.Pix[i+3] = uint8(seed>>24), uint8(seed>>16), uint8(seed>>8), 255
+	}
+	return img
+}
+
+func TestCompareIdentical(t *testing.T) {
+	img := noiseImage(32, 24)
+	result, err := Compare(img, Crop(img, img.Bounds()), 0, "", true)
+	if err != nil {
+		t.Fatalf("Compare: %v", err)
+	}
+	if !math.IsInf(result.PSNR, 1) || math.Abs(result.SSIM-1) > 1e-9 || result.ChangedPixels != 0 || result.TotalPixels != 32*24 {
+		t.Errorf("Compare of identical images = %+v", result)
+	}
+	if red := (color.RGBA{R: 255, A: 255}); result.Diff.RGBAAt(0, 0) == red {
+		t.Error("unchanged pixel marked in diff")
+	}
+}
+
+func TestCompareDifferent(t *testing.T) {
+	a := noiseImage(32, 24)
+	b := Crop(a, a.Bounds())
+
+	// Изменение одного пикселя на 10 по одному каналу
+	p := b.RGBAAt(5, 7)
+	p.G += 10
+	b.SetRGBA(5, 7, p)
+
+	tests := []struct {
+		tolerance int
+		changed   int64
+	}{
+		{0, 1},
+		{9, 1},
+		{10, 0},
+	}
+	for _, tt := range tests {
+		result, err := Compare(a, b, tt.tolerance, MismatchReject, true)
+		if err != nil {
+			t.Fatalf("Compare: %v", err)
+		}
+		if result.ChangedPixels != tt.changed {
+			t.Errorf("tolerance %d: ChangedPixels = %d, want %d", tt.tolerance, result.ChangedPixels, tt.changed)
+		}
+		if math.IsInf(result.PSNR, 1) || result.PSNR < 40 || result.SSIM >= 1 || result.SSIM < 0.9 {
+			t.Errorf("tolerance %d: PSNR %.2f, SSIM %.4f", tt.tolerance, result.PSNR, result.SSIM)
+		}
+		if marked := result.Diff.RGBAAt(5, 7) == (color.RGBA{R: 255, A: 255}); marked != (tt.changed == 1) {
+			t.Errorf("tolerance %d: diff marks changed pixel = %t", tt.tolerance, marked)
+		}
+	}
+
+	// Совершенно разные изображения
+	result, err := Compare(a, fillImage(32, 24, map[color.RGBA]int{{A: 255}: 100}), 0, "", false)
+	if err != nil {
+		t.Fatalf("Compare: %v", err)
+	}
+	if result.PSNR > 10 || result.SSIM > 0.1 || result.Diff != nil {
+		t.Errorf("Compare of different images = %+v", result)
+	}
+}
+
+func TestCompareMismatch(t *testing.T) {
+	a := fillImage(20, 10, map[color.RGBA]int{{B: 200, A: 255}: 100})
+	b := fillImage(40, 20, map[color.RGBA]int{{B: 200, A: 255}: 100})
+
+	if _, err := Compare(a, b, 0, MismatchReject, false); !errors.Is(err, ErrDimensionMismatch) {
+		t.Errorf("Compare with reject = %v, want ErrDimensionMismatch", err)
+	}
+	if _, err := Compare(a, b, 0, "stretch", false); !errors.Is(err, ErrInvalidMismatchMode) {
+		t.Errorf("Compare with unknown mode = %v, want ErrInvalidMismatchMode", err)
+	}
+
+	// Второе изображение приводится к размеру первого
+	result, err := Compare(a, b, 2, MismatchResize, false)
+	if err != nil {
+		t.Fatalf("Compare with resize: %v", err)
+	}
+	if result.TotalPixels != 200 || result.ChangedPixels != 0 {
+		t.Errorf("Compare with resize = %+v", result)
+	}
+}
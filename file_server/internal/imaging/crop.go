@@ -0,0 +1,181 @@
+// crop.go - выбор области обрезки под заданное соотношение сторон
+// Умная обрезка оценивает окна-кандидаты по энергии границ и выбирает самую насыщенную деталями область
+package imaging
+
+import (
+	"image"
+	"image/draw"
+	"math"
+)
+
+// Режимы обрезки
+const (
+	CropFit    = "fit"    // Без обрезки: изображение вписывается целиком
+	CropCenter = "center" // Обрезка по центру
+	CropSmart  = "smart"  // Обрезка по самой "интересной" области
+)
+
+const (
+	MaxOutputSide = 8192 // Максимальная сторона генерируемого изображения
+
+	cropAnalysisSide = 160  // Размер большей стороны уменьшенной копии для оценки окон
+	cropCenterBias   = 0.15 // Штраф за удаленность окна от центра (доля от оценки)
+)
+
+// CropRect возвращает область изображения с соотношением сторон aspectW:aspectH
+// Область максимального размера; ее положение определяется режимом (CropCenter или CropSmart)
+// Для режима CropFit возвращаются границы всего изображения
+func CropRect(img image.Image, aspectW, aspectH int, mode string) (image.Rectangle, error) {
+	bounds := img.Bounds()
+	if aspectW <= 0 || aspectH <= 0 {
+		return image.Rectangle{}, ErrInvalidLayout
+	}
+	if err := CheckCropMode(mode); err != nil {
+		return image.Rectangle{}, err
+	}
+
+	switch mode {
+	case CropCenter:
+		return centerCrop(bounds, aspectW, aspectH), nil
+	case CropSmart:
+		return smartCrop(img, aspectW, aspectH), nil
+	default:
+		return bounds, nil
+	}
+}
+
+// CheckCropMode проверяет, что режим обрезки поддерживается
+func CheckCropMode(mode string) error {
+	switch mode {
+	case "", CropFit, CropCenter, CropSmart:
+		return nil
+	default:
+		return ErrInvalidCropMode
+	}
+}
+
+// Crop возвращает копию области rect изображения с началом координат в (0, 0)
+func Crop(img image.Image, rect image.Rectangle) *image.RGBA {
+	rect = rect.Intersect(img.Bounds())
+	dst := image.NewRGBA(image.Rect(0, 0, rect.Dx(), rect.Dy()))
+	draw.Draw(dst, dst.Bounds(), img, rect.Min, draw.Src)
+	return dst
+}
+
+// cropSize возвращает размер максимальной области с заданным соотношением сторон внутри w x h
+func cropSize(w, h, aspectW, aspectH int) (int, int) {
+	// Изображение шире требуемого - обрезаются бока, иначе - верх и низ
+	if int64(w)*int64(aspectH) > int64(h)*int64(aspectW) {
+		return max(1, int(int64(h)*int64(aspectW)/int64(aspectH))), h
+	}
+	return w, max(1, int(int64(w)*int64(aspectH)/int64(aspectW)))
+}
+
+// centerCrop возвращает область с заданным соотношением сторон по центру изображения
+func centerCrop(bounds image.Rectangle, aspectW, aspectH int) image.Rectangle {
+	cw, ch := cropSize(bounds.Dx(), bounds.Dy(), aspectW, aspectH)
+	x := bounds.Min.X + (bounds.Dx()-cw)/2
+	y := bounds.Min.Y + (bounds.Dy()-ch)/2
+	return image.Rect(x, y, x+cw, y+ch)
+}
+
+// smartCrop выбирает положение области по энергии границ
+// Оценка выполняется на уменьшенной копии; окно сдвигается вдоль свободной оси,
+// сумма энергии в окне считается через префиксные суммы
+func smartCrop(img image.Image, aspectW, aspectH int) image.Rectangle {
+	bounds := img.Bounds()
+	cw, ch := cropSize(bounds.Dx(), bounds.Dy(), aspectW, aspectH)
+
+	// Обрезка не нужна - соотношение сторон уже совпадает
+	if cw == bounds.Dx() && ch == bounds.Dy() {
+		return bounds
+	}
+
+	// Уменьшенная копия для оценки
+	scale := math.Min(1, float64(cropAnalysisSide)/float64(max(bounds.Dx(), bounds.Dy())))
+	sw := max(1, int(float64(bounds.Dx())*scale))
+	sh := max(1, int(float64(bounds.Dy())*scale))
+	small := Resize(img, sw, sh)
+	energy := edgeEnergy(lumaPlane(small), sw, sh)
+
+	// Энергия по свободной оси (столбцы, если обрезаются бока; строки - если верх и низ)
+	horizontal := cw < bounds.Dx()
+	var profile []float64
+	if horizontal {
+		profile = make([]float64, sw)
+		for y := 0; y < sh; y++ {
+			for x := 0; x < sw; x++ {
+				profile[x] += energy[y*sw+x]
+			}
+		}
+	} else {
+		profile = make([]float64, sh)
+		for y := 0; y < sh; y++ {
+			for x := 0; x < sw; x++ {
+				profile[y] += energy[y*sw+x]
+			}
+		}
+	}
+
+	// Размер окна в координатах уменьшенной копии
+	full := bounds.Dx()
+	window := cw
+	if !horizontal {
+		full, window = bounds.Dy(), ch
+	}
+	smallWindow := max(1, min(len(profile), int(math.Round(float64(window)*float64(len(profile))/float64(full)))))
+
+	// Префиксные суммы для быстрого подсчета энергии окна
+	prefix := make([]float64, len(profile)+1)
+	for i, v := range profile {
+		prefix[i+1] = prefix[i] + v
+	}
+
+	// Перебор положений окна; небольшой штраф за удаленность от центра разрешает близкие оценки в пользу центра,
+	// а равные (например, на однотонном изображении) - в пользу окна ближе к центру
+	maxOffset := len(profile) - smallWindow
+	best, bestScore, bestDistance := maxOffset/2, math.Inf(-1), math.Inf(1)
+	for offset := 0; offset <= maxOffset; offset++ {
+		score := prefix[offset+smallWindow] - prefix[offset]
+		distance := 0.0
+		if maxOffset > 0 {
+			distance = math.Abs(float64(offset)-float64(maxOffset)/2) / (float64(maxOffset) / 2)
+			score *= 1 - cropCenterBias*distance
+		}
+		if score > bestScore || (score == bestScore && distance < bestDistance) {
+			best, bestScore, bestDistance = offset, score, distance
+		}
+	}
+
+	// Перевод смещения в координаты исходного изображения
+	offset := 0
+	if maxOffset > 0 {
+		offset = int(math.Round(float64(best) / float64(maxOffset) * float64(full-window)))
+	}
+
+	if horizontal {
+		x := bounds.Min.X + offset
+		return image.Rect(x, bounds.Min.Y, x+cw, bounds.Min.Y+ch)
+	}
+	y := bounds.Min.Y + offset
+	return image.Rect(bounds.Min.X, y, bounds.Min.X+cw, y+ch)
+}
+
+// edgeEnergy вычисляет энергию границ (сумму модулей градиентов яркости) для каждого пикселя
+func edgeEnergy(luma []float64, w, h int) []float64 {
+	energy := make([]float64, w*h)
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			i := y*w + x
+			var gx, gy float64
+			if x+1 < w {
+				gx = luma[i+1] - luma[i]
+			}
+			if y+1 < h {
+				gy = luma[i+w] - luma[i]
+			}
+			energy[i] = math.Abs(gx) + math.Abs(gy)
+		}
+	}
+	return energy
+}
@@ -0,0 +1,82 @@
+package imaging
+
+import (
+	"errors"
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestCropRectSizes(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 400, 300))
+	tests := []struct {
+		name             string
+		aspectW, aspectH int
+		mode             string
+		want             image.Rectangle
+	}{
+		{"fit", 1, 1, CropFit, img.Bounds()},
+		{"default mode", 1, 1, "", img.Bounds()},
+		{"center square", 1, 1, CropCenter, image.Rect(50, 0, 350, 300)},
+		{"center wide", 2, 1, CropCenter, image.Rect(0, 50, 400, 250)},
+		{"center same aspect", 4, 3, CropCenter, img.Bounds()},
+		{"smart same aspect", 4, 3, CropSmart, img.Bounds()},
+	}
+	for _, tt := range tests {
+		got, err := CropRect(img, tt.aspectW, tt.aspectH, tt.mode)
+		if err != nil || got != tt.want {
+			t.Errorf("%s: CropRect = %v, %v, want %v", tt.name, got, err, tt.want)
+		}
+	}
+
+	// Умная обрезка сохраняет размер области по центру
+	got, err := CropRect(img, 9, 16, CropSmart)
+	if err != nil || got.Dx() != 168 || got.Dy() != 300 {
+		t.Errorf("smart 9:16: CropRect = %v, %v", got, err)
+	}
+
+	if _, err := CropRect(img, 0, 1, CropCenter); !errors.Is(err, ErrInvalidLayout) {
+		t.Errorf("CropRect with zero aspect = %v, want ErrInvalidLayout", err)
+	}
+	if _, err := CropRect(img, 1, 1, "zoom"); !errors.Is(err, ErrInvalidCropMode) {
+		t.Errorf("CropRect with unknown mode = %v, want ErrInvalidCropMode", err)
+	}
+}
+
+func TestSmartCropFindsDetail(t *testing.T) {
+	// Ровный фон с детализированной областью у правого края
+	img := fillImage(400, 100, map[color.RGBA]int{{R: 128, G: 128, B: 128, A: 255}: 100})
+	detail := noiseImage(60, 100)
+	for y := 0; y < 100; y++ {
+		for x := 0; x < 60; x++ {
+			img.SetRGBA(330+x, y, detail.RGBAAt(x, y))
+		}
+	}
+
+	got, err := CropRect(img, 1, 1, CropSmart)
+	if err != nil {
+		t.Fatalf("CropRect: %v", err)
+	}
+	if got.Dx() != 100 || got.Dy() != 100 || got.Min.X < 290 {
+		t.Errorf("smart crop = %v, want the detailed area near x=330..390", got)
+	}
+
+	// Без деталей область выбирается по центру
+	plain := fillImage(400, 100, map[color.RGBA]int{{R: 128, G: 128, B: 128, A: 255}: 100})
+	if got, _ := CropRect(plain, 1, 1, CropSmart); got != image.Rect(150, 0, 250, 100) {
+		t.Errorf("smart crop of a plain image = %v, want center", got)
+	}
+}
+
+func TestCrop(t *testing.T) {
+	img := noiseImage(20, 20)
+	img.Rect = image.Rect(10, 10, 30, 30) // Начало координат не в (0, 0)
+
+	cropped := Crop(img, image.Rect(15, 12, 40, 18))
+	if cropped.Bounds() != image.Rect(0, 0, 15, 6) {
+		t.Fatalf("Crop bounds = %v, want clipped to the image", cropped.Bounds())
+	}
+	if cropped.RGBAAt(0, 0) != img.RGBAAt(15, 12) {
+		t.Errorf("Crop pixel = %v, want %v", cropped.RGBAAt(0, 0), img.RGBAAt(15, 12))
+	}
+}
@@ -0,0 +1,42 @@
+// decode.go - декодирование изображений из байтов файла
+// Регистрирует декодеры стандартной библиотеки и golang.org/x/image и защищает от слишком больших изображений
+package imaging
+
+import (
+	"bytes"
+	"image"
+	_ "image/gif"  // Регистрация декодера GIF
+	_ "image/jpeg" // Регистрация декодера JPEG
+	_ "image/png"  // Регистрация декодера PNG
+
+	_ "golang.org/x/image/bmp"  // Регистрация декодера BMP
+	_ "golang.org/x/image/tiff" // Регистрация декодера TIFF
+	_ "golang.org/x/image/webp" // Регистрация декодера WebP (только декодирование)
+)
+
+// MaxPixels - максимальное количество пикселей в декодируемом изображении
+// Защищает сервер от "decompression bomb" (маленький файл, огромная картинка)
+const MaxPixels = 50 * 1000 * 1000
+
+// Decode декодирует изображение из байтов
+// Сначала читает только заголовок, чтобы отклонить слишком большие изображения до выделения памяти
+func Decode(data []byte) (image.Image, string, error) {
+	// Чтение размеров изображения без декодирования пикселей
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", ErrNotAnImage
+	}
+
+	// Проверка ограничения на количество пикселей
+	if int64(cfg.Width)*int64(cfg.Height) > MaxPixels {
+		return nil, "", ErrImageTooLarge
+	}
+
+	// Полное декодирование изображения
+	img, format, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", ErrNotAnImage
+	}
+
+	return img, format, nil
+}
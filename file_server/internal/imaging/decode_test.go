@@ -0,0 +1,62 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+)
+
+// encodeTestPNG кодирует однотонное изображение в PNG
+func encodeTestPNG(t *testing.T, width, height int, c color.Color) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.Set(x, y, c)
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// resizePNGHeader меняет размеры в заголовке PNG (IHDR) без изменения данных пикселей
+func resizePNGHeader(data []byte, width, height uint32) []byte {
+	out := bytes.Clone(data)
+	ihdr := out[8:] // Сигнатура PNG занимает 8 байт, за ней следует чанк IHDR
+	binary.BigEndian.PutUint32(ihdr[8:], width)
+	binary.BigEndian.PutUint32(ihdr[12:], height)
+	binary.BigEndian.PutUint32(ihdr[21:], crc32.ChecksumIEEE(ihdr[4:21]))
+	return out
+}
+
+func TestDecode(t *testing.T) {
+	data := encodeTestPNG(t, 3, 2, color.White)
+
+	img, format, err := Decode(data)
+	if err != nil || format != "png" || img.Bounds().Dx() != 3 || img.Bounds().Dy() != 2 {
+		t.Fatalf("Decode = %v, %q, %v", img.Bounds(), format, err)
+	}
+
+	tests := map[string]struct {
+		data []byte
+		want error
+	}{
+		"garbage":         {[]byte("not an image"), ErrNotAnImage},
+		"empty":           {nil, ErrNotAnImage},
+		"truncated":       {data[:len(data)/2], ErrNotAnImage},
+		"too many pixels": {resizePNGHeader(data, 10000, 10000), ErrImageTooLarge},
+	}
+	for name, tt := range tests {
+		if _, _, err := Decode(tt.data); !errors.Is(err, tt.want) {
+			t.Errorf("%s: Decode = %v, want %v", name, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+// encode.go - кодирование изображений в байты
+package imaging
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"strings"
+
+	"golang.org/x/image/bmp"
+	"golang.org/x/image/tiff"
+)
+
+// DefaultJPEGQuality - качество JPEG по умолчанию
+const DefaultJPEGQuality = 90
+
+// NormalizeFormat приводит имя формата к каноническому виду ("jpg" -> "jpeg", "tif" -> "tiff")
+// Пустая строка означает формат по умолчанию (PNG)
+// Поддерживаются только форматы, в которые можно кодировать (WebP только декодируется)
+func NormalizeFormat(format string) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(format)) {
+	case "", "png":
+		return "png", nil
+	case "jpg", "jpeg":
+		return "jpeg", nil
+	case "bmp":
+		return "bmp", nil
+	case "tif", "tiff":
+		return "tiff", nil
+	default:
+		return "", ErrUnsupportedFormat
+	}
+}
+
+// Encode кодирует изображение в указанный формат
+func Encode(img image.Image, format string) ([]byte, error) {
+	format, err := NormalizeFormat(format)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	switch format {
+	case "jpeg":
+		return EncodeJPEG(img, DefaultJPEGQuality)
+	case "bmp":
+		err = bmp.Encode(&buf, img)
+	case "tiff":
+		err = tiff.Encode(&buf, img, &tiff.Options{Compression: tiff.Deflate})
+	default:
+		return EncodePNG(img)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("FAILED TO ENCODE %s: %w", strings.ToUpper(format), err)
+	}
+	return buf.Bytes(), nil
+}
+
+// EncodeJPEG кодирует изображение в JPEG с указанным качеством
+func EncodeJPEG(img image.Image, quality int) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: quality}); err != nil {
+		return nil, fmt.Errorf("FAILED TO ENCODE JPEG: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// EncodePNG кодирует изображение в PNG
+func EncodePNG(img image.Image) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, fmt.Errorf("FAILED TO ENCODE PNG: %w", err)
+	}
+	return buf.Bytes(), nil
+}
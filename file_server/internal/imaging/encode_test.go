@@ -0,0 +1,74 @@
+package imaging
+
+import (
+	"encoding/base64"
+	"errors"
+	"image/color"
+	"testing"
+)
+
+// testWebP - изображение WebP 1x1 (lossy, серый пиксель): WebP только декодируется, поэтому хранится готовым
+const testWebP = "UklGRiIAAABXRUJQVlA4IBYAAAAwAQCdASoBAAEADsD+JaQAA3AAAAAA"
+
+func TestNormalizeFormat(t *testing.T) {
+	tests := map[string]string{
+		"":       "png",
+		"PNG":    "png",
+		"jpg":    "jpeg",
+		" Jpeg ": "jpeg",
+		"bmp":    "bmp",
+		"tif":    "tiff",
+		"tiff":   "tiff",
+	}
+	for input, want := range tests {
+		if got, err := NormalizeFormat(input); err != nil || got != want {
+			t.Errorf("NormalizeFormat(%q) = %q, %v, want %q", input, got, err, want)
+		}
+	}
+	for _, input := range []string{"webp", "gif", "svg", "exe"} {
+		if _, err := NormalizeFormat(input); !errors.Is(err, ErrUnsupportedFormat) {
+			t.Errorf("NormalizeFormat(%q) = %v, want ErrUnsupportedFormat", input, err)
+		}
+	}
+}
+
+func TestEncodeFormats(t *testing.T) {
+	img := fillImage(4, 4, map[color.RGBA]int{{G: 255, A: 255}: 100})
+	for _, format := range []string{"", "png", "JPG", "bmp", "tif"} {
+		data, err := Encode(img, format)
+		if err != nil {
+			t.Fatalf("Encode(%q): %v", format, err)
+		}
+		want, _ := NormalizeFormat(format)
+		if decoded, got, err := Decode(data); err != nil || got != want || decoded.Bounds().Dx() != 4 {
+			t.Errorf("Encode(%q) decodes as %q, %v", format, got, err)
+		}
+	}
+	if _, err := Encode(img, "webp"); !errors.Is(err, ErrUnsupportedFormat) {
+		t.Errorf("Encode(webp) = %v, want ErrUnsupportedFormat", err)
+	}
+}
+
+func TestDecodeWebP(t *testing.T) {
+	data, err := base64.StdEncoding.DecodeString(testWebP)
+	if err != nil {
+		t.Fatal(err)
+	}
+	img, format, err := Decode(data)
+	if err != nil || format != "webp" || img.Bounds().Dx() != 1 || img.Bounds().Dy() != 1 {
+		t.Fatalf("Decode = %v, %q, %v", img, format, err)
+	}
+
+	// Декодированный WebP можно преобразовать в поддерживаемый формат
+	png, err := Encode(img, "png")
+	if err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+	converted, _, err := Decode(png)
+	if err != nil {
+		t.Fatalf("Decode of converted image: %v", err)
+	}
+	if got := color.GrayModel.Convert(converted.At(0, 0)).(color.Gray); got.Y < 100 || got.Y > 160 {
+		t.Errorf("converted pixel = %v, want gray", got)
+	}
+}
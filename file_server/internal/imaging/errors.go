@@ -0,0 +1,22 @@
+package imaging
+
+import "errors"
+
+var (
+	ErrNotAnImage          = errors.New("NOT AN IMAGE")
+	ErrImageTooLarge       = errors.New("IMAGE TOO LARGE")
+	ErrInvalidColor        = errors.New("INVALID COLOR")
+	ErrAnimationTooLarge   = errors.New("ANIMATION TOO LARGE")
+	ErrFrameOutOfRange     = errors.New("FRAME INDEX OUT OF RANGE")
+	ErrNotAnimated         = errors.New("IMAGE IS NOT AN ANIMATED GIF")
+	ErrTooManyInputs       = errors.New("TOO MANY INPUT IMAGES")
+	ErrInvalidLayout       = errors.New("INVALID LAYOUT")
+	ErrOutputTooLarge      = errors.New("OUTPUT IMAGE TOO LARGE")
+	ErrUnsupportedFormat   = errors.New("UNSUPPORTED OUTPUT FORMAT")
+	ErrInvalidWatermark    = errors.New("INVALID WATERMARK POLICY")
+	ErrDimensionMismatch   = errors.New("IMAGE DIMENSIONS DO NOT MATCH")
+	ErrInvalidMismatchMode = errors.New("INVALID MISMATCH MODE")
+	ErrInvalidCropMode     = errors.New("INVALID CROP MODE")
+	ErrNotOptimizable      = errors.New("ONLY PNG AND JPEG IMAGES CAN BE OPTIMIZED")
+	ErrInvalidOptimize     = errors.New("INVALID OPTIMIZE OPTIONS")
+)
@@ -0,0 +1,259 @@
+// gif.go - работа с анимированными GIF
+// Извлекает метаданные анимации, собирает полные кадры с учетом disposal и строит спрайт-листы
+package imaging
+
+import (
+	"bytes"
+	"image"
+	"image/draw"
+	"image/gif"
+)
+
+const (
+	MaxGIFFrames      = 1000             // Максимальное количество кадров в анимации
+	MaxGIFTotalPixels = 50 * 1000 * 1000 // Максимальная сумма пикселей всех кадров (ширина * высота * кадры)
+	defaultGIFDelayMs = 100              // Задержка кадра, если в файле указан 0 (так поступают браузеры)
+)
+
+// GIFInfo содержит метаданные анимации
+type GIFInfo struct {
+	Width      int   // Ширина холста анимации
+	Height     int   // Высота холста анимации
+	FrameCount int   // Количество кадров
+	DelaysMs   []int // Задержка каждого кадра в миллисекундах
+	DurationMs int64 // Общая длительность одного цикла анимации в миллисекундах
+}
+
+// SpriteFrame описывает положение кадра в спрайт-листе
+type SpriteFrame struct {
+	Index   int `json:"index"`    // Номер кадра
+	X       int `json:"x"`        // Левая граница кадра в спрайт-листе
+	Y       int `json:"y"`        // Верхняя граница кадра в спрайт-листе
+	Width   int `json:"width"`    // Ширина кадра
+	Height  int `json:"height"`   // Высота кадра
+	DelayMs int `json:"delay_ms"` // Задержка кадра в миллисекундах
+}
+
+// DecodeGIF декодирует все кадры GIF с проверкой ограничений
+// Размер холста и количество кадров проверяются до декодирования, чтобы не распаковывать лишние кадры
+func DecodeGIF(data []byte) (*gif.GIF, error) {
+	// Проверка размера холста по заголовку
+	cfg, err := gif.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrNotAnImage
+	}
+	if int64(cfg.Width)*int64(cfg.Height) > MaxPixels {
+		return nil, ErrImageTooLarge
+	}
+
+	// Проверка ограничений анимации по блокам файла (кадры не выходят за границы холста)
+	frames, err := countGIFFrames(data, MaxGIFFrames+1)
+	if err != nil {
+		return nil, err
+	}
+	if frames > MaxGIFFrames {
+		return nil, ErrAnimationTooLarge
+	}
+	if int64(cfg.Width)*int64(cfg.Height)*int64(frames) > MaxGIFTotalPixels {
+		return nil, ErrAnimationTooLarge
+	}
+
+	// Декодирование всех кадров
+	g, err := gif.DecodeAll(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrNotAnImage
+	}
+
+	return g, nil
+}
+
+// countGIFFrames считает дескрипторы изображений GIF, не распаковывая кадры
+// Подсчет прекращается на limit кадрах; данные после завершающего блока не читаются
+func countGIFFrames(data []byte, limit int) (int, error) {
+	// Заголовок (6 байт) и дескриптор логического экрана (7 байт) с глобальной палитрой
+	const headerSize = 13
+	if len(data) < headerSize {
+		return 0, ErrNotAnImage
+	}
+	pos := headerSize
+	if flags := data[10]; flags&0x80 != 0 {
+		pos += 3 << (flags&0x07 + 1)
+	}
+
+	// skipSubBlocks пропускает цепочку подблоков данных до пустого подблока
+	skipSubBlocks := func() bool {
+		for pos < len(data) {
+			size := int(data[pos])
+			pos += 1 + size
+			if size == 0 {
+				return true
+			}
+		}
+		return false
+	}
+
+	frames := 0
+	for frames < limit {
+		if pos >= len(data) {
+			return 0, ErrNotAnImage
+		}
+		switch data[pos] {
+		case 0x21: // Расширение: метка и подблоки
+			pos += 2
+			if !skipSubBlocks() {
+				return 0, ErrNotAnImage
+			}
+		case 0x2C: // Дескриптор изображения (10 байт), локальная палитра, размер кода LZW и подблоки
+			if pos+10 > len(data) {
+				return 0, ErrNotAnImage
+			}
+			flags := data[pos+9]
+			pos += 10
+			if flags&0x80 != 0 {
+				pos += 3 << (flags&0x07 + 1)
+			}
+			pos++
+			if !skipSubBlocks() {
+				return 0, ErrNotAnImage
+			}
+			frames++
+		case 0x3B: // Завершающий блок
+			return frames, nil
+		default:
+			return 0, ErrNotAnImage
+		}
+	}
+
+	return frames, nil
+}
+
+// AnalyzeGIF возвращает метаданные анимации
+func AnalyzeGIF(g *gif.GIF) GIFInfo {
+	info := GIFInfo{
+		Width:      g.Config.Width,
+		Height:     g.Config.Height,
+		FrameCount: len(g.Image),
+		DelaysMs:   make([]int, len(g.Image)),
+	}
+
+	for i := range g.Image {
+		info.DelaysMs[i] = frameDelayMs(g, i)
+		info.DurationMs += int64(info.DelaysMs[i])
+	}
+
+	return info
+}
+
+// GIFFrames собирает полные кадры анимации
+// Кадры GIF хранят только измененную область, поэтому каждый кадр накладывается на холст
+// с учетом способа очистки (disposal) предыдущего кадра
+func GIFFrames(g *gif.GIF) []*image.RGBA {
+	canvas := image.NewRGBA(image.Rect(0, 0, g.Config.Width, g.Config.Height))
+	frames := make([]*image.RGBA, 0, len(g.Image))
+
+	for i, frame := range g.Image {
+		disposal := byte(0)
+		if i < len(g.Disposal) {
+			disposal = g.Disposal[i]
+		}
+
+		// Сохранение холста для восстановления после кадра с DisposalPrevious
+		var previous *image.RGBA
+		if disposal == gif.DisposalPrevious {
+			previous = cloneRGBA(canvas)
+		}
+
+		// Наложение кадра на холст
+		draw.Draw(canvas, frame.Bounds(), frame, frame.Bounds().Min, draw.Over)
+		frames = append(frames, cloneRGBA(canvas))
+
+		// Очистка холста перед следующим кадром
+		switch disposal {
+		case gif.DisposalBackground:
+			draw.Draw(canvas, frame.Bounds(), image.Transparent, image.Point{}, draw.Src)
+		case gif.DisposalPrevious:
+			canvas = previous
+		}
+	}
+
+	return frames
+}
+
+// PosterIndex выбирает кадр-обложку для превью
+// Берется кадр с наибольшим разбросом яркости: первые кадры анимаций часто пустые или однотонные
+func PosterIndex(frames []*image.RGBA) int {
+	best, bestVariance := 0, -1.0
+	for i, frame := range frames {
+		if v := lumaVariance(frame); v > bestVariance {
+			best, bestVariance = i, v
+		}
+	}
+	return best
+}
+
+// SpriteSheet раскладывает кадры в сетку с заданным количеством столбцов
+// Возвращает изображение спрайт-листа и карту расположения кадров
+func SpriteSheet(g *gif.GIF, frames []*image.RGBA, columns int) (*image.RGBA, []SpriteFrame) {
+	if columns <= 0 || columns > len(frames) {
+		columns = len(frames)
+	}
+	rows := (len(frames) + columns - 1) / columns
+
+	w, h := g.Config.Width, g.Config.Height
+	sheet := image.NewRGBA(image.Rect(0, 0, w*columns, h*rows))
+	layout := make([]SpriteFrame, 0, len(frames))
+
+	for i, frame := range frames {
+		x, y := (i%columns)*w, (i/columns)*h
+		draw.Draw(sheet, image.Rect(x, y, x+w, y+h), frame, image.Point{}, draw.Src)
+		layout = append(layout, SpriteFrame{
+			Index:   i,
+			X:       x,
+			Y:       y,
+			Width:   w,
+			Height:  h,
+			DelayMs: frameDelayMs(g, i),
+		})
+	}
+
+	return sheet, layout
+}
+
+// frameDelayMs возвращает задержку кадра в миллисекундах (в GIF хранится в сотых долях секунды)
+func frameDelayMs(g *gif.GIF, i int) int {
+	if i >= len(g.Delay) || g.Delay[i] <= 0 {
+		return defaultGIFDelayMs
+	}
+	return g.Delay[i] * 10
+}
+
+// cloneRGBA создает копию изображения
+func cloneRGBA(src *image.RGBA) *image.RGBA {
+	dst := image.NewRGBA(src.Bounds())
+	copy(dst.Pix, src.Pix)
+	return dst
+}
+
+// lumaVariance вычисляет дисперсию яркости по выборке пикселей
+func lumaVariance(img *image.RGBA) float64 {
+	bounds := img.Bounds()
+	stepX := max(1, bounds.Dx()/sampleGrid)
+	stepY := max(1, bounds.Dy()/sampleGrid)
+
+	var sum, sumSq, n float64
+	for y := bounds.Min.Y; y < bounds.Max.Y; y += stepY {
+		for x := bounds.Min.X; x < bounds.Max.X; x += stepX {
+			c := img.RGBAAt(x, y)
+			l := 0.299*float64(c.R) + 0.587*float64(c.G) + 0.114*float64(c.B)
+			sum += l
+			sumSq += l * l
+			n++
+		}
+	}
+
+	if n == 0 {
+		return 0
+	}
+	mean := sum / n
+	return sumSq/n - mean*mean
+}
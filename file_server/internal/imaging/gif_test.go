@@ -0,0 +1,172 @@
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/gif"
+	"testing"
+)
+
+// testPalette - палитра кадров тестовых анимаций
+var testPalette = color.Palette{color.Black, color.White, color.RGBA{R: 255, A: 255}}
+
+// encodeGIF кодирует анимацию из кадров frames (индексы цветов testPalette) на холсте width x height
+// Кадры имеют размер 1x1 и располагаются в левом верхнем углу
+func encodeGIF(t *testing.T, width, height int, frames []uint8, delays []int) []byte {
+	t.Helper()
+	g := &gif.GIF{Config: image.Config{Width: width, Height: height, ColorModel: testPalette}}
+	for i, c := range frames {
+		frame := image.NewPaletted(image.Rect(0, 0, 1, 1), testPalette)
+		frame.SetColorIndex(0, 0, c)
+		g.Image = append(g.Image, frame)
+		delay := 0
+		if i < len(delays) {
+			delay = delays[i]
+		}
+		g.Delay = append(g.Delay, delay)
+	}
+	var buf bytes.Buffer
+	if err := gif.EncodeAll(&buf, g); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestDecodeGIFLimits(t *testing.T) {
+	tests := []struct {
+		name          string
+		width, height int
+		frames        int
+		want          error
+	}{
+		{"small animation", 4, 4, 3, nil},
+		{"frame limit", 1, 1, MaxGIFFrames, nil},
+		{"too many frames", 1, 1, MaxGIFFrames + 1, ErrAnimationTooLarge},
+		{"too many pixels", 2000, 2000, MaxGIFTotalPixels/(2000*2000) + 1, ErrAnimationTooLarge},
+		{"canvas too large", 10000, 10000, 1, ErrImageTooLarge},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := encodeGIF(t, tt.width, tt.height, make([]uint8, tt.frames), nil)
+			g, err := DecodeGIF(data)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("DecodeGIF = %v, want %v", err, tt.want)
+			}
+			if err == nil && len(g.Image) != tt.frames {
+				t.Errorf("decoded %d frames, want %d", len(g.Image), tt.frames)
+			}
+		})
+	}
+}
+
+func TestDecodeGIFChecksFramesBeforeDecoding(t *testing.T) {
+	data := encodeGIF(t, 1, 1, make([]uint8, MaxGIFFrames+1), nil)
+
+	if frames, err := countGIFFrames(data, MaxGIFFrames+1); err != nil || frames != MaxGIFFrames+1 {
+		t.Fatalf("countGIFFrames = %d, %v", frames, err)
+	}
+
+	// Порча размера кода LZW последнего кадра: лимит должен сработать до распаковки кадров
+	corrupted := bytes.Clone(data)
+	descriptor := bytes.LastIndex(corrupted, []byte{0x2C, 0, 0, 0, 0, 1, 0, 1, 0})
+	if descriptor < 0 {
+		t.Fatal("image descriptor not found")
+	}
+	corrupted[descriptor+10] = 0x0C
+	if _, err := gif.DecodeAll(bytes.NewReader(corrupted)); err == nil {
+		t.Fatal("corrupted animation decodes")
+	}
+	if _, err := DecodeGIF(corrupted); !errors.Is(err, ErrAnimationTooLarge) {
+		t.Errorf("DecodeGIF of corrupted oversized animation = %v, want ErrAnimationTooLarge", err)
+	}
+}
+
+func TestCountGIFFramesRejectsMalformed(t *testing.T) {
+	data := encodeGIF(t, 2, 2, []uint8{0, 1}, nil)
+	tests := map[string][]byte{
+		"truncated header": data[:8],
+		"truncated frame":  data[:len(data)-4],
+		"no trailer":       data[:len(data)-1],
+		"unknown block":    append(bytes.Clone(data[:len(data)-1]), 0x00),
+	}
+	for name, input := range tests {
+		if _, err := countGIFFrames(input, MaxGIFFrames); !errors.Is(err, ErrNotAnImage) {
+			t.Errorf("%s: countGIFFrames = %v, want ErrNotAnImage", name, err)
+		}
+	}
+	if _, err := DecodeGIF([]byte("not a gif")); !errors.Is(err, ErrNotAnImage) {
+		t.Errorf("DecodeGIF of garbage = %v, want ErrNotAnImage", err)
+	}
+}
+
+func TestAnalyzeGIF(t *testing.T) {
+	g, err := DecodeGIF(encodeGIF(t, 3, 2, []uint8{0, 1, 2}, []int{5, 0, 20}))
+	if err != nil {
+		t.Fatalf("DecodeGIF: %v", err)
+	}
+	info := AnalyzeGIF(g)
+	if info.Width != 3 || info.Height != 2 || info.FrameCount != 3 {
+		t.Errorf("AnalyzeGIF = %+v", info)
+	}
+
+	// Нулевая задержка заменяется задержкой по умолчанию
+	wantDelays := []int{50, defaultGIFDelayMs, 200}
+	for i, want := range wantDelays {
+		if info.DelaysMs[i] != want {
+			t.Errorf("DelaysMs[%d] = %d, want %d", i, info.DelaysMs[i], want)
+		}
+	}
+	if info.DurationMs != 350 {
+		t.Errorf("DurationMs = %d, want 350", info.DurationMs)
+	}
+}
+
+func TestGIFFramesAndSpriteSheet(t *testing.T) {
+	g, err := DecodeGIF(encodeGIF(t, 2, 2, []uint8{0, 1, 2}, nil))
+	if err != nil {
+		t.Fatalf("DecodeGIF: %v", err)
+	}
+
+	// Каждый кадр - полный холст с наложенными кадрами
+	frames := GIFFrames(g)
+	if len(frames) != 3 {
+		t.Fatalf("GIFFrames = %d frames, want 3", len(frames))
+	}
+	for i, want := range []color.RGBA{{A: 255}, {R: 255, G: 255, B: 255, A: 255}, {R: 255, A: 255}} {
+		if got := frames[i].RGBAAt(0, 0); got != want {
+			t.Errorf("frame %d pixel = %v, want %v", i, got, want)
+		}
+		if frames[i].Bounds().Dx() != 2 || frames[i].Bounds().Dy() != 2 {
+			t.Errorf("frame %d bounds = %v", i, frames[i].Bounds())
+		}
+	}
+
+	// Сетка из двух столбцов: два ряда
+	sheet, layout := SpriteSheet(g, frames, 2)
+	if sheet.Bounds().Dx() != 4 || sheet.Bounds().Dy() != 4 || len(layout) != 3 {
+		t.Fatalf("SpriteSheet = %v, %d frames", sheet.Bounds(), len(layout))
+	}
+	if last := layout[2]; last.X != 0 || last.Y != 2 || last.Width != 2 || last.DelayMs != defaultGIFDelayMs {
+		t.Errorf("layout[2] = %+v", last)
+	}
+	if got := sheet.RGBAAt(2, 0); got != (color.RGBA{R: 255, G: 255, B: 255, A: 255}) {
+		t.Errorf("sheet pixel of frame 1 = %v", got)
+	}
+}
+
+func TestPosterIndex(t *testing.T) {
+	plain := image.NewRGBA(image.Rect(0, 0, 8, 8))
+	busy := image.NewRGBA(image.Rect(0, 0, 8, 8))
+	for x := 0; x < 8; x++ {
+		for y := 0; y < 8; y++ {
+			if (x+y)%2 == 0 {
+				busy.Set(x, y, color.White)
+			}
+		}
+	}
+	if got := PosterIndex([]*image.RGBA{plain, busy, plain}); got != 1 {
+		t.Errorf("PosterIndex = %d, want 1", got)
+	}
+}
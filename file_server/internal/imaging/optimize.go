@@ -0,0 +1,139 @@
+// optimize.go - оптимизация размера PNG и JPEG
+// PNG перекодируется без потерь с максимальным сжатием, JPEG - с целевым качеством при контроле SSIM
+package imaging
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+)
+
+const (
+	DefaultOptimizeQuality = 85   // Целевое качество JPEG по умолчанию
+	DefaultOptimizeMinSSIM = 0.98 // Минимальный SSIM перекодированного JPEG по умолчанию
+
+	maxPaletteColors = 256 // Максимальное количество цветов для перевода PNG в палитровый
+)
+
+// OptimizeOptions описывает параметры оптимизации
+type OptimizeOptions struct {
+	Quality int     // Целевое качество JPEG (1-100)
+	MinSSIM float64 // Минимальный SSIM перекодированного JPEG относительно исходного
+}
+
+// Normalize подставляет значения по умолчанию и проверяет корректность параметров
+func (o OptimizeOptions) Normalize() (OptimizeOptions, error) {
+	if o.Quality == 0 {
+		o.Quality = DefaultOptimizeQuality
+	}
+	if o.MinSSIM == 0 {
+		o.MinSSIM = DefaultOptimizeMinSSIM
+	}
+	if o.Quality < 1 || o.Quality > 100 || o.MinSSIM < 0 || o.MinSSIM > 1 {
+		return o, ErrInvalidOptimize
+	}
+	return o, nil
+}
+
+// Optimize перекодирует PNG или JPEG для уменьшения размера
+// Возвращает оптимизированные данные и формат; nil, если уменьшить размер не удалось
+// (или для JPEG - если качество упало ниже порога SSIM)
+func Optimize(data []byte, opts OptimizeOptions) ([]byte, string, error) {
+	img, format, err := Decode(data)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var optimized []byte
+	switch format {
+	case "png":
+		optimized, err = optimizePNG(img)
+	case "jpeg":
+		optimized, err = optimizeJPEG(img, opts)
+	default:
+		return nil, "", ErrNotOptimizable
+	}
+	if err != nil {
+		return nil, "", err
+	}
+
+	// Результат сохраняется только если он меньше исходного файла
+	if optimized == nil || len(optimized) >= len(data) {
+		return nil, format, nil
+	}
+	return optimized, format, nil
+}
+
+// optimizePNG перекодирует PNG с максимальным сжатием
+// Кодировщик записывает только обязательные чанки, поэтому вспомогательные (tEXt, iTXt, eXIf и т.п.) удаляются
+// Изображения не более чем из 256 цветов переводятся в палитровые без потерь
+func optimizePNG(img image.Image) ([]byte, error) {
+	if paletted := toPaletted(img); paletted != nil {
+		img = paletted
+	}
+
+	var buf bytes.Buffer
+	encoder := png.Encoder{CompressionLevel: png.BestCompression}
+	if err := encoder.Encode(&buf, img); err != nil {
+		return nil, fmt.Errorf("FAILED TO ENCODE PNG: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// optimizeJPEG перекодирует JPEG с целевым качеством
+// Возвращает nil, если SSIM результата относительно исходного изображения ниже порога
+func optimizeJPEG(img image.Image, opts OptimizeOptions) ([]byte, error) {
+	encoded, err := EncodeJPEG(img, opts.Quality)
+	if err != nil {
+		return nil, err
+	}
+
+	// Контроль качества: декодирование результата и сравнение с исходным изображением
+	decoded, _, err := Decode(encoded)
+	if err != nil {
+		return nil, err
+	}
+	result, err := Compare(img, decoded, 0, MismatchReject, false)
+	if err != nil {
+		return nil, err
+	}
+	if result.SSIM < opts.MinSSIM {
+		return nil, nil
+	}
+
+	return encoded, nil
+}
+
+// toPaletted переводит изображение в палитровое, если в нем не более 256 различных цветов
+// Возвращает nil, если цветов больше или изображение уже палитровое
+func toPaletted(img image.Image) *image.Paletted {
+	if _, ok := img.(*image.Paletted); ok {
+		return nil
+	}
+
+	bounds := img.Bounds()
+	index := make(map[color.NRGBA]uint8, maxPaletteColors)
+	palette := make(color.Palette, 0, maxPaletteColors)
+	dst := image.NewPaletted(bounds, nil)
+
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			c := color.NRGBAModel.Convert(img.At(x, y)).(color.NRGBA)
+			i, ok := index[c]
+			if !ok {
+				if len(palette) == maxPaletteColors {
+					return nil
+				}
+				i = uint8(len(palette))
+				index[c] = i
+				palette = append(palette, c)
+			}
+			dst.SetColorIndex(x, y, i)
+		}
+	}
+
+	dst.Palette = palette
+	return dst
+}
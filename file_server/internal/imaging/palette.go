@@ -0,0 +1,234 @@
+// palette.go - извлечение доминирующих цветов и гистограммы изображения
+// Палитра строится алгоритмом median-cut по выборке пикселей
+package imaging
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	PaletteSize          = 5  // Количество цветов в доминирующей палитре
+	HistogramBins        = 4  // Количество корзин гистограммы на один канал (4x4x4 = 64 корзины)
+	DefaultColorDistance = 48 // Расстояние по умолчанию для поиска по цвету (евклидово в RGB)
+
+	sampleGrid = 64 // Размер сетки выборки пикселей (не более 64x64 точек)
+)
+
+// ColorStats содержит цветовые характеристики изображения
+type ColorStats struct {
+	Palette   []string // Доминирующие цвета в формате #rrggbb, от самого частого к редкому
+	Histogram []uint32 // Грубая RGB гистограмма (HistogramBins^3 корзин) по выборке пикселей
+}
+
+// colorBox - группа пикселей для алгоритма median-cut
+type colorBox struct {
+	pixels [][3]uint8
+}
+
+// AnalyzeColors вычисляет палитру и гистограмму изображения
+// Использует равномерную выборку пикселей, чтобы время не зависело от размера изображения
+func AnalyzeColors(img image.Image) ColorStats {
+	bounds := img.Bounds()
+
+	// Шаг выборки - не более sampleGrid точек по каждой оси
+	stepX := max(1, bounds.Dx()/sampleGrid)
+	stepY := max(1, bounds.Dy()/sampleGrid)
+
+	histogram := make([]uint32, HistogramBins*HistogramBins*HistogramBins)
+	pixels := make([][3]uint8, 0, sampleGrid*sampleGrid)
+	shift := 8 - bitsFor(HistogramBins) // Сдвиг значения канала до номера корзины
+
+	// Сбор выборки пикселей и заполнение гистограммы
+	for y := bounds.Min.Y; y < bounds.Max.Y; y += stepY {
+		for x := bounds.Min.X; x < bounds.Max.X; x += stepX {
+			c := color.NRGBAModel.Convert(img.At(x, y)).(color.NRGBA)
+
+			// Почти прозрачные пиксели не влияют на восприятие цвета
+			if c.A < 128 {
+				continue
+			}
+
+			pixels = append(pixels, [3]uint8{c.R, c.G, c.B})
+
+			// Индекс корзины гистограммы
+			bin := (int(c.R>>shift)*HistogramBins+int(c.G>>shift))*HistogramBins + int(c.B>>shift)
+			histogram[bin]++
+		}
+	}
+
+	return ColorStats{
+		Palette:   medianCut(pixels, PaletteSize),
+		Histogram: histogram,
+	}
+}
+
+// medianCut разбивает пиксели на k групп и возвращает их средние цвета
+// Группы отсортированы по количеству пикселей (самый доминирующий цвет первым)
+func medianCut(pixels [][3]uint8, k int) []string {
+	if len(pixels) == 0 {
+		return []string{}
+	}
+
+	boxes := []*colorBox{{pixels: pixels}}
+
+	// Разбиение группы с наибольшим разбросом цветов, пока не получим k групп
+	for len(boxes) < k {
+		idx, channel, spread := -1, 0, 0
+		for i, box := range boxes {
+			if len(box.pixels) < 2 {
+				continue
+			}
+			ch, s := box.widestChannel()
+			if s > spread {
+				idx, channel, spread = i, ch, s
+			}
+		}
+
+		// Все группы однородные - дальше делить нечего
+		if idx == -1 {
+			break
+		}
+
+		// Разбиение по медиане выбранного канала
+		box := boxes[idx]
+		sort.Slice(box.pixels, func(a, b int) bool {
+			return box.pixels[a][channel] < box.pixels[b][channel]
+		})
+		mid := splitPoint(box.pixels, channel)
+		boxes[idx] = &colorBox{pixels: box.pixels[:mid]}
+		boxes = append(boxes, &colorBox{pixels: box.pixels[mid:]})
+	}
+
+	// Более крупные группы - более доминирующие цвета
+	sort.SliceStable(boxes, func(a, b int) bool {
+		return len(boxes[a].pixels) > len(boxes[b].pixels)
+	})
+
+	palette := make([]string, 0, len(boxes))
+	for _, box := range boxes {
+		palette = append(palette, HexColor(box.average()))
+	}
+
+	return palette
+}
+
+// splitPoint возвращает индекс разбиения отсортированных по каналу пикселей
+// Разрез сдвигается от медианы к ближайшей границе между разными значениями канала,
+// чтобы одинаковые цвета не попадали в разные группы и не смешивались при усреднении
+func splitPoint(pixels [][3]uint8, channel int) int {
+	mid := len(pixels) / 2
+
+	// Ближайшая граница слева от медианы
+	left := mid
+	for left > 0 && pixels[left-1][channel] == pixels[mid][channel] {
+		left--
+	}
+
+	// Ближайшая граница справа от медианы
+	right := mid
+	for right < len(pixels) && pixels[right][channel] == pixels[mid][channel] {
+		right++
+	}
+
+	if left > 0 && (mid-left <= right-mid || right == len(pixels)) {
+		return left
+	}
+	return right
+}
+
+// widestChannel возвращает канал с наибольшим разбросом значений и сам разброс
+func (b *colorBox) widestChannel() (int, int) {
+	lo := [3]uint8{255, 255, 255}
+	hi := [3]uint8{}
+	for _, p := range b.pixels {
+		for ch := 0; ch < 3; ch++ {
+			lo[ch] = min(lo[ch], p[ch])
+			hi[ch] = max(hi[ch], p[ch])
+		}
+	}
+
+	channel, spread := 0, 0
+	for ch := 0; ch < 3; ch++ {
+		if s := int(hi[ch]) - int(lo[ch]); s > spread {
+			channel, spread = ch, s
+		}
+	}
+	return channel, spread
+}
+
+// average возвращает средний цвет группы
+func (b *colorBox) average() color.RGBA {
+	var sum [3]int
+	for _, p := range b.pixels {
+		for ch := 0; ch < 3; ch++ {
+			sum[ch] += int(p[ch])
+		}
+	}
+	n := len(b.pixels)
+	return color.RGBA{R: uint8(sum[0] / n), G: uint8(sum[1] / n), B: uint8(sum[2] / n), A: 255}
+}
+
+// bitsFor возвращает количество бит, необходимое для n корзин (n - степень двойки)
+func bitsFor(n int) uint {
+	bits := uint(0)
+	for (1 << bits) < n {
+		bits++
+	}
+	return bits
+}
+
+// HexColor форматирует цвет в строку вида #rrggbb
+func HexColor(c color.RGBA) string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
+
+// ParseHexColor разбирает цвет в формате #rrggbb или rrggbb (также поддерживается короткая форма #rgb)
+func ParseHexColor(s string) (color.RGBA, error) {
+	s = strings.TrimPrefix(strings.TrimSpace(s), "#")
+
+	// Развертывание короткой формы #rgb в #rrggbb
+	if len(s) == 3 {
+		s = string([]byte{s[0], s[0], s[1], s[1], s[2], s[2]})
+	}
+
+	if len(s) != 6 {
+		return color.RGBA{}, ErrInvalidColor
+	}
+
+	v, err := strconv.ParseUint(s, 16, 32)
+	if err != nil {
+		return color.RGBA{}, ErrInvalidColor
+	}
+
+	return color.RGBA{R: uint8(v >> 16), G: uint8(v >> 8), B: uint8(v), A: 255}, nil
+}
+
+// ColorDistance возвращает евклидово расстояние между цветами в пространстве RGB
+func ColorDistance(a, b color.RGBA) float64 {
+	dr := float64(a.R) - float64(b.R)
+	dg := float64(a.G) - float64(b.G)
+	db := float64(a.B) - float64(b.B)
+	return math.Sqrt(dr*dr + dg*dg + db*db)
+}
+
+// PaletteDistance возвращает расстояние от цвета до ближайшего цвета палитры
+// Второе значение false, если палитра пуста или не содержит корректных цветов
+func PaletteDistance(palette []string, target color.RGBA) (float64, bool) {
+	best, found := math.MaxFloat64, false
+	for _, hex := range palette {
+		c, err := ParseHexColor(hex)
+		if err != nil {
+			continue
+		}
+		if d := ColorDistance(c, target); d < best {
+			best, found = d, true
+		}
+	}
+	return best, found
+}
@@ -0,0 +1,117 @@
+package imaging
+
+import (
+	"errors"
+	"image"
+	"image/color"
+	"testing"
+)
+
+// fillImage создает изображение, закрашенное полосами цветов: ширина полосы задается долей в процентах
+func fillImage(width, height int, stripes map[color.RGBA]int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	x := 0
+	for c, percent := range stripes {
+		w := width * percent / 100
+		for ; w > 0 && x < width; w-- {
+			for y := 0; y < height; y++ {
+				img.SetRGBA(x, y, c)
+			}
+			x++
+		}
+	}
+	return img
+}
+
+func TestAnalyzeColors(t *testing.T) {
+	red := color.RGBA{R: 220, G: 20, B: 20, A: 255}
+	green := color.RGBA{R: 20, G: 200, B: 40, A: 255}
+	blue := color.RGBA{R: 10, G: 30, B: 240, A: 255}
+	img := fillImage(100, 50, map[color.RGBA]int{red: 60, green: 30, blue: 10})
+
+	stats := AnalyzeColors(img)
+
+	// Однотонные полосы дают точные цвета, от самого частого к редкому
+	want := []string{"#dc1414", "#14c828", "#0a1ef0"}
+	if len(stats.Palette) != len(want) {
+		t.Fatalf("Palette = %v, want %v", stats.Palette, want)
+	}
+	for i := range want {
+		if stats.Palette[i] != want[i] {
+			t.Errorf("Palette[%d] = %s, want %s", i, stats.Palette[i], want[i])
+		}
+	}
+
+	// Гистограмма содержит все точки выборки в трех корзинах
+	if len(stats.Histogram) != HistogramBins*HistogramBins*HistogramBins {
+		t.Fatalf("Histogram has %d bins", len(stats.Histogram))
+	}
+	var total, used uint32
+	for _, n := range stats.Histogram {
+		total += n
+		if n > 0 {
+			used++
+		}
+	}
+	if total != 100*50 || used != 3 {
+		t.Errorf("Histogram total %d in %d bins, want %d in 3", total, used, 100*50)
+	}
+}
+
+func TestAnalyzeColorsSkipsTransparent(t *testing.T) {
+	stats := AnalyzeColors(image.NewRGBA(image.Rect(0, 0, 10, 10)))
+	if len(stats.Palette) != 0 {
+		t.Errorf("Palette of a transparent image = %v, want empty", stats.Palette)
+	}
+
+	// Большое изображение анализируется по выборке
+	large := fillImage(1000, 1000, map[color.RGBA]int{{R: 255, A: 255}: 100})
+	stats = AnalyzeColors(large)
+	if len(stats.Palette) != 1 || stats.Palette[0] != "#ff0000" {
+		t.Errorf("Palette = %v, want [#ff0000]", stats.Palette)
+	}
+	var total uint32
+	for _, n := range stats.Histogram {
+		total += n
+	}
+	if total < sampleGrid*sampleGrid || total > 2*sampleGrid*sampleGrid {
+		t.Errorf("Histogram sampled %d pixels, want about %d", total, sampleGrid*sampleGrid)
+	}
+}
+
+func TestParseHexColor(t *testing.T) {
+	tests := []struct {
+		input string
+		want  color.RGBA
+		err   error
+	}{
+		{"#ff8000", color.RGBA{R: 255, G: 128, A: 255}, nil},
+		{"00ff00", color.RGBA{G: 255, A: 255}, nil},
+		{" #F0A ", color.RGBA{R: 255, A: 255, B: 170}, nil},
+		{"#12345", color.RGBA{}, ErrInvalidColor},
+		{"#gggggg", color.RGBA{}, ErrInvalidColor},
+		{"", color.RGBA{}, ErrInvalidColor},
+	}
+	for _, tt := range tests {
+		got, err := ParseHexColor(tt.input)
+		if !errors.Is(err, tt.err) || got != tt.want {
+			t.Errorf("ParseHexColor(%q) = %v, %v, want %v, %v", tt.input, got, err, tt.want, tt.err)
+		}
+		if err == nil && HexColor(got) != HexColor(tt.want) {
+			t.Errorf("HexColor round trip of %q = %s", tt.input, HexColor(got))
+		}
+	}
+}
+
+func TestPaletteDistance(t *testing.T) {
+	target := color.RGBA{R: 250, A: 255}
+	if d, ok := PaletteDistance([]string{"#0000ff", "#ff0000", "nope"}, target); !ok || d != 5 {
+		t.Errorf("PaletteDistance = %v, %t, want 5, true", d, ok)
+	}
+	if _, ok := PaletteDistance([]string{"nope"}, target); ok {
+		t.Error("PaletteDistance of a palette without valid colors found a color")
+	}
+	if d := ColorDistance(color.RGBA{}, color.RGBA{R: 3, G: 4}); d != 5 {
+		t.Errorf("ColorDistance = %v, want 5", d)
+	}
+}
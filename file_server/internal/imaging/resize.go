@@ -0,0 +1,51 @@
+// resize.go - масштабирование изображений
+package imaging
+
+import (
+	"image"
+
+	xdraw "golang.org/x/image/draw"
+)
+
+// FitRect возвращает прямоугольник с пропорциями src, вписанный по центру в box
+func FitRect(src image.Rectangle, box image.Rectangle) image.Rectangle {
+	sw, sh := src.Dx(), src.Dy()
+	bw, bh := box.Dx(), box.Dy()
+	if sw == 0 || sh == 0 || bw == 0 || bh == 0 {
+		return image.Rectangle{Min: box.Min, Max: box.Min}
+	}
+
+	// Масштаб определяется стороной, которая упирается в границу box
+	w, h := bw, sh*bw/sw
+	if h > bh {
+		w, h = sw*bh/sh, bh
+	}
+	w, h = max(1, w), max(1, h)
+
+	x := box.Min.X + (bw-w)/2
+	y := box.Min.Y + (bh-h)/2
+	return image.Rect(x, y, x+w, y+h)
+}
+
+// ScaleInto масштабирует src в прямоугольник dr изображения dst
+func ScaleInto(dst xdraw.Image, dr image.Rectangle, src image.Image) {
+	xdraw.CatmullRom.Scale(dst, dr, src, src.Bounds(), xdraw.Over, nil)
+}
+
+// Resize возвращает копию изображения размером width x height (без сохранения пропорций)
+func Resize(src image.Image, width, height int) *image.RGBA {
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	xdraw.CatmullRom.Scale(dst, dst.Bounds(), src, src.Bounds(), xdraw.Src, nil)
+	return dst
+}
+
+// ToRGBA возвращает изображение в формате *image.RGBA (без копирования, если оно уже в этом формате)
+func ToRGBA(img image.Image) *image.RGBA {
+	if rgba, ok := img.(*image.RGBA); ok && rgba.Bounds().Min == (image.Point{}) {
+		return rgba
+	}
+	b := img.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	xdraw.Draw(dst, dst.Bounds(), img, b.Min, xdraw.Src)
+	return dst
+}
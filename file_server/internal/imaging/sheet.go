@@ -0,0 +1,141 @@
+// sheet.go - построение контактного листа (коллажа) из нескольких изображений
+// Изображения вписываются в ячейки сетки, под каждой ячейкой может быть подпись
+package imaging
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+)
+
+const (
+	MaxSheetInputs = 100  // Максимальное количество изображений в контактном листе
+	MaxSheetWidth  = 8192 // Максимальная ширина контактного листа в пикселях
+	MaxSheetHeight = 8192 // Максимальная высота контактного листа в пикселях
+
+	DefaultSheetCell = 200 // Размер ячейки по умолчанию
+
+	captionHeight  = 16 // Высота области подписи под ячейкой
+	captionPadding = 2  // Отступ текста подписи от краев
+)
+
+// SheetLayout описывает сетку контактного листа
+type SheetLayout struct {
+	Columns    int        // Количество столбцов
+	CellWidth  int        // Ширина ячейки
+	CellHeight int        // Высота ячейки (без подписи)
+	Padding    int        // Отступ между ячейками и от краев листа
+	Background color.RGBA // Цвет фона
+	Captions   bool       // Рисовать подписи под ячейками
+}
+
+// ContactSheet - контактный лист, в который изображения добавляются по одному
+// Позволяет не держать в памяти все исходные изображения одновременно
+type ContactSheet struct {
+	layout SheetLayout
+	canvas *image.RGBA
+	face   font.Face
+}
+
+// NewContactSheet создает пустой контактный лист для count изображений
+// Проверяет ограничения на количество изображений и размер результата
+func NewContactSheet(count int, layout SheetLayout) (*ContactSheet, error) {
+	if count <= 0 || count > MaxSheetInputs {
+		return nil, ErrTooManyInputs
+	}
+	if layout.Columns <= 0 || layout.CellWidth <= 0 || layout.CellHeight <= 0 || layout.Padding < 0 {
+		return nil, ErrInvalidLayout
+	}
+
+	columns := min(layout.Columns, count)
+	rows := (count + columns - 1) / columns
+
+	// Размеры листа с учетом отступов и подписей (int64 защищает от переполнения)
+	width := int64(columns)*int64(layout.CellWidth) + int64(columns+1)*int64(layout.Padding)
+	height := int64(rows)*int64(layout.cellTotalHeight()) + int64(rows+1)*int64(layout.Padding)
+	if width > MaxSheetWidth || height > MaxSheetHeight {
+		return nil, ErrOutputTooLarge
+	}
+
+	// Заливка фона
+	canvas := image.NewRGBA(image.Rect(0, 0, int(width), int(height)))
+	draw.Draw(canvas, canvas.Bounds(), image.NewUniform(layout.Background), image.Point{}, draw.Src)
+
+	layout.Columns = columns
+	return &ContactSheet{
+		layout: layout,
+		canvas: canvas,
+		face:   basicfont.Face7x13,
+	}, nil
+}
+
+// Cell возвращает прямоугольник ячейки с номером index (без области подписи)
+func (s *ContactSheet) Cell(index int) image.Rectangle {
+	l := s.layout
+	col, row := index%l.Columns, index/l.Columns
+	x := l.Padding + col*(l.CellWidth+l.Padding)
+	y := l.Padding + row*(l.cellTotalHeight()+l.Padding)
+	return image.Rect(x, y, x+l.CellWidth, y+l.CellHeight)
+}
+
+// Draw вписывает изображение в ячейку с номером index и рисует подпись
+func (s *ContactSheet) Draw(index int, img image.Image, caption string) {
+	cell := s.Cell(index)
+	ScaleInto(s.canvas, FitRect(img.Bounds(), cell), img)
+
+	if s.layout.Captions && caption != "" {
+		s.drawCaption(cell, caption)
+	}
+}
+
+// Image возвращает готовый контактный лист
+func (s *ContactSheet) Image() *image.RGBA {
+	return s.canvas
+}
+
+// drawCaption рисует подпись под ячейкой, обрезая текст по ширине ячейки
+func (s *ContactSheet) drawCaption(cell image.Rectangle, caption string) {
+	// Обрезка текста по ширине ячейки (шрифт моноширинный)
+	advance := font.MeasureString(s.face, "M").Ceil()
+	maxChars := (cell.Dx() - 2*captionPadding) / max(1, advance)
+	runes := []rune(caption)
+	if len(runes) > maxChars {
+		if maxChars <= 3 {
+			runes = runes[:max(0, maxChars)]
+		} else {
+			runes = append(runes[:maxChars-3], []rune("...")...)
+		}
+	}
+
+	// Цвет текста контрастный к фону
+	textColor := color.Black
+	bg := s.layout.Background
+	if 0.299*float64(bg.R)+0.587*float64(bg.G)+0.114*float64(bg.B) < 128 {
+		textColor = color.White
+	}
+
+	// Горизонтальное выравнивание по центру ячейки
+	textWidth := font.MeasureString(s.face, string(runes)).Ceil()
+	x := cell.Min.X + (cell.Dx()-textWidth)/2
+	y := cell.Max.Y + captionHeight - captionPadding - s.face.Metrics().Descent.Ceil()
+
+	drawer := &font.Drawer{
+		Dst:  s.canvas,
+		Src:  image.NewUniform(textColor),
+		Face: s.face,
+		Dot:  fixed.P(x, y),
+	}
+	drawer.DrawString(string(runes))
+}
+
+// cellTotalHeight возвращает высоту ячейки вместе с областью подписи
+func (l SheetLayout) cellTotalHeight() int {
+	if l.Captions {
+		return l.CellHeight + captionHeight
+	}
+	return l.CellHeight
+}
@@ -0,0 +1,120 @@
+package imaging
+
+import (
+	"errors"
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestNewContactSheetLimits(t *testing.T) {
+	layout := SheetLayout{Columns: 3, CellWidth: 100, CellHeight: 80, Padding: 4}
+	tests := []struct {
+		name   string
+		count  int
+		layout SheetLayout
+		want   error
+	}{
+		{"valid", 5, layout, nil},
+		{"no inputs", 0, layout, ErrTooManyInputs},
+		{"too many inputs", MaxSheetInputs + 1, layout, ErrTooManyInputs},
+		{"no columns", 5, SheetLayout{CellWidth: 100, CellHeight: 80}, ErrInvalidLayout},
+		{"negative padding", 5, SheetLayout{Columns: 3, CellWidth: 100, CellHeight: 80, Padding: -1}, ErrInvalidLayout},
+		{"too wide", 2, SheetLayout{Columns: 2, CellWidth: MaxSheetWidth, CellHeight: 10}, ErrOutputTooLarge},
+		{"too high", MaxSheetInputs, SheetLayout{Columns: 1, CellWidth: 10, CellHeight: MaxSheetHeight / 50}, ErrOutputTooLarge},
+	}
+	for _, tt := range tests {
+		if _, err := NewContactSheet(tt.count, tt.layout); !errors.Is(err, tt.want) {
+			t.Errorf("%s: NewContactSheet = %v, want %v", tt.name, err, tt.want)
+		}
+	}
+}
+
+func TestContactSheetLayout(t *testing.T) {
+	tests := []struct {
+		name          string
+		count         int
+		layout        SheetLayout
+		width, height int
+	}{
+		// Столбцов не больше, чем изображений
+		{"single row", 2, SheetLayout{Columns: 4, CellWidth: 50, CellHeight: 40, Padding: 5}, 2*50 + 3*5, 40 + 2*5},
+		{"grid", 5, SheetLayout{Columns: 2, CellWidth: 50, CellHeight: 40, Padding: 5}, 2*50 + 3*5, 3*40 + 4*5},
+		{"captions", 3, SheetLayout{Columns: 3, CellWidth: 50, CellHeight: 40, Captions: true}, 3 * 50, 40 + captionHeight},
+	}
+	for _, tt := range tests {
+		sheet, err := NewContactSheet(tt.count, tt.layout)
+		if err != nil {
+			t.Fatalf("%s: NewContactSheet: %v", tt.name, err)
+		}
+		if b := sheet.Image().Bounds(); b.Dx() != tt.width || b.Dy() != tt.height {
+			t.Errorf("%s: sheet size = %dx%d, want %dx%d", tt.name, b.Dx(), b.Dy(), tt.width, tt.height)
+		}
+	}
+}
+
+func TestContactSheetDraw(t *testing.T) {
+	background := color.RGBA{R: 255, G: 255, B: 255, A: 255}
+	sheet, err := NewContactSheet(2, SheetLayout{Columns: 2, CellWidth: 40, CellHeight: 40, Padding: 2, Background: background, Captions: true})
+	if err != nil {
+		t.Fatalf("NewContactSheet: %v", err)
+	}
+
+	// Широкое изображение вписывается по центру ячейки с сохранением пропорций
+	red := color.RGBA{R: 255, A: 255}
+	sheet.Draw(1, fillImage(80, 20, map[color.RGBA]int{red: 100}), "a very long caption that does not fit")
+
+	cell := sheet.Cell(1)
+	if cell != image.Rect(44, 2, 84, 42) {
+		t.Fatalf("Cell(1) = %v", cell)
+	}
+	img := sheet.Image()
+	center := image.Pt(cell.Min.X+20, cell.Min.Y+20)
+	if got := img.RGBAAt(center.X, center.Y); got != red {
+		t.Errorf("cell center = %v, want %v", got, red)
+	}
+	if got := img.RGBAAt(cell.Min.X+20, cell.Min.Y+2); got != background {
+		t.Errorf("cell top = %v, want background", got)
+	}
+
+	// Подпись рисуется под ячейкой и не выходит за ее ширину
+	captioned := false
+	for y := cell.Max.Y; y < cell.Max.Y+captionHeight; y++ {
+		for x := cell.Min.X; x < cell.Max.X; x++ {
+			if img.RGBAAt(x, y) != background {
+				captioned = true
+			}
+		}
+		if img.RGBAAt(cell.Max.X, y) != background {
+			t.Errorf("caption overflows the cell at row %d", y)
+		}
+	}
+	if !captioned {
+		t.Error("caption not drawn")
+	}
+
+	// Пустая ячейка остается фоном
+	first := sheet.Cell(0)
+	if got := img.RGBAAt(first.Min.X+20, first.Min.Y+20); got != background {
+		t.Errorf("empty cell = %v, want background", got)
+	}
+}
+
+func TestFitRect(t *testing.T) {
+	box := image.Rect(10, 10, 110, 60)
+	tests := []struct {
+		name string
+		src  image.Rectangle
+		want image.Rectangle
+	}{
+		{"wide", image.Rect(0, 0, 200, 50), image.Rect(10, 22, 110, 47)},
+		{"tall", image.Rect(0, 0, 50, 100), image.Rect(47, 10, 72, 60)},
+		{"same aspect", image.Rect(0, 0, 20, 10), box},
+		{"empty", image.Rectangle{}, image.Rect(10, 10, 10, 10)},
+	}
+	for _, tt := range tests {
+		if got := FitRect(tt.src, box); got != tt.want {
+			t.Errorf("%s: FitRect = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+// watermark.go - наложение водяного знака на изображение
+// Водяной знак масштабируется относительно ширины изображения и накладывается с заданной прозрачностью
+package imaging
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+)
+
+// Позиции водяного знака
+const (
+	PositionCenter      = "center"
+	PositionTopLeft     = "top-left"
+	PositionTopRight    = "top-right"
+	PositionBottomLeft  = "bottom-left"
+	PositionBottomRight = "bottom-right"
+)
+
+const (
+	DefaultWatermarkOpacity = 0.5  // Прозрачность по умолчанию
+	DefaultWatermarkScale   = 0.25 // Ширина водяного знака относительно ширины изображения по умолчанию
+
+	watermarkMargin = 0.02 // Отступ от края изображения относительно его ширины
+)
+
+// WatermarkOptions описывает способ наложения водяного знака
+type WatermarkOptions struct {
+	Position string  // Позиция (игнорируется при Tile = true)
+	Opacity  float64 // Непрозрачность от 0 до 1
+	Scale    float64 // Ширина водяного знака относительно ширины изображения (от 0 до 1)
+	Tile     bool    // Замостить водяным знаком все изображение
+}
+
+// Normalize подставляет значения по умолчанию и проверяет корректность параметров
+func (o WatermarkOptions) Normalize() (WatermarkOptions, error) {
+	if o.Position == "" {
+		o.Position = PositionBottomRight
+	}
+	if o.Opacity == 0 {
+		o.Opacity = DefaultWatermarkOpacity
+	}
+	if o.Scale == 0 {
+		o.Scale = DefaultWatermarkScale
+	}
+
+	switch o.Position {
+	case PositionCenter, PositionTopLeft, PositionTopRight, PositionBottomLeft, PositionBottomRight:
+	default:
+		return o, ErrInvalidWatermark
+	}
+	if o.Opacity < 0 || o.Opacity > 1 || o.Scale < 0 || o.Scale > 1 {
+		return o, ErrInvalidWatermark
+	}
+
+	return o, nil
+}
+
+// ApplyWatermark накладывает водяной знак на копию изображения
+// Параметры должны быть предварительно нормализованы через Normalize
+func ApplyWatermark(img image.Image, mark image.Image, opts WatermarkOptions) *image.RGBA {
+	bounds := img.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(dst, dst.Bounds(), img, bounds.Min, draw.Src)
+
+	// Масштабирование водяного знака относительно ширины изображения с сохранением пропорций
+	mb := mark.Bounds()
+	if mb.Dx() == 0 || mb.Dy() == 0 {
+		return dst
+	}
+	w := max(1, int(float64(dst.Bounds().Dx())*opts.Scale))
+	h := max(1, mb.Dy()*w/mb.Dx())
+	scaled := Resize(mark, w, h)
+
+	// Маска с постоянной прозрачностью
+	mask := image.NewUniform(color.Alpha{A: uint8(opts.Opacity * 255)})
+
+	for _, pt := range watermarkPlacements(dst.Bounds(), scaled.Bounds().Size(), opts) {
+		r := image.Rectangle{Min: pt, Max: pt.Add(scaled.Bounds().Size())}
+		draw.DrawMask(dst, r, scaled, image.Point{}, mask, image.Point{}, draw.Over)
+	}
+
+	return dst
+}
+
+// watermarkPlacements возвращает левые верхние углы, в которые нужно нарисовать водяной знак
+func watermarkPlacements(canvas image.Rectangle, size image.Point, opts WatermarkOptions) []image.Point {
+	// Замощение с промежутком в половину размера водяного знака
+	if opts.Tile {
+		stepX, stepY := size.X+size.X/2, size.Y+size.Y/2
+		points := make([]image.Point, 0)
+		for y := 0; y < canvas.Dy(); y += stepY {
+			for x := 0; x < canvas.Dx(); x += stepX {
+				points = append(points, image.Pt(x, y))
+			}
+		}
+		return points
+	}
+
+	margin := int(float64(canvas.Dx()) * watermarkMargin)
+	left, top := margin, margin
+	right, bottom := canvas.Dx()-size.X-margin, canvas.Dy()-size.Y-margin
+
+	switch opts.Position {
+	case PositionTopLeft:
+		return []image.Point{image.Pt(left, top)}
+	case PositionTopRight:
+		return []image.Point{image.Pt(right, top)}
+	case PositionBottomLeft:
+		return []image.Point{image.Pt(left, bottom)}
+	case PositionCenter:
+		return []image.Point{image.Pt((canvas.Dx()-size.X)/2, (canvas.Dy()-size.Y)/2)}
+	default:
+		return []image.Point{image.Pt(right, bottom)}
+	}
+}
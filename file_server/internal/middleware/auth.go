@@ -0,0 +1,47 @@
+package middleware
+
+import (
+	"context"
+	"file_server/internal/auth"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// Authenticator - middleware для определения клиента по ключу API
+// Кладет учетные данные клиента в контекст запроса для последующих слоев
+type Authenticator struct {
+	store *auth.Store // Хранилище учетных данных
+}
+
+// NewAuthenticator создает middleware аутентификации
+func NewAuthenticator(store *auth.Store) *Authenticator {
+	return &Authenticator{
+		store: store,
+	}
+}
+
+// UnaryServerInterceptor возвращает gRPC interceptor аутентификации
+// Читает ключ API из metadata запроса и отклоняет запросы с неизвестным ключом
+func (a *Authenticator) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		// Извлечение ключа API из metadata
+		apiKey := ""
+		if md, ok := metadata.FromIncomingContext(ctx); ok {
+			if values := md.Get(auth.APIKeyHeader); len(values) > 0 {
+				apiKey = values[0]
+			}
+		}
+
+		// Поиск учетных данных клиента
+		cred, err := a.store.Authenticate(apiKey)
+		if err != nil {
+			return nil, status.Error(codes.Unauthenticated, err.Error())
+		}
+
+		// Передача учетных данных обработчику через контекст
+		return handler(auth.NewContext(ctx, cred), req)
+	}
+}
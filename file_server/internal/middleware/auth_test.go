@@ -0,0 +1,56 @@
+package middleware
+
+import (
+	"context"
+	"file_server/internal/auth"
+	"file_server/pkg/model"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestAuthenticatorInterceptor(t *testing.T) {
+	partner := &model.Credential{Name: "partner-a", Watermark: &model.WatermarkPolicy{WatermarkFileID: "file-1"}}
+	info := &grpc.UnaryServerInfo{FullMethod: "/file.FileService/GetFile"}
+
+	// Обработчик возвращает учетные данные, которые получил через контекст
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return auth.FromContext(ctx), nil
+	}
+	call := func(store *auth.Store, apiKey string) (*model.Credential, error) {
+		ctx := context.Background()
+		if apiKey != "" {
+			ctx = metadata.NewIncomingContext(ctx, metadata.Pairs(auth.APIKeyHeader, apiKey))
+		}
+		resp, err := NewAuthenticator(store).UnaryServerInterceptor()(ctx, nil, info, handler)
+		if err != nil {
+			return nil, err
+		}
+		return resp.(*model.Credential), nil
+	}
+
+	credentials := map[string]*model.Credential{"partner-secret": partner}
+	required := auth.NewStore(credentials, true)
+	optional := auth.NewStore(credentials, false)
+
+	if cred, err := call(required, "partner-secret"); err != nil || cred != partner {
+		t.Errorf("known key: %+v, %v", cred, err)
+	}
+	if cred, err := call(optional, ""); err != nil || cred != nil {
+		t.Errorf("anonymous with optional key: %+v, %v", cred, err)
+	}
+	for name, tt := range map[string]struct {
+		store  *auth.Store
+		apiKey string
+	}{
+		"anonymous with required key": {required, ""},
+		"unknown key":                 {optional, "guess"},
+	} {
+		if _, err := call(tt.store, tt.apiKey); status.Code(err) != codes.Unauthenticated {
+			t.Errorf("%s: %v, want Unauthenticated", name, err)
+		}
+	}
+}
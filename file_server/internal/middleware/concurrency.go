@@ -48,12 +48,12 @@ func (cl *ConcurrencyLimiter) UnaryServerInterceptor() grpc.UnaryServerIntercept
 	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
 		// Определяем тип операции по имени метода gRPC
 		switch {
-		// Операции загрузки и скачивания файлов - ресурсоемкие, лимит 10
-		case strings.Contains(info.FullMethod, "UploadFile") || strings.Contains(info.FullMethod, "GetFile"):
+		// Операции загрузки, скачивания и обработки изображений - ресурсоемкие, лимит 10
+		case isHeavyMethod(info.FullMethod):
 			return cl.handleUploadDownload(ctx, req, info, handler)
 
-		// Операции получения списка файлов - легкие, лимит 100
-		case strings.Contains(info.FullMethod, "ListFiles"):
+		// Операции получения списка и метаданных файлов - легкие, лимит 100
+		case strings.Contains(info.FullMethod, "ListFiles"), strings.HasSuffix(info.FullMethod, "/GetFileInfo"), strings.HasSuffix(info.FullMethod, "/ListVersions"):
 			return cl.handleList(ctx, req, info, handler)
 
 		// Остальные операции пропускаем без ограничений
@@ -63,6 +63,20 @@ func (cl *ConcurrencyLimiter) UnaryServerInterceptor() grpc.UnaryServerIntercept
 	}
 }
 
+// heavyMethods - методы, которые читают или обрабатывают содержимое файлов
+var heavyMethods = []string{"UploadFile", "GetFile", "GetFrame", "GetSpriteSheet", "ContactSheet", "CreateWatermarkVariant", "CompareImages", "CropImage", "Optimize", "Fsck", "RestoreVersion"}
+
+// isHeavyMethod проверяет, относится ли метод к ресурсоемким операциям
+// Имя метода сравнивается целиком, чтобы GetFileInfo не считался разновидностью GetFile
+func isHeavyMethod(fullMethod string) bool {
+	for _, method := range heavyMethods {
+		if strings.HasSuffix(fullMethod, "/"+method) {
+			return true
+		}
+	}
+	return false
+}
+
 // handleUploadDownload обрабатывает запросы загрузки и скачивания файлов
 // Ограничивает количество одновременных операций до 10
 func (cl *ConcurrencyLimiter) handleUploadDownload(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
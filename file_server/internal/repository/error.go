@@ -1,6 +1,9 @@
 package repository
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+)
 
 var (
 	ErrFileNotFound       = errors.New("FILE NOT FOUND")
@@ -10,4 +13,25 @@ var (
 	ErrStorageUnavailable = errors.New("STORAGE UNAVAILABLE")
 	ErrFileIsEmpty        = errors.New("FILE IS EMPTY")
 	ErrFailToDeleteFile   = errors.New("FAIL TO DELETE FILE")
+	ErrQuotaExceeded      = errors.New("QUOTA EXCEEDED")
+	ErrFileCorrupted      = errors.New("FILE CONTENT IS CORRUPTED")
+	ErrVersionNotFound    = errors.New("FILE VERSION NOT FOUND")
+	ErrFileIsVersion      = errors.New("FILE IS A PREVIOUS VERSION OF ANOTHER FILE")
+	ErrNotFileOwner       = errors.New("FILE BELONGS TO ANOTHER CLIENT")
 )
+
+// QuotaError описывает превышенное ограничение места (errors.Is(err, ErrQuotaExceeded) == true)
+type QuotaError struct {
+	Limit     string // Превышенное ограничение (например, "client bytes")
+	Usage     int64  // Текущее значение
+	Requested int64  // Запрошенное увеличение
+	Max       int64  // Ограничение
+}
+
+func (e *QuotaError) Error() string {
+	return fmt.Sprintf("%v: %s %d + %d, limit %d", ErrQuotaExceeded, e.Limit, e.Usage, e.Requested, e.Max)
+}
+
+func (e *QuotaError) Unwrap() error {
+	return ErrQuotaExceeded
+}
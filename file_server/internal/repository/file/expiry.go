@@ -0,0 +1,90 @@
+// expiry.go - срок хранения файлов
+// Файл с истекшим сроком хранения сразу становится недоступным (как удаленный),
+// а фоновая очистка удаляет его обычным путем удаления (deleteFile)
+package file
+
+import (
+	"context"
+	"file_server/pkg/model"
+	"log"
+	"time"
+)
+
+// expired проверяет, истек ли срок хранения файла к моменту now
+func expired(info *model.FileInfo, now time.Time) bool {
+	return info.ExpiresAt != nil && !now.Before(*info.ExpiresAt)
+}
+
+// DeleteExpired удаляет файлы, срок хранения которых истек к моменту now
+// Возвращает количество удаленных файлов
+func (r *Repository) DeleteExpired(ctx context.Context, now time.Time) (int, error) {
+	// Снимок ID файлов с истекшим сроком хранения
+	r.mutex.RLock()
+	var fileIDs []string
+	for fileID, info := range r.files {
+		if expired(info, now) {
+			fileIDs = append(fileIDs, fileID)
+		}
+	}
+	r.mutex.RUnlock()
+
+	// Удаление каждого файла обычным путем (содержимое удаляется вместе с последней ссылкой)
+	deleted := 0
+	for _, fileID := range fileIDs {
+		// Проверка контекста на отмену операции
+		select {
+		case <-ctx.Done():
+			return deleted, ctx.Err()
+		default:
+		}
+
+		removed, err := r.deleteIfExpired(fileID, now)
+		if err != nil {
+			return deleted, err
+		}
+		if removed {
+			deleted++
+		}
+	}
+
+	return deleted, nil
+}
+
+// deleteIfExpired удаляет файл, если его срок хранения истек к моменту now
+// Проверка и удаление выполняются под одной блокировкой: срок хранения мог быть продлен после снимка,
+// и продление во время удаления не должно теряться
+func (r *Repository) deleteIfExpired(fileID string, now time.Time) (bool, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	info, exists := r.files[fileID]
+	if !exists || !expired(info, now) {
+		return false, nil
+	}
+	if err := r.deleteFile(fileID); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// RunJanitor периодически удаляет файлы с истекшим сроком хранения до отмены ctx
+// Ошибки удаления логируются, удаление повторяется на следующем проходе
+func (r *Repository) RunJanitor(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			deleted, err := r.DeleteExpired(ctx, now)
+			if err != nil && ctx.Err() == nil {
+				log.Printf("Expired files cleanup failed: %v", err)
+			}
+			if deleted > 0 {
+				log.Printf("Expired files cleanup: %d files deleted", deleted)
+			}
+		}
+	}
+}
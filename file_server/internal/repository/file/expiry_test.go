@@ -0,0 +1,165 @@
+package file
+
+import (
+	"context"
+	"errors"
+	"file_server/internal/repository"
+	"file_server/pkg/model"
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+)
+
+// setExpiry задает срок хранения файла и завершает тест при ошибке
+func setExpiry(t *testing.T, repo *Repository, fileID string, at time.Time) {
+	t.Helper()
+	if err := repo.UpdateFileInfo(fileID, func(info *model.FileInfo) { info.ExpiresAt = &at }); err != nil {
+		t.Fatalf("UpdateFileInfo: %v", err)
+	}
+}
+
+func TestRepositoryHidesExpiredFiles(t *testing.T) {
+	repo := newMemoryRepo(t)
+	data := []byte("render")
+	tempID, err := repo.SaveFile("temp.png", data, "")
+	if err != nil {
+		t.Fatalf("SaveFile: %v", err)
+	}
+	keepID, err := repo.SaveFile("keep.png", data, "")
+	if err != nil {
+		t.Fatalf("SaveFile: %v", err)
+	}
+	setExpiry(t, repo, tempID, time.Now().Add(50*time.Millisecond))
+	time.Sleep(60 * time.Millisecond)
+
+	// Файл с истекшим сроком недоступен сразу, до удаления очисткой
+	if _, err := repo.GetFile(tempID); !errors.Is(err, repository.ErrFileNotFound) {
+		t.Errorf("GetFile of expired file: %v, want ErrFileNotFound", err)
+	}
+	if _, err := repo.GetFileInfo(tempID); !errors.Is(err, repository.ErrFileNotFound) {
+		t.Errorf("GetFileInfo of expired file: %v, want ErrFileNotFound", err)
+	}
+	if err := repo.UpdateFileInfo(tempID, func(info *model.FileInfo) { info.ExpiresAt = nil }); !errors.Is(err, repository.ErrFileNotFound) {
+		t.Errorf("extending expired file: %v, want ErrFileNotFound", err)
+	}
+	if files := listing(t, repo); len(files) != 1 || files[0].ID != keepID {
+		t.Errorf("listing = %+v, want only %s", files, keepID)
+	}
+
+	// Очистка удаляет файл обычным путем: общее содержимое остается у второго файла
+	deleted, err := repo.DeleteExpired(context.Background(), time.Now())
+	if err != nil || deleted != 1 {
+		t.Fatalf("DeleteExpired = %d, %v; want 1", deleted, err)
+	}
+	if _, exists := repo.files[tempID]; exists {
+		t.Errorf("expired file still in cache")
+	}
+	if got, err := repo.GetFile(keepID); err != nil || string(got.Data) != string(data) {
+		t.Errorf("GetFile of the other copy: %v", err)
+	}
+	if stats, _ := repo.GetStats(); stats.Files != 1 {
+		t.Errorf("GetStats counts %d files, want 1", stats.Files)
+	}
+}
+
+func TestRepositoryExpiryCanBeUpdated(t *testing.T) {
+	repo := newMemoryRepo(t)
+	fileID, err := repo.SaveFile("temp.png", []byte("render"), "")
+	if err != nil {
+		t.Fatalf("SaveFile: %v", err)
+	}
+	now := time.Now()
+	setExpiry(t, repo, fileID, now.Add(time.Hour))
+
+	// Продленный срок не истек
+	setExpiry(t, repo, fileID, now.Add(3*time.Hour))
+	if deleted, _ := repo.DeleteExpired(context.Background(), now.Add(2*time.Hour)); deleted != 0 {
+		t.Errorf("extended file deleted")
+	}
+
+	// Файл без срока хранения не удаляется
+	if err := repo.UpdateFileInfo(fileID, func(info *model.FileInfo) { info.ExpiresAt = nil }); err != nil {
+		t.Fatalf("UpdateFileInfo: %v", err)
+	}
+	if deleted, _ := repo.DeleteExpired(context.Background(), now.Add(100*time.Hour)); deleted != 0 {
+		t.Errorf("file without expiry deleted")
+	}
+}
+
+func TestRepositoryExtensionDuringCleanupIsKept(t *testing.T) {
+	repo := newMemoryRepo(t)
+	now := time.Now()
+
+	var fileIDs []string
+	for i := 0; i < 200; i++ {
+		fileID, err := repo.SaveFile("temp.png", []byte(fmt.Sprintf("render %d", i)), "")
+		if err != nil {
+			t.Fatalf("SaveFile: %v", err)
+		}
+		setExpiry(t, repo, fileID, now.Add(time.Hour))
+		fileIDs = append(fileIDs, fileID)
+	}
+
+	// Продление во время очистки: либо файл продлен и остается, либо уже удален и продление отклоняется
+	extended := make(chan string, len(fileIDs))
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for _, fileID := range slices.Backward(fileIDs) {
+			later := now.Add(3 * time.Hour)
+			if err := repo.UpdateFileInfo(fileID, func(info *model.FileInfo) { info.ExpiresAt = &later }); err == nil {
+				extended <- fileID
+			}
+		}
+		close(extended)
+	}()
+	if _, err := repo.DeleteExpired(context.Background(), now.Add(2*time.Hour)); err != nil {
+		t.Fatalf("DeleteExpired: %v", err)
+	}
+	<-done
+
+	for fileID := range extended {
+		if _, err := repo.GetFileInfo(fileID); err != nil {
+			t.Errorf("extended file %s deleted: %v", fileID, err)
+		}
+	}
+}
+
+func TestRepositoryJanitorStopsOnCancel(t *testing.T) {
+	repo := newMemoryRepo(t)
+	fileID, err := repo.SaveFile("temp.png", []byte("render"), "")
+	if err != nil {
+		t.Fatalf("SaveFile: %v", err)
+	}
+	setExpiry(t, repo, fileID, time.Now().Add(10*time.Millisecond))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		repo.RunJanitor(ctx, 5*time.Millisecond)
+	}()
+
+	// Очистка удаляет файл в фоне
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		repo.mutex.RLock()
+		_, exists := repo.files[fileID]
+		repo.mutex.RUnlock()
+		if !exists {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("janitor did not delete the expired file")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatalf("janitor did not stop after cancel")
+	}
+}
@@ -1,163 +1,384 @@
 // file.go - репозиторий для работы с файлами
-// Обеспечивает сохранение, загрузку и управление файлами на диске
+// Обеспечивает сохранение, загрузку и управление файлами в хранилище
 // Использует кэш метаданных для быстрого доступа к информации о файлах
+// Содержимое и метаданные хранятся в бэкенде хранилища (см. internal/storage) и восстанавливаются при перезапуске
+// Файл - логическая запись (свой ID, имя, владелец, время), ссылающаяся на содержимое по ID содержимого (SHA-256 хэш)
+// Файлы с одинаковым содержимым разделяют одно содержимое; оно удаляется, когда на него не остается ссылок
+// Прежние MD5 ID содержимого переводятся на SHA-256 и остаются псевдонимами файлов (см. migrate.go)
+// Замена содержимого файла сохраняет его ID, прежнее содержимое остается версией файла (см. versions.go)
 package file
 
 import (
-	"crypto/md5"
-	"encoding/hex"
+	"errors"
 	"file_server/internal/repository"
+	"file_server/internal/storage"
 	"file_server/pkg/model"
 	"fmt"
-	"os"
-	"path/filepath"
+	"log"
 	"strings"
 	"sync"
 	"time"
 )
 
 // Repository - репозиторий для работы с файлами
-// Хранит файлы на диске и кэширует их метаданные в памяти
+// Хранит содержимое файлов в BlobStore, метаданные - в MetaStore, и кэширует метаданные в памяти
 type Repository struct {
-	storagePath string                     // Путь к директории хранения файлов
-	mutex       sync.RWMutex               // Мьютекс для thread-safe доступа к кэшу
-	files       map[string]*model.FileInfo // Кэш метаданных файлов (ID -> FileInfo)
+	blobs    storage.BlobStore          // Хранилище содержимого файлов
+	meta     storage.MetaStore          // Хранилище метаданных (изменения кэша записываются под mutex)
+	mutex    sync.RWMutex               // Мьютекс для thread-safe доступа к кэшу
+	files    map[string]*model.FileInfo // Кэш метаданных файлов (ID -> FileInfo)
+	refs     map[string]int             // Количество файлов, ссылающихся на содержимое (ID содержимого -> количество)
+	corrupt  map[string]bool            // Поврежденное содержимое, на которое ссылаются файлы (см. scrub.go), защищено mutex
+	physical map[string]int64           // Размер содержимого в хранилище (ID содержимого -> байт), защищен mutex
+	inflight map[string]*pendingWrite   // Незавершенные записи содержимого (ID содержимого -> запись), защищены mutex
+	aliases  map[string]string          // Прежние ID файлов (прежний ID -> текущий ID), защищены mutex
+	usage    usage                      // Учет занятого места (см. quota.go), защищен mutex
+	limits   Limits                     // Ограничения хранилища, защищены mutex
+	space    SpaceGuard                 // Проверка свободного места (nil - не проверяется), защищена mutex
+	keep     int                        // Количество хранимых версий файла вместе с текущей (0 - все), защищено mutex
 }
 
-// NewRepo создает новый экземпляр репозитория
-// Создает директорию хранения и загружает существующие файлы в кэш
-func NewRepo(storagePath string) (*Repository, error) {
-	// Создание директории хранения файлов (если не существует)
-	if err := os.MkdirAll(storagePath, 0755); err != nil {
-		return nil, fmt.Errorf("FAILED TO CREATE STORAGE DIRECTORY: %w", err)
+// pendingWrite - незавершенная запись содержимого
+// Параллельные загрузки того же содержимого ждут ее завершения вместо повторной записи
+// Результат записи не передается ожидающим: каждый загрузчик заново проверяет, сохранено ли содержимое,
+// и сам проверяет ограничения и сохраняет метаданные (ошибка одного загрузчика не относится к другим)
+type pendingWrite struct {
+	done chan struct{} // Закрывается по завершении записи
+}
+
+// NewRepo создает новый экземпляр репозитория поверх хранилищ содержимого и метаданных
+// Загружает метаданные в кэш, сверяет их с сохраненным содержимым и восстанавливает счетчики ссылок на содержимое
+// Репозиторий владеет хранилищем метаданных и закрывает его в Close
+func NewRepo(blobs storage.BlobStore, meta storage.MetaStore) (*Repository, error) {
+	return newRepo(blobs, meta, true)
+}
+
+// NewRepoForCheck создает репозиторий без сверки метаданных с сохраненным содержимым
+// Используется автономной проверкой целостности (см. fsck.go): расхождения попадают в отчет, а не исправляются при загрузке
+func NewRepoForCheck(blobs storage.BlobStore, meta storage.MetaStore) (*Repository, error) {
+	return newRepo(blobs, meta, false)
+}
+
+// newRepo загружает репозиторий, при reconcile = true сверяя метаданные с сохраненным содержимым
+func newRepo(blobs storage.BlobStore, meta storage.MetaStore, reconcile bool) (*Repository, error) {
+	// Загрузка сохраненных метаданных
+	files, err := meta.Load()
+	if err != nil {
+		return nil, fmt.Errorf("FAILED TO LOAD FILE METADATA: %w", err)
 	}
 
 	// Создание экземпляра репозитория
 	repo := &Repository{
-		storagePath: storagePath,
-		files:       make(map[string]*model.FileInfo), // Инициализация кэша метаданных
+		blobs:    blobs,
+		meta:     meta,
+		files:    files, // Кэш метаданных, восстановленный из хранилища
+		refs:     make(map[string]int),
+		corrupt:  make(map[string]bool),
+		physical: make(map[string]int64),
+		inflight: make(map[string]*pendingWrite),
+		aliases:  make(map[string]string),
+		usage:    usage{owners: make(map[string]model.Usage)},
 	}
 
-	// Загрузка существующих файлов в кэш при инициализации
-	if err := repo.loadExistingFiles(); err != nil {
-		return nil, fmt.Errorf("FAILED TO LOAD EXISTING FILES: %w", err)
+	// Файлы, сохраненные до разделения файлов и содержимого, ссылаются на содержимое под своим ID
+	for fileID, info := range repo.files {
+		if info.BlobID == "" {
+			updated := *info
+			updated.BlobID = fileID
+			repo.files[fileID] = &updated
+		}
+	}
+
+	// Сверка кэша с сохраненным содержимым
+	if reconcile {
+		if err := repo.loadExistingFiles(); err != nil {
+			return nil, fmt.Errorf("FAILED TO LOAD EXISTING FILES: %w", err)
+		}
+	}
+
+	// Восстановление счетчиков ссылок, учета места и псевдонимов по прежним ID, сохраненным в метаданных
+	// Счетчики не сохраняются, а пересчитываются по метаданным файлов, поэтому не могут разойтись с ними после сбоя
+	for fileID, info := range repo.files {
+		repo.retain(info)
+		for _, legacyID := range info.LegacyIDs {
+			repo.aliases[legacyID] = fileID
+		}
 	}
 
 	return repo, nil
 }
 
-// loadExistingFiles загружает информацию о существующих файлах в кэш
-// Сканирует директорию хранения и создает метаданные для каждого файла
+// Close закрывает хранилище метаданных
+func (r *Repository) Close() error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.meta.Close()
+}
+
+// loadExistingFiles сверяет кэш, восстановленный из хранилища метаданных, с сохраненным содержимым
+// Содержимое без метаданных (загруженное до появления журнала) добавляется с метаданными из хранилища содержимого,
+// метаданные файлов, содержимого которых нет, удаляются, временные файлы прерванных записей удаляются
 func (r *Repository) loadExistingFiles() error {
-	// Чтение содержимого директории хранения
-	entities, err := os.ReadDir(r.storagePath)
+	referenced := make(map[string]bool, len(r.files))
+	for _, info := range r.files {
+		referenced[info.BlobID] = true
+	}
+
+	// Обход сохраненного содержимого
+	stored := make(map[string]bool)
+	err := r.blobs.Iterate(func(blob storage.BlobInfo) error {
+		stored[blob.ID] = true
+
+		// На содержимое ссылаются восстановленные метаданные
+		if referenced[blob.ID] {
+			return nil
+		}
+
+		// Добавление файла для содержимого без метаданных
+		_, err := r.adoptBlob(blob)
+		return err
+	})
 	if err != nil {
 		return err
 	}
 
-	// Обработка каждого элемента в директории
-	for _, entry := range entities {
-		// Пропускаем поддиректории
-		if entry.IsDir() {
+	// Удаление метаданных файлов, содержимого которых нет
+	// Метаданные поврежденных файлов сохраняются: их содержимое в карантине, а чтение должно сообщать о потере данных
+	for fileID, info := range r.files {
+		if stored[info.BlobID] || info.Corrupted {
 			continue
 		}
-
-		// Получение информации о файле
-		info, err := entry.Info()
-		if err != nil {
-			continue // Пропускаем файлы с ошибками доступа
-		}
-
-		// Создание метаданных файла
-		// ID файла = имя файла (MD5 хэш содержимого)
-		fileInfo := &model.FileInfo{
-			ID:        entry.Name(),   // ID файла (MD5 хэш)
-			Filename:  entry.Name(),   // Имя файла (временно = ID, будет обновлено при загрузке)
-			CreatedAt: info.ModTime(), // Время создания (время модификации файла)
-			UpdatedAt: info.ModTime(), // Время обновления (время модификации файла)
-			Size:      info.Size(),    // Размер файла в байтах
+		if err := r.meta.Delete(fileID); err != nil {
+			return err
 		}
+		delete(r.files, fileID)
+	}
 
-		// Добавление метаданных в кэш
-		r.files[entry.Name()] = fileInfo
+	// Удаление временных файлов прерванных записей
+	temps, err := r.staleTempFiles()
+	if err != nil {
+		return err
+	}
+	for _, temp := range temps {
+		r.blobs.(storage.TempFileStore).DeleteTempFile(temp.ID)
 	}
 
 	return nil
 }
 
-// SaveFile сохраняет файл на диск и обновляет кэш метаданных
-// Использует MD5 хэш содержимого как уникальный ID файла
-func (r *Repository) SaveFile(filename string, data []byte) (string, error) {
-	// Валидация входящих данных (имя файла, размер, содержимое)
-	if err := r.validateFile(filename, data); err != nil {
-		return "", err
+// adoptBlob создает и сохраняет метаданные файла для содержимого без метаданных
+// Учет ссылок не изменяется (при загрузке он пересчитывается по всем файлам, проверка целостности учитывает файл сама)
+func (r *Repository) adoptBlob(blob storage.BlobInfo) (*model.FileInfo, error) {
+	// Размер файла - размер содержимого до сжатия (blob.Size - размер в хранилище)
+	size := blob.Size
+	if data, err := r.blobs.Get(blob.ID); err == nil {
+		size = int64(len(data))
+	}
+
+	// Создание метаданных файла
+	// ID файла = ID содержимого (хэш содержимого, файлы с MD5 ID переводятся на SHA-256 миграцией)
+	fileInfo := &model.FileInfo{
+		ID:         blob.ID,      // ID файла (хэш содержимого)
+		BlobID:     blob.ID,      // ID содержимого
+		Filename:   blob.ID,      // Имя файла (временно = ID, будет обновлено при загрузке)
+		CreatedAt:  blob.ModTime, // Время создания (время записи содержимого)
+		UpdatedAt:  blob.ModTime, // Время обновления (время записи содержимого)
+		Size:       size,         // Размер файла в байтах
+		StoredSize: blob.Size,    // Размер содержимого в хранилище
 	}
 
-	// Генерация уникального ID на основе MD5 хэша содержимого файла
-	hash := md5.Sum(data)
-	fileID := hex.EncodeToString(hash[:])
+	// Добавление метаданных в хранилище и кэш
+	if err := r.meta.Put(fileInfo); err != nil {
+		return nil, err
+	}
+	r.files[blob.ID] = fileInfo
+	return fileInfo, nil
+}
 
-	// Проверка, существует ли файл с таким содержимым (дедупликация)
-	r.mutex.RLock()
+// resolve возвращает текущий ID файла по его ID или прежнему ID (пусто, если файл не найден)
+// Вызывается под блокировкой mutex
+func (r *Repository) resolve(fileID string) string {
 	if _, exists := r.files[fileID]; exists {
-		r.mutex.RUnlock()
-		return fileID, nil // Возвращаем существующий ID без сохранения
+		return fileID
 	}
-	r.mutex.RUnlock()
+	return r.aliases[fileID]
+}
 
-	// Сохранение файла на диск
-	filePath := filepath.Join(r.storagePath, fileID)
-	if err := os.WriteFile(filePath, data, 0644); err != nil {
-		return "", fmt.Errorf("FAILED TO WRITE FILE: %w", err)
+// SaveFile сохраняет файл в хранилище и обновляет кэш метаданных
+// Каждый вызов создает новый файл со своим ID, именем и владельцем
+// Содержимое адресуется SHA-256 хэшем и записывается, только если такого содержимого еще нет (дедупликация)
+// или если оно повреждено (тогда файлы с этим содержимым восстанавливаются)
+// Дедупликация выполняется только по SHA-256: совпадение MD5 не означает совпадения содержимого
+func (r *Repository) SaveFile(filename string, data []byte, owner string) (string, error) {
+	return r.SaveFileWithInfo(filename, data, owner, nil)
+}
+
+// SaveFileWithInfo сохраняет файл как SaveFile, дополняя его метаданные функцией update (nil - без дополнений)
+// Дополненные метаданные записываются одной записью вместе с файлом: файл не бывает виден без них
+// update не должна изменять ID, содержимое, владельца и размер файла
+func (r *Repository) SaveFileWithInfo(filename string, data []byte, owner string, update func(info *model.FileInfo)) (string, error) {
+	// Валидация входящих данных (имя файла, размер, содержимое)
+	if err := r.validateFile(filename, data); err != nil {
+		return "", err
 	}
 
+	// Проверка свободного места до записи (при нехватке места запись содержимого и метаданных прервалась бы)
+	if err := r.checkSpace(int64(len(data))); err != nil {
+		return "", err
+	}
+
+	// Генерация ID содержимого на основе SHA-256 хэша
+	blobID := model.NewBlobID(data)
+
 	// Создание метаданных файла
 	now := time.Now()
 	fileInfo := &model.FileInfo{
-		ID:        fileID,           // Уникальный ID (MD5 хэш)
-		Filename:  filename,         // Оригинальное имя файла
-		CreatedAt: now,              // Время создания
-		UpdatedAt: now,              // Время обновления
-		Size:      int64(len(data)), // Размер файла в байтах
+		ID:        model.NewFileID(),          // Уникальный ID файла
+		BlobID:    blobID,                     // ID содержимого (SHA-256 хэш)
+		Filename:  filename,                   // Оригинальное имя файла
+		Owner:     owner,                      // Владелец файла
+		CreatedAt: now,                        // Время создания
+		UpdatedAt: now,                        // Время обновления
+		Size:      int64(len(data)),           // Размер файла в байтах
+		Digests:   model.ComputeDigests(data), // Хэши содержимого
+	}
+	if update != nil {
+		update(fileInfo)
 	}
 
-	// Обновление кэша метаданных
-	r.mutex.Lock()
-	r.files[fileID] = fileInfo
+	// Проверка, сохранено ли уже такое содержимое (дедупликация)
+	// Проверка и регистрация записи выполняются под одной блокировкой, чтобы запись вел только один загрузчик
+	for {
+		r.mutex.Lock()
+		if r.refs[blobID] > 0 && !r.corrupt[blobID] {
+			// Содержимое уже сохранено - создается только новая ссылка на него
+			defer r.mutex.Unlock()
+			fileInfo.StoredSize = r.physical[blobID]
+			if err := r.addFile(fileInfo); err != nil {
+				return "", err
+			}
+			return fileInfo.ID, nil
+		}
+		pending, writing := r.inflight[blobID]
+		if !writing {
+			// Предварительная проверка ограничений до записи содержимого (окончательная - в addFile)
+			if err := r.checkQuota(fileInfo); err != nil {
+				r.mutex.Unlock()
+				return "", err
+			}
+			break // Блокировка остается захваченной для регистрации записи
+		}
+		r.mutex.Unlock()
+		<-pending.done // Такое же содержимое уже записывается - ждем завершения и проверяем снова (при сбое записываем сами)
+	}
+	pending := &pendingWrite{done: make(chan struct{})}
+	r.inflight[blobID] = pending
 	r.mutex.Unlock()
 
-	return fileID, nil
+	// Снятие регистрации записи и оповещение ожидающих загрузчиков
+	defer func() {
+		r.mutex.Lock()
+		delete(r.inflight, blobID)
+		r.mutex.Unlock()
+		close(pending.done)
+	}()
+
+	// Атомарное сохранение содержимого в хранилище
+	if err := r.blobs.Put(blobID, data); err != nil {
+		return "", fmt.Errorf("FAILED TO WRITE FILE: %w", err)
+	}
+	fileInfo.StoredSize = r.storedSize(blobID)
+
+	// Сохранение метаданных и обновление кэша
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if err := r.addFile(fileInfo); err != nil {
+		// Содержимое без ссылок не должно остаться в хранилище (при запуске оно было бы восстановлено как файл)
+		if r.refs[blobID] == 0 {
+			r.blobs.Delete(blobID)
+		}
+		return "", err
+	}
+
+	// Повторная загрузка поврежденного содержимого восстанавливает ссылающиеся на него файлы
+	if r.corrupt[blobID] {
+		if err := r.restoreBlob(blobID, fileInfo.StoredSize); err != nil {
+			return "", err
+		}
+	}
+
+	return fileInfo.ID, nil
+}
+
+// addFile проверяет ограничения хранилища, сохраняет метаданные нового файла и учитывает его
+// Вызывается под блокировкой mutex, поэтому параллельные загрузки не могут вместе превысить ограничения
+func (r *Repository) addFile(info *model.FileInfo) error {
+	if err := r.checkQuota(info); err != nil {
+		return err
+	}
+	if err := r.meta.Put(info); err != nil {
+		return fmt.Errorf("FAILED TO SAVE FILE METADATA: %w", err)
+	}
+	r.files[info.ID] = info
+	r.retain(info)
+	return nil
 }
 
 // GetFile загружает файл по его ID
-// Проверяет кэш метаданных и читает содержимое с диска
+// Проверяет кэш метаданных и читает содержимое из хранилища
 func (r *Repository) GetFile(fileID string) (*model.File, error) {
 	// Валидация ID файла
 	if fileID == "" {
 		return nil, repository.ErrInvalidFileID
 	}
 
-	// Проверка существования файла в кэше метаданных
-	r.mutex.RLock()
-	fileInfo, exists := r.files[fileID]
-	r.mutex.RUnlock()
-
-	if !exists {
-		return nil, repository.ErrFileNotFound
-	}
+	// Поиск файла в кэше метаданных (по текущему или прежнему ID)
+	// Если файл переименован миграцией во время чтения, чтение повторяется по новому ID
+	var (
+		fileInfo *model.FileInfo
+		data     []byte
+	)
+	for {
+		r.mutex.RLock()
+		currentID := r.resolve(fileID)
+		fileInfo = r.files[currentID]
+		r.mutex.RUnlock()
 
-	// Чтение содержимого файла с диска
-	filePath := filepath.Join(r.storagePath, fileID)
-	data, err := os.ReadFile(filePath)
-	if err != nil {
-		if os.IsNotExist(err) {
-			// Файл был удален с диска, но существует в кэше - синхронизируем кэш
-			r.mutex.Lock()
-			delete(r.files, fileID)
-			r.mutex.Unlock()
+		// Файл с истекшим сроком хранения недоступен, даже если еще не удален очисткой
+		if fileInfo == nil || expired(fileInfo, time.Now()) {
 			return nil, repository.ErrFileNotFound
 		}
-		return nil, fmt.Errorf("FAILED TO READ FILE: %w", err)
+		// Поврежденное содержимое не выдается
+		if fileInfo.Corrupted {
+			return nil, repository.ErrFileCorrupted
+		}
+
+		// Чтение содержимого файла из хранилища
+		var err error
+		data, err = r.blobs.Get(fileInfo.BlobID)
+		if err == nil {
+			break
+		}
+		if errors.Is(err, storage.ErrBlobCorrupted) {
+			// Содержимое не удается декодировать - оно будет изолировано проверкой содержимого
+			// Причина сохраняется в цепочке ошибки (например, несовпадение тега аутентификации)
+			log.Printf("Content %s of file %s is corrupted: %v", fileInfo.BlobID, currentID, err)
+			return nil, fmt.Errorf("%w: %w", repository.ErrFileCorrupted, err)
+		}
+		if !errors.Is(err, storage.ErrBlobNotFound) {
+			return nil, err
+		}
+
+		// Содержимое отсутствует в хранилище
+		r.mutex.RLock()
+		changed := r.files[currentID] != fileInfo
+		r.mutex.RUnlock()
+		if changed {
+			continue // Метаданные изменились во время чтения (например, содержимое перенесено миграцией) - повторяем
+		}
+		// Метаданные не удаляются: расхождение с хранилищем сообщается и исправляется проверкой целостности (см. fsck.go)
+		log.Printf("Content %s of file %s is missing from storage, run fsck", fileInfo.BlobID, currentID)
+		return nil, repository.ErrFileNotFound
 	}
 
 	// Возврат файла с метаданными и содержимым
@@ -168,6 +389,7 @@ func (r *Repository) GetFile(fileID string) (*model.File, error) {
 }
 
 // ListFiles возвращает список всех файлов из кэша метаданных
+// Файлы с истекшим сроком хранения и прежние версии файлов (см. versions.go) не возвращаются
 // Создает копию метаданных для безопасного возврата
 func (r *Repository) ListFiles() ([]model.FileInfo, error) {
 	// Блокировка для безопасного чтения кэша
@@ -178,8 +400,11 @@ func (r *Repository) ListFiles() ([]model.FileInfo, error) {
 	files := make([]model.FileInfo, 0, len(r.files))
 
 	// Копирование метаданных из кэша
+	now := time.Now()
 	for _, fileInfo := range r.files {
-		files = append(files, *fileInfo)
+		if !expired(fileInfo, now) && fileInfo.VersionOf == "" {
+			files = append(files, *fileInfo)
+		}
 	}
 
 	return files, nil
@@ -219,52 +444,130 @@ func (r *Repository) GetFileInfo(fileID string) (*model.FileInfo, error) {
 	r.mutex.RLock()
 	defer r.mutex.RUnlock()
 
-	// Поиск метаданных в кэше
-	fileInfo, exists := r.files[fileID]
-	if !exists {
+	// Поиск метаданных в кэше (по текущему или прежнему ID)
+	fileInfo, exists := r.files[r.resolve(fileID)]
+	if !exists || expired(fileInfo, time.Now()) {
 		return nil, repository.ErrFileNotFound
 	}
 
 	return fileInfo, nil
 }
 
-// DeleteFile удаляет файл с диска и из кэша метаданных
-// Игнорирует ошибку, если файл уже не существует
-func (r *Repository) DeleteFile(fileID string) error {
+// UpdateFileInfo изменяет метаданные файла в кэше
+// Функция update вызывается под блокировкой и не должна обращаться к репозиторию
+func (r *Repository) UpdateFileInfo(fileID string, update func(info *model.FileInfo)) error {
 	// Валидация ID файла
 	if fileID == "" {
 		return repository.ErrInvalidFileID
 	}
 
-	// Удаление файла с диска
-	filePath := filepath.Join(r.storagePath, fileID)
-	if err := os.Remove(filePath); err != nil && !os.IsNotExist(err) {
-		return repository.ErrFailToDeleteFile
+	// Блокировка для безопасного изменения кэша
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	// Поиск метаданных в кэше (по текущему или прежнему ID)
+	// Срок хранения истекшего файла продлить нельзя - он считается удаленным
+	fileID = r.resolve(fileID)
+	fileInfo, exists := r.files[fileID]
+	if !exists || expired(fileInfo, time.Now()) {
+		return repository.ErrFileNotFound
+	}
+
+	// Изменение копии, чтобы ранее выданные указатели не менялись
+	updated := *fileInfo
+	update(&updated)
+
+	// Сохранение метаданных и обновление кэша
+	if err := r.meta.Put(&updated); err != nil {
+		return fmt.Errorf("FAILED TO SAVE FILE METADATA: %w", err)
 	}
+	r.files[fileID] = &updated
+
+	return nil
+}
 
-	// Удаление метаданных из кэша
+// DeleteFile удаляет файл из кэша метаданных и из хранилища
+// Содержимое удаляется, только если на него не ссылаются другие файлы
+// Игнорирует ошибку, если файл уже не существует
+func (r *Repository) DeleteFile(fileID string) error {
+	// Валидация ID файла
+	if err := storage.ValidateBlobID(fileID); err != nil {
+		return repository.ErrInvalidFileID
+	}
+
+	// Файл удаляется под блокировкой, чтобы загрузка того же содержимого или миграция не выполнялись в это время
 	r.mutex.Lock()
-	delete(r.files, fileID)
-	r.mutex.Unlock()
+	defer r.mutex.Unlock()
+
+	// Поиск файла (по текущему или прежнему ID)
+	return r.deleteFile(r.resolve(fileID))
+}
+
+// deleteFile удаляет файл вместе с его прежними версиями (см. versions.go)
+// Удаленная прежняя версия исключается из истории файла
+// Вызывается под блокировкой mutex
+func (r *Repository) deleteFile(fileID string) error {
+	fileInfo, exists := r.files[fileID]
+	if !exists {
+		return nil
+	}
+
+	// Прежние версии удаляются раньше файла: при сбое между шагами не остается версий без файла
+	for _, version := range fileInfo.Versions {
+		if err := r.deleteFile(version.FileID); err != nil {
+			return err
+		}
+	}
+	fileInfo = r.files[fileID]
+
+	// Удаление содержимого, если это последняя ссылка на него
+	// Содержимое удаляется раньше метаданных: при сбое между шагами метаданные без содержимого удаляются при запуске,
+	// а содержимое без метаданных было бы восстановлено как файл
+	if r.refs[fileInfo.BlobID] <= 1 {
+		if err := r.blobs.Delete(fileInfo.BlobID); err != nil {
+			return repository.ErrFailToDeleteFile
+		}
+	}
+
+	// Удаление метаданных из хранилища и кэша
+	if err := r.meta.Delete(fileID); err != nil {
+		return fmt.Errorf("FAILED TO SAVE FILE METADATA: %w", err)
+	}
+	r.forget(fileID)
+
+	// Исключение удаленной версии из истории файла
+	if fileInfo.VersionOf != "" {
+		return r.dropVersion(fileInfo.VersionOf, fileID)
+	}
 
 	return nil
 }
 
+// forget удаляет метаданные файла и его псевдонимы из кэша и снимает его учет
+// Вызывается под блокировкой mutex
+func (r *Repository) forget(fileID string) {
+	info, exists := r.files[fileID]
+	if !exists {
+		return
+	}
+	for _, legacyID := range info.LegacyIDs {
+		delete(r.aliases, legacyID)
+	}
+	delete(r.files, fileID)
+	r.release(info)
+}
+
 // GetStats возвращает статистику репозитория
-// Подсчитывает количество файлов и общий размер всех файлов
-func (r *Repository) GetStats() (int, int64, error) {
-	// Блокировка для безопасного чтения кэша
+// Возвращает количество файлов, общий размер всех файлов и объем содержимого до и после сжатия (из учета занятого места)
+func (r *Repository) GetStats() (model.StorageStats, error) {
+	// Блокировка для безопасного чтения учета
 	r.mutex.RLock()
 	defer r.mutex.RUnlock()
 
-	// Подсчет количества файлов
-	fileCount := len(r.files)
-
-	// Подсчет общего размера всех файлов
-	totalSize := int64(0)
-	for _, fileInfo := range r.files {
-		totalSize += fileInfo.Size
-	}
-
-	return fileCount, totalSize, nil
+	return model.StorageStats{
+		Files:         r.usage.files,
+		Bytes:         r.usage.bytes,
+		LogicalBytes:  r.usage.stored,
+		PhysicalBytes: r.usage.physical,
+	}, nil
 }
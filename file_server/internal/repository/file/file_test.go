@@ -0,0 +1,628 @@
+package file
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"errors"
+	"file_server/internal/storage"
+	"file_server/internal/storage/fs"
+	"file_server/internal/storage/memory"
+	"file_server/pkg/model"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// openRepo создает репозиторий поверх файлового хранилища в директории dir и закрывает его по окончании теста
+func openRepo(t *testing.T, dir string) *Repository {
+	t.Helper()
+	blobs, err := fs.NewBlobStore(dir, fs.DefaultShardDepth)
+	if err != nil {
+		t.Fatalf("NewBlobStore: %v", err)
+	}
+	meta, err := fs.OpenMetaStore(dir)
+	if err != nil {
+		t.Fatalf("OpenMetaStore: %v", err)
+	}
+	repo, err := NewRepo(blobs, meta)
+	if err != nil {
+		t.Fatalf("NewRepo: %v", err)
+	}
+	t.Cleanup(func() { repo.Close() })
+	return repo
+}
+
+// countingBlobs - хранилище содержимого, считающее записи
+type countingBlobs struct {
+	storage.BlobStore
+	puts atomic.Int32
+}
+
+func (b *countingBlobs) Put(id string, data []byte) error {
+	b.puts.Add(1)
+	return b.BlobStore.Put(id, data)
+}
+
+// listing возвращает список файлов, отсортированный по ID
+func listing(t *testing.T, repo *Repository) []model.FileInfo {
+	t.Helper()
+	files, err := repo.ListFiles()
+	if err != nil {
+		t.Fatalf("ListFiles: %v", err)
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].ID < files[j].ID })
+	return files
+}
+
+// assertSameListing сравнивает списки файлов (время сравнивается через Equal, т.к. JSON не сохраняет монотонные часы)
+func assertSameListing(t *testing.T, want, got []model.FileInfo) {
+	t.Helper()
+	if len(want) != len(got) {
+		t.Fatalf("listing has %d files, want %d", len(got), len(want))
+	}
+	for i := range want {
+		w, g := want[i], got[i]
+		if !w.CreatedAt.Equal(g.CreatedAt) || !w.UpdatedAt.Equal(g.UpdatedAt) {
+			t.Errorf("file %s: times %v/%v, want %v/%v", w.ID, g.CreatedAt, g.UpdatedAt, w.CreatedAt, w.UpdatedAt)
+		}
+		w.CreatedAt, w.UpdatedAt = g.CreatedAt, g.UpdatedAt
+		if !reflect.DeepEqual(w, g) {
+			t.Errorf("file %s:\n got %+v\nwant %+v", w.ID, g, w)
+		}
+	}
+}
+
+func TestRepositoryRestartPreservesMetadata(t *testing.T) {
+	dir := t.TempDir()
+	repo := openRepo(t, dir)
+
+	photoID, err := repo.SaveFile("photo.jpg", []byte("photo"), "")
+	if err != nil {
+		t.Fatalf("SaveFile: %v", err)
+	}
+	if _, err := repo.SaveFile("notes.txt", []byte("notes"), ""); err != nil {
+		t.Fatalf("SaveFile: %v", err)
+	}
+	removedID, err := repo.SaveFile("removed.bin", []byte("removed"), "")
+	if err != nil {
+		t.Fatalf("SaveFile: %v", err)
+	}
+	if err := repo.UpdateFileInfo(photoID, func(info *model.FileInfo) {
+		info.Palette = []string{"#ff0000"}
+		info.Variants = []model.Variant{{Kind: "optimized", FileID: "other"}}
+	}); err != nil {
+		t.Fatalf("UpdateFileInfo: %v", err)
+	}
+	if err := repo.DeleteFile(removedID); err != nil {
+		t.Fatalf("DeleteFile: %v", err)
+	}
+
+	want := listing(t, repo)
+	repo.Close()
+
+	// Перезапуск репозитория
+	restarted := openRepo(t, dir)
+	got := listing(t, restarted)
+	assertSameListing(t, want, got)
+
+	if got[0].Filename == got[0].ID || got[1].Filename == got[1].ID {
+		t.Errorf("original filenames were lost: %+v", got)
+	}
+}
+
+func TestRepositoryAdoptsFilesWithoutJournal(t *testing.T) {
+	dir := t.TempDir()
+
+	// Файл, сохраненный до появления журнала
+	if err := os.WriteFile(filepath.Join(dir, "legacy"), []byte("legacy"), 0644); err != nil {
+		t.Fatalf("write legacy file: %v", err)
+	}
+
+	repo := openRepo(t, dir)
+	files := listing(t, repo)
+	if len(files) != 1 || files[0].ID != "legacy" || files[0].Size != int64(len("legacy")) {
+		t.Fatalf("legacy file not loaded: %+v", files)
+	}
+	repo.Close()
+
+	// Файл, удаленный с диска при остановленном сервере, исчезает из списка
+	stored, _ := filepath.Glob(filepath.Join(dir, "*", "*", "legacy"))
+	if len(stored) != 1 {
+		t.Fatalf("legacy file was not moved into a shard: %v", stored)
+	}
+	os.Remove(stored[0])
+	if files := listing(t, openRepo(t, dir)); len(files) != 0 {
+		t.Fatalf("missing file is still listed: %+v", files)
+	}
+}
+
+func TestRepositoryCoalescesConcurrentUploads(t *testing.T) {
+	blobs := &countingBlobs{BlobStore: memory.NewBlobStore()}
+	repo, err := NewRepo(blobs, memory.NewMetaStore())
+	if err != nil {
+		t.Fatalf("NewRepo: %v", err)
+	}
+
+	const uploaders = 16
+	data := bytes.Repeat([]byte("x"), 1<<20)
+	ids := make(chan string, uploaders)
+	errs := make(chan error, uploaders)
+	for i := 0; i < uploaders; i++ {
+		go func() {
+			id, err := repo.SaveFile(fmt.Sprintf("same-%d.bin", i), data, "")
+			ids <- id
+			errs <- err
+		}()
+	}
+
+	// Каждая загрузка - отдельный файл
+	seen := make(map[string]bool)
+	for i := 0; i < uploaders; i++ {
+		if err := <-errs; err != nil {
+			t.Fatalf("SaveFile: %v", err)
+		}
+		seen[<-ids] = true
+	}
+	if len(seen) != uploaders {
+		t.Errorf("%d uploads returned %d distinct IDs", uploaders, len(seen))
+	}
+
+	// Содержимое записано один раз и учтено всеми файлами
+	if puts := blobs.puts.Load(); puts != 1 {
+		t.Errorf("content written %d times, want 1", puts)
+	}
+	if refs := repo.refs[model.NewBlobID(data)]; refs != uploaders {
+		t.Errorf("content has %d references, want %d", refs, uploaders)
+	}
+}
+
+// gatedBlobs - хранилище содержимого, задерживающее первую запись до закрытия release
+type gatedBlobs struct {
+	storage.BlobStore
+	gated   atomic.Bool
+	after   bool          // Задерживать возврат из первой записи, а не ее начало
+	started chan struct{} // Закрывается, когда первая запись началась
+	release chan struct{} // Закрывается тестом, чтобы завершить первую запись
+}
+
+func (b *gatedBlobs) Put(id string, data []byte) error {
+	if !b.gated.CompareAndSwap(false, true) {
+		return b.BlobStore.Put(id, data)
+	}
+	var err error
+	if b.after {
+		err = b.BlobStore.Put(id, data)
+	}
+	close(b.started)
+	<-b.release
+	if !b.after {
+		err = b.BlobStore.Put(id, data)
+	}
+	return err
+}
+
+func TestRepositoryCoalescedUploadsFailIndependently(t *testing.T) {
+	blobs := &gatedBlobs{BlobStore: memory.NewBlobStore(), started: make(chan struct{}), release: make(chan struct{})}
+	repo, err := NewRepo(blobs, memory.NewMetaStore())
+	if err != nil {
+		t.Fatalf("NewRepo: %v", err)
+	}
+	repo.SetLimits(Limits{Clients: map[string]model.Quota{"alice": {MaxFiles: 1}}})
+	data := []byte("shared content")
+
+	// Загрузка alice записывает содержимое, загрузка bob того же содержимого ждет ее
+	type result struct {
+		id  string
+		err error
+	}
+	alice, bob := make(chan result, 1), make(chan result, 1)
+	go func() {
+		id, err := repo.SaveFile("a.txt", data, "alice")
+		alice <- result{id, err}
+	}()
+	<-blobs.started
+	go func() {
+		id, err := repo.SaveFile("b.txt", data, "bob")
+		bob <- result{id, err}
+	}()
+	time.Sleep(50 * time.Millisecond)
+
+	// Пока содержимое записывается, alice исчерпывает свое ограничение другим файлом
+	if _, err := repo.SaveFile("other.txt", []byte("other content"), "alice"); err != nil {
+		t.Fatalf("SaveFile: %v", err)
+	}
+	close(blobs.release)
+
+	// Отказ по ограничению alice не относится к загрузке bob
+	assertQuotaError(t, (<-alice).err, "client files")
+	got := <-bob
+	if got.err != nil {
+		t.Fatalf("SaveFile by bob = %v, want success", got.err)
+	}
+	if file, err := repo.GetFile(got.id); err != nil || !bytes.Equal(file.Data, data) {
+		t.Errorf("GetFile of bob's upload = %v", err)
+	}
+}
+
+func TestRepositorySharesContentBetweenFiles(t *testing.T) {
+	dir := t.TempDir()
+	repo := openRepo(t, dir)
+	data := []byte("shared photo")
+	blobID := model.NewBlobID(data)
+
+	// Одинаковое содержимое под разными именами от разных владельцев
+	aliceID, err := repo.SaveFile("alice.png", data, "alice")
+	if err != nil {
+		t.Fatalf("SaveFile: %v", err)
+	}
+	bobID, err := repo.SaveFile("bob.png", data, "bob")
+	if err != nil {
+		t.Fatalf("SaveFile: %v", err)
+	}
+	carolID, err := repo.SaveFile("carol.png", data, "carol")
+	if err != nil {
+		t.Fatalf("SaveFile: %v", err)
+	}
+	if aliceID == bobID || bobID == carolID {
+		t.Fatalf("uploads share an ID: %s, %s, %s", aliceID, bobID, carolID)
+	}
+	bob, err := repo.GetFileInfo(bobID)
+	if err != nil || bob.Filename != "bob.png" || bob.Owner != "bob" || bob.BlobID != blobID {
+		t.Fatalf("second upload = %+v, %v", bob, err)
+	}
+
+	// Удаление одного файла не затрагивает содержимое остальных
+	if err := repo.DeleteFile(aliceID); err != nil {
+		t.Fatalf("DeleteFile: %v", err)
+	}
+	if got, err := repo.GetFile(bobID); err != nil || string(got.Data) != string(data) {
+		t.Fatalf("GetFile after deleting another copy: %v", err)
+	}
+	repo.Close()
+
+	// Счетчик ссылок восстанавливается при перезапуске
+	repo = openRepo(t, dir)
+	if refs := repo.refs[blobID]; refs != 2 {
+		t.Fatalf("after restart content has %d references, want 2", refs)
+	}
+	if err := repo.DeleteFile(bobID); err != nil {
+		t.Fatalf("DeleteFile: %v", err)
+	}
+	if _, err := repo.blobs.Stat(blobID); err != nil {
+		t.Fatalf("content removed while still referenced: %v", err)
+	}
+
+	// Содержимое удаляется вместе с последним файлом
+	if err := repo.DeleteFile(carolID); err != nil {
+		t.Fatalf("DeleteFile: %v", err)
+	}
+	if _, err := repo.blobs.Stat(blobID); !errors.Is(err, storage.ErrBlobNotFound) {
+		t.Errorf("content still stored after last file deleted: %v", err)
+	}
+	repo.Close()
+	if files := listing(t, openRepo(t, dir)); len(files) != 0 {
+		t.Errorf("deleted files came back after restart: %+v", files)
+	}
+}
+
+func TestRepositoryConcurrentSaveAndDelete(t *testing.T) {
+	blobs := memory.NewBlobStore()
+	repo, err := NewRepo(blobs, memory.NewMetaStore())
+	if err != nil {
+		t.Fatalf("NewRepo: %v", err)
+	}
+	data := []byte("contended")
+	blobID := model.NewBlobID(data)
+
+	// Файл, который держит содержимое на протяжении всего теста
+	keeperID, err := repo.SaveFile("keeper.bin", data, "")
+	if err != nil {
+		t.Fatalf("SaveFile: %v", err)
+	}
+
+	// Параллельные загрузки и удаления копий того же содержимого
+	const workers = 8
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				id, err := repo.SaveFile(fmt.Sprintf("copy-%d-%d.bin", i, j), data, "")
+				if err != nil {
+					t.Errorf("SaveFile: %v", err)
+					return
+				}
+				if err := repo.DeleteFile(id); err != nil {
+					t.Errorf("DeleteFile: %v", err)
+					return
+				}
+				if _, err := repo.GetFile(keeperID); err != nil {
+					t.Errorf("GetFile(keeper) while copies come and go: %v", err)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	if refs := repo.refs[blobID]; refs != 1 {
+		t.Fatalf("content has %d references, want 1", refs)
+	}
+
+	// Параллельное удаление всех копий удаляет содержимое ровно тогда, когда удалена последняя
+	ids := []string{keeperID}
+	for i := 0; i < workers; i++ {
+		id, err := repo.SaveFile(fmt.Sprintf("last-%d.bin", i), data, "")
+		if err != nil {
+			t.Fatalf("SaveFile: %v", err)
+		}
+		ids = append(ids, id)
+	}
+	for _, id := range ids {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := repo.DeleteFile(id); err != nil {
+				t.Errorf("DeleteFile: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if _, err := blobs.Stat(blobID); !errors.Is(err, storage.ErrBlobNotFound) {
+		t.Errorf("content still stored after all files deleted: %v", err)
+	}
+	if len(repo.refs) != 0 || len(repo.files) != 0 {
+		t.Errorf("repository state not empty: refs %v, files %d", repo.refs, len(repo.files))
+	}
+}
+
+// fullDisk - проверка свободного места, отклоняющая любую запись
+type fullDisk struct{}
+
+func (fullDisk) Check(int64) error { return storage.ErrLowDiskSpace }
+
+func TestRepositoryRefusesUploadsOnLowDiskSpace(t *testing.T) {
+	blobs := memory.NewBlobStore()
+	repo, err := NewRepo(blobs, memory.NewMetaStore())
+	if err != nil {
+		t.Fatalf("NewRepo: %v", err)
+	}
+	keepID, err := repo.SaveFile("keep.bin", []byte("keep"), "")
+	if err != nil {
+		t.Fatalf("SaveFile: %v", err)
+	}
+	removeID, err := repo.SaveFile("remove.bin", []byte("remove"), "")
+	if err != nil {
+		t.Fatalf("SaveFile: %v", err)
+	}
+
+	repo.SetSpaceGuard(fullDisk{})
+
+	// Загрузки отклоняются, в том числе копии уже сохраненного содержимого
+	for _, data := range []string{"new", "keep"} {
+		if _, err := repo.SaveFile("x.bin", []byte(data), ""); !errors.Is(err, storage.ErrLowDiskSpace) {
+			t.Errorf("SaveFile(%q) on full disk: %v, want ErrLowDiskSpace", data, err)
+		}
+	}
+
+	// Чтение и удаление продолжают работать
+	if _, err := repo.GetFile(keepID); err != nil {
+		t.Errorf("GetFile on full disk: %v", err)
+	}
+	if err := repo.DeleteFile(removeID); err != nil {
+		t.Errorf("DeleteFile on full disk: %v", err)
+	}
+	if stats, _ := repo.GetStats(); stats.Files != 1 {
+		t.Errorf("%d files after refused uploads and delete, want 1", stats.Files)
+	}
+}
+
+// writeLegacyFile записывает файл под MD5 ID, как его сохраняли до перехода на SHA-256
+func writeLegacyFile(t *testing.T, dir string, data []byte) string {
+	t.Helper()
+	hash := md5.Sum(data)
+	legacyID := hex.EncodeToString(hash[:])
+	if err := os.WriteFile(filepath.Join(dir, legacyID), data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	return legacyID
+}
+
+// failingMeta - хранилище метаданных, отклоняющее запись файла с заданным именем
+type failingMeta struct {
+	storage.MetaStore
+	filename string
+}
+
+func (m *failingMeta) Put(info *model.FileInfo) error {
+	if info.Filename == m.filename {
+		return errors.New("injected failure")
+	}
+	return m.MetaStore.Put(info)
+}
+
+// countingMeta - хранилище метаданных, считающее записи
+type countingMeta struct {
+	storage.MetaStore
+	puts atomic.Int32
+}
+
+func (m *countingMeta) Put(info *model.FileInfo) error {
+	m.puts.Add(1)
+	return m.MetaStore.Put(info)
+}
+
+func TestRepositorySaveFileWithInfoWritesOnce(t *testing.T) {
+	meta := &countingMeta{MetaStore: memory.NewMetaStore()}
+	repo, err := NewRepo(memory.NewBlobStore(), meta)
+	if err != nil {
+		t.Fatalf("NewRepo: %v", err)
+	}
+	expiresAt := time.Now().Add(time.Hour).UTC()
+
+	fileID, err := repo.SaveFileWithInfo("photo.png", []byte("photo"), "alice", func(info *model.FileInfo) {
+		info.Format = "png"
+		info.ExpiresAt = &expiresAt
+	})
+	if err != nil {
+		t.Fatalf("SaveFileWithInfo: %v", err)
+	}
+	if puts := meta.puts.Load(); puts != 1 {
+		t.Errorf("%d metadata writes, want 1", puts)
+	}
+
+	// Дополненные метаданные сохранены вместе с файлом
+	restarted, err := NewRepo(repo.blobs, meta.MetaStore)
+	if err != nil {
+		t.Fatalf("NewRepo: %v", err)
+	}
+	info, err := restarted.GetFileInfo(fileID)
+	if err != nil || info.Format != "png" || info.ExpiresAt == nil || !info.ExpiresAt.Equal(expiresAt) || info.Owner != "alice" {
+		t.Errorf("GetFileInfo after restart = %+v, %v", info, err)
+	}
+}
+
+func TestRepositoryMigrationCopySurvivesFailedUpload(t *testing.T) {
+	dir := t.TempDir()
+	data := []byte("legacy photo")
+	legacyID := writeLegacyFile(t, dir, data)
+	inner, err := fs.NewBlobStore(dir, fs.DefaultShardDepth)
+	if err != nil {
+		t.Fatalf("NewBlobStore: %v", err)
+	}
+	blobs := &gatedBlobs{BlobStore: inner, after: true, started: make(chan struct{}), release: make(chan struct{})}
+	repo, err := NewRepo(blobs, &failingMeta{MetaStore: memory.NewMetaStore(), filename: "fail.jpg"})
+	if err != nil {
+		t.Fatalf("NewRepo: %v", err)
+	}
+
+	// Миграция записала копию под новым ID, но еще не переключила на нее файл
+	migrated := make(chan error, 1)
+	go func() {
+		_, err := repo.MigrateIDs(context.Background())
+		migrated <- err
+	}()
+	<-blobs.started
+
+	// Неудачная загрузка того же содержимого не удаляет копию миграции
+	uploaded := make(chan error, 1)
+	go func() {
+		_, err := repo.SaveFile("fail.jpg", data, "")
+		uploaded <- err
+	}()
+	time.Sleep(50 * time.Millisecond)
+	close(blobs.release)
+	if err := <-migrated; err != nil {
+		t.Fatalf("MigrateIDs: %v", err)
+	}
+	if err := <-uploaded; err == nil {
+		t.Fatal("SaveFile with failing metadata succeeded")
+	}
+
+	if file, err := repo.GetFile(legacyID); err != nil || !bytes.Equal(file.Data, data) {
+		t.Errorf("GetFile after migration = %v", err)
+	}
+}
+
+func TestRepositoryMigratesLegacyIDs(t *testing.T) {
+	dir := t.TempDir()
+	photoID := writeLegacyFile(t, dir, []byte("photo"))
+	variantID := writeLegacyFile(t, dir, []byte("photo variant"))
+	notesID := writeLegacyFile(t, dir, []byte("notes"))
+	corruptID := writeLegacyFile(t, dir, []byte("corrupt"))
+	if err := os.WriteFile(filepath.Join(dir, corruptID), []byte("tampered"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	repo := openRepo(t, dir)
+	if err := repo.UpdateFileInfo(photoID, func(info *model.FileInfo) {
+		info.Filename = "photo.jpg"
+		info.Variants = []model.Variant{{Kind: "optimized", FileID: variantID}}
+	}); err != nil {
+		t.Fatalf("UpdateFileInfo: %v", err)
+	}
+	if err := repo.UpdateFileInfo(variantID, func(info *model.FileInfo) { info.VariantOf = photoID }); err != nil {
+		t.Fatalf("UpdateFileInfo: %v", err)
+	}
+
+	// Содержимое, загруженное заново до миграции, после миграции разделяется с прежней копией
+	notesNewID, err := repo.SaveFile("notes-again.txt", []byte("notes"), "")
+	if err != nil {
+		t.Fatalf("SaveFile: %v", err)
+	}
+
+	migrated, err := repo.MigrateIDs(context.Background())
+	if err != nil {
+		t.Fatalf("MigrateIDs: %v", err)
+	}
+	if migrated != 3 {
+		t.Errorf("migrated %d files, want 3 (corrupt file skipped)", migrated)
+	}
+
+	// Прежние ID разрешаются в перенесенные файлы, оба хэша сохранены
+	photo, err := repo.GetFile(photoID)
+	if err != nil {
+		t.Fatalf("GetFile(legacy ID): %v", err)
+	}
+	wantID := model.NewBlobID([]byte("photo"))
+	if photo.Info.ID != wantID || photo.Info.Filename != "photo.jpg" || string(photo.Data) != "photo" {
+		t.Errorf("legacy ID resolved to %s %q %q", photo.Info.ID, photo.Info.Filename, photo.Data)
+	}
+	if !reflect.DeepEqual(photo.Info.Digests, model.ComputeDigests([]byte("photo"))) {
+		t.Errorf("digests = %v", photo.Info.Digests)
+	}
+	if !reflect.DeepEqual(photo.Info.LegacyIDs, []string{photoID}) {
+		t.Errorf("legacy IDs = %v", photo.Info.LegacyIDs)
+	}
+	if got := photo.Info.Variants[0].FileID; got != model.NewBlobID([]byte("photo variant")) {
+		t.Errorf("variant link = %s, want migrated ID", got)
+	}
+	if _, err := os.Stat(filepath.Join(dir, photoID)); !os.IsNotExist(err) {
+		t.Errorf("legacy file still on disk: %v", err)
+	}
+
+	notes, err := repo.GetFileInfo(notesID)
+	if err != nil {
+		t.Fatalf("GetFileInfo(legacy ID): %v", err)
+	}
+	notesAgain, err := repo.GetFileInfo(notesNewID)
+	if err != nil {
+		t.Fatalf("GetFileInfo: %v", err)
+	}
+	if notes.ID == notesNewID || notes.BlobID != notesAgain.BlobID || notesAgain.Filename != "notes-again.txt" {
+		t.Errorf("notes = %s -> %s, notes again = %s -> %s", notes.ID, notes.BlobID, notesAgain.ID, notesAgain.BlobID)
+	}
+	if refs := repo.refs[notes.BlobID]; refs != 2 {
+		t.Errorf("migrated notes content has %d references, want 2", refs)
+	}
+	if _, err := repo.GetFileInfo(corruptID); err != nil {
+		t.Errorf("corrupt file should stay under its legacy ID: %v", err)
+	}
+
+	// Псевдонимы переживают перезапуск
+	want := listing(t, repo)
+	repo.Close()
+	repo = openRepo(t, dir)
+	assertSameListing(t, want, listing(t, repo))
+	if info, err := repo.GetFileInfo(variantID); err != nil || info.VariantOf != wantID {
+		t.Errorf("after restart variant = %+v, %v", info, err)
+	}
+
+	// Удаление по прежнему ID удаляет перенесенный файл
+	if err := repo.DeleteFile(photoID); err != nil {
+		t.Fatalf("DeleteFile: %v", err)
+	}
+	if _, err := repo.GetFile(wantID); err == nil {
+		t.Errorf("file still present after delete by legacy ID")
+	}
+}
@@ -0,0 +1,325 @@
+// fsck.go - проверка целостности хранилища
+// Сверяет метаданные файлов с сохраненным содержимым, проверяет, что содержимое совпадает со своим ID,
+// и находит временные файлы прерванных записей
+// Проверка выполняется без остановки сервера: каждое нарушение перепроверяется под блокировкой перед включением в отчет
+package file
+
+import (
+	"context"
+	"errors"
+	"file_server/internal/storage"
+	"file_server/pkg/model"
+	"fmt"
+	"maps"
+	"slices"
+	"sort"
+	"time"
+)
+
+// staleTempAge - возраст, после которого временный файл считается оставшимся от прерванной записи
+const staleTempAge = time.Hour
+
+// Fsck проверяет целостность хранилища и возвращает отчет
+// При repair = true найденные нарушения исправляются:
+//   - метаданные файлов без содержимого удаляются
+//   - содержимое без файлов добавляется как файл (так же, как при запуске)
+//   - содержимое, названное не своим хэшем, переносится под SHA-256 ID (см. migrate.go)
+//   - поврежденное содержимое переносится в карантин, а ссылающиеся файлы помечаются поврежденными (см. scrub.go)
+//   - пустое содержимое удаляется вместе со ссылающимися файлами (восстановить его нельзя)
+//   - временные файлы прерванных записей удаляются
+func (r *Repository) Fsck(ctx context.Context, repair bool) (*model.FsckReport, error) {
+	report := &model.FsckReport{StartedAt: time.Now(), Repair: repair}
+
+	// Временные файлы проверяются до обхода содержимого
+	if err := r.checkTempFiles(report, repair); err != nil {
+		return nil, err
+	}
+
+	// Обход сохраненного содержимого
+	stored := make(map[string]storage.BlobInfo)
+	err := r.blobs.Iterate(func(blob storage.BlobInfo) error {
+		// Проверка контекста на отмену операции
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+		stored[blob.ID] = blob
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("FAILED TO SCAN STORAGE: %w", err)
+	}
+	report.Blobs = len(stored)
+
+	// Проверка каждого содержимого (в порядке ID, чтобы отчет не зависел от порядка обхода)
+	moved := false
+	for _, blobID := range slices.Sorted(maps.Keys(stored)) {
+		// Проверка контекста на отмену операции
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		default:
+		}
+
+		renamed, err := r.checkBlob(report, stored[blobID], repair)
+		if err != nil {
+			return nil, err
+		}
+		moved = moved || renamed
+	}
+
+	// Ссылки вариантов на прежние ID перенесенных файлов заменяются текущими
+	if moved {
+		if err := r.rewriteReferences(); err != nil {
+			return nil, err
+		}
+	}
+
+	// Файлы, содержимого которых нет
+	if err := r.checkMissingBlobs(report, stored, repair); err != nil {
+		return nil, err
+	}
+
+	report.Duration = time.Since(report.StartedAt)
+	return report, nil
+}
+
+// checkBlob проверяет одно содержимое: размер, наличие ссылающихся файлов и совпадение с ID
+// Возвращает true, если содержимое перенесено под новый ID
+func (r *Repository) checkBlob(report *model.FsckReport, blob storage.BlobInfo, repair bool) (bool, error) {
+	// Пустое содержимое не может быть загружено - это след прерванной записи или повреждения
+	if blob.Size == 0 {
+		r.mutex.Lock()
+		defer r.mutex.Unlock()
+		issue := model.FsckIssue{
+			Kind:    model.FsckEmptyBlob,
+			BlobID:  blob.ID,
+			FileIDs: r.blobFiles(blob.ID),
+			Detail:  "content is empty",
+			Action:  "remove content and files referencing it",
+		}
+		return false, r.dropBlob(report, issue, repair)
+	}
+
+	// Содержимое без ссылок (незавершенные записи пропускаются: их метаданные сохраняются после содержимого)
+	r.mutex.Lock()
+	if r.refs[blob.ID] == 0 && r.inflight[blob.ID] == nil {
+		issue := model.FsckIssue{
+			Kind:   model.FsckUnindexedBlob,
+			BlobID: blob.ID,
+			Detail: fmt.Sprintf("%d bytes, no file references this content", blob.Size),
+			Action: "add as file",
+		}
+		if repair {
+			info, err := r.adoptBlob(blob)
+			if err != nil {
+				r.mutex.Unlock()
+				return false, fmt.Errorf("FAILED TO SAVE FILE METADATA: %w", err)
+			}
+			r.retain(info)
+			issue.FileIDs = []string{info.ID}
+			issue.Repaired = true
+		}
+		report.Issues = append(report.Issues, issue)
+	}
+	r.mutex.Unlock()
+
+	// Сверка содержимого с ID (чтение без блокировки: содержимое с данным ID не изменяется)
+	data, detail, misnamed, err := r.readBlob(blob.ID)
+	if err != nil || detail == "" {
+		return false, err // Содержимое совпадает с ID или удалено во время проверки
+	}
+
+	// Содержимое под именем, не являющимся хэшем (например, добавленное вручную), переносится под SHA-256 ID
+	if misnamed {
+		r.mutex.RLock()
+		issue := model.FsckIssue{
+			Kind:    model.FsckHashMismatch,
+			BlobID:  blob.ID,
+			FileIDs: r.blobFiles(blob.ID),
+			Detail:  detail,
+			Action:  "move content to " + model.NewBlobID(data),
+		}
+		r.mutex.RUnlock()
+		if repair && len(issue.FileIDs) > 0 {
+			switched, err := r.migrateBlob(blob.ID)
+			if err != nil {
+				return false, err
+			}
+			issue.Repaired = switched > 0
+		}
+		report.Issues = append(report.Issues, issue)
+		return issue.Repaired, nil
+	}
+
+	// Поврежденное содержимое перепроверяется под блокировкой, чтобы не изолировать содержимое, записанное заново
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if _, detail, _, err = r.readBlob(blob.ID); err != nil || detail == "" {
+		return false, err
+	}
+	issue := model.FsckIssue{
+		Kind:    model.FsckHashMismatch,
+		BlobID:  blob.ID,
+		FileIDs: r.blobFiles(blob.ID),
+		Detail:  detail,
+		Action:  "quarantine content and mark files corrupted",
+	}
+	if repair {
+		if _, err := r.quarantineBlob(blob.ID); err != nil {
+			return false, err
+		}
+		issue.Repaired = true
+	}
+	report.Issues = append(report.Issues, issue)
+	return false, nil
+}
+
+// verifyBlob проверяет, что содержимое совпадает со своим ID
+// Возвращает описание расхождения (пусто, если совпадает) и признак того, что ID вообще не является хэшем
+func verifyBlob(blobID string, data []byte) (string, bool) {
+	switch model.BlobIDAlgorithm(blobID) {
+	case model.DigestSHA256:
+		if actual := model.NewBlobID(data); actual != blobID {
+			return "content hashes to " + actual, false
+		}
+	case model.DigestMD5:
+		if actual := model.ComputeDigests(data)[model.DigestMD5]; actual != blobID {
+			return "content MD5 is " + actual, false
+		}
+	default:
+		return "name is not a content hash", true
+	}
+	return "", false
+}
+
+// blobFiles возвращает отсортированные ID файлов, ссылающихся на содержимое
+// Вызывается под блокировкой mutex
+func (r *Repository) blobFiles(blobID string) []string {
+	var fileIDs []string
+	for fileID, info := range r.files {
+		if info.BlobID == blobID {
+			fileIDs = append(fileIDs, fileID)
+		}
+	}
+	sort.Strings(fileIDs)
+	return fileIDs
+}
+
+// dropBlob добавляет нарушение в отчет и при repair = true удаляет содержимое и ссылающиеся на него файлы
+// Содержимое удаляется раньше метаданных (как в DeleteFile)
+// Вызывается под блокировкой mutex
+func (r *Repository) dropBlob(report *model.FsckReport, issue model.FsckIssue, repair bool) error {
+	if repair {
+		if err := r.blobs.Delete(issue.BlobID); err != nil {
+			return fmt.Errorf("FAILED TO DELETE FILE %s: %w", issue.BlobID, err)
+		}
+		for _, fileID := range issue.FileIDs {
+			if err := r.meta.Delete(fileID); err != nil {
+				return fmt.Errorf("FAILED TO SAVE FILE METADATA: %w", err)
+			}
+			r.forget(fileID)
+		}
+		issue.Repaired = true
+	}
+	report.Issues = append(report.Issues, issue)
+	return nil
+}
+
+// checkMissingBlobs находит файлы, содержимого которых нет в хранилище
+// stored - содержимое, найденное обходом хранилища
+func (r *Repository) checkMissingBlobs(report *model.FsckReport, stored map[string]storage.BlobInfo, repair bool) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	report.Files = len(r.files)
+
+	// Группировка файлов по отсутствующему содержимому
+	missing := make(map[string][]string)
+	// Поврежденные файлы пропускаются: их содержимое в карантине до повторной загрузки
+	for fileID, info := range r.files {
+		if _, exists := stored[info.BlobID]; !exists && !info.Corrupted {
+			missing[info.BlobID] = append(missing[info.BlobID], fileID)
+		}
+	}
+
+	for _, blobID := range slices.Sorted(maps.Keys(missing)) {
+		// Содержимое могло быть записано после обхода (новая загрузка или перенос миграцией)
+		if _, err := r.blobs.Stat(blobID); !errors.Is(err, storage.ErrBlobNotFound) {
+			if err != nil {
+				return fmt.Errorf("FAILED TO STAT FILE %s: %w", blobID, err)
+			}
+			continue
+		}
+
+		fileIDs := missing[blobID]
+		sort.Strings(fileIDs)
+		issue := model.FsckIssue{
+			Kind:    model.FsckMissingBlob,
+			BlobID:  blobID,
+			FileIDs: fileIDs,
+			Detail:  "content is missing from storage",
+			Action:  "remove file metadata",
+		}
+		if repair {
+			for _, fileID := range fileIDs {
+				if err := r.meta.Delete(fileID); err != nil {
+					return fmt.Errorf("FAILED TO SAVE FILE METADATA: %w", err)
+				}
+				r.forget(fileID)
+			}
+			issue.Repaired = true
+		}
+		report.Issues = append(report.Issues, issue)
+	}
+
+	return nil
+}
+
+// checkTempFiles находит временные файлы прерванных записей и при repair = true удаляет их
+func (r *Repository) checkTempFiles(report *model.FsckReport, repair bool) error {
+	temps, err := r.staleTempFiles()
+	if err != nil {
+		return err
+	}
+
+	for _, temp := range temps {
+		issue := model.FsckIssue{
+			Kind:   model.FsckTempFile,
+			BlobID: temp.ID,
+			Detail: fmt.Sprintf("%d bytes, left by a write interrupted at %s", temp.Size, temp.ModTime.Format(time.RFC3339)),
+			Action: "delete",
+		}
+		if repair {
+			if err := r.blobs.(storage.TempFileStore).DeleteTempFile(temp.ID); err != nil {
+				return err
+			}
+			issue.Repaired = true
+		}
+		report.Issues = append(report.Issues, issue)
+	}
+
+	return nil
+}
+
+// staleTempFiles возвращает временные файлы старше staleTempAge, отсортированные по ID
+// Более новые файлы могут принадлежать идущим записям; хранилище без временных файлов возвращает пустой список
+func (r *Repository) staleTempFiles() ([]storage.BlobInfo, error) {
+	temps, ok := r.blobs.(storage.TempFileStore)
+	if !ok {
+		return nil, nil
+	}
+
+	all, err := temps.TempFiles()
+	if err != nil {
+		return nil, err
+	}
+	var stale []storage.BlobInfo
+	for _, temp := range all {
+		if time.Since(temp.ModTime) > staleTempAge {
+			stale = append(stale, temp)
+		}
+	}
+	sort.Slice(stale, func(i, j int) bool { return stale[i].ID < stale[j].ID })
+	return stale, nil
+}
@@ -0,0 +1,195 @@
+package file
+
+import (
+	"context"
+	"errors"
+	"file_server/internal/repository"
+	"file_server/internal/storage/fs"
+	"file_server/pkg/model"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// fsck выполняет проверку целостности и завершает тест при ошибке
+func fsck(t *testing.T, repo *Repository, repair bool) *model.FsckReport {
+	t.Helper()
+	report, err := repo.Fsck(context.Background(), repair)
+	if err != nil {
+		t.Fatalf("Fsck: %v", err)
+	}
+	return report
+}
+
+// openCheckRepo открывает репозиторий для автономной проверки поверх файлового хранилища в директории dir
+func openCheckRepo(t *testing.T, dir string) *Repository {
+	t.Helper()
+	blobs, err := fs.NewBlobStore(dir, fs.DefaultShardDepth)
+	if err != nil {
+		t.Fatalf("NewBlobStore: %v", err)
+	}
+	meta, err := fs.OpenMetaStore(dir)
+	if err != nil {
+		t.Fatalf("OpenMetaStore: %v", err)
+	}
+	repo, err := NewRepoForCheck(blobs, meta)
+	if err != nil {
+		t.Fatalf("NewRepoForCheck: %v", err)
+	}
+	return repo
+}
+
+// blobPath возвращает путь к файлу содержимого в хранилище dir
+func blobPath(t *testing.T, dir, blobID string) string {
+	t.Helper()
+	paths, _ := filepath.Glob(filepath.Join(dir, "*", "*", blobID))
+	if len(paths) != 1 {
+		t.Fatalf("content %s not found on disk: %v", blobID, paths)
+	}
+	return paths[0]
+}
+
+// issueKinds возвращает виды нарушений отчета по ID содержимого
+func issueKinds(report *model.FsckReport) map[string][]string {
+	kinds := make(map[string][]string)
+	for _, issue := range report.Issues {
+		kinds[issue.BlobID] = append(kinds[issue.BlobID], issue.Kind)
+	}
+	return kinds
+}
+
+func TestRepositoryFsckFindsAndRepairsIssues(t *testing.T) {
+	dir := t.TempDir()
+	repo := openRepo(t, dir)
+
+	save := func(name, data string) *model.FileInfo {
+		t.Helper()
+		fileID, err := repo.SaveFile(name, []byte(data), "")
+		if err != nil {
+			t.Fatalf("SaveFile: %v", err)
+		}
+		info, _ := repo.GetFileInfo(fileID)
+		return info
+	}
+	healthy := save("healthy.txt", "healthy")
+	missing := save("missing.txt", "missing")
+	corrupted := save("corrupted.txt", "corrupted")
+
+	// Повреждения хранилища во время работы сервера
+	if err := os.Remove(blobPath(t, dir, missing.BlobID)); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(blobPath(t, dir, corrupted.BlobID), []byte("bit rot"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.blobs.Put("photo.jpg", []byte("added by hand")); err != nil {
+		t.Fatal(err)
+	}
+	emptyID := model.NewBlobID([]byte("truncated"))
+	if err := repo.blobs.Put(emptyID, []byte{}); err != nil {
+		t.Fatal(err)
+	}
+	temp := filepath.Join(filepath.Dir(blobPath(t, dir, healthy.BlobID)), ".tmp-leftover")
+	if err := os.WriteFile(temp, []byte("partial"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	old := time.Now().Add(-2 * staleTempAge)
+	os.Chtimes(temp, old, old)
+
+	// Отсутствующее содержимое не удаляет метаданные при чтении - это делает проверка
+	if _, err := repo.GetFile(missing.ID); !errors.Is(err, repository.ErrFileNotFound) {
+		t.Fatalf("GetFile of missing content: %v, want ErrFileNotFound", err)
+	}
+	if _, err := repo.GetFileInfo(missing.ID); err != nil {
+		t.Fatalf("metadata of missing content removed on read: %v", err)
+	}
+
+	// Проверка без исправления только сообщает о нарушениях
+	report := fsck(t, repo, false)
+	kinds := issueKinds(report)
+	want := map[string][]string{
+		missing.BlobID:                      {model.FsckMissingBlob},
+		corrupted.BlobID:                    {model.FsckHashMismatch},
+		"photo.jpg":                         {model.FsckUnindexedBlob, model.FsckHashMismatch},
+		emptyID:                             {model.FsckEmptyBlob},
+		filepath.ToSlash(temp[len(dir)+1:]): {model.FsckTempFile},
+	}
+	if len(kinds) != len(want) {
+		t.Errorf("report issues = %v, want %v", kinds, want)
+	}
+	for blobID, wantKinds := range want {
+		if got := kinds[blobID]; len(got) != len(wantKinds) || (len(got) > 0 && got[0] != wantKinds[0]) {
+			t.Errorf("issues of %s = %v, want %v", blobID, got, wantKinds)
+		}
+	}
+	if report.Repaired() != 0 {
+		t.Errorf("check-only run repaired %d issues", report.Repaired())
+	}
+	if _, err := os.Stat(temp); err != nil {
+		t.Errorf("check-only run removed temp file: %v", err)
+	}
+
+	// Исправление
+	report = fsck(t, repo, true)
+	if report.Repaired() != len(report.Issues) {
+		t.Errorf("repaired %d of %d issues: %+v", report.Repaired(), len(report.Issues), report.Issues)
+	}
+	if _, err := repo.GetFileInfo(missing.ID); !errors.Is(err, repository.ErrFileNotFound) {
+		t.Errorf("file without content still present: %v", err)
+	}
+	if _, err := repo.GetFile(corrupted.ID); !errors.Is(err, repository.ErrFileCorrupted) {
+		t.Errorf("GetFile of quarantined content: %v, want ErrFileCorrupted", err)
+	}
+	if quarantined, _ := filepath.Glob(filepath.Join(dir, ".quarantine", corrupted.BlobID+"-*")); len(quarantined) != 1 {
+		t.Errorf("corrupted content not quarantined: %v", quarantined)
+	}
+	if _, err := os.Stat(temp); !os.IsNotExist(err) {
+		t.Errorf("temp file not removed: %v", err)
+	}
+
+	// Содержимое, добавленное вручную, становится файлом под SHA-256 ID (прежнее имя - псевдоним)
+	adopted, err := repo.GetFile("photo.jpg")
+	if err != nil || string(adopted.Data) != "added by hand" || adopted.Info.BlobID != model.NewBlobID([]byte("added by hand")) {
+		t.Errorf("hand-added content = %+v, %v", adopted, err)
+	}
+	if got, err := repo.GetFile(healthy.ID); err != nil || string(got.Data) != "healthy" {
+		t.Errorf("healthy file damaged by repair: %v", err)
+	}
+	if stats, _ := repo.GetStats(); stats.Files != 3 || stats.Bytes != int64(len("healthy")+len("added by hand")+len("corrupted")) {
+		t.Errorf("GetStats = %+v after repair", stats)
+	}
+
+	// После исправления хранилище согласовано (поврежденный файл остается до удаления или повторной загрузки)
+	if report := fsck(t, repo, false); len(report.Issues) != 0 || report.Files != 3 || report.Blobs != 2 {
+		t.Errorf("second run = %+v", report)
+	}
+}
+
+func TestRepositoryFsckOffline(t *testing.T) {
+	dir := t.TempDir()
+	repo := openRepo(t, dir)
+	fileID, err := repo.SaveFile("gone.txt", []byte("gone"), "")
+	if err != nil {
+		t.Fatalf("SaveFile: %v", err)
+	}
+	info, _ := repo.GetFileInfo(fileID)
+	repo.Close()
+	os.Remove(blobPath(t, dir, info.BlobID))
+
+	// Репозиторий для автономной проверки не исправляет расхождения при загрузке
+	checked := openCheckRepo(t, dir)
+	report := fsck(t, checked, false)
+	if report.Count(model.FsckMissingBlob) != 1 || report.Issues[0].FileIDs[0] != fileID {
+		t.Fatalf("offline report = %+v", report)
+	}
+	if report := fsck(t, checked, false); report.Count(model.FsckMissingBlob) != 1 {
+		t.Errorf("check-only run changed storage: %+v", report)
+	}
+	checked.Close()
+
+	// Обычный запуск по-прежнему согласует хранилище сам
+	if files := listing(t, openRepo(t, dir)); len(files) != 0 {
+		t.Errorf("file without content loaded: %+v", files)
+	}
+}
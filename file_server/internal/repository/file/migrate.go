@@ -0,0 +1,293 @@
+// migrate.go - перевод содержимого на адресацию SHA-256
+// Содержимое файлов, загруженных до перехода на SHA-256, хранится под MD5 ID
+// Миграция выполняется в фоне без остановки сервера: содержимое перехэшируется и копируется под новым ID,
+// файлы переключаются на новое содержимое, в метаданных сохраняются оба хэша
+// Файлы, ID которых совпадает с прежним ID содержимого, получают новый ID, а прежний становится псевдонимом
+package file
+
+import (
+	"context"
+	"errors"
+	"file_server/internal/storage"
+	"file_server/pkg/model"
+	"fmt"
+	"log"
+	"slices"
+)
+
+// MigrateIDs переводит содержимое с ID старого формата на SHA-256
+// Возвращает количество перенесенных файлов
+// Содержимое, не совпадающее со своим MD5 ID, не переносится (повреждено)
+func (r *Repository) MigrateIDs(ctx context.Context) (int, error) {
+	// Снимок ID содержимого, которое нужно перенести
+	r.mutex.RLock()
+	var legacyIDs []string
+	for _, info := range r.files {
+		if model.BlobIDAlgorithm(info.BlobID) != model.DigestSHA256 && !slices.Contains(legacyIDs, info.BlobID) {
+			legacyIDs = append(legacyIDs, info.BlobID)
+		}
+	}
+	r.mutex.RUnlock()
+
+	// Перенос каждого содержимого
+	migrated := 0
+	for _, legacyID := range legacyIDs {
+		// Проверка контекста на отмену операции
+		select {
+		case <-ctx.Done():
+			return migrated, ctx.Err()
+		default:
+		}
+
+		moved, err := r.migrateBlob(legacyID)
+		if err != nil {
+			return migrated, err
+		}
+		migrated += moved
+	}
+
+	// Ссылки вариантов и версий на прежние ID заменяются текущими
+	if err := r.rewriteReferences(); err != nil {
+		return migrated, err
+	}
+
+	return migrated, nil
+}
+
+// migrateBlob переносит одно содержимое под SHA-256 ID и переключает на него ссылающиеся файлы
+// Возвращает количество переключенных файлов (0, если файлы удалены во время миграции или содержимое повреждено)
+func (r *Repository) migrateBlob(legacyID string) (int, error) {
+	// Чтение и перехэширование содержимого
+	data, err := r.blobs.Get(legacyID)
+	if errors.Is(err, storage.ErrBlobNotFound) {
+		return 0, nil // Файл удален во время миграции
+	}
+	if err != nil {
+		return 0, fmt.Errorf("FAILED TO READ FILE %s: %w", legacyID, err)
+	}
+	digests := model.ComputeDigests(data)
+
+	// Содержимое с MD5 ID должно совпадать с ID
+	if model.BlobIDAlgorithm(legacyID) == model.DigestMD5 && digests[model.DigestMD5] != legacyID {
+		log.Printf("ID migration: skipping %s, content does not match its MD5 digest", legacyID)
+		return 0, nil
+	}
+
+	// Копия содержимого под новым ID (прежнее содержимое остается доступным до переключения метаданных)
+	// Копия регистрируется как незавершенная запись, как в SaveFile: загрузка такого же содержимого ждет ее
+	// и не удаляет копию при своем сбое. Уже сохраненное под новым ID содержимое не перезаписывается,
+	// файлы переключаются на него под той же блокировкой, под которой проверено его наличие
+	blobID := model.NewBlobID(data)
+	var switched int
+	for {
+		r.mutex.Lock()
+		if r.refs[blobID] > 0 && !r.corrupt[blobID] {
+			switched, err = r.switchFiles(legacyID, blobID, digests, false)
+			r.mutex.Unlock()
+			if err != nil {
+				return 0, err
+			}
+			break
+		}
+		pending, writing := r.inflight[blobID]
+		if writing {
+			r.mutex.Unlock()
+			<-pending.done // Такое же содержимое уже записывается - ждем завершения и проверяем снова
+			continue
+		}
+		pending = &pendingWrite{done: make(chan struct{})}
+		r.inflight[blobID] = pending
+		r.mutex.Unlock()
+
+		switched, err = r.copyBlob(legacyID, blobID, data, digests, pending)
+		if err != nil {
+			return 0, err
+		}
+		break
+	}
+	if switched == 0 {
+		return 0, nil
+	}
+
+	// Удаление прежнего содержимого (читатели, успевшие найти его по прежнему ID, повторят чтение по новому)
+	if err := r.blobs.Delete(legacyID); err != nil {
+		log.Printf("ID migration: failed to remove %s: %v", legacyID, err)
+	}
+
+	return switched, nil
+}
+
+// copyBlob записывает копию содержимого под новым ID, зарегистрированную в inflight как pending,
+// переключает на нее файлы и снимает регистрацию
+func (r *Repository) copyBlob(legacyID, blobID string, data []byte, digests map[string]string, pending *pendingWrite) (int, error) {
+	// Снятие регистрации записи и оповещение ожидающих загрузчиков
+	defer func() {
+		r.mutex.Lock()
+		delete(r.inflight, blobID)
+		r.mutex.Unlock()
+		close(pending.done)
+	}()
+
+	if err := r.blobs.Put(blobID, data); err != nil {
+		return 0, fmt.Errorf("FAILED TO WRITE FILE %s: %w", blobID, err)
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.switchFiles(legacyID, blobID, digests, true)
+}
+
+// switchFiles переключает файлы с прежнего содержимого на содержимое blobID
+// Возвращает количество переключенных файлов; если файлы удалены во время миграции,
+// записанная миграцией копия (written = true) удаляется, когда на нее никто не ссылается
+// Вызывается под блокировкой mutex
+func (r *Repository) switchFiles(legacyID, blobID string, digests map[string]string, written bool) (int, error) {
+	var fileIDs []string
+	for fileID, info := range r.files {
+		if info.BlobID == legacyID {
+			fileIDs = append(fileIDs, fileID)
+		}
+	}
+	if len(fileIDs) == 0 {
+		if written && r.refs[blobID] == 0 {
+			r.blobs.Delete(blobID)
+		}
+		return 0, nil
+	}
+	storedSize := r.physical[blobID] // Содержимое уже загружено под новым ID - размер в хранилище уже учтен
+	if storedSize == 0 {
+		storedSize = r.storedSize(blobID)
+	}
+	for _, fileID := range fileIDs {
+		if err := r.switchBlob(fileID, blobID, digests, storedSize); err != nil {
+			return 0, err
+		}
+	}
+	return len(fileIDs), nil
+}
+
+// switchBlob переключает файл на перенесенное содержимое blobID (storedSize - его размер в хранилище)
+// Файл, ID которого совпадает с прежним ID содержимого, получает ID нового содержимого, прежний ID становится псевдонимом
+// Вызывается под блокировкой mutex
+func (r *Repository) switchBlob(fileID, blobID string, digests map[string]string, storedSize int64) error {
+	legacy := r.files[fileID]
+	info := *legacy
+	info.BlobID = blobID
+	info.Digests = digests
+	info.StoredSize = storedSize
+
+	// Файл с собственным ID только переключается на новое содержимое
+	if fileID != legacy.BlobID {
+		if err := r.meta.Put(&info); err != nil {
+			return fmt.Errorf("FAILED TO SAVE FILE METADATA: %w", err)
+		}
+		r.files[fileID] = &info
+		r.release(legacy)
+		r.retain(&info)
+		return nil
+	}
+
+	// Если такое же содержимое уже загружено под новым ID, метаданные объединяются
+	newID := blobID
+	current, saved := r.files[newID]
+	if saved {
+		info = mergeFileInfo(current, legacy)
+		info.StoredSize = storedSize
+	} else {
+		info.ID = newID
+		info.LegacyIDs = slices.Clone(legacy.LegacyIDs)
+	}
+	info.LegacyIDs = append(info.LegacyIDs, fileID)
+
+	// Сохранение метаданных: сначала новый ID, затем удаление прежнего
+	// При сбое между записями прежний файл будет перенесен повторно при следующем запуске
+	if err := r.meta.Put(&info); err != nil {
+		return fmt.Errorf("FAILED TO SAVE FILE METADATA: %w", err)
+	}
+	r.files[newID] = &info
+	if saved {
+		r.release(current)
+	}
+	r.retain(&info)
+	if err := r.meta.Delete(fileID); err != nil {
+		return fmt.Errorf("FAILED TO SAVE FILE METADATA: %w", err)
+	}
+	delete(r.files, fileID)
+	r.release(legacy)
+	for _, alias := range info.LegacyIDs {
+		r.aliases[alias] = newID
+	}
+
+	return nil
+}
+
+// mergeFileInfo объединяет метаданные файла, загруженного заново под новым ID, с метаданными прежней копии
+// Сохраняются имя (если оно известно, а не совпадает с ID) и время создания прежней копии и варианты обеих
+func mergeFileInfo(current, legacy *model.FileInfo) model.FileInfo {
+	merged := *current
+	if legacy.Filename != legacy.ID {
+		merged.Filename = legacy.Filename
+	}
+	if legacy.CreatedAt.Before(merged.CreatedAt) {
+		merged.CreatedAt = legacy.CreatedAt
+	}
+	merged.LegacyIDs = append(slices.Clone(current.LegacyIDs), legacy.LegacyIDs...)
+
+	merged.Variants = slices.Clone(current.Variants)
+	for _, v := range legacy.Variants {
+		if !slices.Contains(merged.Variants, v) {
+			merged.Variants = append(merged.Variants, v)
+		}
+	}
+	if merged.VariantOf == "" {
+		merged.VariantOf = legacy.VariantOf
+		merged.Watermark = legacy.Watermark
+	}
+
+	return merged
+}
+
+// rewriteReferences заменяет в связях вариантов и версий прежние ID файлов текущими
+func (r *Repository) rewriteReferences() error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for fileID, info := range r.files {
+		// Замена прежних ID в копии метаданных (ранее выданные указатели не меняются)
+		updated := *info
+		updated.Variants = slices.Clone(info.Variants)
+		changed := false
+		if current, aliased := r.aliases[updated.VariantOf]; aliased {
+			updated.VariantOf = current
+			changed = true
+		}
+		for i, v := range updated.Variants {
+			if current, aliased := r.aliases[v.FileID]; aliased {
+				updated.Variants[i].FileID = current
+				changed = true
+			}
+		}
+		updated.Versions = slices.Clone(info.Versions)
+		if current, aliased := r.aliases[updated.VersionOf]; aliased {
+			updated.VersionOf = current
+			changed = true
+		}
+		for i, v := range updated.Versions {
+			if current, aliased := r.aliases[v.FileID]; aliased {
+				updated.Versions[i].FileID = current
+				changed = true
+			}
+		}
+		if !changed {
+			continue
+		}
+
+		// Сохранение метаданных и обновление кэша
+		if err := r.meta.Put(&updated); err != nil {
+			return fmt.Errorf("FAILED TO SAVE FILE METADATA: %w", err)
+		}
+		r.files[fileID] = &updated
+	}
+
+	return nil
+}
@@ -0,0 +1,173 @@
+// quota.go - учет занятого места и ограничения хранилища
+// Учет обновляется при каждом изменении кэша метаданных (см. retain и release) и восстанавливается при запуске
+// по метаданным, поэтому остается верным при дедупликации, удалении и перезапуске
+package file
+
+import (
+	"file_server/internal/repository"
+	"file_server/pkg/model"
+)
+
+// Limits - ограничения хранилища (нулевые значения - без ограничения)
+// Объем сервера - размер хранимого содержимого (одинаковое содержимое учитывается один раз),
+// объем клиента - сумма размеров его файлов
+type Limits struct {
+	Total   model.Quota            // Ограничение сервера
+	Client  model.Quota            // Ограничение клиента по умолчанию (в том числе анонимных загрузок)
+	Clients map[string]model.Quota // Ограничения отдельных клиентов (имя клиента -> ограничение)
+}
+
+// SpaceGuard - проверка свободного места перед записью (см. fs.SpaceGuard)
+type SpaceGuard interface {
+	// Check возвращает ошибку, если запись size байт нужно отклонить
+	Check(size int64) error
+}
+
+// usage - учет занятого места, защищен mutex репозитория
+type usage struct {
+	files    int                    // Количество файлов
+	bytes    int64                  // Сумма размеров файлов
+	stored   int64                  // Объем хранимого содержимого
+	physical int64                  // Объем, занятый содержимым в хранилище (после сжатия)
+	owners   map[string]model.Usage // Место, занятое файлами клиентов (имя клиента -> место)
+}
+
+// SetLimits задает ограничения хранилища
+// Ограничения проверяются при сохранении новых файлов, уже сохраненные файлы не удаляются
+func (r *Repository) SetLimits(limits Limits) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.limits = limits
+}
+
+// SetSpaceGuard задает проверку свободного места, выполняемую перед сохранением каждого файла
+// Чтение и удаление файлов не проверяются
+func (r *Repository) SetSpaceGuard(guard SpaceGuard) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.space = guard
+}
+
+// checkSpace проверяет свободное место перед сохранением size байт
+func (r *Repository) checkSpace(size int64) error {
+	r.mutex.RLock()
+	guard := r.space
+	r.mutex.RUnlock()
+
+	if guard == nil {
+		return nil
+	}
+	return guard.Check(size)
+}
+
+// Quota возвращает занятое место и ограничения для клиента owner и для сервера в целом
+func (r *Repository) Quota(owner string) model.QuotaReport {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	return model.QuotaReport{
+		Owner:      owner,
+		Usage:      r.usage.owners[owner],
+		Quota:      r.clientQuota(owner),
+		TotalUsage: model.Usage{Files: r.usage.files, Bytes: r.usage.stored},
+		TotalQuota: r.limits.Total,
+	}
+}
+
+// clientQuota возвращает ограничение клиента owner
+// Вызывается под блокировкой mutex
+func (r *Repository) clientQuota(owner string) model.Quota {
+	if quota, exists := r.limits.Clients[owner]; exists {
+		return quota
+	}
+	return r.limits.Client
+}
+
+// checkQuota проверяет, что новый файл info не превысит ограничений
+// Вызывается под блокировкой mutex; проверка и учет файла (retain) выполняются под одной блокировкой
+func (r *Repository) checkQuota(info *model.FileInfo) error {
+	// Новое содержимое увеличивает объем сервера, ссылка на сохраненное - нет
+	var stored int64
+	if r.refs[info.BlobID] == 0 {
+		stored = info.Size
+	}
+	owner := r.usage.owners[info.Owner]
+	client := r.clientQuota(info.Owner)
+
+	checks := []repository.QuotaError{
+		{Limit: "total files", Usage: int64(r.usage.files), Requested: 1, Max: int64(r.limits.Total.MaxFiles)},
+		{Limit: "total bytes", Usage: r.usage.stored, Requested: stored, Max: r.limits.Total.MaxBytes},
+		{Limit: "client files", Usage: int64(owner.Files), Requested: 1, Max: int64(client.MaxFiles)},
+		{Limit: "client bytes", Usage: owner.Bytes, Requested: info.Size, Max: client.MaxBytes},
+	}
+	for _, check := range checks {
+		if check.Max > 0 && check.Usage+check.Requested > check.Max {
+			return &check
+		}
+	}
+
+	return nil
+}
+
+// retain учитывает файл info: ссылку на содержимое, повреждение содержимого и занятое место
+// Вызывается под блокировкой mutex
+func (r *Repository) retain(info *model.FileInfo) {
+	if r.refs[info.BlobID] == 0 {
+		r.usage.stored += info.Size
+		r.physical[info.BlobID] = physicalSize(info)
+		r.usage.physical += r.physical[info.BlobID]
+	}
+	r.refs[info.BlobID]++
+	if info.Corrupted {
+		r.corrupt[info.BlobID] = true
+	}
+
+	r.usage.files++
+	r.usage.bytes += info.Size
+	owner := r.usage.owners[info.Owner]
+	owner.Files++
+	owner.Bytes += info.Size
+	r.usage.owners[info.Owner] = owner
+}
+
+// release снимает учет файла info
+// Вызывается под блокировкой mutex
+func (r *Repository) release(info *model.FileInfo) {
+	if r.refs[info.BlobID] <= 1 {
+		delete(r.refs, info.BlobID)
+		delete(r.corrupt, info.BlobID)
+		r.usage.stored -= info.Size
+		r.usage.physical -= r.physical[info.BlobID]
+		delete(r.physical, info.BlobID)
+	} else {
+		r.refs[info.BlobID]--
+	}
+
+	r.usage.files--
+	r.usage.bytes -= info.Size
+	owner := r.usage.owners[info.Owner]
+	owner.Files--
+	owner.Bytes -= info.Size
+	if owner.Files == 0 {
+		delete(r.usage.owners, info.Owner)
+	} else {
+		r.usage.owners[info.Owner] = owner
+	}
+}
+
+// physicalSize возвращает размер содержимого файла info в хранилище
+// Файлы, сохраненные до появления сжатия, хранятся без него
+func physicalSize(info *model.FileInfo) int64 {
+	if info.StoredSize > 0 {
+		return info.StoredSize
+	}
+	return info.Size
+}
+
+// storedSize возвращает размер записанного содержимого в хранилище (0 - неизвестен, считается равным размеру файла)
+func (r *Repository) storedSize(blobID string) int64 {
+	blob, err := r.blobs.Stat(blobID)
+	if err != nil {
+		return 0
+	}
+	return blob.Size
+}
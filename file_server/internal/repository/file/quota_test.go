@@ -0,0 +1,198 @@
+package file
+
+import (
+	"bytes"
+	"crypto/rand"
+	"errors"
+	"file_server/internal/repository"
+	"file_server/internal/storage"
+	"file_server/internal/storage/compress"
+	"file_server/internal/storage/memory"
+	"file_server/pkg/model"
+	"fmt"
+	"sync"
+	"testing"
+)
+
+// newMemoryRepo создает репозиторий поверх хранилищ в памяти
+func newMemoryRepo(t *testing.T) *Repository {
+	t.Helper()
+	repo, err := NewRepo(memory.NewBlobStore(), memory.NewMetaStore())
+	if err != nil {
+		t.Fatalf("NewRepo: %v", err)
+	}
+	return repo
+}
+
+// assertQuotaError проверяет, что err - превышение ограничения limit
+func assertQuotaError(t *testing.T, err error, limit string) {
+	t.Helper()
+	var quotaErr *repository.QuotaError
+	if !errors.Is(err, repository.ErrQuotaExceeded) || !errors.As(err, &quotaErr) {
+		t.Fatalf("got %v, want quota error", err)
+	}
+	if quotaErr.Limit != limit {
+		t.Errorf("exceeded %q, want %q (%v)", quotaErr.Limit, limit, err)
+	}
+}
+
+func TestRepositoryEnforcesQuotas(t *testing.T) {
+	repo := newMemoryRepo(t)
+	repo.SetLimits(Limits{
+		Total:   model.Quota{MaxBytes: 16},
+		Client:  model.Quota{MaxBytes: 10, MaxFiles: 3},
+		Clients: map[string]model.Quota{"big": {MaxBytes: 100}},
+	})
+
+	aliceID, err := repo.SaveFile("a.bin", []byte("123456"), "alice")
+	if err != nil {
+		t.Fatalf("SaveFile: %v", err)
+	}
+
+	// Копия того же содержимого учитывается в объеме клиента, но не в объеме сервера
+	_, err = repo.SaveFile("a-copy.bin", []byte("123456"), "alice")
+	assertQuotaError(t, err, "client bytes")
+	if _, err := repo.SaveFile("b.bin", []byte("123456"), "bob"); err != nil {
+		t.Fatalf("SaveFile of shared content by another client: %v", err)
+	}
+
+	// Новое содержимое увеличивает объем сервера
+	_, err = repo.SaveFile("big.bin", []byte("0123456789a"), "big")
+	assertQuotaError(t, err, "total bytes")
+	var quotaErr *repository.QuotaError
+	errors.As(err, &quotaErr)
+	if quotaErr.Usage != 6 || quotaErr.Requested != 11 || quotaErr.Max != 16 {
+		t.Errorf("quota error reports %+v, want usage 6, requested 11, limit 16", quotaErr)
+	}
+
+	// Удаление освобождает место клиента (содержимое остается, пока на него ссылается файл bob)
+	if err := repo.DeleteFile(aliceID); err != nil {
+		t.Fatalf("DeleteFile: %v", err)
+	}
+	if _, err := repo.SaveFile("a2.bin", []byte("abcdefghij"), "alice"); err != nil {
+		t.Fatalf("SaveFile after delete: %v", err)
+	}
+
+	report := repo.Quota("alice")
+	if report.Usage != (model.Usage{Files: 1, Bytes: 10}) || report.Quota != (model.Quota{MaxBytes: 10, MaxFiles: 3}) {
+		t.Errorf("alice quota = %+v", report)
+	}
+	if report.TotalUsage != (model.Usage{Files: 2, Bytes: 16}) {
+		t.Errorf("total usage = %+v, want 2 files and 16 stored bytes", report.TotalUsage)
+	}
+	if stats, _ := repo.GetStats(); stats.Files != 2 || stats.Bytes != 16 {
+		t.Errorf("GetStats = %+v", stats)
+	}
+}
+
+func TestRepositoryUsageSurvivesRestart(t *testing.T) {
+	dir := t.TempDir()
+	repo := openRepo(t, dir)
+	for i, owner := range []string{"alice", "alice", "bob"} {
+		if _, err := repo.SaveFile(fmt.Sprintf("%d.bin", i), []byte("shared"), owner); err != nil {
+			t.Fatalf("SaveFile: %v", err)
+		}
+	}
+	if _, err := repo.SaveFile("own.bin", []byte("own"), "bob"); err != nil {
+		t.Fatalf("SaveFile: %v", err)
+	}
+	alice, bob := repo.Quota("alice"), repo.Quota("bob")
+	repo.Close()
+
+	repo = openRepo(t, dir)
+	if got := repo.Quota("alice"); got != alice {
+		t.Errorf("alice after restart = %+v, want %+v", got, alice)
+	}
+	if got := repo.Quota("bob"); got != bob {
+		t.Errorf("bob after restart = %+v, want %+v", got, bob)
+	}
+	if bob.Usage != (model.Usage{Files: 2, Bytes: 9}) || bob.TotalUsage != (model.Usage{Files: 4, Bytes: 9}) {
+		t.Errorf("bob usage = %+v", bob)
+	}
+}
+
+func TestRepositoryQuotaIsAtomic(t *testing.T) {
+	repo := newMemoryRepo(t)
+	repo.SetLimits(Limits{Client: model.Quota{MaxFiles: 5}})
+
+	// Параллельные загрузки разного и одинакового содержимого не превышают ограничение вместе
+	const uploaders = 32
+	var (
+		wg       sync.WaitGroup
+		mutex    sync.Mutex
+		accepted int
+	)
+	for i := 0; i < uploaders; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := repo.SaveFile("f.bin", []byte(fmt.Sprintf("content %d", i%8)), "alice")
+			switch {
+			case err == nil:
+				mutex.Lock()
+				accepted++
+				mutex.Unlock()
+			case !errors.Is(err, repository.ErrQuotaExceeded):
+				t.Errorf("SaveFile: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if accepted != 5 {
+		t.Errorf("%d uploads accepted, want 5", accepted)
+	}
+	if usage := repo.Quota("alice").Usage; usage.Files != 5 {
+		t.Errorf("usage = %+v, want 5 files", usage)
+	}
+
+	// Содержимое отклоненных загрузок не остается в хранилище
+	stored := 0
+	repo.blobs.Iterate(func(storage.BlobInfo) error {
+		stored++
+		return nil
+	})
+	if stored != len(repo.refs) {
+		t.Errorf("%d blobs stored, %d referenced", stored, len(repo.refs))
+	}
+}
+
+func TestRepositoryReportsLogicalAndPhysicalBytes(t *testing.T) {
+	blobs, err := compress.NewBlobStore(memory.NewBlobStore(), compress.Config{Enabled: true, MinSaving: compress.DefaultMinSaving})
+	if err != nil {
+		t.Fatalf("NewBlobStore: %v", err)
+	}
+	meta := memory.NewMetaStore()
+	repo, err := NewRepo(blobs, meta)
+	if err != nil {
+		t.Fatalf("NewRepo: %v", err)
+	}
+
+	// Сжимаемое содержимое дважды (дедупликация) и несжимаемое
+	bitmap := bytes.Repeat([]byte{0x10, 0x20, 0x30, 0x40}, 4096)
+	noise := make([]byte, 1024)
+	rand.Read(noise)
+	for _, data := range [][]byte{bitmap, bitmap, noise} {
+		if _, err := repo.SaveFile("file.bin", data, ""); err != nil {
+			t.Fatalf("SaveFile: %v", err)
+		}
+	}
+
+	stats, _ := repo.GetStats()
+	logical := int64(len(bitmap) + len(noise))
+	if stats.Files != 3 || stats.Bytes != logical+int64(len(bitmap)) || stats.LogicalBytes != logical {
+		t.Errorf("GetStats = %+v", stats)
+	}
+	if stats.PhysicalBytes <= int64(len(noise)) || stats.PhysicalBytes >= logical/2 {
+		t.Errorf("physical bytes = %d, want compressed size well below %d", stats.PhysicalBytes, logical)
+	}
+
+	// Учет восстанавливается по метаданным после перезапуска
+	restarted, err := NewRepo(blobs, meta)
+	if err != nil {
+		t.Fatalf("NewRepo: %v", err)
+	}
+	if got, _ := restarted.GetStats(); got != stats {
+		t.Errorf("GetStats after restart = %+v, want %+v", got, stats)
+	}
+}
@@ -0,0 +1,314 @@
+// scrub.go - фоновая проверка содержимого на скрытые повреждения
+// Содержимое адресуется своим хэшем, поэтому повреждение на диске (bit rot) обнаруживается пересчетом хэша
+// Поврежденное содержимое переносится в карантин хранилища, а ссылающиеся на него файлы помечаются поврежденными:
+// их чтение возвращает ErrFileCorrupted вместо испорченных данных. Повторная загрузка того же содержимого
+// восстанавливает файлы (см. SaveFile)
+package file
+
+import (
+	"context"
+	"errors"
+	"file_server/internal/storage"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ScrubStatus - состояние фоновой проверки содержимого
+type ScrubStatus struct {
+	Running      bool          // Идет проход
+	Passes       int           // Завершено проходов
+	Checked      int           // Проверено содержимого в текущем (или последнем) проходе
+	Total        int           // Содержимого в текущем (или последнем) проходе
+	BytesRead    int64         // Прочитано байт в текущем (или последнем) проходе
+	StartedAt    time.Time     // Начало текущего (или последнего) прохода
+	FinishedAt   time.Time     // Завершение последнего прохода (нулевое - проходов не было)
+	LastDuration time.Duration // Длительность последнего завершенного прохода
+	LastFound    []string      // Поврежденное содержимое, найденное последним завершенным проходом
+	Found        int           // Поврежденного содержимого найдено за все проходы
+}
+
+// String форматирует состояние для логирования
+func (s ScrubStatus) String() string {
+	var b strings.Builder
+	if s.Running {
+		fmt.Fprintf(&b, "running, %d/%d blobs, %d bytes read", s.Checked, s.Total, s.BytesRead)
+	} else {
+		b.WriteString("idle")
+	}
+	if s.FinishedAt.IsZero() {
+		b.WriteString(", no completed passes")
+	} else {
+		fmt.Fprintf(&b, ", last pass finished %s in %v, %d corrupted", s.FinishedAt.Format(time.RFC3339), s.LastDuration.Round(time.Second), len(s.LastFound))
+	}
+	fmt.Fprintf(&b, ", %d corrupted in total", s.Found)
+	return b.String()
+}
+
+// Scrubber - фоновая проверка содержимого репозитория с ограничением скорости чтения
+type Scrubber struct {
+	repo   *Repository // Проверяемый репозиторий
+	rate   int64       // Ограничение скорости чтения в байтах в секунду (0 - без ограничения)
+	mutex  sync.Mutex  // Мьютекс для thread-safe доступа к состоянию
+	status ScrubStatus // Состояние проверки
+}
+
+// NewScrubber создает проверку содержимого репозитория, читающую не быстрее rate байт в секунду (0 - без ограничения)
+func NewScrubber(repo *Repository, rate int64) *Scrubber {
+	return &Scrubber{
+		repo: repo,
+		rate: rate,
+	}
+}
+
+// Status возвращает текущее состояние проверки
+func (s *Scrubber) Status() ScrubStatus {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	status := s.status
+	status.LastFound = append([]string(nil), s.status.LastFound...)
+	return status
+}
+
+// update изменяет состояние под блокировкой
+func (s *Scrubber) update(fn func(status *ScrubStatus)) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	fn(&s.status)
+}
+
+// Run выполняет проходы проверки сразу и затем каждые interval до отмены ctx
+// Ошибки прохода логируются, проверка продолжается на следующем проходе
+func (s *Scrubber) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := s.Scrub(ctx); err != nil && ctx.Err() == nil {
+			log.Printf("Scrub failed: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Scrub выполняет один проход: перечитывает все содержимое и сверяет его хэш с ID
+// Возвращает ID поврежденного содержимого, найденного и перенесенного в карантин
+func (s *Scrubber) Scrub(ctx context.Context) ([]string, error) {
+	// Снимок содержимого (содержимое, записанное во время прохода, проверяется следующим проходом)
+	var blobs []storage.BlobInfo
+	if err := s.repo.blobs.Iterate(func(blob storage.BlobInfo) error {
+		blobs = append(blobs, blob)
+		return nil
+	}); err != nil {
+		return nil, fmt.Errorf("FAILED TO SCAN STORAGE: %w", err)
+	}
+
+	start := time.Now()
+	s.update(func(status *ScrubStatus) {
+		status.Running = true
+		status.Checked, status.Total, status.BytesRead = 0, len(blobs), 0
+		status.StartedAt = start
+	})
+	defer s.update(func(status *ScrubStatus) { status.Running = false })
+
+	// Проверка каждого содержимого с ограничением скорости чтения
+	limiter := rateLimiter{rate: s.rate, start: start}
+	var found []string
+	for _, blob := range blobs {
+		// Проверка контекста на отмену операции
+		select {
+		case <-ctx.Done():
+			return found, ctx.Err()
+		default:
+		}
+
+		corrupted, read, err := s.repo.scrubBlob(blob.ID)
+		if err != nil {
+			log.Printf("Scrub: %v", err) // Ошибка чтения одного содержимого не прерывает проход
+		}
+		if corrupted {
+			found = append(found, blob.ID)
+		}
+		s.update(func(status *ScrubStatus) {
+			status.Checked++
+			status.BytesRead += read
+		})
+
+		if err := limiter.wait(ctx, read); err != nil {
+			return found, err
+		}
+	}
+
+	// Итог прохода
+	duration := time.Since(start)
+	s.update(func(status *ScrubStatus) {
+		status.Passes++
+		status.FinishedAt = time.Now()
+		status.LastDuration = duration
+		status.LastFound = found
+		status.Found += len(found)
+	})
+	log.Printf("Scrub finished: %d blobs in %v, %d corrupted", len(blobs), duration.Round(time.Millisecond), len(found))
+
+	return found, nil
+}
+
+// rateLimiter ограничивает среднюю скорость чтения с начала прохода
+type rateLimiter struct {
+	rate  int64     // Байт в секунду (0 - без ограничения)
+	start time.Time // Начало отсчета
+	bytes int64     // Прочитано байт с начала отсчета
+}
+
+// wait учитывает n прочитанных байт и ждет, пока средняя скорость не опустится до rate
+func (l *rateLimiter) wait(ctx context.Context, n int64) error {
+	if l.rate <= 0 {
+		return nil
+	}
+	l.bytes += n
+
+	due := l.start.Add(time.Duration(float64(l.bytes) / float64(l.rate) * float64(time.Second)))
+	delay := time.Until(due)
+	if delay <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// scrubBlob перечитывает содержимое и при несовпадении с ID переносит его в карантин
+// Возвращает признак повреждения и количество прочитанных байт
+// Содержимое под именем, не являющимся хэшем, не проверяется (его переносит миграция ID или проверка целостности)
+func (r *Repository) scrubBlob(blobID string) (bool, int64, error) {
+	// Содержимое, уже помеченное поврежденным, не перепроверяется (оно в карантине или ждет повторной загрузки)
+	r.mutex.RLock()
+	known := r.corrupt[blobID]
+	r.mutex.RUnlock()
+	if known {
+		return false, 0, nil
+	}
+
+	// Чтение и сверка без блокировки: содержимое с данным ID не изменяется
+	// Удаленное во время прохода содержимое возвращается пустым и пропускается
+	data, detail, misnamed, err := r.readBlob(blobID)
+	read := int64(len(data))
+	if err != nil || detail == "" || misnamed {
+		return false, read, err
+	}
+
+	// Перепроверка под блокировкой, чтобы не изолировать содержимое, записанное заново во время проверки
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if _, detail, _, err = r.readBlob(blobID); err != nil || detail == "" {
+		return false, read, err
+	}
+	fileIDs, err := r.quarantineBlob(blobID)
+	if err != nil {
+		return false, read, err
+	}
+	log.Printf("Scrub: content %s is corrupted (%s), moved to quarantine, files marked corrupted: %v", blobID, detail, fileIDs)
+
+	return true, read, nil
+}
+
+// readBlob читает содержимое и сверяет его с ID (см. verifyBlob)
+// Содержимое, которое хранилище не может декодировать, считается несовпадающим; отсутствующее - пустым и совпадающим
+func (r *Repository) readBlob(blobID string) (data []byte, detail string, misnamed bool, err error) {
+	data, err = r.blobs.Get(blobID)
+	if errors.Is(err, storage.ErrBlobNotFound) {
+		return nil, "", false, nil
+	}
+	if errors.Is(err, storage.ErrBlobCorrupted) {
+		return nil, err.Error(), false, nil
+	}
+	if err != nil {
+		return nil, "", false, fmt.Errorf("FAILED TO READ FILE %s: %w", blobID, err)
+	}
+	detail, misnamed = verifyBlob(blobID, data)
+	return data, detail, misnamed, nil
+}
+
+// quarantineBlob помечает файлы, ссылающиеся на содержимое, поврежденными и переносит содержимое в карантин
+// Метаданные помечаются первыми: после сбоя между шагами файл остается поврежденным, а не исчезает при запуске
+// Хранилище без карантина оставляет содержимое на месте - чтение файлов все равно отклоняется по пометке
+// Возвращает ID помеченных файлов; вызывается под блокировкой mutex
+func (r *Repository) quarantineBlob(blobID string) ([]string, error) {
+	fileIDs := r.blobFiles(blobID)
+	for _, fileID := range fileIDs {
+		if err := r.markCorrupted(fileID); err != nil {
+			return nil, err
+		}
+	}
+
+	if quarantiner, ok := r.blobs.(storage.Quarantiner); ok {
+		if err := quarantiner.Quarantine(blobID); err != nil && !errors.Is(err, storage.ErrBlobNotFound) {
+			return nil, fmt.Errorf("FAILED TO QUARANTINE FILE %s: %w", blobID, err)
+		}
+	}
+
+	return fileIDs, nil
+}
+
+// restoreBlob снимает пометку повреждения с файлов, содержимое которых записано заново
+// storedSize - размер записанного содержимого в хранилище (0 - неизвестен)
+// Вызывается под блокировкой mutex
+func (r *Repository) restoreBlob(blobID string, storedSize int64) error {
+	fileIDs := r.blobFiles(blobID)
+	for _, fileID := range fileIDs {
+		info := r.files[fileID]
+		if !info.Corrupted && info.StoredSize == storedSize {
+			continue
+		}
+		updated := *info
+		updated.Corrupted = false
+		updated.StoredSize = storedSize
+		if err := r.meta.Put(&updated); err != nil {
+			return fmt.Errorf("FAILED TO SAVE FILE METADATA: %w", err)
+		}
+		r.files[fileID] = &updated
+	}
+
+	// Учет места по новому содержимому
+	if len(fileIDs) > 0 {
+		physical := physicalSize(r.files[fileIDs[0]])
+		r.usage.physical += physical - r.physical[blobID]
+		r.physical[blobID] = physical
+	}
+	delete(r.corrupt, blobID)
+	log.Printf("Content %s restored by upload, %d files repaired", blobID, len(fileIDs))
+	return nil
+}
+
+// markCorrupted сохраняет пометку повреждения файла и учитывает его содержимое как поврежденное
+// Вызывается под блокировкой mutex
+func (r *Repository) markCorrupted(fileID string) error {
+	info := r.files[fileID]
+	if info.Corrupted {
+		return nil
+	}
+
+	// Изменение копии, чтобы ранее выданные указатели не менялись
+	updated := *info
+	updated.Corrupted = true
+	if err := r.meta.Put(&updated); err != nil {
+		return fmt.Errorf("FAILED TO SAVE FILE METADATA: %w", err)
+	}
+	r.files[fileID] = &updated
+	r.corrupt[info.BlobID] = true
+
+	return nil
+}
@@ -0,0 +1,157 @@
+package file
+
+import (
+	"context"
+	"errors"
+	"file_server/internal/repository"
+	"file_server/internal/storage"
+	"file_server/internal/storage/encrypt"
+	"file_server/internal/storage/memory"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestRepositoryScrubQuarantinesCorruptedContent(t *testing.T) {
+	dir := t.TempDir()
+	repo := openRepo(t, dir)
+
+	healthyID, err := repo.SaveFile("healthy.txt", []byte("healthy"), "")
+	if err != nil {
+		t.Fatalf("SaveFile: %v", err)
+	}
+	firstID, err := repo.SaveFile("first.txt", []byte("shared content"), "")
+	if err != nil {
+		t.Fatalf("SaveFile: %v", err)
+	}
+	secondID, err := repo.SaveFile("second.txt", []byte("shared content"), "")
+	if err != nil {
+		t.Fatalf("SaveFile: %v", err)
+	}
+	info, _ := repo.GetFileInfo(firstID)
+
+	// Повреждение содержимого на диске
+	if err := os.WriteFile(blobPath(t, dir, info.BlobID), []byte("shared c0ntent"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	scrubber := NewScrubber(repo, 0)
+	found, err := scrubber.Scrub(context.Background())
+	if err != nil {
+		t.Fatalf("Scrub: %v", err)
+	}
+	if len(found) != 1 || found[0] != info.BlobID {
+		t.Fatalf("Scrub found %v, want [%s]", found, info.BlobID)
+	}
+	status := scrubber.Status()
+	if status.Running || status.Passes != 1 || status.Checked != 2 || status.Found != 1 || status.FinishedAt.IsZero() {
+		t.Errorf("Status = %+v", status)
+	}
+
+	// Оба файла с общим содержимым помечены поврежденными, содержимое в карантине
+	for _, fileID := range []string{firstID, secondID} {
+		if _, err := repo.GetFile(fileID); !errors.Is(err, repository.ErrFileCorrupted) {
+			t.Errorf("GetFile(%s) = %v, want ErrFileCorrupted", fileID, err)
+		}
+	}
+	if quarantined, _ := filepath.Glob(filepath.Join(dir, ".quarantine", info.BlobID+"-*")); len(quarantined) != 1 {
+		t.Errorf("corrupted content not quarantined: %v", quarantined)
+	}
+	if got, err := repo.GetFile(healthyID); err != nil || string(got.Data) != "healthy" {
+		t.Errorf("healthy file = %v", err)
+	}
+
+	// Повторный проход не находит уже изолированное содержимое
+	if found, _ := scrubber.Scrub(context.Background()); len(found) != 0 {
+		t.Errorf("second pass found %v", found)
+	}
+
+	// Пометка сохраняется после перезапуска, файлы без содержимого не удаляются
+	repo.Close()
+	repo = openRepo(t, dir)
+	if _, err := repo.GetFile(secondID); !errors.Is(err, repository.ErrFileCorrupted) {
+		t.Fatalf("GetFile after restart = %v, want ErrFileCorrupted", err)
+	}
+
+	// Повторная загрузка того же содержимого восстанавливает файлы
+	if _, err := repo.SaveFile("again.txt", []byte("shared content"), ""); err != nil {
+		t.Fatalf("SaveFile: %v", err)
+	}
+	for _, fileID := range []string{firstID, secondID} {
+		if got, err := repo.GetFile(fileID); err != nil || string(got.Data) != "shared content" || got.Info.Corrupted {
+			t.Errorf("GetFile(%s) after re-upload = %+v, %v", fileID, got, err)
+		}
+	}
+	if report := fsck(t, repo, false); len(report.Issues) != 0 {
+		t.Errorf("fsck after restore = %+v", report.Issues)
+	}
+}
+
+func TestRepositoryScrubIsRateLimited(t *testing.T) {
+	repo := newMemoryRepo(t)
+	data := make([]byte, 4096)
+	for i := range 4 {
+		data[0] = byte(i)
+		if _, err := repo.SaveFile("file.bin", data, ""); err != nil {
+			t.Fatalf("SaveFile: %v", err)
+		}
+	}
+
+	// 16 КБ при 64 КБ/с - не менее четверти секунды
+	start := time.Now()
+	if _, err := NewScrubber(repo, 64<<10).Scrub(context.Background()); err != nil {
+		t.Fatalf("Scrub: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 200*time.Millisecond {
+		t.Errorf("Scrub took %v, rate limit not applied", elapsed)
+	}
+
+	// Отмена прерывает ожидание
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	scrubber := NewScrubber(repo, 1)
+	if _, err := scrubber.Scrub(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Scrub = %v, want DeadlineExceeded", err)
+	}
+	if status := scrubber.Status(); status.Running || status.Passes != 0 {
+		t.Errorf("Status after cancel = %+v", status)
+	}
+}
+
+func TestRepositoryScrubQuarantinesTamperedEncryptedContent(t *testing.T) {
+	keyFile := filepath.Join(t.TempDir(), "keys.json")
+	if _, err := encrypt.AddKey(keyFile); err != nil {
+		t.Fatalf("AddKey: %v", err)
+	}
+	keys, err := encrypt.LoadKeyRing(keyFile)
+	if err != nil {
+		t.Fatalf("LoadKeyRing: %v", err)
+	}
+	inner := memory.NewBlobStore()
+	repo, err := NewRepo(encrypt.NewBlobStore(inner, keys, false), memory.NewMetaStore())
+	if err != nil {
+		t.Fatalf("NewRepo: %v", err)
+	}
+	fileID, err := repo.SaveFile("scan.png", []byte("customer document"), "")
+	if err != nil {
+		t.Fatalf("SaveFile: %v", err)
+	}
+	info, _ := repo.GetFileInfo(fileID)
+
+	// Изменение шифротекста в хранилище
+	stored, _ := inner.Get(info.BlobID)
+	stored[len(stored)-1] ^= 1
+	inner.Put(info.BlobID, stored)
+
+	// Чтение сообщает о несовпадении тега аутентификации, проверка содержимого изолирует его
+	if _, err := repo.GetFile(fileID); !errors.Is(err, repository.ErrFileCorrupted) || !errors.Is(err, storage.ErrBlobAuthFailed) {
+		t.Fatalf("GetFile = %v, want authentication failure", err)
+	}
+	if found, err := NewScrubber(repo, 0).Scrub(context.Background()); err != nil || len(found) != 1 {
+		t.Fatalf("Scrub = %v, %v", found, err)
+	}
+	if _, err := repo.GetFile(fileID); !errors.Is(err, repository.ErrFileCorrupted) {
+		t.Errorf("GetFile after scrub = %v, want ErrFileCorrupted", err)
+	}
+}
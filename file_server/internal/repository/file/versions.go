@@ -0,0 +1,244 @@
+// versions.go - история версий файлов
+// Замена содержимого сохраняет ID файла: новое содержимое становится текущей версией файла,
+// а прежнее остается отдельным файлом со ссылкой на него (VersionOf), который не попадает в список файлов
+// Прежние версии учитываются в занятом месте, проверяются и изолируются в карантин как обычные файлы
+// Количество хранимых версий ограничивается (см. SetVersionRetention): самые старые удаляются при замене
+package file
+
+import (
+	"file_server/internal/repository"
+	"file_server/pkg/model"
+	"fmt"
+	"log"
+	"slices"
+	"time"
+)
+
+// SetVersionRetention задает количество хранимых версий файла вместе с текущей (0 - без ограничения)
+// Лишние версии удаляются при следующей замене файла
+func (r *Repository) SetVersionRetention(keep int) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.keep = keep
+}
+
+// ReplaceFile делает содержимое сохраненного файла replacementID новой версией файла fileID
+// Файл fileID сохраняет ID, владельца, время создания, срок хранения (если он не задан у замены) и прежние ID,
+// а имя, содержимое и его характеристики получает от замены; прежнее содержимое сохраняется версией под новым ID,
+// а файл replacementID перестает существовать (его содержимое теперь принадлежит файлу fileID)
+// Файл и замена должны принадлежать одному владельцу
+// Возвращает обновленные метаданные файла
+func (r *Repository) ReplaceFile(fileID, replacementID string) (*model.FileInfo, error) {
+	// Валидация ID файлов
+	if fileID == "" || replacementID == "" {
+		return nil, repository.ErrInvalidFileID
+	}
+
+	// Замена выполняется под блокировкой, чтобы параллельные замены получили разные номера версий
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	// Поиск файла (по текущему или прежнему ID) и замены
+	fileID = r.resolve(fileID)
+	current, exists := r.files[fileID]
+	if !exists || expired(current, time.Now()) {
+		return nil, repository.ErrFileNotFound
+	}
+	replacement, exists := r.files[replacementID]
+	if !exists || replacementID == fileID {
+		return nil, repository.ErrFileNotFound
+	}
+	if current.VersionOf != "" || replacement.VersionOf != "" || len(replacement.Versions) > 0 {
+		return nil, repository.ErrFileIsVersion
+	}
+	if replacement.Owner != current.Owner {
+		return nil, repository.ErrNotFileOwner
+	}
+
+	// Прежнее содержимое становится версией под новым ID (вместе с производными вариантами)
+	previous := *current
+	previous.ID = model.NewFileID()
+	previous.Version = current.CurrentVersion()
+	previous.VersionOf = fileID
+	previous.Versions = nil
+	previous.LegacyIDs = nil
+	previous.ExpiresAt = nil // Версии удаляются вместе с файлом
+
+	// Новое содержимое становится текущей версией под ID файла
+	updated := *replacement
+	updated.ID = fileID
+	updated.CreatedAt = current.CreatedAt
+	updated.UpdatedAt = time.Now()
+	if updated.ExpiresAt == nil {
+		updated.ExpiresAt = current.ExpiresAt
+	}
+	updated.LegacyIDs = current.LegacyIDs
+	updated.VariantOf = current.VariantOf
+	updated.Version = previous.Version + 1
+	updated.Versions = append(slices.Clone(current.Versions), model.FileVersion{Version: previous.Version, FileID: previous.ID})
+
+	// Сохранение метаданных: сначала версия, затем файл
+	// При сбое между записями файл остается с прежним содержимым, а записанная версия не попадает в его историю
+	if err := r.meta.Put(&previous); err != nil {
+		return nil, fmt.Errorf("FAILED TO SAVE FILE METADATA: %w", err)
+	}
+	if err := r.meta.Put(&updated); err != nil {
+		r.meta.Delete(previous.ID) // Версия без файла не нужна
+		return nil, fmt.Errorf("FAILED TO SAVE FILE METADATA: %w", err)
+	}
+
+	// Обновление кэша и учета (содержимое обоих файлов остается тем же, меняются только записи)
+	r.release(current)
+	r.files[fileID] = &updated
+	r.files[previous.ID] = &previous
+	r.retain(&updated)
+	r.retain(&previous)
+
+	// Замена выполнена; дальнейшие шаги не отменяют ее, а их сбои только логируются
+	// Запись замены удаляется: ее содержимое теперь учитывается файлом (при сбое она остается отдельным файлом)
+	if err := r.meta.Delete(replacementID); err != nil {
+		log.Printf("Replace of %s: keeping replacement %s as a separate file: %v", fileID, replacementID, err)
+	} else {
+		r.forget(replacementID)
+	}
+
+	// Производные варианты прежнего содержимого ссылаются на прежнюю версию
+	for _, v := range previous.Variants {
+		variant, exists := r.files[v.FileID]
+		if !exists || variant.VariantOf != fileID {
+			continue
+		}
+		relinked := *variant
+		relinked.VariantOf = previous.ID
+		if err := r.meta.Put(&relinked); err != nil {
+			log.Printf("Replace of %s: failed to link variant %s to version %d: %v", fileID, v.FileID, previous.Version, err)
+			continue
+		}
+		r.files[v.FileID] = &relinked
+	}
+
+	// Удаление версий сверх ограничения (оставшиеся лишние версии удаляются при следующей замене)
+	if err := r.pruneVersions(fileID); err != nil {
+		log.Printf("Replace of %s: failed to prune old versions: %v", fileID, err)
+	}
+
+	return r.files[fileID], nil
+}
+
+// pruneVersions удаляет самые старые версии файла сверх ограничения
+// Вызывается под блокировкой mutex
+func (r *Repository) pruneVersions(fileID string) error {
+	if r.keep <= 0 {
+		return nil
+	}
+	for {
+		info := r.files[fileID]
+		if len(info.Versions)+1 <= r.keep {
+			return nil
+		}
+		oldest := info.Versions[0].FileID
+		if err := r.deleteFile(oldest); err != nil {
+			return err
+		}
+
+		// Версия, метаданные которой уже удалены (например, проверкой целостности), исключается из истории отдельно
+		if err := r.dropVersion(fileID, oldest); err != nil {
+			return err
+		}
+	}
+}
+
+// dropVersion исключает версию versionID из истории файла fileID
+// Вызывается под блокировкой mutex
+func (r *Repository) dropVersion(fileID, versionID string) error {
+	info, exists := r.files[fileID]
+	if !exists {
+		return nil
+	}
+
+	// Изменение копии, чтобы ранее выданные указатели не менялись
+	updated := *info
+	updated.Versions = slices.DeleteFunc(slices.Clone(info.Versions), func(v model.FileVersion) bool {
+		return v.FileID == versionID
+	})
+	if len(updated.Versions) == len(info.Versions) {
+		return nil
+	}
+
+	// Сохранение метаданных и обновление кэша
+	if err := r.meta.Put(&updated); err != nil {
+		return fmt.Errorf("FAILED TO SAVE FILE METADATA: %w", err)
+	}
+	r.files[fileID] = &updated
+
+	return nil
+}
+
+// FileVersion возвращает метаданные версии version файла fileID (0 - текущей версии)
+// Для текущей версии возвращаются метаданные самого файла, для прежней - метаданные файла с ее содержимым
+func (r *Repository) FileVersion(fileID string, version int) (*model.FileInfo, error) {
+	// Валидация ID файла
+	if fileID == "" {
+		return nil, repository.ErrInvalidFileID
+	}
+
+	// Блокировка для безопасного чтения кэша
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	// Поиск файла (по текущему или прежнему ID)
+	info, exists := r.files[r.resolve(fileID)]
+	if !exists || expired(info, time.Now()) {
+		return nil, repository.ErrFileNotFound
+	}
+	if info.VersionOf != "" {
+		return nil, repository.ErrFileIsVersion
+	}
+
+	// Поиск версии в истории файла
+	if version == 0 || version == info.CurrentVersion() {
+		return info, nil
+	}
+	for _, v := range info.Versions {
+		if v.Version == version {
+			if versionInfo, exists := r.files[v.FileID]; exists {
+				return versionInfo, nil
+			}
+		}
+	}
+
+	return nil, repository.ErrVersionNotFound
+}
+
+// ListVersions возвращает метаданные всех версий файла, начиная с текущей (от новых к старым)
+// Для прежней версии возвращается история файла, к которому она относится
+func (r *Repository) ListVersions(fileID string) ([]model.FileInfo, error) {
+	// Валидация ID файла
+	if fileID == "" {
+		return nil, repository.ErrInvalidFileID
+	}
+
+	// Блокировка для безопасного чтения кэша
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	// Поиск файла (по текущему или прежнему ID, для версии - по ID файла, к которому она относится)
+	info, exists := r.files[r.resolve(fileID)]
+	if exists && info.VersionOf != "" {
+		info, exists = r.files[info.VersionOf]
+	}
+	if !exists || expired(info, time.Now()) {
+		return nil, repository.ErrFileNotFound
+	}
+
+	// Копирование метаданных текущей и прежних версий
+	versions := make([]model.FileInfo, 0, len(info.Versions)+1)
+	versions = append(versions, *info)
+	for _, v := range slices.Backward(info.Versions) {
+		if versionInfo, exists := r.files[v.FileID]; exists {
+			versions = append(versions, *versionInfo)
+		}
+	}
+
+	return versions, nil
+}
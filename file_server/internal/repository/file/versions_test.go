@@ -0,0 +1,144 @@
+package file
+
+import (
+	"errors"
+	"file_server/internal/repository"
+	"file_server/pkg/model"
+	"fmt"
+	"testing"
+)
+
+// replace сохраняет data новой версией файла fileID (от имени владельца файла)
+func replace(t *testing.T, repo *Repository, fileID, filename string, data []byte) *model.FileInfo {
+	t.Helper()
+	current, err := repo.GetFileInfo(fileID)
+	if err != nil {
+		t.Fatalf("GetFileInfo: %v", err)
+	}
+	replacementID, err := repo.SaveFile(filename, data, current.Owner)
+	if err != nil {
+		t.Fatalf("SaveFile: %v", err)
+	}
+	info, err := repo.ReplaceFile(fileID, replacementID)
+	if err != nil {
+		t.Fatalf("ReplaceFile: %v", err)
+	}
+	return info
+}
+
+func TestRepositoryReplaceKeepsFileID(t *testing.T) {
+	dir := t.TempDir()
+	repo := openRepo(t, dir)
+
+	fileID, err := repo.SaveFile("logo.png", []byte("logo v1"), "alice")
+	if err != nil {
+		t.Fatalf("SaveFile: %v", err)
+	}
+	original, _ := repo.GetFileInfo(fileID)
+
+	// Замена другого владельца отклоняется
+	foreignID, _ := repo.SaveFile("logo.png", []byte("logo by bob"), "bob")
+	if _, err := repo.ReplaceFile(fileID, foreignID); !errors.Is(err, repository.ErrNotFileOwner) {
+		t.Errorf("ReplaceFile by another owner = %v, want ErrNotFileOwner", err)
+	}
+	repo.DeleteFile(foreignID)
+
+	// Новая версия под тем же ID, запись замены удаляется
+	replacementID, err := repo.SaveFile("logo-new.png", []byte("logo v2"), "alice")
+	if err != nil {
+		t.Fatalf("SaveFile: %v", err)
+	}
+	updated, err := repo.ReplaceFile(fileID, replacementID)
+	if err != nil {
+		t.Fatalf("ReplaceFile: %v", err)
+	}
+	if updated.ID != fileID || updated.Version != 2 || updated.Filename != "logo-new.png" || updated.Owner != "alice" || updated.BlobID != model.NewBlobID([]byte("logo v2")) {
+		t.Errorf("ReplaceFile = %+v", updated)
+	}
+	if _, err := repo.GetFileInfo(replacementID); !errors.Is(err, repository.ErrFileNotFound) {
+		t.Errorf("GetFileInfo of the replacement = %v, want ErrFileNotFound", err)
+	}
+	if !updated.CreatedAt.Equal(original.CreatedAt) || !updated.UpdatedAt.After(original.UpdatedAt) {
+		t.Errorf("times after replace: created %v, updated %v", updated.CreatedAt, updated.UpdatedAt)
+	}
+	if got, err := repo.GetFile(fileID); err != nil || string(got.Data) != "logo v2" {
+		t.Errorf("GetFile = %v, want the latest version", err)
+	}
+
+	// Прежняя версия доступна по номеру, но не попадает в список файлов
+	previous, err := repo.FileVersion(fileID, 1)
+	if err != nil || previous.VersionOf != fileID || previous.Owner != "alice" || previous.ID == replacementID {
+		t.Fatalf("FileVersion(1) = %+v, %v", previous, err)
+	}
+	if got, err := repo.GetFile(previous.ID); err != nil || string(got.Data) != "logo v1" {
+		t.Errorf("GetFile of version 1 = %v", err)
+	}
+	if files := listing(t, repo); len(files) != 1 || files[0].ID != fileID {
+		t.Errorf("ListFiles = %+v, want only the file", files)
+	}
+	if _, err := repo.FileVersion(fileID, 3); !errors.Is(err, repository.ErrVersionNotFound) {
+		t.Errorf("FileVersion(3) = %v, want ErrVersionNotFound", err)
+	}
+
+	// Прежнюю версию нельзя заменить
+	otherID, _ := repo.SaveFile("other.png", []byte("other"), "")
+	if _, err := repo.ReplaceFile(previous.ID, otherID); !errors.Is(err, repository.ErrFileIsVersion) {
+		t.Errorf("ReplaceFile of a version = %v, want ErrFileIsVersion", err)
+	}
+	repo.DeleteFile(otherID)
+
+	// История сохраняется после перезапуска
+	repo.Close()
+	repo = openRepo(t, dir)
+	versions, err := repo.ListVersions(fileID)
+	if err != nil || len(versions) != 2 || versions[0].Version != 2 || versions[1].ID != previous.ID {
+		t.Fatalf("ListVersions after restart = %+v, %v", versions, err)
+	}
+	if stats, _ := repo.GetStats(); stats.Files != 2 || stats.LogicalBytes != int64(len("logo v1")+len("logo v2")) {
+		t.Errorf("GetStats = %+v, versions must be accounted", stats)
+	}
+}
+
+func TestRepositoryPrunesOldVersions(t *testing.T) {
+	repo := newMemoryRepo(t)
+	repo.SetVersionRetention(3)
+
+	fileID, err := repo.SaveFile("banner.png", []byte("banner 1"), "")
+	if err != nil {
+		t.Fatalf("SaveFile: %v", err)
+	}
+	for i := 2; i <= 5; i++ {
+		replace(t, repo, fileID, "banner.png", []byte(fmt.Sprintf("banner %d", i)))
+	}
+
+	// Хранятся три последние версии, содержимое удаленных версий удалено
+	versions, err := repo.ListVersions(fileID)
+	if err != nil || len(versions) != 3 {
+		t.Fatalf("ListVersions = %+v, %v", versions, err)
+	}
+	for i, want := range []int{5, 4, 3} {
+		if versions[i].CurrentVersion() != want {
+			t.Errorf("versions[%d] = %d, want %d", i, versions[i].CurrentVersion(), want)
+		}
+	}
+	if _, err := repo.FileVersion(fileID, 2); !errors.Is(err, repository.ErrVersionNotFound) {
+		t.Errorf("FileVersion(2) = %v, want ErrVersionNotFound", err)
+	}
+	if _, err := repo.blobs.Get(model.NewBlobID([]byte("banner 1"))); err == nil {
+		t.Error("content of a pruned version is still stored")
+	}
+
+	// Восстановленное содержимое прежней версии разделяется с ней, а не записывается заново
+	replace(t, repo, fileID, "banner.png", []byte("banner 4"))
+	if stats, _ := repo.GetStats(); stats.Files != 3 || stats.LogicalBytes != 2*int64(len("banner 4")) {
+		t.Errorf("GetStats = %+v", stats)
+	}
+
+	// Удаление файла удаляет все версии
+	if err := repo.DeleteFile(fileID); err != nil {
+		t.Fatalf("DeleteFile: %v", err)
+	}
+	if stats, _ := repo.GetStats(); stats.Files != 0 || stats.LogicalBytes != 0 {
+		t.Errorf("GetStats after delete = %+v", stats)
+	}
+}
@@ -0,0 +1,94 @@
+// backend.go - открытие хранилища выбранного бэкенда
+// Используется сервером и автономной проверкой целостности хранилища (cmd/fsck)
+package backend
+
+import (
+	"file_server/internal/storage"
+	"file_server/internal/storage/compress"
+	"file_server/internal/storage/encrypt"
+	"file_server/internal/storage/fs"
+	"file_server/internal/storage/memory"
+	"file_server/internal/storage/s3"
+	"fmt"
+)
+
+// Config - параметры хранилища
+type Config struct {
+	Backend    string    // Имя бэкенда (storage.BackendFS, storage.BackendMemory, storage.BackendS3)
+	Path       string    // fs: директория хранения содержимого и журнала метаданных
+	ShardDepth int       // fs: глубина раскладки содержимого по поддиректориям
+	S3         s3.Config // s3: бакет и ключи доступа
+
+	// Сжатие нового содержимого (сжатое ранее содержимое читается при любых параметрах)
+	Compression compress.Config
+
+	// Файл мастер-ключей шифрования (пусто - новое содержимое не шифруется, зашифрованное не читается)
+	KeyFile string
+	// Читать открытое содержимое при заданном файле ключей (на время перехода на шифрование, до cmd/rotatekeys)
+	AllowPlaintext bool
+}
+
+// Open открывает хранилище содержимого и метаданных выбранного бэкенда
+// Для бэкенда fs содержимое (в поддиректориях глубины ShardDepth) и журнал метаданных хранятся в директории Path,
+// для s3 - в бакете S3. Содержимое любого бэкенда читается и записывается через слои шифрования и сжатия
+// (см. encrypt и compress): содержимое сжимается до шифрования
+func Open(cfg Config) (storage.BlobStore, storage.MetaStore, error) {
+	blobs, err := OpenEncrypted(cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+	compressed, err := compress.NewBlobStore(blobs, cfg.Compression)
+	if err != nil {
+		return nil, nil, err
+	}
+	meta, err := openMeta(cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+	return compressed, meta, nil
+}
+
+// OpenEncrypted открывает хранилище содержимого выбранного бэкенда со слоем шифрования, но без сжатия
+// Используется ротацией ключей (cmd/rotatekeys), которой нужен доступ к заголовкам шифрования
+func OpenEncrypted(cfg Config) (*encrypt.BlobStore, error) {
+	var keys *encrypt.KeyRing
+	if cfg.KeyFile != "" {
+		var err error
+		if keys, err = encrypt.LoadKeyRing(cfg.KeyFile); err != nil {
+			return nil, err
+		}
+	}
+	blobs, err := openBlobs(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return encrypt.NewBlobStore(blobs, keys, cfg.AllowPlaintext), nil
+}
+
+// openBlobs открывает хранилище содержимого выбранного бэкенда без дополнительных слоев
+func openBlobs(cfg Config) (storage.BlobStore, error) {
+	switch cfg.Backend {
+	case storage.BackendFS:
+		return fs.NewBlobStore(cfg.Path, cfg.ShardDepth)
+	case storage.BackendMemory:
+		return memory.NewBlobStore(), nil
+	case storage.BackendS3:
+		return s3.NewBlobStore(cfg.S3)
+	default:
+		return nil, fmt.Errorf("%w: %s", storage.ErrUnknownBackend, cfg.Backend)
+	}
+}
+
+// openMeta открывает хранилище метаданных выбранного бэкенда
+func openMeta(cfg Config) (storage.MetaStore, error) {
+	switch cfg.Backend {
+	case storage.BackendFS:
+		return fs.OpenMetaStore(cfg.Path)
+	case storage.BackendMemory:
+		return memory.NewMetaStore(), nil
+	case storage.BackendS3:
+		return s3.NewMetaStore(cfg.S3)
+	default:
+		return nil, fmt.Errorf("%w: %s", storage.ErrUnknownBackend, cfg.Backend)
+	}
+}
@@ -0,0 +1,154 @@
+// compress.go - прозрачное сжатие содержимого поверх любого хранилища
+// Содержимое сжимается при записи, только если сжатие экономит не меньше заданной доли размера,
+// иначе сохраняется как есть. Сжатое содержимое начинается с заголовка (сигнатура и кодек), по которому
+// оно распаковывается при чтении; содержимое без заголовка (записанное до включения сжатия) читается как есть
+// ID содержимого по-прежнему вычисляется вызывающим по исходным байтам
+package compress
+
+import (
+	"bytes"
+	"compress/gzip"
+	"file_server/internal/storage"
+	"fmt"
+	"io"
+)
+
+// magic - сигнатура заголовка сжатого содержимого (двоичная, чтобы не совпасть с началом текстовых файлов)
+const magic = "\x89FSBLOB\n"
+
+// Кодеки содержимого (байт после сигнатуры)
+const (
+	codecRaw  byte = 0 // Без сжатия (заголовок нужен, только если исходное содержимое начинается с сигнатуры)
+	codecGzip byte = 1 // gzip (контрольная сумма CRC-32 проверяется при распаковке)
+)
+
+// headerSize - размер заголовка: сигнатура и кодек
+const headerSize = len(magic) + 1
+
+// DefaultMinSaving - доля размера, которую сжатие должно сэкономить по умолчанию
+const DefaultMinSaving = 0.1
+
+// Config - параметры сжатия
+type Config struct {
+	Enabled   bool    // Сжимать новое содержимое (чтение сжатого содержимого работает всегда)
+	MinSaving float64 // Минимальная доля размера, которую должно сэкономить сжатие (0.1 - 10%)
+}
+
+// BlobStore - хранилище содержимого со сжатием поверх другого хранилища
+// Stat и Iterate возвращают размер содержимого в нижнем хранилище (после сжатия)
+type BlobStore struct {
+	inner storage.BlobStore // Хранилище сжатого содержимого
+	cfg   Config            // Параметры сжатия
+}
+
+// NewBlobStore создает хранилище со сжатием поверх inner
+func NewBlobStore(inner storage.BlobStore, cfg Config) (*BlobStore, error) {
+	if cfg.MinSaving < 0 || cfg.MinSaving >= 1 {
+		return nil, fmt.Errorf("INVALID COMPRESSION MIN SAVING %v: expected value in range [0, 1)", cfg.MinSaving)
+	}
+	return &BlobStore{inner: inner, cfg: cfg}, nil
+}
+
+// Put сжимает содержимое (если сжатие выгодно) и записывает его в нижнее хранилище
+func (s *BlobStore) Put(id string, data []byte) error {
+	return s.inner.Put(id, s.encode(data))
+}
+
+// Get читает содержимое и распаковывает его
+// Содержимое, которое не удается распаковать, возвращает storage.ErrBlobCorrupted
+func (s *BlobStore) Get(id string) ([]byte, error) {
+	data, err := s.inner.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	return decode(data)
+}
+
+// Stat возвращает информацию о содержимом в нижнем хранилище (размер после сжатия)
+func (s *BlobStore) Stat(id string) (storage.BlobInfo, error) {
+	return s.inner.Stat(id)
+}
+
+// Delete удаляет содержимое из нижнего хранилища
+func (s *BlobStore) Delete(id string) error {
+	return s.inner.Delete(id)
+}
+
+// Iterate обходит содержимое нижнего хранилища (размеры после сжатия)
+func (s *BlobStore) Iterate(fn func(info storage.BlobInfo) error) error {
+	return s.inner.Iterate(fn)
+}
+
+// TempFiles возвращает временные файлы нижнего хранилища (пусто, если оно их не оставляет)
+func (s *BlobStore) TempFiles() ([]storage.BlobInfo, error) {
+	temps, ok := s.inner.(storage.TempFileStore)
+	if !ok {
+		return nil, nil
+	}
+	return temps.TempFiles()
+}
+
+// DeleteTempFile удаляет временный файл нижнего хранилища
+func (s *BlobStore) DeleteTempFile(id string) error {
+	temps, ok := s.inner.(storage.TempFileStore)
+	if !ok {
+		return nil
+	}
+	return temps.DeleteTempFile(id)
+}
+
+// Quarantine переносит содержимое нижнего хранилища в карантин
+// Хранилище без карантина оставляет содержимое на месте (так же, как репозиторий поверх такого хранилища)
+func (s *BlobStore) Quarantine(id string) error {
+	quarantiner, ok := s.inner.(storage.Quarantiner)
+	if !ok {
+		return nil
+	}
+	return quarantiner.Quarantine(id)
+}
+
+// encode возвращает содержимое для записи: сжатое с заголовком, если сжатие экономит не меньше MinSaving,
+// иначе исходное (с заголовком без сжатия, только если исходное начинается с сигнатуры)
+func (s *BlobStore) encode(data []byte) []byte {
+	if s.cfg.Enabled && len(data) > 0 {
+		var buf bytes.Buffer
+		buf.WriteString(magic)
+		buf.WriteByte(codecGzip)
+		zw := gzip.NewWriter(&buf)
+		zw.Write(data) // Запись в bytes.Buffer не возвращает ошибок
+		zw.Close()
+		if float64(buf.Len()) <= float64(len(data))*(1-s.cfg.MinSaving) {
+			return buf.Bytes()
+		}
+	}
+
+	if bytes.HasPrefix(data, []byte(magic)) {
+		return append([]byte(magic+string(codecRaw)), data...)
+	}
+	return data
+}
+
+// decode возвращает исходное содержимое по записанному
+func decode(data []byte) ([]byte, error) {
+	if !bytes.HasPrefix(data, []byte(magic)) || len(data) < headerSize {
+		return data, nil // Содержимое без заголовка записано без сжатия
+	}
+
+	payload := data[headerSize:]
+	switch codec := data[len(magic)]; codec {
+	case codecRaw:
+		return payload, nil
+	case codecGzip:
+		zr, err := gzip.NewReader(bytes.NewReader(payload))
+		if err != nil {
+			return nil, fmt.Errorf("%w: gzip header: %v", storage.ErrBlobCorrupted, err)
+		}
+		decoded, err := io.ReadAll(zr)
+		if err != nil {
+			return nil, fmt.Errorf("%w: gzip stream: %v", storage.ErrBlobCorrupted, err)
+		}
+		return decoded, nil
+	default:
+		return nil, fmt.Errorf("%w: unknown codec %d", storage.ErrBlobCorrupted, codec)
+	}
+}
@@ -0,0 +1,110 @@
+package compress
+
+import (
+	"bytes"
+	"crypto/rand"
+	"errors"
+	"file_server/internal/storage"
+	"file_server/internal/storage/memory"
+	"file_server/internal/storage/storagetest"
+	"testing"
+)
+
+// newStore создает хранилище со сжатием поверх хранилища в памяти
+func newStore(t *testing.T, inner storage.BlobStore, enabled bool) *BlobStore {
+	t.Helper()
+	store, err := NewBlobStore(inner, Config{Enabled: enabled, MinSaving: DefaultMinSaving})
+	if err != nil {
+		t.Fatalf("NewBlobStore: %v", err)
+	}
+	return store
+}
+
+func TestBlobStore(t *testing.T) {
+	storagetest.RunBlobStore(t, func(t *testing.T) storage.BlobStore {
+		return newStore(t, memory.NewBlobStore(), true)
+	})
+}
+
+func TestBlobStoreCompressesOnlyWhenWorthIt(t *testing.T) {
+	inner := memory.NewBlobStore()
+	store := newStore(t, inner, true)
+
+	compressible := bytes.Repeat([]byte("BM uncompressed bitmap row "), 1000)
+	random := make([]byte, 4096)
+	rand.Read(random)
+	tests := []struct {
+		name       string
+		data       []byte
+		compressed bool
+	}{
+		{"compressible", compressible, true},
+		{"random", random, false},
+		{"starts with signature", append([]byte(magic), random...), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := store.Put(tt.name, tt.data); err != nil {
+				t.Fatalf("Put: %v", err)
+			}
+			got, err := store.Get(tt.name)
+			if err != nil || !bytes.Equal(got, tt.data) {
+				t.Fatalf("Get = %d bytes, %v; want original %d bytes", len(got), err, len(tt.data))
+			}
+
+			stored, _ := inner.Get(tt.name)
+			if compressed := len(stored) < len(tt.data); compressed != tt.compressed {
+				t.Errorf("stored %d of %d bytes, want compressed = %v", len(stored), len(tt.data), tt.compressed)
+			}
+			info, err := store.Stat(tt.name)
+			if err != nil || info.Size != int64(len(stored)) {
+				t.Errorf("Stat = %+v, %v; want stored size %d", info, err, len(stored))
+			}
+		})
+	}
+}
+
+func TestBlobStoreReadsExistingContent(t *testing.T) {
+	inner := memory.NewBlobStore()
+	data := bytes.Repeat([]byte("<svg></svg>"), 100)
+
+	// Содержимое, записанное до включения сжатия, читается как есть
+	inner.Put("raw", data)
+	if got, err := newStore(t, inner, true).Get("raw"); err != nil || !bytes.Equal(got, data) {
+		t.Errorf("Get of uncompressed content = %v", err)
+	}
+
+	// Сжатое содержимое читается и после отключения сжатия
+	newStore(t, inner, true).Put("compressed", data)
+	if got, err := newStore(t, inner, false).Get("compressed"); err != nil || !bytes.Equal(got, data) {
+		t.Errorf("Get with compression disabled = %v", err)
+	}
+}
+
+func TestBlobStoreReportsCorruptedContent(t *testing.T) {
+	inner := memory.NewBlobStore()
+	store := newStore(t, inner, true)
+	store.Put("a", bytes.Repeat([]byte("a"), 1000))
+
+	// Повреждение сжатого потока (контрольная сумма gzip не совпадает)
+	stored, _ := inner.Get("a")
+	stored[len(stored)-5] ^= 0xff
+	inner.Put("a", stored)
+	if _, err := store.Get("a"); !errors.Is(err, storage.ErrBlobCorrupted) {
+		t.Errorf("Get of damaged stream = %v, want ErrBlobCorrupted", err)
+	}
+
+	// Неизвестный кодек
+	inner.Put("b", []byte(magic+"\x07payload"))
+	if _, err := store.Get("b"); !errors.Is(err, storage.ErrBlobCorrupted) {
+		t.Errorf("Get with unknown codec = %v, want ErrBlobCorrupted", err)
+	}
+}
+
+func TestNewBlobStoreValidatesMinSaving(t *testing.T) {
+	for _, saving := range []float64{-0.1, 1, 2} {
+		if _, err := NewBlobStore(memory.NewBlobStore(), Config{Enabled: true, MinSaving: saving}); err == nil {
+			t.Errorf("MinSaving %v accepted", saving)
+		}
+	}
+}
@@ -0,0 +1,420 @@
+// encrypt.go - шифрование содержимого поверх любого хранилища (AES-256-GCM, конвертная схема)
+// Каждое содержимое шифруется собственным случайным ключом данных, который хранится отдельной записью ключа
+// ("<ID содержимого>.key") зашифрованным мастер-ключом из файла ключей. Форматы записанного содержимого и записи ключа:
+//
+//	содержимое:   сигнатура | режим | nonce | шифротекст с тегом
+//	запись ключа: длина ID ключа | ID мастер-ключа | зашифрованный ключ данных
+//
+// Ротация мастер-ключа (Rewrap) перезаписывает только запись ключа, содержимое не изменяется
+// Содержимое без заголовка (записанное до включения шифрования) при заданных ключах читается только с AllowPlaintext
+// и шифруется при ротации
+package encrypt
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"file_server/internal/storage"
+	"fmt"
+	"strings"
+)
+
+// magic - сигнатура заголовка зашифрованного содержимого
+const magic = "\x89FSENC1\n"
+
+// Режимы содержимого с заголовком
+const (
+	modePlain     = 0 // Открытое содержимое (заголовок только экранирует сигнатуру)
+	modeEncrypted = 1 // Зашифрованное содержимое, ключ данных - в записи ключа
+)
+
+// keySuffix - суффикс ID записи ключа данных
+const keySuffix = ".key"
+
+// Размеры частей заголовка
+const (
+	nonceSize   = 12                            // Nonce AES-GCM
+	tagSize     = 16                            // Тег аутентификации AES-GCM
+	wrappedSize = nonceSize + KeySize + tagSize // Зашифрованный ключ данных с nonce и тегом
+)
+
+// BlobStore - хранилище содержимого с шифрованием поверх другого хранилища
+type BlobStore struct {
+	inner          storage.BlobStore // Хранилище зашифрованного содержимого и записей ключей
+	keys           *KeyRing          // Мастер-ключи (nil - шифрование отключено, зашифрованное содержимое не читается)
+	allowPlaintext bool              // Читать открытое содержимое при заданных ключах (на время перехода на шифрование)
+}
+
+// NewBlobStore создает хранилище с шифрованием поверх inner
+// При keys = nil новое содержимое записывается открытым, а чтение зашифрованного возвращает ErrUnknownKey.
+// При заданных ключах открытое содержимое читается только с allowPlaintext, иначе чтение возвращает
+// storage.ErrBlobAuthFailed: подмена шифротекста открытыми данными не проходит незамеченной
+func NewBlobStore(inner storage.BlobStore, keys *KeyRing, allowPlaintext bool) *BlobStore {
+	return &BlobStore{inner: inner, keys: keys, allowPlaintext: allowPlaintext}
+}
+
+// Put шифрует содержимое активным мастер-ключом и записывает его в нижнее хранилище
+// Новая запись ключа записывается до содержимого: содержимое не остается без своего ключа данных.
+// Перезапись зашифрованного содержимого использует его прежний ключ данных, а запись ключа не изменяется:
+// сбой между двумя записями не должен оставить прежний шифротекст с чужим ключом данных
+func (s *BlobStore) Put(id string, data []byte) error {
+	if strings.HasSuffix(id, keySuffix) {
+		return fmt.Errorf("%w: %s is reserved for data keys", storage.ErrInvalidBlobID, id)
+	}
+	sealed, record, err := s.seal(id, data)
+	if err != nil {
+		return err
+	}
+	if record != nil {
+		if err := s.inner.Put(id+keySuffix, record); err != nil {
+			return err
+		}
+	}
+	return s.inner.Put(id, sealed)
+}
+
+// Get читает и расшифровывает содержимое
+// Несовпадение тега аутентификации возвращает storage.ErrBlobAuthFailed (и storage.ErrBlobCorrupted),
+// содержимое под ключом, которого нет в файле ключей, - ErrUnknownKey
+func (s *BlobStore) Get(id string) ([]byte, error) {
+	data, err := s.inner.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	return s.open(id, data)
+}
+
+// Stat возвращает информацию о содержимом в нижнем хранилище (размер с заголовком шифрования)
+func (s *BlobStore) Stat(id string) (storage.BlobInfo, error) {
+	return s.inner.Stat(id)
+}
+
+// Delete удаляет запись ключа и содержимое из нижнего хранилища
+// Запись ключа удаляется первой: оставшееся после сбоя содержимое без ключа видно проверке хранилища,
+// а запись ключа без содержимого не видна никому (Iterate пропускает записи ключей)
+func (s *BlobStore) Delete(id string) error {
+	if err := s.inner.Delete(id + keySuffix); err != nil && !errors.Is(err, storage.ErrBlobNotFound) {
+		return err
+	}
+	return s.inner.Delete(id)
+}
+
+// Iterate обходит содержимое нижнего хранилища (записи ключей пропускаются)
+func (s *BlobStore) Iterate(fn func(info storage.BlobInfo) error) error {
+	return s.inner.Iterate(func(info storage.BlobInfo) error {
+		if strings.HasSuffix(info.ID, keySuffix) {
+			return nil
+		}
+		return fn(info)
+	})
+}
+
+// TempFiles возвращает временные файлы нижнего хранилища (пусто, если оно их не оставляет)
+func (s *BlobStore) TempFiles() ([]storage.BlobInfo, error) {
+	temps, ok := s.inner.(storage.TempFileStore)
+	if !ok {
+		return nil, nil
+	}
+	return temps.TempFiles()
+}
+
+// DeleteTempFile удаляет временный файл нижнего хранилища
+func (s *BlobStore) DeleteTempFile(id string) error {
+	temps, ok := s.inner.(storage.TempFileStore)
+	if !ok {
+		return nil
+	}
+	return temps.DeleteTempFile(id)
+}
+
+// Quarantine переносит содержимое нижнего хранилища в карантин
+// Хранилище без карантина оставляет содержимое на месте (так же, как репозиторий поверх такого хранилища)
+func (s *BlobStore) Quarantine(id string) error {
+	quarantiner, ok := s.inner.(storage.Quarantiner)
+	if !ok {
+		return nil
+	}
+	if err := quarantiner.Quarantine(id); err != nil {
+		return err
+	}
+
+	// Запись ключа переносится вместе с содержимым, чтобы его можно было расшифровать при разборе
+	if err := quarantiner.Quarantine(id + keySuffix); err != nil && !errors.Is(err, storage.ErrBlobNotFound) {
+		return err
+	}
+	return nil
+}
+
+// Rewrap переводит содержимое на активный мастер-ключ
+// Для зашифрованного другим ключом содержимого перезаписывается только запись ключа с тем же ключом данных
+// (содержимое не изменяется), открытое содержимое шифруется. Возвращает false, если содержимое уже на активном ключе
+func (s *BlobStore) Rewrap(id string) (bool, error) {
+	if s.keys == nil {
+		return false, fmt.Errorf("%w: no key file configured", ErrUnknownKey)
+	}
+	data, err := s.inner.Get(id)
+	if err != nil {
+		return false, err
+	}
+	env, err := parse(data)
+	if err != nil {
+		return false, fmt.Errorf("%w: %s: %v", storage.ErrBlobCorrupted, id, err)
+	}
+
+	// Открытое содержимое шифруется целиком
+	if env == nil || !env.encrypted {
+		plain := data
+		if env != nil {
+			plain = env.payload
+		}
+		if err := s.Put(id, plain); err != nil {
+			return false, err
+		}
+		return true, nil
+	}
+
+	rec, err := s.readKey(id)
+	if err != nil {
+		return false, err
+	}
+	if rec.keyID == s.keys.Active() {
+		return false, nil
+	}
+
+	// Перешифрование ключа данных активным мастер-ключом
+	dataKey, err := s.unwrap(id, rec)
+	if err != nil {
+		return false, err
+	}
+	record, err := s.wrap(id, dataKey)
+	if err != nil {
+		return false, err
+	}
+	if err := s.inner.Put(id+keySuffix, record); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// KeyOf возвращает ID мастер-ключа, которым зашифровано содержимое (пусто для открытого содержимого)
+func (s *BlobStore) KeyOf(id string) (string, error) {
+	data, err := s.inner.Get(id)
+	if err != nil {
+		return "", err
+	}
+	env, err := parse(data)
+	if err != nil {
+		return "", fmt.Errorf("%w: %s: %v", storage.ErrBlobCorrupted, id, err)
+	}
+	if env == nil || !env.encrypted {
+		return "", nil
+	}
+	rec, err := s.readKey(id)
+	if err != nil {
+		return "", err
+	}
+	return rec.keyID, nil
+}
+
+// envelope - разобранный заголовок содержимого
+type envelope struct {
+	encrypted bool   // Содержимое зашифровано (false - открыто, заголовок только экранирует сигнатуру)
+	payload   []byte // nonce и шифротекст с тегом (для открытого содержимого - само содержимое)
+}
+
+// keyRecord - разобранная запись ключа данных
+type keyRecord struct {
+	keyID   string // ID мастер-ключа
+	wrapped []byte // Зашифрованный ключ данных с nonce и тегом
+}
+
+// parse разбирает заголовок, nil - содержимое без заголовка
+func parse(data []byte) (*envelope, error) {
+	if !bytes.HasPrefix(data, []byte(magic)) {
+		return nil, nil
+	}
+	rest := data[len(magic):]
+	if len(rest) < 1 {
+		return nil, fmt.Errorf("truncated header")
+	}
+	switch rest[0] {
+	case modePlain:
+		return &envelope{payload: rest[1:]}, nil
+	case modeEncrypted:
+		if len(rest) < 1+nonceSize+tagSize {
+			return nil, fmt.Errorf("truncated header")
+		}
+		return &envelope{encrypted: true, payload: rest[1:]}, nil
+	default:
+		return nil, fmt.Errorf("unknown mode %d", rest[0])
+	}
+}
+
+// readKey читает и разбирает запись ключа данных содержимого
+// Отсутствующая или поврежденная запись - повреждение содержимого: без ключа данных его не расшифровать
+func (s *BlobStore) readKey(id string) (*keyRecord, error) {
+	data, err := s.inner.Get(id + keySuffix)
+	if errors.Is(err, storage.ErrBlobNotFound) {
+		return nil, fmt.Errorf("%w: %s: data key is missing", storage.ErrBlobCorrupted, id)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < 1 || len(data) != 1+int(data[0])+wrappedSize {
+		return nil, fmt.Errorf("%w: %s: truncated data key", storage.ErrBlobCorrupted, id)
+	}
+	idLen := int(data[0])
+	return &keyRecord{keyID: string(data[1 : 1+idLen]), wrapped: data[1+idLen:]}, nil
+}
+
+// seal возвращает содержимое для записи и новую запись ключа: зашифрованное ключом данных
+// или открытое без ключей. Запись ключа nil, если записывать ее не нужно
+func (s *BlobStore) seal(id string, data []byte) ([]byte, []byte, error) {
+	// Шифрование отключено: открытое содержимое, начинающееся с сигнатуры, экранируется пустым заголовком
+	if s.keys == nil {
+		if bytes.HasPrefix(data, []byte(magic)) {
+			return append([]byte(magic+"\x00"), data...), nil, nil
+		}
+		return data, nil, nil
+	}
+
+	// Ключ данных: прежний ключ перезаписываемого содержимого или новый с записью ключа под активным мастер-ключом
+	var record []byte
+	dataKey, err := s.existingKey(id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if dataKey == nil {
+		dataKey = make([]byte, KeySize)
+		if _, err := rand.Read(dataKey); err != nil {
+			return nil, nil, fmt.Errorf("FAILED TO GENERATE DATA KEY: %w", err)
+		}
+		if record, err = s.wrap(id, dataKey); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	// Шифрование содержимого ключом данных (ID содержимого аутентифицируется вместе с ним)
+	aead, err := newGCM(dataKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	nonce := make([]byte, nonceSize)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, nil, fmt.Errorf("FAILED TO GENERATE NONCE: %w", err)
+	}
+	sealed := make([]byte, 0, len(magic)+1+nonceSize+len(data)+tagSize)
+	sealed = append(sealed, magic...)
+	sealed = append(sealed, modeEncrypted)
+	sealed = append(sealed, nonce...)
+	return aead.Seal(sealed, nonce, data, []byte(id)), record, nil
+}
+
+// existingKey возвращает ключ данных записанного зашифрованного содержимого
+// nil - содержимого нет или его ключ данных недоступен (тогда прежнее содержимое не читается и ключ можно заменить)
+func (s *BlobStore) existingKey(id string) ([]byte, error) {
+	rec, err := s.readKey(id)
+	if errors.Is(err, storage.ErrBlobCorrupted) {
+		return nil, nil // Записи ключа нет или она повреждена
+	}
+	if err != nil {
+		return nil, err
+	}
+	dataKey, err := s.unwrap(id, rec)
+	if errors.Is(err, storage.ErrBlobCorrupted) || errors.Is(err, ErrUnknownKey) {
+		return nil, nil
+	}
+	return dataKey, err
+}
+
+// open возвращает открытое содержимое по записанному
+func (s *BlobStore) open(id string, data []byte) ([]byte, error) {
+	env, err := parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", storage.ErrBlobCorrupted, err)
+	}
+	if env == nil || !env.encrypted {
+		// При заданных ключах открытое содержимое допускается только на время перехода на шифрование
+		if s.keys != nil && !s.allowPlaintext {
+			return nil, fmt.Errorf("%w: %w: content is not encrypted", storage.ErrBlobCorrupted, storage.ErrBlobAuthFailed)
+		}
+		if env == nil {
+			return data, nil // Содержимое записано до включения шифрования
+		}
+		return env.payload, nil
+	}
+
+	// Расшифрование ключа данных и содержимого
+	rec, err := s.readKey(id)
+	if err != nil {
+		return nil, err
+	}
+	dataKey, err := s.unwrap(id, rec)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := newGCM(dataKey)
+	if err != nil {
+		return nil, err
+	}
+	plain, err := aead.Open(nil, env.payload[:nonceSize], env.payload[nonceSize:], []byte(id))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w: content tag mismatch", storage.ErrBlobCorrupted, storage.ErrBlobAuthFailed)
+	}
+	return plain, nil
+}
+
+// wrap возвращает запись ключа с ключом данных, зашифрованным активным мастер-ключом
+// ID мастер-ключа и ID содержимого аутентифицируются вместе с ключом данных
+func (s *BlobStore) wrap(id string, dataKey []byte) ([]byte, error) {
+	keyID := s.keys.Active()
+	master, err := s.keys.key(keyID)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := newGCM(master)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, nonceSize)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("FAILED TO GENERATE NONCE: %w", err)
+	}
+
+	record := make([]byte, 0, 1+len(keyID)+wrappedSize)
+	record = append(record, byte(len(keyID)))
+	record = append(record, keyID...)
+	record = append(record, nonce...)
+	return aead.Seal(record, nonce, dataKey, []byte(keyID+"/"+id)), nil
+}
+
+// unwrap расшифровывает ключ данных из записи ключа
+func (s *BlobStore) unwrap(id string, rec *keyRecord) ([]byte, error) {
+	if s.keys == nil {
+		return nil, fmt.Errorf("%w: %s (no key file configured)", ErrUnknownKey, rec.keyID)
+	}
+	master, err := s.keys.key(rec.keyID)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := newGCM(master)
+	if err != nil {
+		return nil, err
+	}
+	dataKey, err := aead.Open(nil, rec.wrapped[:nonceSize], rec.wrapped[nonceSize:], []byte(rec.keyID+"/"+id))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w: data key tag mismatch", storage.ErrBlobCorrupted, storage.ErrBlobAuthFailed)
+	}
+	return dataKey, nil
+}
+
+// newGCM создает AES-256-GCM для ключа
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("FAILED TO CREATE CIPHER: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
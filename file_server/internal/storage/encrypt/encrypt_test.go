@@ -0,0 +1,299 @@
+package encrypt
+
+import (
+	"bytes"
+	"errors"
+	"file_server/internal/storage"
+	"file_server/internal/storage/memory"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// newKeyFile создает файл ключей с одним активным ключом и возвращает путь к нему
+func newKeyFile(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "keys.json")
+	if _, err := AddKey(path); err != nil {
+		t.Fatalf("AddKey: %v", err)
+	}
+	return path
+}
+
+// loadKeys читает файл ключей и завершает тест при ошибке
+func loadKeys(t *testing.T, path string) *KeyRing {
+	t.Helper()
+	keys, err := LoadKeyRing(path)
+	if err != nil {
+		t.Fatalf("LoadKeyRing: %v", err)
+	}
+	return keys
+}
+
+func TestBlobStoreWithoutKeysStoresPlaintext(t *testing.T) {
+	inner := memory.NewBlobStore()
+	store := NewBlobStore(inner, nil, false)
+
+	// Открытое содержимое записывается как есть, а начинающееся с сигнатуры - экранируется
+	for id, data := range map[string][]byte{"plain": []byte("plain"), "signature": []byte(magic + "not an envelope")} {
+		if err := store.Put(id, data); err != nil {
+			t.Fatalf("Put: %v", err)
+		}
+		if got, err := store.Get(id); err != nil || !bytes.Equal(got, data) {
+			t.Errorf("Get(%s) = %q, %v", id, got, err)
+		}
+	}
+	if stored, _ := inner.Get("plain"); string(stored) != "plain" {
+		t.Errorf("stored %q, want plaintext", stored)
+	}
+	if _, err := store.Get("missing"); !errors.Is(err, storage.ErrBlobNotFound) {
+		t.Errorf("Get of missing content = %v, want ErrBlobNotFound", err)
+	}
+}
+
+func TestBlobStoreEncryptsContent(t *testing.T) {
+	inner := memory.NewBlobStore()
+	keys := loadKeys(t, newKeyFile(t))
+	store := NewBlobStore(inner, keys, false)
+	data := []byte("customer passport scan")
+
+	if err := store.Put("a", data); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	stored, _ := inner.Get("a")
+	if bytes.Contains(stored, data) {
+		t.Error("content is stored in plaintext")
+	}
+	if got, err := store.Get("a"); err != nil || !bytes.Equal(got, data) {
+		t.Errorf("Get = %q, %v", got, err)
+	}
+	if keyID, _ := store.KeyOf("a"); keyID != keys.Active() {
+		t.Errorf("KeyOf = %q, want %q", keyID, keys.Active())
+	}
+
+	// Одинаковое содержимое шифруется разными ключами данных
+	store.Put("b", data)
+	if other, _ := inner.Get("b"); bytes.Equal(other[len(other)-len(data):], stored[len(stored)-len(data):]) {
+		t.Error("same content produced the same ciphertext")
+	}
+
+	// Ключ данных хранится отдельной записью, которая не видна при обходе и удаляется вместе с содержимым
+	if _, err := inner.Stat("a" + keySuffix); err != nil {
+		t.Errorf("data key record: %v", err)
+	}
+	var ids []string
+	store.Iterate(func(info storage.BlobInfo) error {
+		ids = append(ids, info.ID)
+		return nil
+	})
+	if len(ids) != 2 {
+		t.Errorf("Iterate = %v, want content only", ids)
+	}
+	if err := store.Delete("a"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := inner.Stat("a" + keySuffix); !errors.Is(err, storage.ErrBlobNotFound) {
+		t.Errorf("data key record after Delete: %v", err)
+	}
+	if err := store.Put("c"+keySuffix, data); !errors.Is(err, storage.ErrInvalidBlobID) {
+		t.Errorf("Put of a reserved ID = %v, want ErrInvalidBlobID", err)
+	}
+}
+
+// failingBlobs - хранилище, в котором запись и удаление заданного ID завершаются ошибкой
+type failingBlobs struct {
+	storage.BlobStore
+	failID string
+}
+
+var errInjected = errors.New("injected failure")
+
+func (f *failingBlobs) Put(id string, data []byte) error {
+	if id == f.failID {
+		return errInjected
+	}
+	return f.BlobStore.Put(id, data)
+}
+
+func (f *failingBlobs) Delete(id string) error {
+	if id == f.failID {
+		return errInjected
+	}
+	return f.BlobStore.Delete(id)
+}
+
+func TestBlobStoreOverwriteKeepsDataKey(t *testing.T) {
+	inner := memory.NewBlobStore()
+	keys := loadKeys(t, newKeyFile(t))
+	NewBlobStore(inner, keys, false).Put("a", []byte("original content"))
+	record, _ := inner.Get("a" + keySuffix)
+
+	// Перезапись существующего содержимого, оборванная до записи шифротекста, не трогает запись ключа
+	failing := NewBlobStore(&failingBlobs{BlobStore: inner, failID: "a"}, keys, false)
+	if err := failing.Put("a", []byte("new content")); !errors.Is(err, errInjected) {
+		t.Fatalf("Put = %v, want injected failure", err)
+	}
+	store := NewBlobStore(inner, keys, false)
+	if got, err := store.Get("a"); err != nil || string(got) != "original content" {
+		t.Errorf("Get after interrupted overwrite = %q, %v", got, err)
+	}
+
+	// Успешная перезапись шифруется прежним ключом данных
+	if err := store.Put("a", []byte("new content")); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	if after, _ := inner.Get("a" + keySuffix); !bytes.Equal(after, record) {
+		t.Error("overwrite replaced the data key record")
+	}
+	if got, err := store.Get("a"); err != nil || string(got) != "new content" {
+		t.Errorf("Get after overwrite = %q, %v", got, err)
+	}
+}
+
+func TestBlobStoreDeleteRemovesKeyFirst(t *testing.T) {
+	inner := memory.NewBlobStore()
+	keys := loadKeys(t, newKeyFile(t))
+	NewBlobStore(inner, keys, false).Put("a", []byte("content"))
+
+	// Содержимое, которое не удалось удалить, остается видимым при обходе, запись ключа уже удалена
+	store := NewBlobStore(&failingBlobs{BlobStore: inner, failID: "a"}, keys, false)
+	if err := store.Delete("a"); !errors.Is(err, errInjected) {
+		t.Fatalf("Delete = %v, want injected failure", err)
+	}
+	if _, err := inner.Stat("a" + keySuffix); !errors.Is(err, storage.ErrBlobNotFound) {
+		t.Errorf("data key record after failed Delete: %v", err)
+	}
+	var ids []string
+	store.Iterate(func(info storage.BlobInfo) error {
+		ids = append(ids, info.ID)
+		return nil
+	})
+	if len(ids) != 1 || ids[0] != "a" {
+		t.Errorf("Iterate = %v, want the remaining content", ids)
+	}
+}
+
+func TestBlobStoreDetectsTampering(t *testing.T) {
+	inner := memory.NewBlobStore()
+	store := NewBlobStore(inner, loadKeys(t, newKeyFile(t)), false)
+	store.Put("a", []byte("original content"))
+	stored, _ := inner.Get("a")
+	record, _ := inner.Get("a" + keySuffix)
+
+	// change возвращает ID, содержимое и запись ключа, записываемые в хранилище
+	tamper := func(name string, change func(data, record []byte) (string, []byte, []byte)) {
+		t.Run(name, func(t *testing.T) {
+			id, data, rec := change(bytes.Clone(stored), bytes.Clone(record))
+			inner.Put(id, data)
+			inner.Put(id+keySuffix, rec)
+			_, err := store.Get(id)
+			if !errors.Is(err, storage.ErrBlobAuthFailed) || !errors.Is(err, storage.ErrBlobCorrupted) {
+				t.Errorf("Get = %v, want authentication failure", err)
+			}
+		})
+	}
+	tamper("ciphertext", func(data, rec []byte) (string, []byte, []byte) {
+		data[len(data)-1] ^= 1
+		return "a", data, rec
+	})
+	tamper("data key", func(data, rec []byte) (string, []byte, []byte) {
+		rec[len(rec)-1] ^= 1
+		return "a", data, rec
+	})
+	tamper("moved to another ID", func(data, rec []byte) (string, []byte, []byte) {
+		return "b", data, rec
+	})
+	tamper("replaced with plaintext", func(data, rec []byte) (string, []byte, []byte) {
+		return "a", []byte("forged content"), rec
+	})
+
+	// Содержимое без записи ключа считается поврежденным
+	inner.Put("c", stored)
+	if _, err := store.Get("c"); !errors.Is(err, storage.ErrBlobCorrupted) {
+		t.Errorf("Get without data key = %v, want ErrBlobCorrupted", err)
+	}
+}
+
+func TestBlobStoreRequiresKnownKey(t *testing.T) {
+	inner := memory.NewBlobStore()
+	NewBlobStore(inner, loadKeys(t, newKeyFile(t)), false).Put("a", []byte("secret"))
+
+	// Без файла ключей и с чужим файлом ключей содержимое не читается, но и не считается поврежденным
+	for name, keys := range map[string]*KeyRing{"no keys": nil, "other keys": loadKeys(t, newKeyFile(t))} {
+		_, err := NewBlobStore(inner, keys, false).Get("a")
+		if !errors.Is(err, ErrUnknownKey) || errors.Is(err, storage.ErrBlobCorrupted) {
+			t.Errorf("%s: Get = %v, want ErrUnknownKey", name, err)
+		}
+	}
+
+	// Открытое содержимое читается без ключей, а при заданных ключах - только с разрешением на время перехода
+	inner.Put("plain", []byte("written before encryption"))
+	keys := loadKeys(t, newKeyFile(t))
+	for name, store := range map[string]*BlobStore{"no keys": NewBlobStore(inner, nil, false), "allowed": NewBlobStore(inner, keys, true)} {
+		if got, err := store.Get("plain"); err != nil || string(got) != "written before encryption" {
+			t.Errorf("%s: Get of plaintext = %q, %v", name, got, err)
+		}
+	}
+	if _, err := NewBlobStore(inner, keys, false).Get("plain"); !errors.Is(err, storage.ErrBlobAuthFailed) {
+		t.Errorf("Get of plaintext with keys = %v, want ErrBlobAuthFailed", err)
+	}
+}
+
+func TestBlobStoreRewrap(t *testing.T) {
+	inner := memory.NewBlobStore()
+	path := newKeyFile(t)
+	oldKeys := loadKeys(t, path)
+	NewBlobStore(inner, oldKeys, false).Put("a", []byte("rotated content"))
+	inner.Put("plain", []byte("legacy content"))
+	before, _ := inner.Get("a")
+	recordBefore, _ := inner.Get("a" + keySuffix)
+
+	// Новый активный ключ
+	if _, err := AddKey(path); err != nil {
+		t.Fatalf("AddKey: %v", err)
+	}
+	keys := loadKeys(t, path)
+	store := NewBlobStore(inner, keys, false)
+	for _, id := range []string{"a", "plain"} {
+		if changed, err := store.Rewrap(id); err != nil || !changed {
+			t.Fatalf("Rewrap(%s) = %v, %v", id, changed, err)
+		}
+		if keyID, _ := store.KeyOf(id); keyID != keys.Active() {
+			t.Errorf("KeyOf(%s) = %q after rotation, want %q", id, keyID, keys.Active())
+		}
+	}
+	if changed, err := store.Rewrap("a"); err != nil || changed {
+		t.Errorf("second Rewrap = %v, %v", changed, err)
+	}
+
+	// Содержимое не перезаписывается, меняется только запись ключа
+	after, _ := inner.Get("a")
+	recordAfter, _ := inner.Get("a" + keySuffix)
+	if !bytes.Equal(before, after) || bytes.Equal(recordBefore, recordAfter) {
+		t.Error("rotation rewrote the content or left the data key record unchanged")
+	}
+
+	// После ротации прежний ключ не нужен
+	onlyNew := &KeyRing{active: keys.Active(), keys: map[string][]byte{keys.Active(): keys.keys[keys.Active()]}}
+	for id, want := range map[string]string{"a": "rotated content", "plain": "legacy content"} {
+		if got, err := NewBlobStore(inner, onlyNew, false).Get(id); err != nil || string(got) != want {
+			t.Errorf("Get(%s) with new key only = %q, %v", id, got, err)
+		}
+	}
+}
+
+func TestLoadKeyRingValidatesKeys(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"wrong id":       `{"active": "k0000000000000000", "keys": {"k0000000000000000": "AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA="}}`,
+		"short key":      `{"active": "k1", "keys": {"k1": "AAAA"}}`,
+		"missing active": `{"active": "k1", "keys": {}}`,
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name+".json")
+		os.WriteFile(path, []byte(content), 0600)
+		if _, err := LoadKeyRing(path); !errors.Is(err, ErrInvalidKeyFile) {
+			t.Errorf("%s: LoadKeyRing = %v, want ErrInvalidKeyFile", name, err)
+		}
+	}
+}
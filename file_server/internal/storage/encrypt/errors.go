@@ -0,0 +1,8 @@
+package encrypt
+
+import "errors"
+
+var (
+	ErrUnknownKey     = errors.New("ENCRYPTION KEY NOT FOUND") // Содержимое зашифровано ключом, которого нет в файле ключей
+	ErrInvalidKeyFile = errors.New("INVALID KEY FILE")
+)
@@ -0,0 +1,138 @@
+// keys.go - мастер-ключи шифрования и файл ключей
+// Файл ключей - JSON с активным ключом и всеми ключами, которыми может быть зашифровано содержимое:
+//
+//	{"active": "k3f2a...", "keys": {"k3f2a...": "<base64, 32 байта>", "k91c0...": "..."}}
+//
+// ID ключа вычисляется по самому ключу, поэтому ключ с другим значением не может выдать себя за сохраненный:
+// содержимое под отсутствующим ключом сообщает об ошибке ключа, а не о повреждении
+package encrypt
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// KeySize - размер мастер-ключа и ключа данных (AES-256)
+const KeySize = 32
+
+// keyFile - формат файла ключей
+type keyFile struct {
+	Active string            `json:"active"` // ID ключа, которым шифруется новое содержимое
+	Keys   map[string]string `json:"keys"`   // Ключи (ID -> ключ в base64)
+}
+
+// KeyRing - набор мастер-ключей: активный шифрует новое содержимое, остальные нужны для чтения до ротации
+type KeyRing struct {
+	active string            // ID активного ключа
+	keys   map[string][]byte // Ключи (ID -> ключ)
+}
+
+// KeyID возвращает ID ключа: префикс SHA-256 хэша ключа
+func KeyID(key []byte) string {
+	sum := sha256.Sum256(key)
+	return "k" + hex.EncodeToString(sum[:8])
+}
+
+// LoadKeyRing читает файл ключей
+func LoadKeyRing(path string) (*KeyRing, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("FAILED TO READ KEY FILE: %w", err)
+	}
+	var file keyFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("FAILED TO PARSE KEY FILE: %w", err)
+	}
+
+	// Проверка ключей: размер и соответствие ID значению ключа
+	ring := &KeyRing{active: file.Active, keys: make(map[string][]byte, len(file.Keys))}
+	for id, encoded := range file.Keys {
+		key, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil || len(key) != KeySize {
+			return nil, fmt.Errorf("%w: key %s must be %d bytes in base64", ErrInvalidKeyFile, id, KeySize)
+		}
+		if KeyID(key) != id {
+			return nil, fmt.Errorf("%w: key %s does not match its ID (expected %s)", ErrInvalidKeyFile, id, KeyID(key))
+		}
+		ring.keys[id] = key
+	}
+	if _, exists := ring.keys[ring.active]; !exists {
+		return nil, fmt.Errorf("%w: active key %q is not in the file", ErrInvalidKeyFile, ring.active)
+	}
+
+	return ring, nil
+}
+
+// Active возвращает ID активного ключа
+func (k *KeyRing) Active() string {
+	return k.active
+}
+
+// key возвращает ключ по ID, ErrUnknownKey - если ключа нет в наборе
+func (k *KeyRing) key(id string) ([]byte, error) {
+	key, exists := k.keys[id]
+	if !exists {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownKey, id)
+	}
+	return key, nil
+}
+
+// AddKey создает новый мастер-ключ, делает его активным и сохраняет файл ключей
+// Отсутствующий файл создается; прежние ключи остаются в файле для чтения содержимого до завершения ротации
+// Возвращает ID нового ключа
+func AddKey(path string) (string, error) {
+	file := keyFile{Keys: make(map[string]string)}
+	data, err := os.ReadFile(path)
+	switch {
+	case err == nil:
+		if _, err := LoadKeyRing(path); err != nil {
+			return "", err // Поврежденный файл не перезаписывается
+		}
+		json.Unmarshal(data, &file)
+	case !errors.Is(err, os.ErrNotExist):
+		return "", fmt.Errorf("FAILED TO READ KEY FILE: %w", err)
+	}
+
+	// Генерация ключа
+	key := make([]byte, KeySize)
+	if _, err := rand.Read(key); err != nil {
+		return "", fmt.Errorf("FAILED TO GENERATE KEY: %w", err)
+	}
+	id := KeyID(key)
+	file.Keys[id] = base64.StdEncoding.EncodeToString(key)
+	file.Active = id
+
+	// Атомарная запись файла, доступного только владельцу
+	data, err = json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("FAILED TO ENCODE KEY FILE: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-keys-*")
+	if err != nil {
+		return "", fmt.Errorf("FAILED TO WRITE KEY FILE: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		tmp.Close()
+		return "", fmt.Errorf("FAILED TO WRITE KEY FILE: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return "", fmt.Errorf("FAILED TO WRITE KEY FILE: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return "", fmt.Errorf("FAILED TO WRITE KEY FILE: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return "", fmt.Errorf("FAILED TO WRITE KEY FILE: %w", err)
+	}
+
+	return id, nil
+}
@@ -0,0 +1,12 @@
+package storage
+
+import "errors"
+
+var (
+	ErrBlobNotFound   = errors.New("BLOB NOT FOUND")
+	ErrInvalidBlobID  = errors.New("INVALID BLOB ID")
+	ErrUnknownBackend = errors.New("UNKNOWN STORAGE BACKEND")
+	ErrLowDiskSpace   = errors.New("LOW DISK SPACE")
+	ErrBlobCorrupted  = errors.New("BLOB IS CORRUPTED")          // Содержимое прочитано, но не может быть декодировано
+	ErrBlobAuthFailed = errors.New("BLOB AUTHENTICATION FAILED") // Тег аутентификации зашифрованного содержимого не совпал
+)
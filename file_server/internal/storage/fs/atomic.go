@@ -0,0 +1,58 @@
+// atomic.go - атомарная запись файлов
+// Файл пишется во временный файл в той же директории, сбрасывается на диск и переименовывается в итоговое имя,
+// поэтому при сбое на диске остается либо полный файл, либо временный файл, который удаляется при старте
+package fs
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// tempPrefix - префикс временных файлов в директории хранения
+const tempPrefix = ".tmp-"
+
+// writeFileAtomic атомарно записывает data в dir/name
+func writeFileAtomic(dir, name string, data []byte) error {
+	// Временный файл в той же директории (rename атомарен только в пределах одной файловой системы)
+	tmp, err := os.CreateTemp(dir, tempPrefix+name+"-*")
+	if err != nil {
+		return fmt.Errorf("FAILED TO CREATE TEMP FILE: %w", err)
+	}
+	tmpPath := tmp.Name()
+
+	// Запись и сброс содержимого на диск до переименования
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmpPath)
+		return fmt.Errorf("FAILED TO WRITE TEMP FILE: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		os.Remove(tmpPath)
+		return fmt.Errorf("FAILED TO SYNC TEMP FILE: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("FAILED TO CLOSE TEMP FILE: %w", err)
+	}
+	if err := os.Chmod(tmpPath, 0644); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("FAILED TO CHMOD TEMP FILE: %w", err)
+	}
+
+	// Атомарная замена и сброс директории, чтобы переименование пережило сбой питания
+	if err := os.Rename(tmpPath, filepath.Join(dir, name)); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("FAILED TO RENAME TEMP FILE: %w", err)
+	}
+	syncDir(dir)
+
+	return nil
+}
+
+// isTempFile проверяет, является ли файл временным файлом незавершенной записи
+func isTempFile(name string) bool {
+	return strings.HasPrefix(name, tempPrefix)
+}
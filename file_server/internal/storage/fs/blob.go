@@ -0,0 +1,298 @@
+// blob.go - хранилище содержимого файлов на локальном диске
+// Каждый файл хранится под своим ID в поддиректории шарда (см. layout.go), служебные файлы начинаются с точки
+package fs
+
+import (
+	"errors"
+	"file_server/internal/storage"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	scanWorkers       = 16            // Количество шардов, обходимых параллельно
+	quarantineDirName = ".quarantine" // Служебная директория поврежденного содержимого
+)
+
+// errScanStopped - внутренний сигнал остановки параллельного обхода
+var errScanStopped = errors.New("scan stopped")
+
+// BlobStore - хранилище содержимого файлов в директории на диске
+type BlobStore struct {
+	dir   string // Директория хранения
+	depth int    // Глубина раскладки по шардам (0 - все файлы в директории хранения)
+}
+
+// NewBlobStore создает хранилище в директории dir с раскладкой глубины depth
+// Если файлы хранятся в раскладке другой глубины (например, плоской), они переносятся до возврата
+func NewBlobStore(dir string, depth int) (*BlobStore, error) {
+	if depth < 0 || depth > MaxShardDepth {
+		return nil, fmt.Errorf("%w: %d (allowed 0-%d)", ErrInvalidShardDepth, depth, MaxShardDepth)
+	}
+
+	// Создание директории хранения файлов (если не существует)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("FAILED TO CREATE STORAGE DIRECTORY: %w", err)
+	}
+
+	// Перенос файлов в раскладку заданной глубины
+	current, err := readLayout(dir)
+	if err != nil {
+		return nil, err
+	}
+	if current != depth {
+		if err := migrateLayout(dir, current, depth); err != nil {
+			return nil, fmt.Errorf("FAILED TO MIGRATE STORAGE LAYOUT: %w", err)
+		}
+	}
+
+	return &BlobStore{dir: dir, depth: depth}, nil
+}
+
+// shardDir возвращает директорию шарда и путь к файлу содержимого
+func (s *BlobStore) shardDir(id string) (string, string, error) {
+	if err := storage.ValidateBlobID(id); err != nil {
+		return "", "", err
+	}
+	dir := filepath.Join(s.dir, shardPath(id, s.depth))
+	return dir, filepath.Join(dir, id), nil
+}
+
+// path возвращает путь к файлу содержимого
+func (s *BlobStore) path(id string) (string, error) {
+	_, path, err := s.shardDir(id)
+	return path, err
+}
+
+// Put атомарно записывает содержимое на диск
+func (s *BlobStore) Put(id string, data []byte) error {
+	dir, _, err := s.shardDir(id)
+	if err != nil {
+		return err
+	}
+	if err := makeShardDirs(s.dir, dir); err != nil {
+		return err
+	}
+	return writeFileAtomic(dir, id, data)
+}
+
+// Get читает содержимое с диска
+func (s *BlobStore) Get(id string) ([]byte, error) {
+	path, err := s.path(id)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, storage.ErrBlobNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("FAILED TO READ FILE: %w", err)
+	}
+
+	return data, nil
+}
+
+// Stat возвращает размер и время записи содержимого
+func (s *BlobStore) Stat(id string) (storage.BlobInfo, error) {
+	path, err := s.path(id)
+	if err != nil {
+		return storage.BlobInfo{}, err
+	}
+
+	info, err := os.Stat(path)
+	if errors.Is(err, fs.ErrNotExist) || (err == nil && !info.Mode().IsRegular()) {
+		return storage.BlobInfo{}, storage.ErrBlobNotFound
+	}
+	if err != nil {
+		return storage.BlobInfo{}, fmt.Errorf("FAILED TO STAT FILE: %w", err)
+	}
+
+	return storage.BlobInfo{ID: id, Size: info.Size(), ModTime: info.ModTime()}, nil
+}
+
+// Delete удаляет файл содержимого
+func (s *BlobStore) Delete(id string) error {
+	path, err := s.path(id)
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("FAILED TO DELETE FILE: %w", err)
+	}
+
+	return nil
+}
+
+// Quarantine переносит файл содержимого в служебную директорию карантина (<id>-<время переноса>)
+// Файл переносится атомарным rename, поэтому содержимое не копируется и не теряется при сбое
+func (s *BlobStore) Quarantine(id string) error {
+	path, err := s.path(id)
+	if err != nil {
+		return err
+	}
+	dir := filepath.Join(s.dir, quarantineDirName)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("FAILED TO CREATE QUARANTINE DIRECTORY: %w", err)
+	}
+
+	target := filepath.Join(dir, id+"-"+time.Now().UTC().Format("20060102T150405.000000000"))
+	if err := os.Rename(path, target); err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return storage.ErrBlobNotFound
+		}
+		return fmt.Errorf("FAILED TO QUARANTINE FILE: %w", err)
+	}
+	syncDir(filepath.Dir(path))
+	syncDir(dir)
+
+	return nil
+}
+
+// Iterate обходит файлы содержимого, шарды первого уровня обходятся параллельно
+// fn вызывается последовательно (не конкурентно); временные файлы пропускаются (см. TempFiles)
+// Прогресс длительного обхода (например, при запуске с большим хранилищем) периодически логируется
+func (s *BlobStore) Iterate(fn func(info storage.BlobInfo) error) error {
+	// Шарды первого уровня (директория хранения для плоской раскладки)
+	shards := []string{s.dir}
+	if s.depth > 0 {
+		entries, err := os.ReadDir(s.dir)
+		if err != nil {
+			return fmt.Errorf("FAILED TO READ STORAGE DIRECTORY: %w", err)
+		}
+		shards = shards[:0]
+		for _, entry := range entries {
+			if entry.IsDir() && isShardName(entry.Name()) {
+				shards = append(shards, filepath.Join(s.dir, entry.Name()))
+			}
+		}
+	}
+
+	var (
+		mutex    sync.Mutex // Защищает состояние обхода и вызовы fn
+		firstErr error      // Первая ошибка (fn или чтения директории)
+		scanned  int        // Обойдено шардов
+		blobs    int        // Найдено файлов содержимого
+		start    = time.Now()
+		lastLog  = start
+		jobs     = make(chan string)
+		wg       sync.WaitGroup
+	)
+
+	// visit обрабатывает один файл шарда
+	visit := func(path string, entry fs.DirEntry) error {
+		if isTempFile(entry.Name()) {
+			return nil
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return nil // Файл удален после чтения директории
+		}
+
+		mutex.Lock()
+		defer mutex.Unlock()
+		if firstErr != nil {
+			return errScanStopped
+		}
+		if err := fn(storage.BlobInfo{ID: entry.Name(), Size: info.Size(), ModTime: info.ModTime()}); err != nil {
+			firstErr = err
+			return errScanStopped
+		}
+		blobs++
+		return nil
+	}
+
+	// Параллельный обход шардов
+	for i := 0; i < min(scanWorkers, len(shards)); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for shard := range jobs {
+				depth := max(s.depth-1, 0)
+				err := walkLayout(shard, depth, visit)
+
+				mutex.Lock()
+				if err != nil && !errors.Is(err, errScanStopped) && firstErr == nil {
+					firstErr = err
+				}
+				scanned++
+				if time.Since(lastLog) >= progressInterval {
+					log.Printf("Blob scan: %d/%d shards, %d blobs", scanned, len(shards), blobs)
+					lastLog = time.Now()
+				}
+				mutex.Unlock()
+			}
+		}()
+	}
+	for _, shard := range shards {
+		jobs <- shard
+	}
+	close(jobs)
+	wg.Wait()
+
+	if firstErr != nil {
+		return firstErr
+	}
+	if lastLog != start {
+		log.Printf("Blob scan finished: %d blobs in %v", blobs, time.Since(start).Round(time.Millisecond))
+	}
+	return nil
+}
+
+// TempFiles возвращает временные файлы записей в директории хранения и ее шардах
+// ID временного файла - путь относительно директории хранения; служебные директории (журнал) не обходятся
+func (s *BlobStore) TempFiles() ([]storage.BlobInfo, error) {
+	var temps []storage.BlobInfo
+	err := filepath.WalkDir(s.dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil // Шард удален во время обхода
+			}
+			return err
+		}
+		if entry.IsDir() {
+			if path != s.dir && strings.HasPrefix(entry.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !entry.Type().IsRegular() || !isTempFile(entry.Name()) {
+			return nil
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return nil // Запись завершилась после чтения директории
+		}
+		rel, err := filepath.Rel(s.dir, path)
+		if err != nil {
+			return err
+		}
+		temps = append(temps, storage.BlobInfo{ID: filepath.ToSlash(rel), Size: info.Size(), ModTime: info.ModTime()})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("FAILED TO READ STORAGE DIRECTORY: %w", err)
+	}
+	return temps, nil
+}
+
+// DeleteTempFile удаляет временный файл по пути относительно директории хранения
+// Удаляются только временные файлы внутри директории хранения, для других путей возвращается ErrInvalidBlobID
+func (s *BlobStore) DeleteTempFile(id string) error {
+	rel := filepath.FromSlash(id)
+	if !filepath.IsLocal(rel) || !isTempFile(filepath.Base(rel)) {
+		return storage.ErrInvalidBlobID
+	}
+	if err := os.Remove(filepath.Join(s.dir, rel)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("FAILED TO DELETE TEMP FILE: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,8 @@
+package fs
+
+import "errors"
+
+var (
+	ErrInvalidShardDepth = errors.New("INVALID SHARD DEPTH")
+	ErrInvalidLayout     = errors.New("INVALID STORAGE LAYOUT FILE")
+)
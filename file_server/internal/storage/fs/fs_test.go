@@ -0,0 +1,388 @@
+package fs
+
+import (
+	"bytes"
+	"errors"
+	"file_server/internal/storage"
+	"file_server/internal/storage/storagetest"
+	"file_server/pkg/model"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+)
+
+// openMeta открывает журнал метаданных в директории dir и закрывает его по окончании теста
+func openMeta(t *testing.T, dir string) *MetaStore {
+	t.Helper()
+	store, err := OpenMetaStore(dir)
+	if err != nil {
+		t.Fatalf("OpenMetaStore: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+	return store
+}
+
+// load возвращает метаданные из журнала
+func load(t *testing.T, store *MetaStore) map[string]*model.FileInfo {
+	t.Helper()
+	files, err := store.Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	return files
+}
+
+func TestBlobStore(t *testing.T) {
+	for _, depth := range []int{0, 1, DefaultShardDepth} {
+		t.Run(fmt.Sprintf("Depth%d", depth), func(t *testing.T) {
+			storagetest.RunBlobStore(t, func(t *testing.T) storage.BlobStore {
+				store, err := NewBlobStore(t.TempDir(), depth)
+				if err != nil {
+					t.Fatalf("NewBlobStore: %v", err)
+				}
+				return store
+			})
+		})
+	}
+}
+
+func TestMetaStore(t *testing.T) {
+	storagetest.RunMetaStore(t, func(t *testing.T) storage.MetaStore {
+		return openMeta(t, t.TempDir())
+	})
+}
+
+func TestBlobStoreIgnoresServiceFiles(t *testing.T) {
+	dir := t.TempDir()
+	openMeta(t, dir) // Служебная директория журнала внутри директории хранения
+	store, err := NewBlobStore(dir, DefaultShardDepth)
+	if err != nil {
+		t.Fatalf("NewBlobStore: %v", err)
+	}
+
+	// Временные файлы прерванных записей в шарде и в корне хранилища
+	shard := filepath.Join(dir, "ab", "cd")
+	if err := os.MkdirAll(shard, 0755); err != nil {
+		t.Fatal(err)
+	}
+	temps := []string{filepath.Join(shard, tempPrefix+"abc-123"), filepath.Join(dir, tempPrefix+".layout-456")}
+	for _, path := range temps {
+		if err := os.WriteFile(path, []byte("trunc"), 0644); err != nil {
+			t.Fatalf("write temp file: %v", err)
+		}
+	}
+
+	// Временные файлы не обходятся как содержимое и не удаляются обходом
+	if err := store.Iterate(func(info storage.BlobInfo) error {
+		t.Errorf("service entry iterated as blob: %s", info.ID)
+		return nil
+	}); err != nil {
+		t.Fatalf("Iterate: %v", err)
+	}
+	for _, path := range temps {
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("temp file removed by Iterate: %v", err)
+		}
+	}
+
+	// Временные файлы перечисляются отдельно и удаляются по ID
+	found, err := store.TempFiles()
+	if err != nil {
+		t.Fatalf("TempFiles: %v", err)
+	}
+	if len(found) != len(temps) {
+		t.Fatalf("TempFiles = %+v, want %d files", found, len(temps))
+	}
+	for _, temp := range found {
+		if err := store.DeleteTempFile(temp.ID); err != nil {
+			t.Errorf("DeleteTempFile(%s): %v", temp.ID, err)
+		}
+	}
+	for _, path := range temps {
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Errorf("temp file was not removed: %v", err)
+		}
+	}
+
+	// Удаляются только временные файлы внутри хранилища
+	for _, id := range []string{"../" + tempPrefix + "x", ".meta/journal.log", "ab/cd/sha256-abcd"} {
+		if err := store.DeleteTempFile(id); !errors.Is(err, storage.ErrInvalidBlobID) {
+			t.Errorf("DeleteTempFile(%q): %v, want ErrInvalidBlobID", id, err)
+		}
+	}
+}
+
+func TestBlobStoreShardsByDigest(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewBlobStore(dir, DefaultShardDepth)
+	if err != nil {
+		t.Fatalf("NewBlobStore: %v", err)
+	}
+
+	id := model.NewBlobID([]byte("image"))
+	if err := store.Put(id, []byte("image")); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	digest := strings.TrimPrefix(id, model.DigestSHA256+"-")
+	if _, err := os.Stat(filepath.Join(dir, digest[:2], digest[2:4], id)); err != nil {
+		t.Errorf("blob is not stored under its digest shard: %v", err)
+	}
+}
+
+// flatFiles записывает файлы в директорию хранения, как до появления раскладки по шардам
+func flatFiles(t *testing.T, dir string, count int) map[string]string {
+	t.Helper()
+	files := make(map[string]string, count)
+	for i := 0; i < count; i++ {
+		data := fmt.Sprintf("file %d", i)
+		id := model.NewBlobID([]byte(data))
+		if err := os.WriteFile(filepath.Join(dir, id), []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+		files[id] = data
+	}
+	return files
+}
+
+// assertBlobs проверяет, что хранилище содержит ровно заданные файлы
+func assertBlobs(t *testing.T, store *BlobStore, want map[string]string) {
+	t.Helper()
+	seen := 0
+	if err := store.Iterate(func(info storage.BlobInfo) error {
+		seen++
+		if _, ok := want[info.ID]; !ok {
+			t.Errorf("unexpected blob %s", info.ID)
+		}
+		return nil
+	}); err != nil {
+		t.Fatalf("Iterate: %v", err)
+	}
+	if seen != len(want) {
+		t.Errorf("Iterate found %d blobs, want %d", seen, len(want))
+	}
+	for id, data := range want {
+		if got, err := store.Get(id); err != nil || string(got) != data {
+			t.Errorf("Get(%s) = %q, %v", id, got, err)
+		}
+	}
+}
+
+func TestBlobStoreMigratesLayout(t *testing.T) {
+	dir := t.TempDir()
+	files := flatFiles(t, dir, 50)
+
+	// Плоская раскладка -> глубина 2 -> глубина 1 -> плоская
+	for _, depth := range []int{DefaultShardDepth, 1, 0} {
+		store, err := NewBlobStore(dir, depth)
+		if err != nil {
+			t.Fatalf("NewBlobStore(depth %d): %v", depth, err)
+		}
+		assertBlobs(t, store, files)
+		if layout, err := readLayout(dir); err != nil || layout != depth {
+			t.Errorf("layout file = %d, %v; want %d", layout, err, depth)
+		}
+	}
+
+	// После возврата к плоской раскладке не остается директорий шардов
+	entries, _ := os.ReadDir(dir)
+	for _, entry := range entries {
+		if entry.IsDir() {
+			t.Errorf("shard directory left after migration: %s", entry.Name())
+		}
+	}
+}
+
+func TestBlobStoreResumesInterruptedMigration(t *testing.T) {
+	dir := t.TempDir()
+	files := flatFiles(t, dir, 20)
+
+	// Прерванная миграция: часть файлов уже перенесена, файл раскладки не записан
+	moved := 0
+	for id := range files {
+		if moved == len(files)/2 {
+			break
+		}
+		shard := filepath.Join(dir, shardPath(id, DefaultShardDepth))
+		if err := os.MkdirAll(shard, 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Rename(filepath.Join(dir, id), filepath.Join(shard, id)); err != nil {
+			t.Fatal(err)
+		}
+		moved++
+	}
+
+	store, err := NewBlobStore(dir, DefaultShardDepth)
+	if err != nil {
+		t.Fatalf("NewBlobStore: %v", err)
+	}
+	assertBlobs(t, store, files)
+}
+
+func TestBlobStoreRejectsInvalidDepth(t *testing.T) {
+	for _, depth := range []int{-1, MaxShardDepth + 1} {
+		if _, err := NewBlobStore(t.TempDir(), depth); !errors.Is(err, ErrInvalidShardDepth) {
+			t.Errorf("NewBlobStore(depth %d): %v, want ErrInvalidShardDepth", depth, err)
+		}
+	}
+}
+
+func TestMetaStoreReplaysJournal(t *testing.T) {
+	dir := t.TempDir()
+	store := openMeta(t, dir)
+	for _, info := range []*model.FileInfo{{ID: "a", Filename: "a.txt"}, {ID: "b", Filename: "b.txt"}} {
+		if err := store.Put(info); err != nil {
+			t.Fatalf("Put: %v", err)
+		}
+	}
+	if err := store.Delete("b"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	store.Close()
+
+	files := load(t, openMeta(t, dir))
+	if len(files) != 1 || files["a"] == nil || files["a"].Filename != "a.txt" {
+		t.Errorf("replayed journal = %v", files)
+	}
+}
+
+func TestMetaStoreRecoversCorruptJournalTail(t *testing.T) {
+	dir := t.TempDir()
+	store := openMeta(t, dir)
+	if err := store.Put(&model.FileInfo{ID: "a"}); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	store.Close()
+
+	// Недописанная запись и запись с неверной контрольной суммой в конце журнала
+	journalPath := filepath.Join(dir, metaDirName, journalFileName)
+	f, err := os.OpenFile(journalPath, os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		t.Fatalf("open journal: %v", err)
+	}
+	f.WriteString("00000000 {\"op\":\"delete\",\"id\":\"a\"}\n")
+	f.WriteString("1234abcd {\"op\":\"put\",\"info\":{\"id\":")
+	f.Close()
+
+	restarted := openMeta(t, dir)
+	if files := load(t, restarted); len(files) != 1 || files["a"] == nil {
+		t.Fatalf("corrupt records were applied: %v", files)
+	}
+
+	// Поврежденный хвост отброшен, новые записи читаются после перезапуска
+	if err := restarted.Put(&model.FileInfo{ID: "b"}); err != nil {
+		t.Fatalf("Put after recovery: %v", err)
+	}
+	restarted.Close()
+
+	data, err := os.ReadFile(journalPath)
+	if err != nil {
+		t.Fatalf("read journal: %v", err)
+	}
+	if bytes.Contains(data, []byte("1234abcd")) {
+		t.Errorf("corrupt tail was not truncated:\n%s", data)
+	}
+	if files := load(t, openMeta(t, dir)); len(files) != 2 {
+		t.Errorf("records after recovery = %v, want a and b", files)
+	}
+}
+
+func TestMetaStoreCompactsJournal(t *testing.T) {
+	dir := t.TempDir()
+	store := openMeta(t, dir)
+
+	// Многократное изменение метаданных одного файла порождает устаревшие записи
+	for i := 0; i < 2*compactMinGarbage; i++ {
+		if err := store.Put(&model.FileInfo{ID: "a", DurationMs: int64(i)}); err != nil {
+			t.Fatalf("Put: %v", err)
+		}
+	}
+	if store.journal.records > compactMinGarbage+1 {
+		t.Errorf("journal has %d records, compaction did not run", store.journal.records)
+	}
+
+	// Записи после сжатия попадают в новый журнал
+	if err := store.Put(&model.FileInfo{ID: "b"}); err != nil {
+		t.Fatalf("Put after compaction: %v", err)
+	}
+	store.Close()
+
+	files := load(t, openMeta(t, dir))
+	if len(files) != 2 || files["a"].DurationMs != 2*compactMinGarbage-1 {
+		t.Errorf("compacted journal = %v", files)
+	}
+}
+
+// shortWriteFile - файл журнала, следующая запись в который обрывается на половине
+type shortWriteFile struct {
+	*os.File
+	fail bool // Оборвать следующую запись
+}
+
+func (f *shortWriteFile) Write(data []byte) (int, error) {
+	if !f.fail {
+		return f.File.Write(data)
+	}
+	f.fail = false
+	n, _ := f.File.Write(data[:len(data)/2])
+	return n, syscall.ENOSPC
+}
+
+func TestMetaStoreRollsBackPartialJournalWrite(t *testing.T) {
+	dir := t.TempDir()
+	store := openMeta(t, dir)
+	if err := store.Put(&model.FileInfo{ID: "a"}); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+
+	// Запись обрывается на половине (например, закончилось место на диске)
+	file := &shortWriteFile{File: store.journal.file.(*os.File), fail: true}
+	store.journal.file = file
+	if err := store.Put(&model.FileInfo{ID: "lost"}); !errors.Is(err, syscall.ENOSPC) {
+		t.Fatalf("Put with short write = %v, want ENOSPC", err)
+	}
+
+	// Следующая запись не склеивается с недописанной и переживает перезапуск
+	if err := store.Put(&model.FileInfo{ID: "b"}); err != nil {
+		t.Fatalf("Put after short write: %v", err)
+	}
+	store.Close()
+
+	files := load(t, openMeta(t, dir))
+	if len(files) != 2 || files["a"] == nil || files["b"] == nil {
+		t.Errorf("journal after short write = %v, want a and b", files)
+	}
+}
+
+func TestMetaStoreKeepsJournalWhenCompactionFails(t *testing.T) {
+	dir := t.TempDir()
+	store := openMeta(t, dir)
+	if err := store.Put(&model.FileInfo{ID: "a"}); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+
+	// Замена журнала невозможна: на месте журнала непустая директория
+	journalPath := store.journal.path
+	blocked := filepath.Join(t.TempDir(), "blocked")
+	if err := os.MkdirAll(filepath.Join(blocked, "entry"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	store.journal.path = blocked
+	if err := store.journal.compact(store.files); err == nil {
+		t.Fatal("compaction over a directory succeeded")
+	}
+	store.journal.path = journalPath
+
+	// Дозапись продолжается в прежний журнал
+	if err := store.Put(&model.FileInfo{ID: "b"}); err != nil {
+		t.Fatalf("Put after failed compaction: %v", err)
+	}
+	store.Close()
+
+	if files := load(t, openMeta(t, dir)); len(files) != 2 {
+		t.Errorf("journal after failed compaction = %v, want a and b", files)
+	}
+}
@@ -0,0 +1,337 @@
+// journal.go - журнал метаданных файлов
+// Каждое изменение метаданных дописывается в журнал в директории хранения и воспроизводится при старте,
+// поэтому оригинальные имена файлов и время создания переживают перезапуск сервера
+package fs
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"file_server/pkg/model"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+const (
+	metaDirName     = ".meta"       // Служебная директория внутри директории хранения
+	journalFileName = "journal.log" // Имя файла журнала
+
+	journalOpPut    = "put"    // Запись метаданных файла (создание или изменение)
+	journalOpDelete = "delete" // Удаление файла
+
+	// Журнал сжимается, когда устаревших записей становится больше, чем актуальных, и не меньше этого порога
+	compactMinGarbage = 1000
+)
+
+// journalRecord - запись журнала
+// В файле каждая запись занимает одну строку: "<crc32 в hex> <json>\n"
+type journalRecord struct {
+	Op   string          `json:"op"`             // Операция: put или delete
+	ID   string          `json:"id,omitempty"`   // ID файла (для delete)
+	Info *model.FileInfo `json:"info,omitempty"` // Метаданные файла (для put)
+}
+
+// journalFile - файл журнала, открытый на дозапись (*os.File; в тестах - с внедренными ошибками)
+type journalFile interface {
+	io.Writer
+	io.Seeker
+	Sync() error
+	Truncate(size int64) error
+	Close() error
+}
+
+// journal - журнал метаданных, открытый на дозапись
+type journal struct {
+	dir     string      // Служебная директория журнала
+	path    string      // Путь к файлу журнала
+	file    journalFile // Файл журнала
+	records int         // Количество записей в файле журнала
+	broken  error       // Ошибка отката недописанной записи (дозапись после нее склеила бы записи)
+}
+
+// openJournal открывает журнал и воспроизводит его
+// Возвращает журнал, готовый к дозаписи, и восстановленные метаданные файлов
+// Поврежденные записи в конце журнала (например, недописанные при сбое) отбрасываются и журнал обрезается
+func openJournal(storagePath string) (*journal, map[string]*model.FileInfo, error) {
+	dir := filepath.Join(storagePath, metaDirName)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, nil, fmt.Errorf("FAILED TO CREATE META DIRECTORY: %w", err)
+	}
+
+	j := &journal{
+		dir:  dir,
+		path: filepath.Join(dir, journalFileName),
+	}
+
+	// Временный файл прерванного сжатия журнала
+	os.Remove(j.path + ".tmp")
+
+	// Воспроизведение существующего журнала
+	files, validSize, err := j.replay()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Открытие журнала на дозапись
+	file, err := os.OpenFile(j.path, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, nil, fmt.Errorf("FAILED TO OPEN JOURNAL: %w", err)
+	}
+
+	// Обрезка поврежденного хвоста, чтобы новые записи не оказались после мусора
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, nil, fmt.Errorf("FAILED TO STAT JOURNAL: %w", err)
+	}
+	if info.Size() > validSize {
+		log.Printf("Metadata journal: discarding %d bytes of corrupt tail", info.Size()-validSize)
+		if err := file.Truncate(validSize); err != nil {
+			file.Close()
+			return nil, nil, fmt.Errorf("FAILED TO TRUNCATE JOURNAL: %w", err)
+		}
+		if err := file.Sync(); err != nil {
+			file.Close()
+			return nil, nil, fmt.Errorf("FAILED TO SYNC JOURNAL: %w", err)
+		}
+	}
+	if _, err := file.Seek(validSize, io.SeekStart); err != nil {
+		file.Close()
+		return nil, nil, fmt.Errorf("FAILED TO SEEK JOURNAL: %w", err)
+	}
+
+	j.file = file
+	return j, files, nil
+}
+
+// replay читает журнал и применяет записи по порядку
+// Возвращает метаданные файлов и размер корректной части журнала
+// Поврежденная запись в середине журнала пропускается, поврежденные записи в конце - отбрасываются
+func (j *journal) replay() (map[string]*model.FileInfo, int64, error) {
+	files := make(map[string]*model.FileInfo)
+
+	file, err := os.Open(j.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return files, 0, nil // Журнала еще нет - новое хранилище
+	}
+	if err != nil {
+		return nil, 0, fmt.Errorf("FAILED TO OPEN JOURNAL: %w", err)
+	}
+	defer file.Close()
+
+	var (
+		offset    int64 // Смещение начала текущей строки
+		validSize int64 // Конец последней корректной записи
+		skipped   int   // Поврежденные записи в середине журнала
+		pending   int   // Поврежденные записи после последней корректной
+	)
+
+	reader := bufio.NewReader(file)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if len(line) == 0 && errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil && !errors.Is(err, io.EOF) {
+			return nil, 0, fmt.Errorf("FAILED TO READ JOURNAL: %w", err)
+		}
+		offset += int64(len(line))
+
+		// Строка без перевода строки в конце файла - недописанная запись
+		record, ok := decodeJournalLine(line)
+		if !ok || !bytes.HasSuffix(line, []byte("\n")) {
+			pending++
+			continue
+		}
+
+		// Корректная запись после поврежденных - повреждение было в середине
+		skipped += pending
+		pending = 0
+
+		record.apply(files)
+		j.records++
+		validSize = offset
+	}
+
+	if skipped > 0 {
+		log.Printf("Metadata journal: skipped %d corrupt records", skipped)
+	}
+
+	return files, validSize, nil
+}
+
+// decodeJournalLine разбирает строку журнала и проверяет контрольную сумму
+func decodeJournalLine(line []byte) (*journalRecord, bool) {
+	line = bytes.TrimSuffix(line, []byte("\n"))
+
+	checksum, payload, found := bytes.Cut(line, []byte(" "))
+	if !found {
+		return nil, false
+	}
+	sum, err := strconv.ParseUint(string(checksum), 16, 32)
+	if err != nil || uint32(sum) != crc32.ChecksumIEEE(payload) {
+		return nil, false
+	}
+
+	var record journalRecord
+	if err := json.Unmarshal(payload, &record); err != nil {
+		return nil, false
+	}
+	switch {
+	case record.Op == journalOpPut && record.Info != nil && record.Info.ID != "":
+	case record.Op == journalOpDelete && record.ID != "":
+	default:
+		return nil, false
+	}
+
+	return &record, true
+}
+
+// apply применяет запись к метаданным файлов
+func (r *journalRecord) apply(files map[string]*model.FileInfo) {
+	switch r.Op {
+	case journalOpPut:
+		files[r.Info.ID] = r.Info
+	case journalOpDelete:
+		delete(files, r.ID)
+	}
+}
+
+// encodeJournalLine кодирует запись в строку журнала
+func encodeJournalLine(record *journalRecord) ([]byte, error) {
+	payload, err := json.Marshal(record)
+	if err != nil {
+		return nil, fmt.Errorf("FAILED TO ENCODE JOURNAL RECORD: %w", err)
+	}
+	return fmt.Appendf(nil, "%08x %s\n", crc32.ChecksumIEEE(payload), payload), nil
+}
+
+// appendPut дописывает в журнал метаданные файла
+func (j *journal) appendPut(info *model.FileInfo) error {
+	return j.append(&journalRecord{Op: journalOpPut, Info: info})
+}
+
+// appendDelete дописывает в журнал удаление файла
+func (j *journal) appendDelete(fileID string) error {
+	return j.append(&journalRecord{Op: journalOpDelete, ID: fileID})
+}
+
+// append дописывает запись в журнал и сбрасывает ее на диск
+// При ошибке записи или сброса журнал обрезается до начала записи: недописанная строка не должна склеиться
+// со следующей записью, иначе при воспроизведении пропадет и та запись, об успехе которой уже сообщено
+func (j *journal) append(record *journalRecord) error {
+	if j.broken != nil {
+		return fmt.Errorf("FAILED TO WRITE JOURNAL: %w", j.broken)
+	}
+	line, err := encodeJournalLine(record)
+	if err != nil {
+		return err
+	}
+
+	// Конец журнала до записи
+	offset, err := j.file.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return fmt.Errorf("FAILED TO SEEK JOURNAL: %w", err)
+	}
+
+	if _, err := j.file.Write(line); err != nil {
+		return j.rollback(offset, fmt.Errorf("FAILED TO WRITE JOURNAL: %w", err))
+	}
+	if err := j.file.Sync(); err != nil {
+		return j.rollback(offset, fmt.Errorf("FAILED TO SYNC JOURNAL: %w", err))
+	}
+	j.records++
+	return nil
+}
+
+// rollback обрезает журнал до offset после неудачной дозаписи и возвращает cause
+// Если обрезать не удалось, дальнейшая дозапись отклоняется до перезапуска (воспроизведение отбросит хвост)
+func (j *journal) rollback(offset int64, cause error) error {
+	if err := j.file.Truncate(offset); err != nil {
+		j.broken = fmt.Errorf("FAILED TO ROLL BACK JOURNAL: %w", err)
+		return fmt.Errorf("%w (%v)", cause, j.broken)
+	}
+	if _, err := j.file.Seek(offset, io.SeekStart); err != nil {
+		j.broken = fmt.Errorf("FAILED TO ROLL BACK JOURNAL: %w", err)
+		return fmt.Errorf("%w (%v)", cause, j.broken)
+	}
+	return cause
+}
+
+// needsCompaction проверяет, накопилось ли в журнале достаточно устаревших записей
+func (j *journal) needsCompaction(live int) bool {
+	garbage := j.records - live
+	return garbage >= compactMinGarbage && garbage > live
+}
+
+// compact перезаписывает журнал, оставляя по одной записи на каждый существующий файл
+// Новый журнал пишется во временный файл и атомарно заменяет старый через rename
+// Файл для дозаписи открывается до замены: после rename нет шагов, которые могут завершиться ошибкой,
+// поэтому журнал не может остаться с дескриптором замененного (удаленного) файла
+func (j *journal) compact(files map[string]*model.FileInfo) error {
+	tmpPath := j.path + ".tmp"
+	tmp, err := os.OpenFile(tmpPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("FAILED TO CREATE COMPACTED JOURNAL: %w", err)
+	}
+
+	// Запись актуальных метаданных
+	writer := bufio.NewWriter(tmp)
+	for _, info := range files {
+		line, err := encodeJournalLine(&journalRecord{Op: journalOpPut, Info: info})
+		if err == nil {
+			_, err = writer.Write(line)
+		}
+		if err != nil {
+			tmp.Close()
+			os.Remove(tmpPath)
+			return fmt.Errorf("FAILED TO WRITE COMPACTED JOURNAL: %w", err)
+		}
+	}
+	if err := writer.Flush(); err != nil {
+		tmp.Close()
+		os.Remove(tmpPath)
+		return fmt.Errorf("FAILED TO WRITE COMPACTED JOURNAL: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		os.Remove(tmpPath)
+		return fmt.Errorf("FAILED TO SYNC COMPACTED JOURNAL: %w", err)
+	}
+
+	// Атомарная замена журнала (при ошибке дозапись продолжается в прежний журнал)
+	if err := os.Rename(tmpPath, j.path); err != nil {
+		tmp.Close()
+		os.Remove(tmpPath)
+		return fmt.Errorf("FAILED TO REPLACE JOURNAL: %w", err)
+	}
+	syncDir(j.dir)
+
+	// Дозапись продолжается в новый журнал через уже открытый дескриптор
+	j.file.Close()
+	j.file = tmp
+	j.records = len(files)
+	j.broken = nil
+
+	return nil
+}
+
+// close закрывает файл журнала
+func (j *journal) close() error {
+	return j.file.Close()
+}
+
+// syncDir сбрасывает на диск содержимое директории (создание и переименование файлов в ней)
+// Ошибки игнорируются: не все файловые системы поддерживают fsync директории
+func syncDir(dir string) {
+	if d, err := os.Open(dir); err == nil {
+		d.Sync()
+		d.Close()
+	}
+}
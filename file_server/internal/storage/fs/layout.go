@@ -0,0 +1,200 @@
+// layout.go - раскладка файлов содержимого по поддиректориям (шардам)
+// Файл с ID sha256-abcd... хранится как ab/cd/sha256-abcd... при глубине 2, поэтому ни одна директория
+// не разрастается до сотен тысяч записей. Текущая глубина записывается в служебный файл .layout,
+// при смене глубины (в том числе при переходе с плоской раскладки) файлы переносятся при запуске
+package fs
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultShardDepth = 2 // Глубина раскладки по умолчанию: 65536 директорий второго уровня
+	MaxShardDepth     = 4 // Максимальная глубина раскладки
+
+	layoutFileName   = ".layout"       // Служебный файл с глубиной раскладки
+	progressInterval = 5 * time.Second // Интервал логирования прогресса длительных операций
+)
+
+// shardPath возвращает поддиректорию файла содержимого относительно директории хранения
+// Шарды берутся из hex хэша в ID (часть после префикса алгоритма), для ID другого вида - из SHA-256 самого ID
+func shardPath(id string, depth int) string {
+	if depth == 0 {
+		return ""
+	}
+	digest := id[strings.LastIndex(id, "-")+1:]
+	if len(digest) < 2*depth || !isLowerHex(digest[:2*depth]) {
+		hash := sha256.Sum256([]byte(id))
+		digest = hex.EncodeToString(hash[:])
+	}
+
+	parts := make([]string, depth)
+	for i := range parts {
+		parts[i] = digest[2*i : 2*i+2]
+	}
+	return filepath.Join(parts...)
+}
+
+// isShardName проверяет, является ли имя директории именем шарда (два hex символа)
+func isShardName(name string) bool {
+	return len(name) == 2 && isLowerHex(name)
+}
+
+// isLowerHex проверяет, что строка состоит из hex символов в нижнем регистре
+func isLowerHex(s string) bool {
+	for _, c := range s {
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+// readLayout читает глубину текущей раскладки (0 для хранилища без файла раскладки - плоская раскладка)
+func readLayout(dir string) (int, error) {
+	data, err := os.ReadFile(filepath.Join(dir, layoutFileName))
+	if errors.Is(err, fs.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("FAILED TO READ STORAGE LAYOUT: %w", err)
+	}
+	depth, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil || depth < 0 || depth > MaxShardDepth {
+		return 0, fmt.Errorf("%w: %q", ErrInvalidLayout, strings.TrimSpace(string(data)))
+	}
+	return depth, nil
+}
+
+// migrateLayout переносит файлы содержимого из раскладки глубины from в раскладку глубины to
+// Каждый файл переносится атомарным rename, файл раскладки обновляется только после переноса всех файлов,
+// поэтому прерванная миграция продолжается при следующем запуске с уже перенесенными файлами на новых местах
+func migrateLayout(dir string, from, to int) error {
+	log.Printf("Storage layout: migrating from depth %d to depth %d", from, to)
+	start := time.Now()
+	lastLog := start
+	moved := 0
+	touched := make(map[string]bool) // Директории, содержимое которых изменилось
+
+	err := walkLayout(dir, from, func(path string, entry fs.DirEntry) error {
+		// Временные файлы прерванной записи не переносятся
+		if isTempFile(entry.Name()) {
+			os.Remove(path)
+			return nil
+		}
+
+		target := filepath.Join(dir, shardPath(entry.Name(), to))
+		if err := makeShardDirs(dir, target); err != nil {
+			return err
+		}
+		if err := os.Rename(path, filepath.Join(target, entry.Name())); err != nil {
+			return fmt.Errorf("FAILED TO MOVE %s: %w", entry.Name(), err)
+		}
+		touched[filepath.Dir(path)] = true
+		touched[target] = true
+		moved++
+
+		if time.Since(lastLog) >= progressInterval {
+			log.Printf("Storage layout: %d files moved", moved)
+			lastLog = time.Now()
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// Переименования должны пережить сбой до записи новой раскладки
+	for path := range touched {
+		syncDir(path)
+	}
+	if err := writeFileAtomic(dir, layoutFileName, []byte(strconv.Itoa(to)+"\n")); err != nil {
+		return fmt.Errorf("FAILED TO WRITE STORAGE LAYOUT: %w", err)
+	}
+
+	// Удаление опустевших шардов прежней раскладки (шарды новой раскладки не пусты и остаются)
+	if from > 0 {
+		removeEmptyShards(dir, from)
+	}
+
+	log.Printf("Storage layout: migration finished, %d files moved in %v", moved, time.Since(start).Round(time.Millisecond))
+	return nil
+}
+
+// walkLayout вызывает fn для каждого файла на уровне depth раскладки (служебные файлы пропускаются)
+// Файлы и директории на других уровнях не затрагиваются
+func walkLayout(dir string, depth int, fn func(path string, entry fs.DirEntry) error) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("FAILED TO READ STORAGE DIRECTORY: %w", err)
+	}
+
+	for _, entry := range entries {
+		path := filepath.Join(dir, entry.Name())
+		switch {
+		case depth > 0 && entry.IsDir() && isShardName(entry.Name()):
+			if err := walkLayout(path, depth-1, fn); err != nil {
+				return err
+			}
+		case depth == 0 && entry.Type().IsRegular() && (!strings.HasPrefix(entry.Name(), ".") || isTempFile(entry.Name())):
+			if err := fn(path, entry); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// makeShardDirs создает директории шарда от директории хранения до target
+// Родительская директория каждой созданной директории сбрасывается на диск
+func makeShardDirs(root, target string) error {
+	rel, err := filepath.Rel(root, target)
+	if err != nil || rel == "." {
+		return err
+	}
+
+	path := root
+	for _, part := range strings.Split(rel, string(filepath.Separator)) {
+		parent := path
+		path = filepath.Join(path, part)
+		err := os.Mkdir(path, 0755)
+		if err == nil {
+			syncDir(parent)
+			continue
+		}
+		if !errors.Is(err, fs.ErrExist) {
+			return fmt.Errorf("FAILED TO CREATE SHARD DIRECTORY: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// removeEmptyShards удаляет пустые директории шардов раскладки глубины depth (снизу вверх)
+func removeEmptyShards(dir string, depth int) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() || !isShardName(entry.Name()) {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		if depth > 1 {
+			removeEmptyShards(path, depth-1)
+		}
+		os.Remove(path) // Удаляет только пустую директорию
+	}
+}
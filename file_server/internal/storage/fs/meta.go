@@ -0,0 +1,96 @@
+// meta.go - хранилище метаданных файлов в журнале на локальном диске
+// Журнал (см. journal.go) хранится в служебной директории внутри директории хранения
+package fs
+
+import (
+	"file_server/internal/storage"
+	"file_server/pkg/model"
+	"fmt"
+	"log"
+	"maps"
+	"sync"
+)
+
+// MetaStore - хранилище метаданных файлов в журнале
+type MetaStore struct {
+	mutex   sync.Mutex                 // Мьютекс для последовательной записи в журнал
+	journal *journal                   // Журнал метаданных
+	files   map[string]*model.FileInfo // Актуальные метаданные (для сжатия журнала)
+}
+
+// OpenMetaStore открывает журнал метаданных в директории хранения dir и воспроизводит его
+func OpenMetaStore(dir string) (*MetaStore, error) {
+	journal, files, err := openJournal(dir)
+	if err != nil {
+		return nil, fmt.Errorf("FAILED TO OPEN METADATA JOURNAL: %w", err)
+	}
+
+	store := &MetaStore{
+		journal: journal,
+		files:   files,
+	}
+
+	// Сжатие журнала, если в нем накопились устаревшие записи
+	store.compactIfNeeded()
+
+	return store, nil
+}
+
+// Load возвращает метаданные, восстановленные из журнала, с учетом последующих изменений
+func (s *MetaStore) Load() (map[string]*model.FileInfo, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return maps.Clone(s.files), nil
+}
+
+// Put дописывает метаданные файла в журнал
+func (s *MetaStore) Put(info *model.FileInfo) error {
+	if err := storage.ValidateBlobID(info.ID); err != nil {
+		return err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if err := s.journal.appendPut(info); err != nil {
+		return err
+	}
+	s.files[info.ID] = info
+	s.compactIfNeeded()
+
+	return nil
+}
+
+// Delete дописывает удаление файла в журнал
+func (s *MetaStore) Delete(fileID string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if _, exists := s.files[fileID]; !exists {
+		return nil
+	}
+	if err := s.journal.appendDelete(fileID); err != nil {
+		return err
+	}
+	delete(s.files, fileID)
+	s.compactIfNeeded()
+
+	return nil
+}
+
+// Close закрывает журнал
+func (s *MetaStore) Close() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.journal.close()
+}
+
+// compactIfNeeded сжимает журнал, если в нем накопились устаревшие записи
+// Вызывается под блокировкой mutex (или до начала конкурентного доступа)
+// Ошибка сжатия не критична: журнал остается корректным, сжатие будет повторено позже
+func (s *MetaStore) compactIfNeeded() {
+	if !s.journal.needsCompaction(len(s.files)) {
+		return
+	}
+	if err := s.journal.compact(s.files); err != nil {
+		log.Printf("Metadata journal compaction failed: %v", err)
+	}
+}
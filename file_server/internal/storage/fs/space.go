@@ -0,0 +1,114 @@
+// space.go - контроль свободного места на томе хранилища
+// При нехватке места запись прерывается на середине и проявляется как непонятная внутренняя ошибка,
+// поэтому загрузки отклоняются заранее, пока свободного места меньше порога; чтение и удаление продолжают работать
+package fs
+
+import (
+	"file_server/internal/storage"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+// spaceCacheTTL - время, в течение которого результат проверки свободного места используется повторно
+// Загрузки не обращаются к файловой системе на каждый запрос, фоновый мониторинг обновляет результат принудительно
+const spaceCacheTTL = time.Second
+
+// SpaceStatus - состояние свободного места
+type SpaceStatus struct {
+	Free      uint64 // Свободно байт
+	Threshold uint64 // Порог, ниже которого загрузки отклоняются
+	Degraded  bool   // Свободного места меньше порога (загрузки отклоняются)
+}
+
+// String форматирует состояние для логов
+func (s SpaceStatus) String() string {
+	state := "ok"
+	if s.Degraded {
+		state = "degraded, uploads refused"
+	}
+	return fmt.Sprintf("%d bytes free, threshold %d (%s)", s.Free, s.Threshold, state)
+}
+
+// SpaceGuard отслеживает свободное место на томе с директорией хранения
+type SpaceGuard struct {
+	dir       string                           // Директория хранения
+	threshold uint64                           // Минимальное свободное место для загрузок
+	statfs    func(dir string) (uint64, error) // Получение свободного места (подменяется в тестах)
+
+	mutex     sync.Mutex  // Защищает результат проверки
+	status    SpaceStatus // Результат последней проверки
+	checkedAt time.Time   // Время последней проверки
+	failed    bool        // Последняя проверка завершилась ошибкой (ошибка логируется один раз)
+}
+
+// NewSpaceGuard создает контроль свободного места для директории dir с порогом threshold байт
+func NewSpaceGuard(dir string, threshold uint64) *SpaceGuard {
+	return &SpaceGuard{
+		dir:       dir,
+		threshold: threshold,
+		statfs:    freeSpace,
+		status:    SpaceStatus{Threshold: threshold},
+	}
+}
+
+// Check проверяет, что после записи size байт свободного места останется не меньше порога
+// Возвращает storage.ErrLowDiskSpace, если загрузку нужно отклонить
+// Если свободное место определить не удалось, загрузка не отклоняется
+func (g *SpaceGuard) Check(size int64) error {
+	status, known := g.refresh(false)
+	if known && status.Free < status.Threshold+uint64(max(size, 0)) {
+		return fmt.Errorf("%w: %d bytes free, %d requested, threshold %d", storage.ErrLowDiskSpace, status.Free, size, status.Threshold)
+	}
+	return nil
+}
+
+// Refresh принудительно проверяет свободное место и возвращает состояние (для фонового мониторинга)
+func (g *SpaceGuard) Refresh() SpaceStatus {
+	status, _ := g.refresh(true)
+	return status
+}
+
+// Status возвращает состояние по результату последней проверки
+func (g *SpaceGuard) Status() SpaceStatus {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	return g.status
+}
+
+// refresh проверяет свободное место, если результат последней проверки устарел (или force)
+// Возвращает false, если свободное место определить не удалось
+// Переход через порог логируется один раз в каждую сторону
+func (g *SpaceGuard) refresh(force bool) (SpaceStatus, bool) {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	if !force && time.Since(g.checkedAt) < spaceCacheTTL {
+		return g.status, !g.failed
+	}
+	g.checkedAt = time.Now()
+
+	// Ошибка проверки не блокирует загрузки
+	free, err := g.statfs(g.dir)
+	if err != nil {
+		if !g.failed {
+			log.Printf("Free space check on %s failed, uploads are not limited: %v", g.dir, err)
+			g.failed = true
+		}
+		g.status = SpaceStatus{Threshold: g.threshold}
+		return g.status, false
+	}
+	g.failed = false
+
+	status := SpaceStatus{Free: free, Threshold: g.threshold, Degraded: free < g.threshold}
+	switch {
+	case status.Degraded && !g.status.Degraded:
+		log.Printf("WARNING: low disk space on %s: %s", g.dir, status)
+	case !status.Degraded && g.status.Degraded:
+		log.Printf("Disk space on %s recovered: %s", g.dir, status)
+	}
+	g.status = status
+
+	return status, true
+}
@@ -0,0 +1,77 @@
+package fs
+
+import (
+	"errors"
+	"file_server/internal/storage"
+	"testing"
+	"time"
+)
+
+func TestSpaceGuardRefusesBelowThreshold(t *testing.T) {
+	guard := NewSpaceGuard(t.TempDir(), 1000)
+	free := uint64(5000)
+	guard.statfs = func(string) (uint64, error) { return free, nil }
+
+	if err := guard.Check(100); err != nil {
+		t.Fatalf("Check with enough space: %v", err)
+	}
+
+	// Запись, после которой места останется меньше порога, отклоняется
+	if err := guard.Check(4500); !errors.Is(err, storage.ErrLowDiskSpace) {
+		t.Errorf("Check beyond threshold: %v, want ErrLowDiskSpace", err)
+	}
+
+	// Место закончилось: состояние обновляется фоновой проверкой
+	free = 500
+	if status := guard.Refresh(); !status.Degraded || status.Free != 500 {
+		t.Errorf("Refresh = %+v, want degraded", status)
+	}
+	if err := guard.Check(1); !errors.Is(err, storage.ErrLowDiskSpace) {
+		t.Errorf("Check when degraded: %v, want ErrLowDiskSpace", err)
+	}
+
+	// Место освободилось
+	free = 2000
+	if status := guard.Refresh(); status.Degraded {
+		t.Errorf("Refresh = %+v, want recovered", status)
+	}
+}
+
+func TestSpaceGuardCachesResult(t *testing.T) {
+	guard := NewSpaceGuard(t.TempDir(), 10)
+	calls := 0
+	guard.statfs = func(string) (uint64, error) {
+		calls++
+		return 100, nil
+	}
+
+	for i := 0; i < 5; i++ {
+		guard.Check(1)
+	}
+	if calls != 1 {
+		t.Errorf("free space read %d times within %v, want 1", calls, spaceCacheTTL)
+	}
+}
+
+func TestSpaceGuardIgnoresStatErrors(t *testing.T) {
+	guard := NewSpaceGuard(t.TempDir(), 10)
+	guard.statfs = func(string) (uint64, error) { return 0, errors.New("unsupported") }
+
+	if err := guard.Check(1); err != nil {
+		t.Errorf("Check when free space is unknown: %v, want nil", err)
+	}
+	guard.checkedAt = time.Time{}
+	if err := guard.Check(1); err != nil {
+		t.Errorf("repeated Check when free space is unknown: %v, want nil", err)
+	}
+}
+
+func TestSpaceGuardReadsVolume(t *testing.T) {
+	free, err := freeSpace(t.TempDir())
+	if err != nil {
+		t.Skipf("free space is not available on this platform: %v", err)
+	}
+	if free == 0 {
+		t.Errorf("freeSpace = 0 on a writable temp directory")
+	}
+}
@@ -0,0 +1,10 @@
+//go:build !unix
+
+package fs
+
+import "errors"
+
+// freeSpace не поддерживается на этой платформе: проверка свободного места отключается
+func freeSpace(dir string) (uint64, error) {
+	return 0, errors.New("FREE SPACE CHECK IS NOT SUPPORTED ON THIS PLATFORM")
+}
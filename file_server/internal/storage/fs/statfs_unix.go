@@ -0,0 +1,17 @@
+//go:build unix
+
+package fs
+
+import (
+	"fmt"
+	"syscall"
+)
+
+// freeSpace возвращает количество байт, доступных для записи непривилегированному процессу на томе с директорией dir
+func freeSpace(dir string) (uint64, error) {
+	var stat syscall.Statfs_t
+	if err := syscall.Statfs(dir, &stat); err != nil {
+		return 0, fmt.Errorf("FAILED TO STAT FILESYSTEM: %w", err)
+	}
+	return stat.Bavail * uint64(stat.Bsize), nil
+}
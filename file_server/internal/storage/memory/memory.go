@@ -0,0 +1,173 @@
+// memory.go - хранилище в памяти процесса
+// Содержимое и метаданные не переживают перезапуск; используется в тестах и для временных серверов
+package memory
+
+import (
+	"file_server/internal/storage"
+	"file_server/pkg/model"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+)
+
+// blob - сохраненное содержимое
+type blob struct {
+	data    []byte    // Содержимое (не изменяется после записи)
+	modTime time.Time // Время записи
+}
+
+// BlobStore - хранилище содержимого файлов в памяти
+type BlobStore struct {
+	mutex       sync.RWMutex    // Мьютекс для thread-safe доступа к содержимому
+	blobs       map[string]blob // Содержимое (ID -> содержимое)
+	quarantined []blob          // Содержимое, перенесенное в карантин
+}
+
+// NewBlobStore создает пустое хранилище содержимого
+func NewBlobStore() *BlobStore {
+	return &BlobStore{
+		blobs: make(map[string]blob),
+	}
+}
+
+// Put сохраняет копию содержимого
+func (s *BlobStore) Put(id string, data []byte) error {
+	if err := storage.ValidateBlobID(id); err != nil {
+		return err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.blobs[id] = blob{data: slices.Clone(data), modTime: time.Now()}
+
+	return nil
+}
+
+// Get возвращает копию содержимого
+func (s *BlobStore) Get(id string) ([]byte, error) {
+	if err := storage.ValidateBlobID(id); err != nil {
+		return nil, err
+	}
+
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	b, exists := s.blobs[id]
+	if !exists {
+		return nil, storage.ErrBlobNotFound
+	}
+
+	return slices.Clone(b.data), nil
+}
+
+// Stat возвращает информацию о содержимом
+func (s *BlobStore) Stat(id string) (storage.BlobInfo, error) {
+	if err := storage.ValidateBlobID(id); err != nil {
+		return storage.BlobInfo{}, err
+	}
+
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	b, exists := s.blobs[id]
+	if !exists {
+		return storage.BlobInfo{}, storage.ErrBlobNotFound
+	}
+
+	return storage.BlobInfo{ID: id, Size: int64(len(b.data)), ModTime: b.modTime}, nil
+}
+
+// Delete удаляет содержимое
+func (s *BlobStore) Delete(id string) error {
+	if err := storage.ValidateBlobID(id); err != nil {
+		return err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	delete(s.blobs, id)
+
+	return nil
+}
+
+// Quarantine убирает содержимое из хранилища, сохраняя его в карантине до завершения процесса
+func (s *BlobStore) Quarantine(id string) error {
+	if err := storage.ValidateBlobID(id); err != nil {
+		return err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	b, exists := s.blobs[id]
+	if !exists {
+		return storage.ErrBlobNotFound
+	}
+	s.quarantined = append(s.quarantined, b)
+	delete(s.blobs, id)
+
+	return nil
+}
+
+// Iterate обходит снимок содержимого, сделанный в начале обхода
+// fn вызывается без блокировки и может обращаться к хранилищу
+func (s *BlobStore) Iterate(fn func(info storage.BlobInfo) error) error {
+	s.mutex.RLock()
+	infos := make([]storage.BlobInfo, 0, len(s.blobs))
+	for id, b := range s.blobs {
+		infos = append(infos, storage.BlobInfo{ID: id, Size: int64(len(b.data)), ModTime: b.modTime})
+	}
+	s.mutex.RUnlock()
+
+	for _, info := range infos {
+		if err := fn(info); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// MetaStore - хранилище метаданных файлов в памяти
+type MetaStore struct {
+	mutex sync.RWMutex               // Мьютекс для thread-safe доступа к метаданным
+	files map[string]*model.FileInfo // Метаданные (ID -> метаданные)
+}
+
+// NewMetaStore создает пустое хранилище метаданных
+func NewMetaStore() *MetaStore {
+	return &MetaStore{
+		files: make(map[string]*model.FileInfo),
+	}
+}
+
+// Load возвращает копию набора метаданных
+func (s *MetaStore) Load() (map[string]*model.FileInfo, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return maps.Clone(s.files), nil
+}
+
+// Put сохраняет метаданные файла
+func (s *MetaStore) Put(info *model.FileInfo) error {
+	if err := storage.ValidateBlobID(info.ID); err != nil {
+		return err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.files[info.ID] = info
+
+	return nil
+}
+
+// Delete удаляет метаданные файла
+func (s *MetaStore) Delete(fileID string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	delete(s.files, fileID)
+	return nil
+}
+
+// Close ничего не делает: хранилищу в памяти нечего освобождать
+func (s *MetaStore) Close() error {
+	return nil
+}
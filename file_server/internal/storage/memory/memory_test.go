@@ -0,0 +1,19 @@
+package memory
+
+import (
+	"file_server/internal/storage"
+	"file_server/internal/storage/storagetest"
+	"testing"
+)
+
+func TestBlobStore(t *testing.T) {
+	storagetest.RunBlobStore(t, func(t *testing.T) storage.BlobStore {
+		return NewBlobStore()
+	})
+}
+
+func TestMetaStore(t *testing.T) {
+	storagetest.RunMetaStore(t, func(t *testing.T) storage.MetaStore {
+		return NewMetaStore()
+	})
+}
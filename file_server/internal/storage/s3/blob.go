@@ -0,0 +1,108 @@
+// blob.go - хранилище содержимого файлов в S3-совместимом бакете
+// Содержимое хранится объектами <prefix>blobs/<id>: ID файла - хэш содержимого,
+// поэтому одинаковое содержимое всегда попадает в один объект
+package s3
+
+import (
+	"file_server/internal/storage"
+	"strings"
+	"time"
+)
+
+const (
+	blobsDir      = "blobs/"      // Подкаталог ключей содержимого
+	quarantineDir = "quarantine/" // Подкаталог поврежденного содержимого (<id>-<время переноса>)
+)
+
+// BlobStore - хранилище содержимого файлов в бакете
+type BlobStore struct {
+	client *client // Клиент бакета
+}
+
+// NewBlobStore создает хранилище содержимого в бакете
+func NewBlobStore(cfg Config) (*BlobStore, error) {
+	c, err := newClient(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &BlobStore{client: c}, nil
+}
+
+// key возвращает ключ объекта содержимого
+func (s *BlobStore) key(id string) (string, error) {
+	if err := storage.ValidateBlobID(id); err != nil {
+		return "", err
+	}
+	return s.client.prefix + blobsDir + id, nil
+}
+
+// Put записывает содержимое объектом (запись объекта в S3 атомарна)
+func (s *BlobStore) Put(id string, data []byte) error {
+	key, err := s.key(id)
+	if err != nil {
+		return err
+	}
+	return s.client.put(key, data)
+}
+
+// Get читает содержимое
+func (s *BlobStore) Get(id string) ([]byte, error) {
+	key, err := s.key(id)
+	if err != nil {
+		return nil, err
+	}
+	return s.client.get(key)
+}
+
+// Stat возвращает размер и время записи содержимого
+func (s *BlobStore) Stat(id string) (storage.BlobInfo, error) {
+	key, err := s.key(id)
+	if err != nil {
+		return storage.BlobInfo{}, err
+	}
+	size, modTime, err := s.client.head(key)
+	if err != nil {
+		return storage.BlobInfo{}, err
+	}
+	return storage.BlobInfo{ID: id, Size: size, ModTime: modTime}, nil
+}
+
+// Delete удаляет содержимое
+func (s *BlobStore) Delete(id string) error {
+	key, err := s.key(id)
+	if err != nil {
+		return err
+	}
+	return s.client.delete(key)
+}
+
+// Quarantine копирует содержимое в подкаталог карантина и удаляет исходный объект
+// Объект копируется через чтение и запись (без серверного копирования, которое поддерживают не все бакеты)
+func (s *BlobStore) Quarantine(id string) error {
+	key, err := s.key(id)
+	if err != nil {
+		return err
+	}
+	data, err := s.client.get(key)
+	if err != nil {
+		return err
+	}
+	target := s.client.prefix + quarantineDir + id + "-" + time.Now().UTC().Format("20060102T150405.000000000")
+	if err := s.client.put(target, data); err != nil {
+		return err
+	}
+	return s.client.delete(key)
+}
+
+// Iterate обходит содержимое постраничным листингом бакета
+// Объекты, ключи которых не являются корректными ID, пропускаются
+func (s *BlobStore) Iterate(fn func(info storage.BlobInfo) error) error {
+	prefix := s.client.prefix + blobsDir
+	return s.client.list(prefix, func(obj object) error {
+		id := strings.TrimPrefix(obj.Key, prefix)
+		if storage.ValidateBlobID(id) != nil {
+			return nil
+		}
+		return fn(storage.BlobInfo{ID: id, Size: obj.Size, ModTime: obj.LastModified})
+	})
+}
@@ -0,0 +1,275 @@
+// client.go - клиент S3-совместимого объектного хранилища
+// Использует path-style адресацию (<endpoint>/<bucket>/<key>), которую поддерживают AWS S3, MinIO, Ceph и другие
+package s3
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"file_server/internal/storage"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultRegion   = "us-east-1"      // Регион по умолчанию (S3-совместимые хранилища обычно принимают любой)
+	defaultPageSize = 1000             // Размер страницы листинга по умолчанию (максимум S3)
+	requestTimeout  = 30 * time.Second // Таймаут одного запроса
+)
+
+// Config - параметры подключения к бакету
+type Config struct {
+	Endpoint  string // Адрес хранилища, например https://s3.eu-central-1.amazonaws.com или http://localhost:9000
+	Bucket    string // Имя бакета
+	Prefix    string // Префикс ключей объектов сервера внутри бакета (может быть пустым)
+	Region    string // Регион для подписи запросов (пусто - us-east-1)
+	AccessKey string // Идентификатор ключа доступа
+	SecretKey string // Секретный ключ доступа
+
+	PageSize   int          // Количество ключей на странице листинга (0 - 1000)
+	HTTPClient *http.Client // HTTP клиент (nil - клиент с таймаутом запроса)
+}
+
+// client - клиент бакета
+type client struct {
+	endpoint *url.URL     // Адрес хранилища
+	bucket   string       // Имя бакета
+	prefix   string       // Префикс ключей (пустой или оканчивается на "/")
+	region   string       // Регион для подписи
+	access   string       // Идентификатор ключа доступа
+	secret   string       // Секретный ключ доступа
+	pageSize int          // Количество ключей на странице листинга
+	http     *http.Client // HTTP клиент
+}
+
+// object - объект из листинга бакета
+type object struct {
+	Key          string    `xml:"Key"`
+	Size         int64     `xml:"Size"`
+	LastModified time.Time `xml:"LastModified"`
+}
+
+// listResult - ответ ListObjectsV2
+type listResult struct {
+	Contents              []object `xml:"Contents"`
+	IsTruncated           bool     `xml:"IsTruncated"`
+	NextContinuationToken string   `xml:"NextContinuationToken"`
+}
+
+// errorResponse - тело ответа S3 с ошибкой
+type errorResponse struct {
+	Code    string `xml:"Code"`
+	Message string `xml:"Message"`
+}
+
+// newClient проверяет конфигурацию и создает клиент
+func newClient(cfg Config) (*client, error) {
+	if cfg.Endpoint == "" || cfg.Bucket == "" {
+		return nil, fmt.Errorf("%w: endpoint and bucket are required", ErrInvalidConfig)
+	}
+	if cfg.AccessKey == "" || cfg.SecretKey == "" {
+		return nil, fmt.Errorf("%w: access key and secret key are required", ErrInvalidConfig)
+	}
+	endpoint, err := url.Parse(strings.TrimSuffix(cfg.Endpoint, "/"))
+	if err != nil || (endpoint.Scheme != "http" && endpoint.Scheme != "https") || endpoint.Host == "" {
+		return nil, fmt.Errorf("%w: invalid endpoint %q", ErrInvalidConfig, cfg.Endpoint)
+	}
+
+	c := &client{
+		endpoint: endpoint,
+		bucket:   cfg.Bucket,
+		prefix:   strings.Trim(cfg.Prefix, "/"),
+		region:   cfg.Region,
+		access:   cfg.AccessKey,
+		secret:   cfg.SecretKey,
+		pageSize: cfg.PageSize,
+		http:     cfg.HTTPClient,
+	}
+	if c.prefix != "" {
+		c.prefix += "/"
+	}
+	if c.region == "" {
+		c.region = defaultRegion
+	}
+	if c.pageSize <= 0 {
+		c.pageSize = defaultPageSize
+	}
+	if c.http == nil {
+		c.http = &http.Client{Timeout: requestTimeout}
+	}
+
+	return c, nil
+}
+
+// objectURL возвращает адрес объекта (или бакета при пустом ключе)
+func (c *client) objectURL(key string, query url.Values) *url.URL {
+	u := *c.endpoint
+	u.Path = strings.TrimSuffix(u.Path, "/") + "/" + c.bucket
+	if key != "" {
+		u.Path += "/" + key
+	}
+	u.RawPath = ""
+	u.RawQuery = query.Encode()
+	return &u
+}
+
+// do выполняет подписанный запрос и возвращает ответ с успешным статусом
+// Ответ 404 возвращается как storage.ErrBlobNotFound, прочие ошибки - с кодом и сообщением S3
+func (c *client) do(method, key string, query url.Values, body []byte) (*http.Response, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+
+	// Пустое тело передается как http.NoBody, иначе клиент отправит его с chunked кодированием
+	var reader io.Reader = http.NoBody
+	if len(body) > 0 {
+		reader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.objectURL(key, query).String(), reader)
+	if err != nil {
+		cancel()
+		return nil, fmt.Errorf("FAILED TO CREATE S3 REQUEST: %w", err)
+	}
+	payloadHash := emptySHA256
+	if body != nil {
+		payloadHash = sha256Hex(body)
+		req.Header.Set("Content-Type", "application/octet-stream")
+	}
+	signRequest(req, payloadHash, c.region, c.access, c.secret, time.Now())
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		cancel()
+		return nil, fmt.Errorf("S3 %s %s FAILED: %w", method, key, err)
+	}
+
+	// Тело ответа должно быть прочитано до отмены контекста
+	resp.Body = &cancelBody{ReadCloser: resp.Body, cancel: cancel}
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return resp, nil
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, storage.ErrBlobNotFound
+	}
+	var s3err errorResponse
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	if xml.Unmarshal(data, &s3err) != nil || s3err.Code == "" {
+		s3err.Code = strconv.Itoa(resp.StatusCode)
+	}
+	return nil, fmt.Errorf("%w: %s %s: %s %s", ErrRequestFailed, method, key, s3err.Code, s3err.Message)
+}
+
+// cancelBody отменяет контекст запроса при закрытии тела ответа
+type cancelBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
+
+// put записывает объект
+func (c *client) put(key string, data []byte) error {
+	if data == nil {
+		data = []byte{}
+	}
+	resp, err := c.do(http.MethodPut, key, nil, data)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// get читает объект
+func (c *client) get(key string) ([]byte, error) {
+	resp, err := c.do(http.MethodGet, key, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("FAILED TO READ S3 OBJECT %s: %w", key, err)
+	}
+	return data, nil
+}
+
+// head возвращает размер и время изменения объекта
+func (c *client) head(key string) (int64, time.Time, error) {
+	resp, err := c.do(http.MethodHead, key, nil, nil)
+	if err != nil {
+		return 0, time.Time{}, err
+	}
+	resp.Body.Close()
+
+	modTime, _ := http.ParseTime(resp.Header.Get("Last-Modified"))
+	return resp.ContentLength, modTime, nil
+}
+
+// delete удаляет объект (S3 не возвращает ошибку для отсутствующего объекта)
+func (c *client) delete(key string) error {
+	resp, err := c.do(http.MethodDelete, key, nil, nil)
+	if errors.Is(err, storage.ErrBlobNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// list обходит объекты с префиксом prefix постранично (ListObjectsV2)
+// Ошибка fn прерывает обход и возвращается из list
+func (c *client) list(prefix string, fn func(obj object) error) error {
+	token := ""
+	for {
+		// Запрос очередной страницы
+		query := url.Values{
+			"list-type": {"2"},
+			"prefix":    {prefix},
+			"max-keys":  {strconv.Itoa(c.pageSize)},
+		}
+		if token != "" {
+			query.Set("continuation-token", token)
+		}
+		resp, err := c.do(http.MethodGet, "", query, nil)
+		if err != nil {
+			return err
+		}
+		var page listResult
+		err = xml.NewDecoder(resp.Body).Decode(&page)
+		resp.Body.Close()
+		if err != nil {
+			return fmt.Errorf("FAILED TO DECODE S3 LISTING: %w", err)
+		}
+
+		// Обработка объектов страницы
+		for _, obj := range page.Contents {
+			if err := fn(obj); err != nil {
+				return err
+			}
+		}
+
+		// Переход к следующей странице
+		if !page.IsTruncated {
+			return nil
+		}
+		if page.NextContinuationToken == "" {
+			return fmt.Errorf("%w: truncated listing without continuation token", ErrRequestFailed)
+		}
+		token = page.NextContinuationToken
+	}
+}
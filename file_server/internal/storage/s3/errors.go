@@ -0,0 +1,8 @@
+package s3
+
+import "errors"
+
+var (
+	ErrInvalidConfig = errors.New("INVALID S3 CONFIG")
+	ErrRequestFailed = errors.New("S3 REQUEST FAILED")
+)
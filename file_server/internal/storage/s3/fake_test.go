@@ -0,0 +1,222 @@
+package s3
+
+import (
+	"crypto/hmac"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Ключи доступа тестового хранилища
+const (
+	fakeAccessKey = "AKIDFAKE"
+	fakeSecretKey = "fake-secret"
+	fakeRegion    = "us-east-1"
+)
+
+// fakeObject - объект тестового хранилища
+type fakeObject struct {
+	data    []byte
+	modTime time.Time
+}
+
+// fakeS3 - S3-совместимый HTTP сервер в памяти процесса
+// Поддерживает path-style PUT, GET, HEAD, DELETE объекта и ListObjectsV2, проверяет подпись SigV4
+type fakeS3 struct {
+	t       *testing.T
+	bucket  string
+	mutex   sync.Mutex
+	objects map[string]fakeObject
+	lists   int // Количество запрошенных страниц листинга
+}
+
+// newFakeS3 запускает тестовое хранилище и возвращает конфигурацию для подключения к нему
+func newFakeS3(t *testing.T, prefix string) (*fakeS3, Config) {
+	t.Helper()
+	fake := &fakeS3{t: t, bucket: "files", objects: make(map[string]fakeObject)}
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	return fake, Config{
+		Endpoint:  server.URL,
+		Bucket:    fake.bucket,
+		Prefix:    prefix,
+		Region:    fakeRegion,
+		AccessKey: fakeAccessKey,
+		SecretKey: fakeSecretKey,
+		PageSize:  2, // Маленькие страницы, чтобы листинг всегда был постраничным
+	}
+}
+
+// keys возвращает отсортированные ключи объектов
+func (f *fakeS3) keys() []string {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	keys := make([]string, 0, len(f.objects))
+	for key := range f.objects {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// ServeHTTP обрабатывает запрос к хранилищу
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		f.fail(w, http.StatusBadRequest, "IncompleteBody", err.Error())
+		return
+	}
+	if code, message := f.verifySignature(r, body); code != "" {
+		f.fail(w, http.StatusForbidden, code, message)
+		return
+	}
+
+	bucket, key, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+	if bucket != f.bucket {
+		f.fail(w, http.StatusNotFound, "NoSuchBucket", bucket)
+		return
+	}
+
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	switch {
+	case key == "" && r.Method == http.MethodGet && r.URL.Query().Get("list-type") == "2":
+		f.list(w, r)
+	case key == "":
+		f.fail(w, http.StatusMethodNotAllowed, "MethodNotAllowed", r.Method)
+	case r.Method == http.MethodPut:
+		f.objects[key] = fakeObject{data: body, modTime: time.Now().UTC()}
+	case r.Method == http.MethodGet || r.Method == http.MethodHead:
+		obj, exists := f.objects[key]
+		if !exists {
+			f.fail(w, http.StatusNotFound, "NoSuchKey", key)
+			return
+		}
+		w.Header().Set("Content-Length", strconv.Itoa(len(obj.data)))
+		w.Header().Set("Last-Modified", obj.modTime.Format(http.TimeFormat))
+		if r.Method == http.MethodGet {
+			w.Write(obj.data)
+		}
+	case r.Method == http.MethodDelete:
+		delete(f.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		f.fail(w, http.StatusMethodNotAllowed, "MethodNotAllowed", r.Method)
+	}
+}
+
+// list отвечает на ListObjectsV2 (продолжение листинга - ключ, после которого начинается страница)
+func (f *fakeS3) list(w http.ResponseWriter, r *http.Request) {
+	f.lists++
+	query := r.URL.Query()
+	maxKeys, err := strconv.Atoi(query.Get("max-keys"))
+	if err != nil || maxKeys <= 0 {
+		maxKeys = 1000
+	}
+
+	var keys []string
+	for key := range f.objects {
+		if strings.HasPrefix(key, query.Get("prefix")) && key > query.Get("continuation-token") {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	var result listResult
+	if len(keys) > maxKeys {
+		keys = keys[:maxKeys]
+		result.IsTruncated = true
+		result.NextContinuationToken = keys[len(keys)-1]
+	}
+	for _, key := range keys {
+		result.Contents = append(result.Contents, object{Key: key, Size: int64(len(f.objects[key].data)), LastModified: f.objects[key].modTime})
+	}
+
+	w.Header().Set("Content-Type", "application/xml")
+	xml.NewEncoder(w).Encode(struct {
+		XMLName xml.Name `xml:"ListBucketResult"`
+		listResult
+	}{listResult: result})
+}
+
+// fail отвечает ошибкой в формате S3
+func (f *fakeS3) fail(w http.ResponseWriter, status int, code, message string) {
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(status)
+	xml.NewEncoder(w).Encode(struct {
+		XMLName xml.Name `xml:"Error"`
+		errorResponse
+	}{errorResponse: errorResponse{Code: code, Message: message}})
+}
+
+// verifySignature проверяет подпись запроса так, как ее проверяет S3: по заголовкам из SignedHeaders
+// Возвращает код ошибки S3 (пусто, если подпись верна)
+func (f *fakeS3) verifySignature(r *http.Request, body []byte) (string, string) {
+	auth := r.Header.Get("Authorization")
+	fields, found := strings.CutPrefix(auth, signAlgorithm+" ")
+	if !found {
+		return "AccessDenied", "missing SigV4 authorization"
+	}
+	params := make(map[string]string)
+	for _, field := range strings.Split(fields, ", ") {
+		name, value, _ := strings.Cut(field, "=")
+		params[name] = value
+	}
+
+	// Тело должно совпадать с подписанным хэшем
+	payloadHash := r.Header.Get("X-Amz-Content-Sha256")
+	if payloadHash != sha256Hex(body) {
+		return "XAmzContentSHA256Mismatch", "payload hash mismatch"
+	}
+
+	// Область действия подписи: ключ/дата/регион/s3/aws4_request
+	scope := strings.Split(params["Credential"], "/")
+	if len(scope) != 5 || scope[0] != fakeAccessKey || scope[2] != fakeRegion || scope[3] != signService {
+		return "InvalidAccessKeyId", params["Credential"]
+	}
+	amzDate := r.Header.Get("X-Amz-Date")
+	if !strings.HasPrefix(amzDate, scope[1]) {
+		return "SignatureDoesNotMatch", "date mismatch"
+	}
+
+	// Канонический запрос по подписанным заголовкам
+	var headers strings.Builder
+	for _, name := range strings.Split(params["SignedHeaders"], ";") {
+		value := r.Header.Get(name)
+		if name == "host" {
+			value = r.Host
+		}
+		headers.WriteString(name + ":" + strings.TrimSpace(value) + "\n")
+	}
+	canonical := strings.Join([]string{
+		r.Method,
+		canonicalURI(r.URL),
+		canonicalQuery(r.URL.Query()),
+		headers.String(),
+		params["SignedHeaders"],
+		payloadHash,
+	}, "\n")
+	stringToSign := strings.Join([]string{signAlgorithm, amzDate, strings.Join(scope[1:], "/"), sha256Hex([]byte(canonical))}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+fakeSecretKey), scope[1])
+	for _, part := range scope[2:] {
+		key = hmacSHA256(key, part)
+	}
+	want := hex.EncodeToString(hmacSHA256(key, stringToSign))
+	if !hmac.Equal([]byte(want), []byte(params["Signature"])) {
+		return "SignatureDoesNotMatch", fmt.Sprintf("canonical request:\n%s", canonical)
+	}
+
+	return "", ""
+}
@@ -0,0 +1,138 @@
+// meta.go - хранилище метаданных файлов в S3-совместимом бакете
+// Метаданные каждого файла хранятся отдельным JSON объектом <prefix>meta/<id>.json,
+// поэтому сервер не хранит состояния локально и может быть перезапущен на другой машине
+package s3
+
+import (
+	"encoding/json"
+	"errors"
+	"file_server/internal/storage"
+	"file_server/pkg/model"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+)
+
+const (
+	metaDir     = "meta/" // Подкаталог ключей метаданных
+	metaExt     = ".json" // Расширение объектов метаданных
+	loadWorkers = 8       // Количество параллельных чтений при загрузке метаданных
+)
+
+// MetaStore - хранилище метаданных файлов в бакете
+type MetaStore struct {
+	client *client // Клиент бакета
+}
+
+// NewMetaStore создает хранилище метаданных в бакете
+func NewMetaStore(cfg Config) (*MetaStore, error) {
+	c, err := newClient(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &MetaStore{client: c}, nil
+}
+
+// key возвращает ключ объекта метаданных файла
+func (s *MetaStore) key(fileID string) string {
+	return s.client.prefix + metaDir + fileID + metaExt
+}
+
+// Load читает метаданные всех файлов
+// Ключи собираются постраничным листингом, объекты читаются параллельно
+func (s *MetaStore) Load() (map[string]*model.FileInfo, error) {
+	// Сбор ключей объектов метаданных
+	prefix := s.client.prefix + metaDir
+	var keys []string
+	err := s.client.list(prefix, func(obj object) error {
+		if id, ok := strings.CutSuffix(strings.TrimPrefix(obj.Key, prefix), metaExt); ok && storage.ValidateBlobID(id) == nil {
+			keys = append(keys, obj.Key)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("FAILED TO LIST METADATA: %w", err)
+	}
+
+	// Параллельное чтение объектов
+	var (
+		mutex    sync.Mutex
+		wg       sync.WaitGroup
+		firstErr error
+		files    = make(map[string]*model.FileInfo, len(keys))
+		jobs     = make(chan string)
+	)
+	for i := 0; i < loadWorkers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for key := range jobs {
+				info, err := s.read(key)
+				mutex.Lock()
+				switch {
+				case err != nil && firstErr == nil:
+					firstErr = err
+				case info != nil:
+					files[info.ID] = info
+				}
+				mutex.Unlock()
+			}
+		}()
+	}
+	for _, key := range keys {
+		jobs <- key
+	}
+	close(jobs)
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	return files, nil
+}
+
+// read читает объект метаданных (nil, если объект удален после листинга или поврежден)
+func (s *MetaStore) read(key string) (*model.FileInfo, error) {
+	data, err := s.client.get(key)
+	if errors.Is(err, storage.ErrBlobNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("FAILED TO READ METADATA %s: %w", key, err)
+	}
+
+	// Поврежденный объект пропускается, как поврежденная запись журнала в бэкенде fs:
+	// файл без метаданных будет заново добавлен по содержимому при сверке в репозитории
+	var info model.FileInfo
+	if err := json.Unmarshal(data, &info); err != nil || info.ID == "" {
+		log.Printf("S3 metadata: skipping corrupt object %s", key)
+		return nil, nil
+	}
+	return &info, nil
+}
+
+// Put записывает метаданные файла объектом
+func (s *MetaStore) Put(info *model.FileInfo) error {
+	if err := storage.ValidateBlobID(info.ID); err != nil {
+		return err
+	}
+	data, err := json.Marshal(info)
+	if err != nil {
+		return fmt.Errorf("FAILED TO ENCODE METADATA: %w", err)
+	}
+	return s.client.put(s.key(info.ID), data)
+}
+
+// Delete удаляет объект метаданных файла
+func (s *MetaStore) Delete(fileID string) error {
+	if storage.ValidateBlobID(fileID) != nil {
+		return nil // Метаданных с таким ID не может существовать
+	}
+	return s.client.delete(s.key(fileID))
+}
+
+// Close ничего не делает: соединения HTTP клиента переиспользуются и не требуют закрытия
+func (s *MetaStore) Close() error {
+	return nil
+}
@@ -0,0 +1,169 @@
+package s3
+
+import (
+	"errors"
+	"file_server/internal/repository/file"
+	"file_server/internal/storage"
+	"file_server/internal/storage/storagetest"
+	"file_server/pkg/model"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestBlobStore(t *testing.T) {
+	storagetest.RunBlobStore(t, func(t *testing.T) storage.BlobStore {
+		_, cfg := newFakeS3(t, "server")
+		store, err := NewBlobStore(cfg)
+		if err != nil {
+			t.Fatalf("NewBlobStore: %v", err)
+		}
+		return store
+	})
+}
+
+func TestMetaStore(t *testing.T) {
+	storagetest.RunMetaStore(t, func(t *testing.T) storage.MetaStore {
+		_, cfg := newFakeS3(t, "server")
+		store, err := NewMetaStore(cfg)
+		if err != nil {
+			t.Fatalf("NewMetaStore: %v", err)
+		}
+		return store
+	})
+}
+
+func TestObjectKeys(t *testing.T) {
+	fake, cfg := newFakeS3(t, "/tenant/files/")
+	blobs, err := NewBlobStore(cfg)
+	if err != nil {
+		t.Fatalf("NewBlobStore: %v", err)
+	}
+	meta, err := NewMetaStore(cfg)
+	if err != nil {
+		t.Fatalf("NewMetaStore: %v", err)
+	}
+
+	// Содержимое адресуется ID (хэшем), метаданные лежат рядом под тем же ID
+	data := []byte("image")
+	id := model.NewBlobID(data)
+	if err := blobs.Put(id, data); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	if err := blobs.Put(id, data); err != nil {
+		t.Fatalf("repeated Put: %v", err)
+	}
+	if err := meta.Put(&model.FileInfo{ID: id, Filename: "a.png"}); err != nil {
+		t.Fatalf("Put metadata: %v", err)
+	}
+
+	want := []string{"tenant/files/blobs/" + id, "tenant/files/meta/" + id + ".json"}
+	if got := fake.keys(); !reflect.DeepEqual(got, want) {
+		t.Errorf("object keys = %v, want %v", got, want)
+	}
+}
+
+func TestListingIsPaginated(t *testing.T) {
+	fake, cfg := newFakeS3(t, "")
+	blobs, err := NewBlobStore(cfg)
+	if err != nil {
+		t.Fatalf("NewBlobStore: %v", err)
+	}
+	for _, id := range []string{"a", "b", "c", "d", "e"} {
+		if err := blobs.Put(id, []byte(id)); err != nil {
+			t.Fatalf("Put: %v", err)
+		}
+	}
+
+	var ids []string
+	if err := blobs.Iterate(func(info storage.BlobInfo) error {
+		ids = append(ids, info.ID)
+		return nil
+	}); err != nil {
+		t.Fatalf("Iterate: %v", err)
+	}
+	if len(ids) != 5 {
+		t.Errorf("Iterate returned %v, want 5 blobs", ids)
+	}
+	if fake.lists != 3 {
+		t.Errorf("listing took %d pages, want 3 pages of 2 keys", fake.lists)
+	}
+}
+
+func TestRejectsBadCredentials(t *testing.T) {
+	_, cfg := newFakeS3(t, "")
+	cfg.SecretKey = "wrong"
+	blobs, err := NewBlobStore(cfg)
+	if err != nil {
+		t.Fatalf("NewBlobStore: %v", err)
+	}
+
+	err = blobs.Put("a", []byte("a"))
+	if !errors.Is(err, ErrRequestFailed) {
+		t.Fatalf("Put with wrong secret: %v, want ErrRequestFailed", err)
+	}
+	if msg := err.Error(); !strings.Contains(msg, "SignatureDoesNotMatch") {
+		t.Errorf("error does not carry S3 error code: %v", msg)
+	}
+}
+
+func TestConfigValidation(t *testing.T) {
+	for _, cfg := range []Config{
+		{Bucket: "b", AccessKey: "a", SecretKey: "s"},
+		{Endpoint: "http://localhost:9000", AccessKey: "a", SecretKey: "s"},
+		{Endpoint: "localhost:9000", Bucket: "b", AccessKey: "a", SecretKey: "s"},
+		{Endpoint: "http://localhost:9000", Bucket: "b"},
+	} {
+		if _, err := NewBlobStore(cfg); !errors.Is(err, ErrInvalidConfig) {
+			t.Errorf("NewBlobStore(%+v): %v, want ErrInvalidConfig", cfg, err)
+		}
+	}
+}
+
+func TestRepositoryIsStateless(t *testing.T) {
+	_, cfg := newFakeS3(t, "server")
+	open := func() *file.Repository {
+		blobs, err := NewBlobStore(cfg)
+		if err != nil {
+			t.Fatalf("NewBlobStore: %v", err)
+		}
+		meta, err := NewMetaStore(cfg)
+		if err != nil {
+			t.Fatalf("NewMetaStore: %v", err)
+		}
+		repo, err := file.NewRepo(blobs, meta)
+		if err != nil {
+			t.Fatalf("NewRepo: %v", err)
+		}
+		return repo
+	}
+
+	// Файл, загруженный через один экземпляр сервера, доступен другому без локального состояния
+	first := open()
+	fileID, err := first.SaveFile("photo.png", []byte("photo"), "alice")
+	if err != nil {
+		t.Fatalf("SaveFile: %v", err)
+	}
+	copyID, err := first.SaveFile("copy.png", []byte("photo"), "bob")
+	if err != nil {
+		t.Fatalf("SaveFile: %v", err)
+	}
+	first.Close()
+
+	got, err := open().GetFile(fileID)
+	if err != nil {
+		t.Fatalf("GetFile on new instance: %v", err)
+	}
+	if got.Info.Filename != "photo.png" || string(got.Data) != "photo" {
+		t.Errorf("GetFile = %q %q", got.Info.Filename, got.Data)
+	}
+
+	// Удаление копии на другом экземпляре не затрагивает общее содержимое
+	second := open()
+	if err := second.DeleteFile(copyID); err != nil {
+		t.Fatalf("DeleteFile: %v", err)
+	}
+	if _, err := second.GetFile(fileID); err != nil {
+		t.Errorf("GetFile after deleting the copy: %v", err)
+	}
+}
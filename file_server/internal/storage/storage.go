@@ -0,0 +1,87 @@
+// storage.go - интерфейсы хранилища файлов
+// Хранилище разделено на две части: содержимое файлов (BlobStore) и их метаданные (MetaStore)
+// Репозиторий работает только через эти интерфейсы, поэтому бэкенд выбирается при запуске сервера
+package storage
+
+import (
+	"file_server/pkg/model"
+	"strings"
+	"time"
+)
+
+// Имена бэкендов хранилища
+const (
+	BackendFS     = "fs"     // Файлы на локальном диске, метаданные в журнале (см. fs)
+	BackendMemory = "memory" // Файлы и метаданные в памяти процесса, для тестов (см. memory)
+	BackendS3     = "s3"     // Файлы и метаданные в S3-совместимом бакете (см. s3)
+)
+
+// BlobInfo - информация о содержимом файла в хранилище
+type BlobInfo struct {
+	ID      string    // ID содержимого (хэш содержимого, на него ссылаются файлы)
+	Size    int64     // Размер в байтах в хранилище (для сжатого содержимого - после сжатия, см. compress)
+	ModTime time.Time // Время последней записи
+}
+
+// BlobStore - хранилище содержимого файлов
+// Реализации должны быть безопасны для конкурентного использования
+type BlobStore interface {
+	// Put атомарно записывает содержимое: читатели видят либо прежнее содержимое, либо новое целиком
+	Put(id string, data []byte) error
+
+	// Get читает содержимое, ErrBlobNotFound - если содержимого нет, ErrBlobCorrupted - если его не удается декодировать
+	Get(id string) ([]byte, error)
+
+	// Stat возвращает информацию о содержимом без чтения, ErrBlobNotFound - если содержимого нет
+	Stat(id string) (BlobInfo, error)
+
+	// Delete удаляет содержимое, удаление отсутствующего содержимого не является ошибкой
+	Delete(id string) error
+
+	// Iterate вызывает fn для каждого сохраненного содержимого в произвольном порядке
+	// Ошибка fn прерывает обход и возвращается из Iterate
+	Iterate(fn func(info BlobInfo) error) error
+}
+
+// TempFileStore - необязательный интерфейс хранилища, в котором прерванные записи оставляют временные файлы
+// Временные файлы не являются содержимым и не обходятся Iterate; удаляет их вызывающий (см. проверку целостности)
+type TempFileStore interface {
+	// TempFiles возвращает временные файлы (ID - путь относительно хранилища), в том числе файлы идущих записей
+	TempFiles() ([]BlobInfo, error)
+
+	// DeleteTempFile удаляет временный файл, удаление отсутствующего файла не является ошибкой
+	DeleteTempFile(id string) error
+}
+
+// Quarantiner - необязательный интерфейс хранилища, умеющего изолировать поврежденное содержимое
+// Изолированное содержимое сохраняется для разбора, но больше не доступно по ID и не обходится Iterate
+type Quarantiner interface {
+	// Quarantine переносит содержимое в карантин, ErrBlobNotFound - если содержимого нет
+	Quarantine(id string) error
+}
+
+// MetaStore - хранилище метаданных файлов
+// Реализации должны быть безопасны для конкурентного использования
+// Переданные метаданные не изменяются вызывающим после сохранения
+type MetaStore interface {
+	// Load возвращает метаданные всех файлов (ID -> метаданные)
+	Load() (map[string]*model.FileInfo, error)
+
+	// Put сохраняет метаданные файла (создание или замена)
+	Put(info *model.FileInfo) error
+
+	// Delete удаляет метаданные файла, удаление отсутствующих метаданных не является ошибкой
+	Delete(fileID string) error
+
+	// Close освобождает ресурсы хранилища
+	Close() error
+}
+
+// ValidateBlobID проверяет, что ID можно использовать как ключ содержимого в любом бэкенде
+// ID не должен быть пустым, содержать разделители пути или начинаться с точки (служебные имена)
+func ValidateBlobID(id string) error {
+	if id == "" || strings.HasPrefix(id, ".") || strings.ContainsAny(id, `/\`) {
+		return ErrInvalidBlobID
+	}
+	return nil
+}
@@ -0,0 +1,337 @@
+// storagetest.go - общий набор тестов соответствия для бэкендов хранилища
+// Каждый бэкенд запускает этот набор в своих тестах, поэтому поведение бэкендов проверяется одинаково
+package storagetest
+
+import (
+	"bytes"
+	"errors"
+	"file_server/internal/storage"
+	"file_server/pkg/model"
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+)
+
+// RunBlobStore проверяет реализацию BlobStore
+// newStore должен возвращать новое пустое хранилище для каждого подтеста
+func RunBlobStore(t *testing.T, newStore func(t *testing.T) storage.BlobStore) {
+	t.Run("PutGet", func(t *testing.T) {
+		store := newStore(t)
+		data := []byte("content")
+		if err := store.Put("a", data); err != nil {
+			t.Fatalf("Put: %v", err)
+		}
+		data[0] = 'X' // Хранилище не должно зависеть от буфера вызывающего
+
+		got, err := store.Get("a")
+		if err != nil {
+			t.Fatalf("Get: %v", err)
+		}
+		if string(got) != "content" {
+			t.Errorf("Get = %q, want %q", got, "content")
+		}
+	})
+
+	t.Run("Overwrite", func(t *testing.T) {
+		store := newStore(t)
+		mustPut(t, store, "a", []byte("first"))
+		mustPut(t, store, "a", []byte("second version"))
+
+		got, err := store.Get("a")
+		if err != nil || string(got) != "second version" {
+			t.Errorf("Get after overwrite = %q, %v", got, err)
+		}
+		info, err := store.Stat("a")
+		if err != nil || info.Size != int64(len("second version")) {
+			t.Errorf("Stat after overwrite = %+v, %v", info, err)
+		}
+	})
+
+	t.Run("Stat", func(t *testing.T) {
+		store := newStore(t)
+		before := time.Now().Add(-time.Second)
+		mustPut(t, store, "a", []byte("12345"))
+
+		info, err := store.Stat("a")
+		if err != nil {
+			t.Fatalf("Stat: %v", err)
+		}
+		if info.ID != "a" || info.Size != 5 {
+			t.Errorf("Stat = %+v, want ID a and size 5", info)
+		}
+		if info.ModTime.Before(before) || info.ModTime.After(time.Now().Add(time.Second)) {
+			t.Errorf("Stat mod time %v is not the time of Put", info.ModTime)
+		}
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		store := newStore(t)
+		if _, err := store.Get("missing"); !errors.Is(err, storage.ErrBlobNotFound) {
+			t.Errorf("Get missing: %v, want ErrBlobNotFound", err)
+		}
+		if _, err := store.Stat("missing"); !errors.Is(err, storage.ErrBlobNotFound) {
+			t.Errorf("Stat missing: %v, want ErrBlobNotFound", err)
+		}
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		store := newStore(t)
+		mustPut(t, store, "a", []byte("a"))
+		if err := store.Delete("a"); err != nil {
+			t.Fatalf("Delete: %v", err)
+		}
+		if _, err := store.Get("a"); !errors.Is(err, storage.ErrBlobNotFound) {
+			t.Errorf("Get after Delete: %v, want ErrBlobNotFound", err)
+		}
+		if err := store.Delete("a"); err != nil {
+			t.Errorf("Delete of missing blob: %v, want nil", err)
+		}
+	})
+
+	t.Run("InvalidID", func(t *testing.T) {
+		store := newStore(t)
+		for _, id := range []string{"", ".hidden", "../escape", "dir/name", `dir\name`} {
+			if err := store.Put(id, []byte("x")); !errors.Is(err, storage.ErrInvalidBlobID) {
+				t.Errorf("Put(%q): %v, want ErrInvalidBlobID", id, err)
+			}
+			if _, err := store.Get(id); !errors.Is(err, storage.ErrInvalidBlobID) {
+				t.Errorf("Get(%q): %v, want ErrInvalidBlobID", id, err)
+			}
+			if _, err := store.Stat(id); !errors.Is(err, storage.ErrInvalidBlobID) {
+				t.Errorf("Stat(%q): %v, want ErrInvalidBlobID", id, err)
+			}
+			if err := store.Delete(id); !errors.Is(err, storage.ErrInvalidBlobID) {
+				t.Errorf("Delete(%q): %v, want ErrInvalidBlobID", id, err)
+			}
+		}
+	})
+
+	t.Run("Iterate", func(t *testing.T) {
+		store := newStore(t)
+		want := map[string]int64{"a": 1, "bb": 2, "ccc": 3}
+		for id, size := range want {
+			mustPut(t, store, id, bytes.Repeat([]byte("x"), int(size)))
+		}
+		mustPut(t, store, "removed", []byte("x"))
+		if err := store.Delete("removed"); err != nil {
+			t.Fatalf("Delete: %v", err)
+		}
+
+		got := make(map[string]int64)
+		if err := store.Iterate(func(info storage.BlobInfo) error {
+			got[info.ID] = info.Size
+			return nil
+		}); err != nil {
+			t.Fatalf("Iterate: %v", err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("Iterate = %v, want %v", got, want)
+		}
+	})
+
+	t.Run("IterateStops", func(t *testing.T) {
+		store := newStore(t)
+		mustPut(t, store, "a", []byte("a"))
+		mustPut(t, store, "b", []byte("b"))
+
+		stop := errors.New("stop")
+		calls := 0
+		err := store.Iterate(func(storage.BlobInfo) error {
+			calls++
+			return stop
+		})
+		if !errors.Is(err, stop) || calls != 1 {
+			t.Errorf("Iterate returned %v after %d calls, want stop after 1", err, calls)
+		}
+	})
+
+	t.Run("Quarantine", func(t *testing.T) {
+		store := newStore(t)
+		quarantiner, ok := store.(storage.Quarantiner)
+		if !ok {
+			t.Skip("store does not support quarantine")
+		}
+		mustPut(t, store, "rotten", []byte("rotten"))
+		mustPut(t, store, "healthy", []byte("healthy"))
+
+		// Содержимое в карантине недоступно по ID и не обходится
+		if err := quarantiner.Quarantine("rotten"); err != nil {
+			t.Fatalf("Quarantine: %v", err)
+		}
+		if _, err := store.Get("rotten"); !errors.Is(err, storage.ErrBlobNotFound) {
+			t.Errorf("Get after Quarantine: %v, want ErrBlobNotFound", err)
+		}
+		var ids []string
+		if err := store.Iterate(func(info storage.BlobInfo) error {
+			ids = append(ids, info.ID)
+			return nil
+		}); err != nil {
+			t.Fatalf("Iterate: %v", err)
+		}
+		if len(ids) != 1 || ids[0] != "healthy" {
+			t.Errorf("Iterate after Quarantine = %v, want [healthy]", ids)
+		}
+		if err := quarantiner.Quarantine("rotten"); !errors.Is(err, storage.ErrBlobNotFound) {
+			t.Errorf("Quarantine of missing content: %v, want ErrBlobNotFound", err)
+		}
+
+		// Содержимое можно записать заново (например, повторной загрузкой)
+		mustPut(t, store, "rotten", []byte("restored"))
+		if got, err := store.Get("rotten"); err != nil || string(got) != "restored" {
+			t.Errorf("Get after re-upload = %q, %v", got, err)
+		}
+	})
+
+	t.Run("Concurrent", func(t *testing.T) {
+		store := newStore(t)
+		var wg sync.WaitGroup
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				id := fmt.Sprintf("blob-%d", i%2)
+				data := bytes.Repeat([]byte{byte('a' + i%2)}, 64<<10)
+				for j := 0; j < 20; j++ {
+					if err := store.Put(id, data); err != nil {
+						t.Errorf("Put: %v", err)
+						return
+					}
+					got, err := store.Get(id)
+					if err != nil || !bytes.Equal(got, data) {
+						t.Errorf("Get(%s) returned partial or foreign content: %v", id, err)
+						return
+					}
+				}
+			}()
+		}
+		wg.Wait()
+	})
+}
+
+// RunMetaStore проверяет реализацию MetaStore
+// newStore должен возвращать новое пустое хранилище для каждого подтеста
+func RunMetaStore(t *testing.T, newStore func(t *testing.T) storage.MetaStore) {
+	t.Run("Empty", func(t *testing.T) {
+		files, err := newStore(t).Load()
+		if err != nil || len(files) != 0 {
+			t.Errorf("Load of new store = %v, %v", files, err)
+		}
+	})
+
+	t.Run("PutUpdateDelete", func(t *testing.T) {
+		store := newStore(t)
+		created := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+		a := &model.FileInfo{ID: "a", Filename: "a.png", CreatedAt: created, UpdatedAt: created, Size: 1}
+		b := &model.FileInfo{ID: "b", Filename: "b.png", CreatedAt: created, UpdatedAt: created, Size: 2}
+		for _, info := range []*model.FileInfo{a, b} {
+			if err := store.Put(info); err != nil {
+				t.Fatalf("Put: %v", err)
+			}
+		}
+
+		updated := *a
+		updated.Palette = []string{"#ff0000"}
+		updated.Variants = []model.Variant{{Kind: "optimized", FileID: "b"}}
+		if err := store.Put(&updated); err != nil {
+			t.Fatalf("Put update: %v", err)
+		}
+		if err := store.Delete("b"); err != nil {
+			t.Fatalf("Delete: %v", err)
+		}
+		if err := store.Delete("missing"); err != nil {
+			t.Errorf("Delete of missing record: %v, want nil", err)
+		}
+
+		files, err := store.Load()
+		if err != nil {
+			t.Fatalf("Load: %v", err)
+		}
+		assertFiles(t, files, []model.FileInfo{updated})
+	})
+
+	t.Run("LoadIsSnapshot", func(t *testing.T) {
+		store := newStore(t)
+		if err := store.Put(&model.FileInfo{ID: "a"}); err != nil {
+			t.Fatalf("Put: %v", err)
+		}
+		files, err := store.Load()
+		if err != nil {
+			t.Fatalf("Load: %v", err)
+		}
+		delete(files, "a") // Изменение результата Load не должно затрагивать хранилище
+
+		if files, _ := store.Load(); len(files) != 1 {
+			t.Errorf("store lost a record after caller modified Load result: %v", files)
+		}
+	})
+
+	t.Run("Concurrent", func(t *testing.T) {
+		store := newStore(t)
+		var wg sync.WaitGroup
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for j := 0; j < 20; j++ {
+					info := &model.FileInfo{ID: fmt.Sprintf("file-%d", i), Size: int64(j)}
+					if err := store.Put(info); err != nil {
+						t.Errorf("Put: %v", err)
+						return
+					}
+				}
+			}()
+		}
+		wg.Wait()
+
+		files, err := store.Load()
+		if err != nil {
+			t.Fatalf("Load: %v", err)
+		}
+		if len(files) != 8 {
+			t.Fatalf("Load returned %d records, want 8", len(files))
+		}
+		for id, info := range files {
+			if info.Size != 19 {
+				t.Errorf("%s has size %d, want the last written 19", id, info.Size)
+			}
+		}
+	})
+}
+
+// mustPut сохраняет содержимое и завершает тест при ошибке
+func mustPut(t *testing.T, store storage.BlobStore, id string, data []byte) {
+	t.Helper()
+	if err := store.Put(id, data); err != nil {
+		t.Fatalf("Put(%s): %v", id, err)
+	}
+}
+
+// assertFiles сравнивает загруженные метаданные с ожидаемыми (время сравнивается через Equal)
+func assertFiles(t *testing.T, files map[string]*model.FileInfo, want []model.FileInfo) {
+	t.Helper()
+	got := make([]model.FileInfo, 0, len(files))
+	for id, info := range files {
+		if id != info.ID {
+			t.Errorf("record %s is stored under key %s", info.ID, id)
+		}
+		got = append(got, *info)
+	}
+	sort.Slice(got, func(i, j int) bool { return got[i].ID < got[j].ID })
+
+	if len(got) != len(want) {
+		t.Fatalf("Load returned %d records, want %d", len(got), len(want))
+	}
+	for i := range want {
+		w, g := want[i], got[i]
+		if !w.CreatedAt.Equal(g.CreatedAt) || !w.UpdatedAt.Equal(g.UpdatedAt) {
+			t.Errorf("%s: times %v/%v, want %v/%v", w.ID, g.CreatedAt, g.UpdatedAt, w.CreatedAt, w.UpdatedAt)
+		}
+		w.CreatedAt, w.UpdatedAt = g.CreatedAt, g.UpdatedAt
+		if !reflect.DeepEqual(w, g) {
+			t.Errorf("%s:\n got %+v\nwant %+v", w.ID, g, w)
+		}
+	}
+}
@@ -0,0 +1,8 @@
+package svg
+
+import "errors"
+
+var (
+	ErrInvalidSVG   = errors.New("INVALID SVG DOCUMENT")
+	ErrTooManyNodes = errors.New("SVG HAS TOO MANY NODES")
+)
@@ -0,0 +1,387 @@
+// sanitize.go - очистка SVG документов от активного содержимого
+// SVG - это XML документ, который может содержать скрипты, обработчики событий и внешние ссылки
+// Документ разбирается через encoding/xml и собирается заново только из разрешенных элементов и атрибутов
+package svg
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"io"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	MaxNodes = 10000 // Максимальное количество элементов в документе
+	MaxDepth = 256   // Максимальная вложенность элементов
+
+	detectTokens = 16 // Количество токенов, просматриваемых при определении SVG по содержимому
+)
+
+// allowedElements - разрешенные элементы (по локальному имени, без учета префикса)
+// Неразрешенный элемент удаляется вместе со всем содержимым
+var allowedElements = toSet(
+	"svg", "g", "defs", "symbol", "use", "a", "title", "desc", "switch",
+	"path", "rect", "circle", "ellipse", "line", "polyline", "polygon",
+	"text", "tspan", "textPath",
+	"linearGradient", "radialGradient", "stop", "pattern", "clipPath", "mask", "marker",
+	"image", "style",
+	"filter", "feBlend", "feColorMatrix", "feComponentTransfer", "feComposite", "feConvolveMatrix",
+	"feDiffuseLighting", "feDisplacementMap", "feDistantLight", "feDropShadow", "feFlood",
+	"feFuncA", "feFuncB", "feFuncG", "feFuncR", "feGaussianBlur", "feMerge", "feMergeNode",
+	"feMorphology", "feOffset", "fePointLight", "feSpecularLighting", "feSpotLight", "feTile", "feTurbulence",
+)
+
+// allowedAttributes - разрешенные атрибуты (по локальному имени, без учета префикса)
+// Объявления пространств имен, обработчики событий и ссылки проверяются отдельно
+var allowedAttributes = toSet(
+	// Общие и структурные
+	"id", "class", "style", "lang", "space", "version", "baseProfile", "viewBox", "preserveAspectRatio",
+	"x", "y", "width", "height", "transform", "href",
+	// Геометрия
+	"d", "pathLength", "points", "cx", "cy", "r", "rx", "ry", "x1", "y1", "x2", "y2", "fx", "fy", "fr",
+	"dx", "dy", "rotate", "textLength", "lengthAdjust", "startOffset", "method", "spacing",
+	// Оформление
+	"fill", "fill-opacity", "fill-rule", "stroke", "stroke-width", "stroke-opacity", "stroke-linecap",
+	"stroke-linejoin", "stroke-miterlimit", "stroke-dasharray", "stroke-dashoffset", "opacity",
+	"color", "display", "visibility", "overflow", "clip-path", "clip-rule", "mask", "filter",
+	"marker-start", "marker-mid", "marker-end", "paint-order", "vector-effect", "shape-rendering",
+	"color-interpolation", "color-interpolation-filters", "image-rendering", "text-rendering",
+	"stop-color", "stop-opacity", "flood-color", "flood-opacity", "lighting-color", "mix-blend-mode",
+	// Текст
+	"font-family", "font-size", "font-style", "font-weight", "font-variant", "font-stretch",
+	"text-anchor", "text-decoration", "dominant-baseline", "alignment-baseline", "baseline-shift",
+	"letter-spacing", "word-spacing", "writing-mode", "direction", "unicode-bidi",
+	// Градиенты, шаблоны, маркеры, маски
+	"gradientUnits", "gradientTransform", "spreadMethod", "offset", "patternUnits",
+	"patternContentUnits", "patternTransform", "clipPathUnits", "maskUnits", "maskContentUnits",
+	"markerUnits", "markerWidth", "markerHeight", "refX", "refY", "orient",
+	// Фильтры
+	"filterUnits", "primitiveUnits", "in", "in2", "result", "mode", "type", "values", "operator",
+	"k1", "k2", "k3", "k4", "stdDeviation", "edgeMode", "radius", "scale", "xChannelSelector",
+	"yChannelSelector", "baseFrequency", "numOctaves", "seed", "stitchTiles", "order", "kernelMatrix",
+	"divisor", "bias", "targetX", "targetY", "preserveAlpha", "surfaceScale", "diffuseConstant",
+	"specularConstant", "specularExponent", "kernelUnitLength", "azimuth", "elevation", "z",
+	"pointsAtX", "pointsAtY", "pointsAtZ", "limitingConeAngle", "tableValues", "slope", "intercept",
+	"amplitude", "exponent",
+)
+
+// safeDataImage - разрешенные встроенные растровые изображения в href элемента image
+var safeDataImage = regexp.MustCompile(`^data:image/(png|jpeg|gif|webp);base64,[A-Za-z0-9+/=\s]*$`)
+
+// cssURL - ссылки url(...) в CSS; разрешены только ссылки на фрагменты документа url(#id)
+var cssURL = regexp.MustCompile(`(?i)url\(\s*['"]?\s*([^'")\s]*)`)
+
+// Экранирование при сборке документа: xml.EscapeText заменяет и переводы строк, что портит форматирование
+var (
+	textEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", "]]>", "]]&gt;")
+	attrEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", `"`, "&quot;", "\n", "&#xA;", "\r", "&#xD;", "\t", "&#x9;")
+)
+
+// IsSVG определяет, является ли файл SVG документом
+// Проверяется расширение имени файла, а для остальных файлов - корневой элемент XML документа
+func IsSVG(filename string, data []byte) bool {
+	if strings.EqualFold(filepath.Ext(filename), ".svg") {
+		return true
+	}
+
+	// Быстрая проверка: XML документ начинается с '<' (после BOM и пробелов)
+	trimmed := bytes.TrimLeft(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf")), " \t\r\n")
+	if !bytes.HasPrefix(trimmed, []byte("<")) {
+		return false
+	}
+
+	// Поиск корневого элемента среди первых токенов
+	decoder := xml.NewDecoder(bytes.NewReader(trimmed))
+	for i := 0; i < detectTokens; i++ {
+		token, err := decoder.RawToken()
+		if err != nil {
+			return false
+		}
+		if start, ok := token.(xml.StartElement); ok {
+			return start.Name.Local == "svg"
+		}
+	}
+	return false
+}
+
+// Sanitize удаляет из SVG документа активное содержимое
+// Удаляются неразрешенные элементы (script, foreignObject и т.п.) вместе с содержимым,
+// обработчики событий on*, неразрешенные атрибуты и внешние ссылки; DOCTYPE и инструкции обработки отбрасываются
+// Возвращает очищенный документ и признак того, что исходный документ был изменен
+// Документы, которые не удалось разобрать или превышающие ограничения, отклоняются
+func Sanitize(data []byte) ([]byte, bool, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	decoder.Strict = true
+
+	var (
+		out      bytes.Buffer
+		modified bool
+		stack    []xml.Name      // Открытые элементы для проверки парности тегов
+		skip     int             // Глубина внутри удаляемого элемента (0 - элемент не удаляется)
+		nodes    int             // Количество элементов в документе
+		rootSeen bool            // Корневой элемент уже встречался
+		style    int             // Глубина стека, на которой открыт элемент style (0 - вне таблицы стилей)
+		css      strings.Builder // Текст таблицы стилей, собранный из всех ее частей (CDATA, текст)
+	)
+
+	for {
+		token, err := decoder.RawToken()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, false, ErrInvalidSVG
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			// Проверка ограничений
+			nodes++
+			if nodes > MaxNodes {
+				return nil, false, ErrTooManyNodes
+			}
+			if len(stack) >= MaxDepth {
+				return nil, false, ErrTooManyNodes
+			}
+
+			// Корневой элемент должен быть svg и единственным
+			if len(stack) == 0 {
+				if rootSeen || t.Name.Local != "svg" {
+					return nil, false, ErrInvalidSVG
+				}
+				rootSeen = true
+			}
+			stack = append(stack, t.Name)
+
+			// Содержимое удаляемого элемента пропускается целиком
+			if skip > 0 {
+				skip++
+				continue
+			}
+			// Внутри таблицы стилей допускается только текст
+			if !allowedElements[t.Name.Local] || style > 0 {
+				modified = true
+				skip = 1
+				continue
+			}
+			if t.Name.Local == "style" {
+				style = len(stack)
+				css.Reset()
+			}
+
+			// Атрибуты элемента
+			out.WriteByte('<')
+			out.WriteString(qualifiedName(t.Name))
+			for _, attr := range t.Attr {
+				if !allowedAttribute(t.Name.Local, attr) {
+					modified = true
+					continue
+				}
+				out.WriteByte(' ')
+				out.WriteString(qualifiedName(attr.Name))
+				out.WriteString(`="`)
+				out.WriteString(attrEscaper.Replace(attr.Value))
+				out.WriteByte('"')
+			}
+			out.WriteByte('>')
+
+		case xml.EndElement:
+			// Проверка парности тегов (RawToken ее не выполняет)
+			if len(stack) == 0 || stack[len(stack)-1] != t.Name {
+				return nil, false, ErrInvalidSVG
+			}
+			stack = stack[:len(stack)-1]
+
+			if skip > 0 {
+				skip--
+				continue
+			}
+
+			// Таблица стилей проверяется целиком: проверка отдельных частей пропускает конструкции,
+			// разделенные CDATA или комментариями. Таблица с внешними ссылками удаляется
+			if style > 0 && len(stack) == style-1 {
+				style = 0
+				if safeCSS(css.String()) {
+					out.WriteString(textEscaper.Replace(css.String()))
+				} else {
+					modified = true
+				}
+			}
+			out.WriteString("</")
+			out.WriteString(qualifiedName(t.Name))
+			out.WriteByte('>')
+
+		case xml.CharData:
+			if skip > 0 {
+				continue
+			}
+			// Текст вне корневого элемента допускается только пробельный
+			if len(stack) == 0 {
+				if len(bytes.TrimSpace(t)) > 0 {
+					return nil, false, ErrInvalidSVG
+				}
+				out.Write(t)
+				continue
+			}
+			// Текст таблицы стилей собирается до закрытия элемента
+			if style > 0 {
+				css.Write(t)
+				continue
+			}
+			out.WriteString(textEscaper.Replace(string(t)))
+
+		case xml.Comment:
+			if skip > 0 {
+				continue
+			}
+			// Комментарий внутри таблицы стилей удаляется: он не должен разделять ее текст
+			if style > 0 {
+				modified = true
+				continue
+			}
+			out.WriteString("<!--")
+			out.Write(t)
+			out.WriteString("-->")
+
+		case xml.ProcInst:
+			// Сохраняется только XML декларация (xml-stylesheet и подобные - внешние ссылки)
+			if t.Target == "xml" && len(stack) == 0 && !rootSeen {
+				out.WriteString("<?xml ")
+				out.Write(t.Inst)
+				out.WriteString("?>")
+				continue
+			}
+			modified = true
+
+		case xml.Directive:
+			// DOCTYPE может объявлять сущности и внешние ресурсы
+			modified = true
+		}
+	}
+
+	// Документ должен содержать корневой элемент и быть завершен
+	if !rootSeen || len(stack) != 0 {
+		return nil, false, ErrInvalidSVG
+	}
+
+	return out.Bytes(), modified, nil
+}
+
+// allowedAttribute проверяет, можно ли сохранить атрибут элемента
+func allowedAttribute(element string, attr xml.Attr) bool {
+	name := attr.Name.Local
+
+	// Объявления пространств имен (xmlns и xmlns:prefix)
+	if (attr.Name.Space == "" && name == "xmlns") || attr.Name.Space == "xmlns" {
+		return true
+	}
+
+	// Обработчики событий запрещены всегда
+	if strings.HasPrefix(strings.ToLower(name), "on") {
+		return false
+	}
+
+	if !allowedAttributes[name] {
+		return false
+	}
+
+	switch name {
+	case "href":
+		// Ссылки (href и xlink:href) - только на фрагменты документа, в image - также встроенные растровые изображения
+		value := strings.TrimSpace(attr.Value)
+		if strings.HasPrefix(value, "#") {
+			return true
+		}
+		return element == "image" && safeDataImage.MatchString(value)
+	case "style":
+		return safeCSS(attr.Value)
+	default:
+		// Ссылки url(...) в атрибутах оформления (fill, filter, mask и т.п.)
+		return safeCSS(attr.Value)
+	}
+}
+
+// safeCSS проверяет, что CSS не содержит внешних ссылок и исполняемых конструкций
+// Разрешены только ссылки на фрагменты документа url(#id)
+// Проверяется CSS без комментариев и с раскрытыми экранированиями (u\72l( - это url()
+func safeCSS(css string) bool {
+	css = normalizeCSS(css)
+	lower := strings.ToLower(css)
+	if strings.Contains(lower, "@import") || strings.Contains(lower, "expression(") ||
+		strings.Contains(lower, "javascript:") || strings.Contains(lower, "behavior:") {
+		return false
+	}
+	for _, match := range cssURL.FindAllStringSubmatch(css, -1) {
+		if !strings.HasPrefix(match[1], "#") {
+			return false
+		}
+	}
+	return true
+}
+
+// normalizeCSS удаляет из CSS комментарии /* */ и раскрывает экранирования (\HHHHHH и \<символ>)
+// Незакрытый комментарий удаляется до конца текста
+func normalizeCSS(css string) string {
+	var out strings.Builder
+	for i := 0; i < len(css); i++ {
+		switch {
+		case strings.HasPrefix(css[i:], "/*"):
+			end := strings.Index(css[i+2:], "*/")
+			if end < 0 {
+				return out.String()
+			}
+			i += 2 + end + 1
+
+		case css[i] == '\\' && i+1 < len(css):
+			// Шестнадцатеричное экранирование: до 6 цифр и необязательный пробел после них
+			j := i + 1
+			for j < len(css) && j-i <= 6 && isHexDigit(css[j]) {
+				j++
+			}
+			if j > i+1 {
+				code, _ := strconv.ParseUint(css[i+1:j], 16, 32)
+				out.WriteRune(rune(code))
+				if j < len(css) && strings.IndexByte(" \t\n\r\f", css[j]) >= 0 {
+					j++
+				}
+				i = j - 1
+				continue
+			}
+			// Экранированный перевод строки - продолжение строки, остальные символы - сами себя
+			if css[i+1] != '\n' {
+				out.WriteByte(css[i+1])
+			}
+			i++
+
+		default:
+			out.WriteByte(css[i])
+		}
+	}
+	return out.String()
+}
+
+// isHexDigit проверяет, является ли символ шестнадцатеричной цифрой
+func isHexDigit(c byte) bool {
+	return '0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F'
+}
+
+// qualifiedName возвращает имя с префиксом пространства имен (RawToken сохраняет префикс в Space)
+func qualifiedName(name xml.Name) string {
+	if name.Space == "" {
+		return name.Local
+	}
+	return name.Space + ":" + name.Local
+}
+
+// toSet создает множество из списка строк
+func toSet(values ...string) map[string]bool {
+	set := make(map[string]bool, len(values))
+	for _, v := range values {
+		set[v] = true
+	}
+	return set
+}
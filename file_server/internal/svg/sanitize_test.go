@@ -0,0 +1,105 @@
+package svg
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// doc оборачивает содержимое в корневой элемент svg
+func doc(body string) string {
+	return `<svg xmlns="http://www.w3.org/2000/svg" xmlns:xlink="http://www.w3.org/1999/xlink">` + body + `</svg>`
+}
+
+func TestSanitizeRemovesActiveContent(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		forbidden []string // Подстроки, которых не должно быть в результате
+	}{
+		{"script", doc(`<script>alert(1)</script><rect/>`), []string{"script", "alert"}},
+		{"foreignObject", doc(`<foreignObject><body xmlns="http://www.w3.org/1999/xhtml"><iframe src="http://evil/"/></body></foreignObject>`), []string{"foreignObject", "iframe", "evil"}},
+		{"event handler", doc(`<rect onclick="alert(1)" onLoad="alert(2)" width="10"/>`), []string{"onclick", "onLoad", "alert"}},
+		{"javascript href", doc(`<a href="javascript:alert(1)"><text>x</text></a>`), []string{"javascript"}},
+		{"external href", doc(`<use xlink:href="http://evil/sprite.svg#icon"/>`), []string{"evil"}},
+		{"external image", doc(`<image href="http://evil/a.png" width="1" height="1"/>`), []string{"evil"}},
+		{"import split by CDATA", doc(`<style>@imp<![CDATA[ort 'http://evil/x.css';]]></style>`), []string{"import", "evil"}},
+		{"import split by comment", doc(`<style>@imp<!-- -->ort 'http://evil/x.css';</style>`), []string{"import", "evil"}},
+		{"import split by CSS comment", doc(`<style>@imp/**/ort 'http://evil/x.css';</style>`), []string{"evil"}},
+		{"escaped url in style element", doc(`<style>rect { fill:u\72l(http://evil/a) }</style>`), []string{"evil"}},
+		{"escaped url in style attribute", doc(`<rect style="fill:u\72l(http://evil/a)"/>`), []string{"evil"}},
+		{"escaped import", doc(`<style>@\69mport 'http://evil/x.css';</style>`), []string{"evil"}},
+		{"escaped url in presentation attribute", doc(`<rect fill="\75rl(http://evil/a)"/>`), []string{"evil"}},
+		{"element inside style", doc(`<style><g/>@import 'http://evil/x.css';</style>`), []string{"evil"}},
+		{"stylesheet instruction", `<?xml-stylesheet href="http://evil/x.css"?>` + doc(`<rect/>`), []string{"evil"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, modified, err := Sanitize([]byte(tt.input))
+			if err != nil {
+				t.Fatalf("Sanitize: %v", err)
+			}
+			if !modified {
+				t.Errorf("modified = false, output %s", out)
+			}
+			for _, s := range tt.forbidden {
+				if strings.Contains(string(out), s) {
+					t.Errorf("output contains %q: %s", s, out)
+				}
+			}
+		})
+	}
+}
+
+func TestSanitizeKeepsSafeContent(t *testing.T) {
+	input := doc(`<defs><linearGradient id="g"><stop offset="0" stop-color="#fff"/></linearGradient></defs>` +
+		`<style><![CDATA[rect { fill: url(#g); }]]></style>` +
+		`<rect width="10" height="10" style="stroke:url(#g)"/><use href="#g"/>`)
+
+	out, modified, err := Sanitize([]byte(input))
+	if err != nil {
+		t.Fatalf("Sanitize: %v", err)
+	}
+	if modified {
+		t.Errorf("safe document reported as modified: %s", out)
+	}
+	for _, s := range []string{"rect { fill: url(#g); }", `style="stroke:url(#g)"`, `<use href="#g">`} {
+		if !strings.Contains(string(out), s) {
+			t.Errorf("output lost %q: %s", s, out)
+		}
+	}
+}
+
+func TestSanitizeRejectsInvalidDocuments(t *testing.T) {
+	tests := map[string]string{
+		"not svg root":  `<html></html>`,
+		"unclosed":      `<svg><g></svg>`,
+		"two roots":     doc(``) + doc(``),
+		"text outside":  doc(``) + `text`,
+		"too deep":      strings.Repeat("<svg>", MaxDepth+1) + strings.Repeat("</svg>", MaxDepth+1),
+		"too many":      doc(strings.Repeat("<g/>", MaxNodes)),
+		"malformed xml": `<svg><rect</svg>`,
+	}
+	for name, input := range tests {
+		if _, _, err := Sanitize([]byte(input)); !errors.Is(err, ErrInvalidSVG) && !errors.Is(err, ErrTooManyNodes) {
+			t.Errorf("%s: Sanitize = %v, want rejection", name, err)
+		}
+	}
+}
+
+func TestNormalizeCSS(t *testing.T) {
+	tests := map[string]string{
+		`u\72l(x)`:        "url(x)",
+		`u\000072 l(x)`:   "url(x)",
+		`\@import`:        "@import",
+		`a/* comment */b`: "ab",
+		`a/* open`:        "a",
+		"a\\\nb":          "ab",
+	}
+	for input, want := range tests {
+		if got := normalizeCSS(input); got != want {
+			t.Errorf("normalizeCSS(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
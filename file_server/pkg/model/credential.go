@@ -0,0 +1,10 @@
+// credential.go - модели учетных данных API клиентов
+package model
+
+// Credential описывает клиента API, определенного по ключу
+type Credential struct {
+	Name      string           `json:"name"`                // Имя клиента (используется в логах и как владелец файлов)
+	Watermark *WatermarkPolicy `json:"watermark,omitempty"` // Принудительный водяной знак для всех выдаваемых изображений
+	Quota     *Quota           `json:"quota,omitempty"`     // Ограничение места для файлов клиента (nil - ограничение по умолчанию)
+	Admin     bool             `json:"admin,omitempty"`     // Доступ к административным вызовам (проверка целостности хранилища)
+}
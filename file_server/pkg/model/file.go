@@ -7,11 +7,60 @@ import "time"
 // FileInfo содержит метаданные файла
 // Используется для хранения информации о файле без его содержимого
 type FileInfo struct {
-	ID        string    `json:"id"`         // Уникальный идентификатор файла (MD5 хэш содержимого)
-	Filename  string    `json:"filename"`   // Оригинальное имя файла
-	CreatedAt time.Time `json:"created_at"` // Время создания файла
-	UpdatedAt time.Time `json:"updated_at"` // Время последнего обновления файла
-	Size      int64     `json:"size"`       // Размер файла в байтах
+	ID        string    `json:"id"`              // Уникальный идентификатор файла (см. id.go)
+	BlobID    string    `json:"blob_id"`         // ID содержимого файла (общего для файлов с одинаковым содержимым)
+	Filename  string    `json:"filename"`        // Оригинальное имя файла
+	Owner     string    `json:"owner,omitempty"` // Имя клиента, загрузившего файл (пусто для анонимных загрузок)
+	CreatedAt time.Time `json:"created_at"`      // Время создания файла
+	UpdatedAt time.Time `json:"updated_at"`      // Время последнего обновления файла
+	Size      int64     `json:"size"`            // Размер файла в байтах
+
+	// Размер содержимого в хранилище (после сжатия, одинаков у файлов с общим содержимым), 0 - совпадает с Size
+	StoredSize int64 `json:"stored_size,omitempty"`
+
+	// Срок хранения: после ExpiresAt файл недоступен и удаляется фоновой очисткой (nil - бессрочно)
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+
+	// Содержимое не совпало со своим хэшем и перенесено в карантин: чтение файла отклоняется до повторной загрузки
+	Corrupted bool `json:"corrupted,omitempty"`
+
+	// Хэши содержимого и прежние ID файла, загруженного до перехода на SHA-256
+	Digests   map[string]string `json:"digests,omitempty"`    // Хэши содержимого (алгоритм -> hex)
+	LegacyIDs []string          `json:"legacy_ids,omitempty"` // Прежние ID, которые остаются псевдонимами файла
+
+	// Характеристики изображения (пустые для файлов, не являющихся изображениями)
+	Format    string   `json:"format,omitempty"`    // Исходный формат изображения (png, jpeg, gif, webp, bmp, tiff, svg)
+	Sanitized bool     `json:"sanitized,omitempty"` // SVG был изменен при очистке от активного содержимого
+	Palette   []string `json:"palette,omitempty"`   // Доминирующие цвета в формате #rrggbb
+	Histogram []uint32 `json:"histogram,omitempty"` // Грубая RGB гистограмма (4x4x4 корзины)
+	BlurHash  string   `json:"blurhash,omitempty"`  // BlurHash для размытой заглушки до загрузки изображения
+	Analyzed  bool     `json:"analyzed,omitempty"`  // Характеристики вычислены (в том числе для файлов, не являющихся изображениями)
+
+	// Характеристики анимации (только для GIF)
+	FrameCount  int   `json:"frame_count,omitempty"`     // Количество кадров
+	FrameDelays []int `json:"frame_delays_ms,omitempty"` // Задержка каждого кадра в миллисекундах
+	DurationMs  int64 `json:"duration_ms,omitempty"`     // Общая длительность анимации в миллисекундах
+
+	// Связь с производными вариантами
+	VariantOf string           `json:"variant_of,omitempty"` // ID исходного файла (для производного варианта)
+	Variants  []Variant        `json:"variants,omitempty"`   // Производные варианты файла
+	Watermark *WatermarkPolicy `json:"watermark,omitempty"`  // Водяной знак, наложенный на вариант
+
+	// История версий: при замене содержимого файл сохраняет свой ID, а прежнее содержимое остается отдельным файлом
+	Version   int           `json:"version,omitempty"`    // Номер текущей версии (0 - файл не заменялся, то же, что 1)
+	VersionOf string        `json:"version_of,omitempty"` // ID файла, прежней версией которого является этот файл
+	Versions  []FileVersion `json:"versions,omitempty"`   // Прежние версии файла (от старых к новым)
+}
+
+// FileVersion описывает прежнюю версию файла
+type FileVersion struct {
+	Version int    `json:"version"` // Номер версии
+	FileID  string `json:"file_id"` // ID файла с содержимым версии
+}
+
+// CurrentVersion возвращает номер версии файла (1 для файлов, которые не заменялись)
+func (info *FileInfo) CurrentVersion() int {
+	return max(info.Version, 1)
 }
 
 // File содержит полную информацию о файле включая содержимое
@@ -24,20 +73,26 @@ type File struct {
 // UploadRequest представляет запрос на загрузку файла
 // Содержит имя файла и его содержимое
 type UploadRequest struct {
-	Filename string // Имя загружаемого файла
-	Data     []byte // Содержимое файла в байтах
+	Filename  string     // Имя загружаемого файла
+	Data      []byte     // Содержимое файла в байтах
+	ExpiresAt *time.Time // Время истечения срока хранения (nil - бессрочно, при замене - срок не меняется)
+	Replace   string     // ID файла, новой версией которого становится загрузка (пусто - новый файл)
 }
 
 // UploadResponse представляет ответ на запрос загрузки файла
 // Содержит уникальный идентификатор сохраненного файла
 type UploadResponse struct {
-	FileID string // Уникальный идентификатор сохраненного файла
+	FileID  string // Уникальный идентификатор сохраненного файла
+	Version int    // Номер версии файла (1 для нового файла)
 }
 
 // GetRequest представляет запрос на получение файла
 // Содержит идентификатор файла для загрузки
 type GetRequest struct {
-	FileID string // Идентификатор файла для загрузки
+	FileID    string           // Идентификатор файла для загрузки
+	Watermark *WatermarkPolicy // Водяной знак, накладываемый при выдаче (nil - без водяного знака)
+	Format    string           // Формат, в который нужно преобразовать изображение (пусто - без преобразования)
+	Version   int              // Номер версии файла (0 - текущая)
 }
 
 // GetResponse представляет ответ на запрос получения файла
@@ -47,6 +102,13 @@ type GetResponse struct {
 	Data     []byte // Содержимое файла в байтах
 }
 
+// ListRequest представляет запрос списка файлов с необязательным фильтром по цвету
+// Если Color пуст, возвращаются все файлы
+type ListRequest struct {
+	Color       string  // Искомый цвет в формате #rrggbb
+	MaxDistance float64 // Максимальное расстояние от цвета до палитры изображения (0 - значение по умолчанию)
+}
+
 // ListResponse представляет ответ на запрос списка файлов
 // Содержит массив метаданных всех файлов
 type ListResponse struct {
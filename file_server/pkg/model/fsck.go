@@ -0,0 +1,55 @@
+// fsck.go - модели отчета проверки целостности хранилища
+package model
+
+import "time"
+
+// Виды нарушений целостности хранилища
+const (
+	FsckMissingBlob   = "missing_blob"   // Файлы ссылаются на содержимое, которого нет в хранилище
+	FsckUnindexedBlob = "unindexed_blob" // Содержимое, на которое не ссылается ни один файл
+	FsckHashMismatch  = "hash_mismatch"  // Содержимое не совпадает со своим ID (повреждено или названо не хэшем)
+	FsckEmptyBlob     = "empty_blob"     // Содержимое нулевого размера (загрузка пустых файлов запрещена)
+	FsckTempFile      = "temp_file"      // Временный файл, оставшийся от прерванной записи
+)
+
+// FsckIssue - найденное нарушение целостности
+type FsckIssue struct {
+	Kind     string   `json:"kind"`               // Вид нарушения (FsckMissingBlob, ...)
+	BlobID   string   `json:"blob_id"`            // ID содержимого (для временного файла - его путь в хранилище)
+	FileIDs  []string `json:"file_ids,omitempty"` // Файлы, ссылающиеся на содержимое
+	Detail   string   `json:"detail"`             // Описание нарушения
+	Repaired bool     `json:"repaired"`           // Нарушение исправлено
+	Action   string   `json:"action,omitempty"`   // Выполненное или предлагаемое исправление
+}
+
+// FsckReport - отчет проверки целостности хранилища
+type FsckReport struct {
+	StartedAt time.Time     `json:"started_at"` // Время начала проверки
+	Duration  time.Duration `json:"duration"`   // Длительность проверки
+	Repair    bool          `json:"repair"`     // Проверка исправляла найденные нарушения
+	Files     int           `json:"files"`      // Проверено файлов (метаданных)
+	Blobs     int           `json:"blobs"`      // Проверено содержимого
+	Issues    []FsckIssue   `json:"issues"`     // Найденные нарушения
+}
+
+// Count возвращает количество нарушений вида kind
+func (r *FsckReport) Count(kind string) int {
+	count := 0
+	for _, issue := range r.Issues {
+		if issue.Kind == kind {
+			count++
+		}
+	}
+	return count
+}
+
+// Repaired возвращает количество исправленных нарушений
+func (r *FsckReport) Repaired() int {
+	count := 0
+	for _, issue := range r.Issues {
+		if issue.Repaired {
+			count++
+		}
+	}
+	return count
+}
@@ -0,0 +1,75 @@
+// id.go - формат идентификаторов файлов и содержимого
+// ID содержимого - адрес содержимого с префиксом алгоритма хэширования ("sha256-<hex>"),
+// чтобы формат можно было сменить снова без неоднозначности
+// ID файла - случайный ID логической записи ("file-<hex>"), несколько файлов могут ссылаться на одно содержимое
+// Файлы, загруженные до разделения файлов и содержимого, имеют ID своего содержимого (SHA-256 или MD5 хэш без префикса)
+// и остаются доступными по нему
+package model
+
+import (
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+)
+
+// FileIDPrefix - префикс ID логических файлов
+const FileIDPrefix = "file-"
+
+// Алгоритмы хэширования содержимого
+const (
+	DigestSHA256 = "sha256" // Текущий алгоритм адресации содержимого
+	DigestMD5    = "md5"    // Устаревший алгоритм (ID без префикса)
+)
+
+// NewBlobID возвращает ID содержимого data
+func NewBlobID(data []byte) string {
+	hash := sha256.Sum256(data)
+	return DigestSHA256 + "-" + hex.EncodeToString(hash[:])
+}
+
+// NewFileID возвращает новый случайный ID логического файла
+func NewFileID() string {
+	var id [16]byte
+	rand.Read(id[:])
+	return FileIDPrefix + hex.EncodeToString(id[:])
+}
+
+// ComputeDigests вычисляет все поддерживаемые хэши содержимого (алгоритм -> hex)
+func ComputeDigests(data []byte) map[string]string {
+	sha := sha256.Sum256(data)
+	md := md5.Sum(data)
+	return map[string]string{
+		DigestSHA256: hex.EncodeToString(sha[:]),
+		DigestMD5:    hex.EncodeToString(md[:]),
+	}
+}
+
+// BlobIDAlgorithm возвращает алгоритм, которым получен ID содержимого
+// Пустая строка означает, что ID не соответствует ни одному известному формату
+func BlobIDAlgorithm(id string) string {
+	if digest, found := strings.CutPrefix(id, DigestSHA256+"-"); found {
+		if isHex(digest, sha256.Size*2) {
+			return DigestSHA256
+		}
+		return ""
+	}
+	if isHex(id, md5.Size*2) {
+		return DigestMD5
+	}
+	return ""
+}
+
+// isHex проверяет, что s состоит из length шестнадцатеричных символов в нижнем регистре
+func isHex(s string, length int) bool {
+	if len(s) != length {
+		return false
+	}
+	for _, c := range s {
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,134 @@
+// image.go - модели запросов и ответов для операций над изображениями
+package model
+
+// FrameRequest представляет запрос на получение кадра анимации
+// Если Poster = true, индекс игнорируется и возвращается кадр-обложка
+type FrameRequest struct {
+	FileID string // Идентификатор файла
+	Index  int    // Номер кадра (с нуля)
+	Poster bool   // Вернуть кадр-обложку для превью
+}
+
+// FrameResponse представляет ответ с кадром анимации в формате PNG
+type FrameResponse struct {
+	Data       []byte // Кадр в формате PNG
+	Index      int    // Номер возвращенного кадра
+	FrameCount int    // Общее количество кадров
+	DelayMs    int    // Задержка кадра в миллисекундах
+}
+
+// SpriteSheetRequest представляет запрос на преобразование GIF в спрайт-лист
+type SpriteSheetRequest struct {
+	FileID  string // Идентификатор файла
+	Columns int    // Количество столбцов (0 - все кадры в одну строку)
+}
+
+// SpriteSheetResponse представляет ответ со спрайт-листом
+type SpriteSheetResponse struct {
+	Data     []byte // Спрайт-лист в формате PNG
+	FrameMap string // JSON карта расположения кадров
+}
+
+// ContactSheetRequest представляет запрос на построение контактного листа
+// Нулевые значения параметров сетки заменяются значениями по умолчанию
+type ContactSheetRequest struct {
+	FileIDs    []string // Идентификаторы изображений в порядке размещения
+	Columns    int      // Количество столбцов (0 - примерно квадратная сетка)
+	CellWidth  int      // Ширина ячейки
+	CellHeight int      // Высота ячейки
+	Padding    int      // Отступ между ячейками
+	Background string   // Цвет фона в формате #rrggbb (пусто - белый)
+	Captions   bool     // Подписывать ячейки именами файлов
+	Format     string   // Формат результата: png или jpeg
+	Crop       string   // Режим заполнения ячеек: fit, center или smart
+}
+
+// ContactSheetResponse представляет ответ с контактным листом
+type ContactSheetResponse struct {
+	Data   []byte     // Изображение контактного листа
+	Format string     // Формат изображения
+	Crops  []CropRect // Использованная область каждого исходного изображения
+}
+
+// CropRect описывает область исходного изображения в его собственных координатах
+type CropRect struct {
+	X      int // Левая граница
+	Y      int // Верхняя граница
+	Width  int // Ширина
+	Height int // Высота
+}
+
+// CropRequest представляет запрос на обрезку изображения под заданные пропорции
+type CropRequest struct {
+	FileID       string // ID изображения
+	AspectWidth  int    // Пропорции области обрезки (0 - по размеру результата или 1:1)
+	AspectHeight int
+	Width        int // Размер результата (0 - по другой стороне или по размеру области)
+	Height       int
+	Mode         string // Режим обрезки: smart или center
+	Format       string // Формат результата: png или jpeg
+}
+
+// CropResponse представляет результат обрезки
+type CropResponse struct {
+	Data   []byte   // Обрезанное изображение
+	Format string   // Формат изображения
+	Crop   CropRect // Выбранная область исходного изображения
+}
+
+// WatermarkPolicy описывает наложение водяного знака
+// Водяной знак - ранее загруженное изображение, на которое ссылается WatermarkFileID
+type WatermarkPolicy struct {
+	WatermarkFileID string  `json:"watermark_file_id"`  // ID изображения водяного знака
+	Position        string  `json:"position,omitempty"` // center, top-left, top-right, bottom-left, bottom-right
+	Opacity         float64 `json:"opacity,omitempty"`  // Непрозрачность от 0 до 1
+	Scale           float64 `json:"scale,omitempty"`    // Ширина относительно ширины изображения (от 0 до 1)
+	Tile            bool    `json:"tile,omitempty"`     // Замостить изображение водяным знаком
+}
+
+// Variant описывает производный файл, созданный из исходного
+type Variant struct {
+	Kind   string `json:"kind"`    // Тип варианта (например, watermark)
+	FileID string `json:"file_id"` // ID производного файла
+}
+
+// WatermarkVariantRequest представляет запрос на сохранение варианта файла с водяным знаком
+type WatermarkVariantRequest struct {
+	FileID    string           // ID исходного файла
+	Watermark *WatermarkPolicy // Политика водяного знака
+}
+
+// CompareRequest представляет запрос на сравнение двух изображений
+type CompareRequest struct {
+	FileIDA     string // ID первого (эталонного) изображения
+	FileIDB     string // ID второго изображения
+	Tolerance   int    // Допустимое отличие канала (0-255), при котором пиксель не считается измененным
+	IncludeDiff bool   // Построить изображение различий
+	Mismatch    string // Поведение при разных размерах: reject или resize
+}
+
+// CompareResponse представляет результат сравнения изображений
+type CompareResponse struct {
+	PSNR          float64 // Пиковое отношение сигнал/шум в дБ (+Inf для идентичных изображений)
+	SSIM          float64 // Индекс структурного сходства
+	ChangedPixels int64   // Количество измененных пикселей
+	TotalPixels   int64   // Общее количество пикселей
+	Diff          []byte  // Изображение различий в формате PNG (пусто, если не запрошено)
+}
+
+// OptimizeRequest представляет запрос на оптимизацию размера изображения
+// Нулевые значения параметров заменяются значениями по умолчанию
+type OptimizeRequest struct {
+	FileID  string  // ID исходного изображения
+	Quality int     // Целевое качество JPEG (1-100)
+	MinSSIM float64 // Минимальный SSIM перекодированного JPEG относительно исходного
+}
+
+// OptimizeResponse представляет результат оптимизации
+type OptimizeResponse struct {
+	VariantID     string // ID оптимизированного варианта (пусто, если уменьшить размер не удалось)
+	Format        string // Формат изображения
+	OriginalSize  int64  // Размер исходного файла в байтах
+	OptimizedSize int64  // Размер оптимизированного варианта в байтах
+	BytesSaved    int64  // Сэкономлено байт
+}
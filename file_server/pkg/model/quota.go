@@ -0,0 +1,33 @@
+// quota.go - модели ограничений и учета занятого места
+package model
+
+// Quota - ограничение занятого места (0 - без ограничения)
+type Quota struct {
+	MaxBytes int64 `json:"max_bytes,omitempty"` // Максимальный объем в байтах
+	MaxFiles int   `json:"max_files,omitempty"` // Максимальное количество файлов
+}
+
+// Usage - занятое место
+type Usage struct {
+	Files int   // Количество файлов
+	Bytes int64 // Объем в байтах
+}
+
+// StorageStats - статистика хранилища
+// Логический объем - размер хранимого содержимого до сжатия, физический - после (одинаковое содержимое учитывается один раз)
+type StorageStats struct {
+	Files         int   // Количество файлов
+	Bytes         int64 // Сумма размеров файлов
+	LogicalBytes  int64 // Объем хранимого содержимого
+	PhysicalBytes int64 // Объем, занятый содержимым в хранилище
+}
+
+// QuotaReport - занятое место и ограничения для клиента и для сервера в целом
+// Объем клиента - сумма размеров его файлов, объем сервера - размер хранимого содержимого (одинаковое содержимое учитывается один раз)
+type QuotaReport struct {
+	Owner      string // Имя клиента (пусто для анонимного клиента)
+	Usage      Usage  // Место, занятое файлами клиента
+	Quota      Quota  // Ограничение клиента
+	TotalUsage Usage  // Место, занятое всеми файлами
+	TotalQuota Quota  // Ограничение сервера
+}